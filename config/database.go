@@ -25,14 +25,24 @@ type DatabaseConfig struct {
 var DBConfig = DatabaseConfig{
 	DatabaseName: "my_manager_db", // Nama database Anda. Sesuaikan jika perlu.
 	Collections: map[string]string{
-		"Businesses":        "businesses",         // Nama koleksi untuk entitas Business
-		"Users":             "users",              // Contoh untuk koleksi Users
-		"Channels":          "channels",           // Contoh untuk koleksi Channels
-		"ChannelCategories": "channel_categories", // Koleksi baru untuk kategori channel
-		"Messages":          "messages",           // Koleksi baru untuk pesan
-		"Databases":         "databases",          // Menambahkan koleksi Database di sini
-		"Roles":             "roles",              // Tambahkan koleksi Roles di sini
-		"ActivityLogs":      "activity_logs",      // Koleksi untuk log aktivitas
+		"Businesses":           "businesses",             // Nama koleksi untuk entitas Business
+		"Users":                "users",                  // Contoh untuk koleksi Users
+		"Channels":             "channels",               // Contoh untuk koleksi Channels
+		"ChannelCategories":    "channel_categories",     // Koleksi baru untuk kategori channel
+		"Messages":             "messages",               // Koleksi baru untuk pesan
+		"Databases":            "databases",              // Menambahkan koleksi Database di sini
+		"Roles":                "roles",                  // Tambahkan koleksi Roles di sini
+		"ActivityLogs":         "activity_logs",          // Koleksi untuk log aktivitas
+		"Documents":            "documents",              // Koleksi untuk dokumen (channel bertipe documents)
+		"Drawings":             "drawings",               // Koleksi untuk gambar/whiteboard (channel bertipe drawings)
+		"Reports":              "reports",                // Koleksi untuk laporan (channel bertipe reports)
+		"DatabaseRows":         "database_rows",          // Koleksi untuk baris data milik Databases (dipisah dari dokumen database)
+		"DatabaseRowRevisions": "database_row_revisions", // Koleksi untuk riwayat perubahan baris database
+		"DatabaseTemplates":    "database_templates",     // Koleksi untuk template skema database per bisnis
+		"DatabaseFiles":        "database_files",         // Koleksi untuk metadata file kolom database bertipe file
+		"DeletionJobs":         "deletion_jobs",          // Koleksi untuk job penghapusan berantai channel dan bisnis
+		"Notifications":        "notifications",          // Koleksi untuk notifikasi in-app (mention) per user
+		"ChannelReadStates":    "channel_read_states",    // Koleksi untuk posisi baca (read cursor) user per channel
 		// Tambahkan koleksi lain di sini sesuai kebutuhan Anda
 	},
 }
@@ -4,11 +4,39 @@ import "time"
 
 // DatabaseColumnRequest merepresentasikan definisi kolom untuk request.
 type DatabaseColumnRequest struct {
-	ID      string   `json:"id,omitempty"`
-	Name    string   `json:"name" validate:"required"`
-	Type    string   `json:"type" validate:"required,oneof=date text select boolean number"` // Tipe kolom yang diizinkan
-	Options []string `json:"options,omitempty"`                                              // Hanya untuk tipe "select"
-	Order   int      `json:"order,omitempty"`
+	ID          string                            `json:"id,omitempty"`
+	Name        string                            `json:"name" validate:"required"`
+	Type        string                            `json:"type" validate:"required,oneof=date text select boolean number formula relation rollup file"` // Tipe kolom yang diizinkan
+	Options     []string                          `json:"options,omitempty"`                                                                           // Hanya untuk tipe "select"
+	Order       int                               `json:"order,omitempty"`
+	Formula     string                            `json:"formula,omitempty"`     // Hanya untuk tipe "formula"
+	Relation    *DatabaseRelationRequest          `json:"relation,omitempty"`    // Hanya untuk tipe "relation"
+	Rollup      *DatabaseRollupRequest            `json:"rollup,omitempty"`      // Hanya untuk tipe "rollup"
+	Constraints *DatabaseColumnConstraintsRequest `json:"constraints,omitempty"` // Batasan nilai kolom
+}
+
+// DatabaseColumnConstraintsRequest merepresentasikan batasan nilai kolom untuk request.
+// Min dan Max berupa angka untuk kolom number atau tanggal (RFC3339/YYYY-MM-DD) untuk kolom date.
+type DatabaseColumnConstraintsRequest struct {
+	Required     bool        `json:"required,omitempty"`
+	Unique       bool        `json:"unique,omitempty"`       // Hanya untuk tipe text, number, date dan select
+	DefaultValue interface{} `json:"defaultValue,omitempty"` // Diisikan ke baris baru yang tidak mengisi kolom
+	Min          interface{} `json:"min,omitempty"`          // Hanya untuk tipe number dan date
+	Max          interface{} `json:"max,omitempty"`          // Hanya untuk tipe number dan date
+	Pattern      string      `json:"pattern,omitempty"`      // Hanya untuk tipe text, regex yang harus cocok dengan seluruh teks
+}
+
+// DatabaseRelationRequest merepresentasikan konfigurasi kolom relation untuk request.
+type DatabaseRelationRequest struct {
+	DatabaseID string `json:"databaseId" validate:"required"` // Database target, harus berada di channel yang sama
+}
+
+// DatabaseRollupRequest merepresentasikan konfigurasi kolom rollup untuk request.
+// RelationColumnID dan TargetColumnID dapat diisi ID atau nama kolom.
+type DatabaseRollupRequest struct {
+	RelationColumnID string `json:"relationColumnId" validate:"required"`                        // Kolom relation di database ini
+	TargetColumnID   string `json:"targetColumnId,omitempty"`                                    // Kolom number di database target; tidak perlu untuk "count"
+	Aggregation      string `json:"aggregation" validate:"required,oneof=count sum avg min max"` // Fungsi agregasi
 }
 
 // SelectOptionResponse merepresentasikan opsi untuk kolom bertipe "select" untuk response.
@@ -46,10 +74,33 @@ type DatabaseUpdateRequest struct {
 
 // DatabaseColumnUpdateRequest merepresentasikan data yang diterima saat memperbarui kolom.
 type DatabaseColumnUpdateRequest struct {
-	Name    string   `json:"name,omitempty" validate:"omitempty,min=1"`
-	Type    string   `json:"type,omitempty" validate:"omitempty,oneof=date text select boolean number"`
-	Options []string `json:"options,omitempty"` // Untuk update massal atau ganti tipe
-	Order   int      `json:"order,omitempty"`
+	Name        string                            `json:"name,omitempty" validate:"omitempty,min=1"`
+	Type        string                            `json:"type,omitempty" validate:"omitempty,oneof=date text select boolean number formula relation rollup file"`
+	Options     []string                          `json:"options,omitempty"` // Untuk update massal atau ganti tipe
+	Order       int                               `json:"order,omitempty"`
+	Formula     string                            `json:"formula,omitempty"`     // Wajib jika tipe diubah menjadi "formula"
+	Relation    *DatabaseRelationRequest          `json:"relation,omitempty"`    // Wajib jika tipe diubah menjadi "relation"
+	Rollup      *DatabaseRollupRequest            `json:"rollup,omitempty"`      // Wajib jika tipe diubah menjadi "rollup"
+	Constraints *DatabaseColumnConstraintsRequest `json:"constraints,omitempty"` // Jika dikirim, menggantikan seluruh batasan kolom; {} menghapusnya
+}
+
+// DatabaseColumnCreateRequest merepresentasikan data yang diterima saat menambahkan kolom ke database.
+// DefaultValue diisikan ke semua baris yang sudah ada; jika kosong, dipakai default sesuai tipe kolom.
+type DatabaseColumnCreateRequest struct {
+	Name         string                            `json:"name" validate:"required"`
+	Type         string                            `json:"type" validate:"required,oneof=date text select boolean number formula relation rollup file"`
+	Options      []string                          `json:"options,omitempty"`  // Hanya untuk tipe "select"
+	Order        int                               `json:"order,omitempty"`    // Jika kosong, kolom ditempatkan di akhir
+	Formula      string                            `json:"formula,omitempty"`  // Hanya untuk tipe "formula"
+	Relation     *DatabaseRelationRequest          `json:"relation,omitempty"` // Hanya untuk tipe "relation"
+	Rollup       *DatabaseRollupRequest            `json:"rollup,omitempty"`   // Hanya untuk tipe "rollup"
+	DefaultValue interface{}                       `json:"defaultValue,omitempty"`
+	Constraints  *DatabaseColumnConstraintsRequest `json:"constraints,omitempty"` // Batasan nilai kolom
+}
+
+// DatabaseColumnReorderRequest merepresentasikan urutan baru seluruh kolom database.
+type DatabaseColumnReorderRequest struct {
+	ColumnIDs []string `json:"columnIds" validate:"required,min=1"` // Harus berisi semua ID kolom, masing-masing tepat sekali
 }
 
 // SelectOptionRequest merepresentasikan data yang diterima saat membuat opsi select baru.
@@ -66,11 +117,43 @@ type SelectOptionUpdateRequest struct {
 
 // DatabaseColumnResponse merepresentasikan definisi kolom untuk response.
 type DatabaseColumnResponse struct {
-	ID      string                 `json:"id"`
-	Name    string                 `json:"name"`
-	Type    string                 `json:"type"`
-	Options []SelectOptionResponse `json:"options,omitempty"`
-	Order   int                    `json:"order"`
+	ID          string                             `json:"id"`
+	Name        string                             `json:"name"`
+	Type        string                             `json:"type"`
+	Options     []SelectOptionResponse             `json:"options,omitempty"`
+	Order       int                                `json:"order"`
+	Formula     string                             `json:"formula,omitempty"`
+	Relation    *DatabaseRelationResponse          `json:"relation,omitempty"`
+	Rollup      *DatabaseRollupResponse            `json:"rollup,omitempty"`
+	Constraints *DatabaseColumnConstraintsResponse `json:"constraints,omitempty"`
+}
+
+// DatabaseColumnConstraintsResponse merepresentasikan batasan nilai kolom untuk response.
+type DatabaseColumnConstraintsResponse struct {
+	Required     bool        `json:"required,omitempty"`
+	Unique       bool        `json:"unique,omitempty"`
+	DefaultValue interface{} `json:"defaultValue,omitempty"`
+	Min          interface{} `json:"min,omitempty"`
+	Max          interface{} `json:"max,omitempty"`
+	Pattern      string      `json:"pattern,omitempty"`
+}
+
+// DatabaseColumnUniqueConflictResponse merepresentasikan nilai kolom unique yang dipakai lebih dari satu baris.
+type DatabaseColumnUniqueConflictResponse struct {
+	Value  string   `json:"value"`
+	RowIDs []string `json:"rowIds"`
+}
+
+// DatabaseRelationResponse merepresentasikan konfigurasi kolom relation untuk response.
+type DatabaseRelationResponse struct {
+	DatabaseID string `json:"databaseId"`
+}
+
+// DatabaseRollupResponse merepresentasikan konfigurasi kolom rollup untuk response.
+type DatabaseRollupResponse struct {
+	RelationColumnID string `json:"relationColumnId"`
+	TargetColumnID   string `json:"targetColumnId,omitempty"`
+	Aggregation      string `json:"aggregation"`
 }
 
 // DatabaseRowValueResponse merepresentasikan nilai-nilai dalam satu baris data untuk response.
@@ -78,8 +161,9 @@ type DatabaseRowValueResponse map[string]interface{}
 
 // DatabaseRowResponse merepresentasikan satu baris data untuk response.
 type DatabaseRowResponse struct {
-	ID     string                   `json:"id"`
-	Values DatabaseRowValueResponse `json:"values"`
+	ID      string                   `json:"id"`
+	Values  DatabaseRowValueResponse `json:"values"`
+	Version int64                    `json:"version"` // Sama dengan ETag baris, kirim lewat If-Match saat update
 }
 
 // DatabaseResponse merepresentasikan data database yang dikirimkan sebagai respons API.
@@ -88,8 +172,228 @@ type DatabaseResponse struct {
 	ChannelID string                   `json:"channelId"`
 	AuthorID  string                   `json:"authorId"`
 	Title     string                   `json:"title"`
+	Version   int64                    `json:"version"` // Sama dengan ETag database, kirim lewat If-Match saat update database atau kolom
 	Columns   []DatabaseColumnResponse `json:"columns"`
 	Rows      []DatabaseRowResponse    `json:"rows"`
 	CreatedAt time.Time                `json:"createdAt"`
 	UpdatedAt time.Time                `json:"updatedAt"`
 }
+
+// DatabaseRowsPageResponse merepresentasikan satu halaman hasil query baris database.
+// NextCursor hanya terisi jika masih ada halaman berikutnya.
+type DatabaseRowsPageResponse struct {
+	Rows       []DatabaseRowResponse `json:"rows"`
+	Total      int                   `json:"total"`          // Jumlah baris yang cocok dengan filter
+	Page       int                   `json:"page,omitempty"` // Kosong jika memakai cursor
+	Limit      int                   `json:"limit"`
+	HasMore    bool                  `json:"hasMore"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+}
+
+// DatabaseImportFieldError menjelaskan kesalahan nilai satu kolom pada baris yang gagal di-import.
+type DatabaseImportFieldError struct {
+	ColumnID   string `json:"columnId"`
+	ColumnName string `json:"columnName,omitempty"`
+	Message    string `json:"message"`
+}
+
+// DatabaseImportRowError menjelaskan satu baris spreadsheet yang gagal di-import.
+// Row adalah nomor baris di file (header = baris 1).
+type DatabaseImportRowError struct {
+	Row    int                        `json:"row"`
+	Errors []DatabaseImportFieldError `json:"errors"`
+}
+
+// DatabaseImportResponse merepresentasikan hasil import CSV/XLSX.
+type DatabaseImportResponse struct {
+	Database       DatabaseResponse         `json:"database"`
+	ImportedRows   int                      `json:"importedRows"`
+	FailedRows     int                      `json:"failedRows"`
+	IgnoredHeaders []string                 `json:"ignoredHeaders,omitempty"`
+	Errors         []DatabaseImportRowError `json:"errors"`
+}
+
+// DatabaseViewFilterCondition merepresentasikan satu kondisi filter view (operator sama seperti parameter filters baris).
+type DatabaseViewFilterCondition struct {
+	ColumnID string      `json:"columnId" validate:"required"`
+	Operator string      `json:"operator" validate:"required"`
+	Value    interface{} `json:"value,omitempty"`
+}
+
+// DatabaseViewFilter merepresentasikan filter view. Match bernilai "all" (default) atau "any".
+type DatabaseViewFilter struct {
+	Match      string                        `json:"match,omitempty" validate:"omitempty,oneof=all any"`
+	Conditions []DatabaseViewFilterCondition `json:"conditions"`
+}
+
+// DatabaseViewSort merepresentasikan satu kriteria pengurutan view.
+type DatabaseViewSort struct {
+	ColumnID  string `json:"columnId" validate:"required"`
+	Direction string `json:"direction,omitempty" validate:"omitempty,oneof=asc desc"`
+}
+
+// DatabaseViewRequest merepresentasikan data yang diterima saat membuat atau memperbarui view database.
+type DatabaseViewRequest struct {
+	Name            string              `json:"name" validate:"required"`
+	Type            string              `json:"type" validate:"required,oneof=table kanban calendar"`
+	Filter          *DatabaseViewFilter `json:"filter,omitempty"`
+	Sort            []DatabaseViewSort  `json:"sort,omitempty"`
+	VisibleColumns  []string            `json:"visibleColumns,omitempty"`  // Kosong berarti semua kolom
+	GroupByColumnID string              `json:"groupByColumnId,omitempty"` // Wajib untuk kanban, kolom select
+	DateColumnID    string              `json:"dateColumnId,omitempty"`    // Wajib untuk calendar, kolom date
+}
+
+// DatabaseViewResponse merepresentasikan view database untuk response.
+type DatabaseViewResponse struct {
+	ID              string              `json:"id"`
+	Name            string              `json:"name"`
+	Type            string              `json:"type"`
+	Filter          *DatabaseViewFilter `json:"filter,omitempty"`
+	Sort            []DatabaseViewSort  `json:"sort,omitempty"`
+	VisibleColumns  []string            `json:"visibleColumns,omitempty"`
+	GroupByColumnID string              `json:"groupByColumnId,omitempty"`
+	DateColumnID    string              `json:"dateColumnId,omitempty"`
+	CreatedAt       time.Time           `json:"createdAt"`
+	UpdatedAt       time.Time           `json:"updatedAt"`
+}
+
+// DatabaseViewGroupResponse merepresentasikan satu kolom kanban. Key adalah ID opsi select,
+// atau string kosong untuk grup baris tanpa opsi. Total adalah jumlah seluruh baris dalam grup.
+type DatabaseViewGroupResponse struct {
+	Key   string                `json:"key"`
+	Label string                `json:"label"`
+	Rows  []DatabaseRowResponse `json:"rows"`
+	Total int                   `json:"total"`
+}
+
+// DatabaseViewDateBucketResponse merepresentasikan baris calendar pada satu tanggal (YYYY-MM-DD, UTC).
+type DatabaseViewDateBucketResponse struct {
+	Date string                `json:"date"`
+	Rows []DatabaseRowResponse `json:"rows"`
+}
+
+// DatabaseViewRowsResponse merepresentasikan baris database yang sudah dibentuk sesuai view.
+// Hanya salah satu dari Table, Groups atau Buckets yang terisi sesuai tipe view.
+type DatabaseViewRowsResponse struct {
+	View    DatabaseViewResponse             `json:"view"`
+	Columns []DatabaseColumnResponse         `json:"columns"`           // Kolom yang terlihat, sesuai urutan tampilan
+	Table   *DatabaseRowsPageResponse        `json:"table,omitempty"`   // View table
+	Groups  []DatabaseViewGroupResponse      `json:"groups,omitempty"`  // View kanban
+	Buckets []DatabaseViewDateBucketResponse `json:"buckets,omitempty"` // View calendar
+	Undated int                              `json:"undated,omitempty"` // View calendar: jumlah baris tanpa tanggal
+}
+
+// DatabaseRowFieldChangeResponse merepresentasikan perubahan nilai satu kolom pada revisi baris.
+type DatabaseRowFieldChangeResponse struct {
+	ColumnID string      `json:"columnId"`
+	OldValue interface{} `json:"oldValue"`
+	NewValue interface{} `json:"newValue"`
+}
+
+// DatabaseRowRevisionResponse merepresentasikan satu revisi baris. Values adalah isi baris setelah perubahan,
+// atau isi baris sesaat sebelum dihapus untuk action "delete".
+type DatabaseRowRevisionResponse struct {
+	ID           string                           `json:"id"`
+	RowID        string                           `json:"rowId"`
+	Action       string                           `json:"action"` // create, update, delete, restore
+	AuthorID     string                           `json:"authorId,omitempty"`
+	Values       DatabaseRowValueResponse         `json:"values"`
+	Changes      []DatabaseRowFieldChangeResponse `json:"changes"`
+	RestoredFrom string                           `json:"restoredFrom,omitempty"` // ID revisi sumber untuk action "restore"
+	CreatedAt    time.Time                        `json:"createdAt"`
+}
+
+// DatabaseRowBatchOperation merepresentasikan satu operasi dalam request batch baris.
+type DatabaseRowBatchOperation struct {
+	Op     string                  `json:"op" validate:"required,oneof=insert update delete"`
+	RowID  string                  `json:"rowId,omitempty"`  // Wajib untuk update dan delete
+	Values DatabaseRowValueRequest `json:"values,omitempty"` // Wajib untuk update, opsional untuk insert
+}
+
+// DatabaseRowBatchRequest merepresentasikan request batch baris. Operasi diterapkan berurutan
+// dalam satu transaksi.
+type DatabaseRowBatchRequest struct {
+	Operations []DatabaseRowBatchOperation `json:"operations" validate:"required,min=1,max=500,dive"`
+}
+
+// DatabaseRowBatchResult merepresentasikan hasil satu operasi batch sesuai indeksnya di request.
+type DatabaseRowBatchResult struct {
+	Index  int                        `json:"index"`
+	Op     string                     `json:"op"`
+	RowID  string                     `json:"rowId,omitempty"` // ID baris baru untuk insert
+	Status string                     `json:"status"`          // applied, invalid, failed, skipped
+	Error  string                     `json:"error,omitempty"`
+	Errors []DatabaseImportFieldError `json:"errors,omitempty"` // Kolom yang nilainya tidak valid
+}
+
+// DatabaseRowBatchResponse merepresentasikan hasil batch baris yang berhasil diterapkan.
+type DatabaseRowBatchResponse struct {
+	Results  []DatabaseRowBatchResult `json:"results"`
+	Database DatabaseResponse         `json:"database"`
+}
+
+// DatabaseDuplicateRequest merepresentasikan request untuk menduplikasi database.
+type DatabaseDuplicateRequest struct {
+	ChannelID   string `json:"channelId,omitempty"` // Kosong berarti channel yang sama dengan database sumber
+	Title       string `json:"title,omitempty"`     // Kosong berarti "<judul sumber> (copy)"
+	IncludeRows bool   `json:"includeRows"`
+}
+
+// DatabaseTemplateCreateRequest merepresentasikan request untuk menyimpan skema database sebagai template.
+type DatabaseTemplateCreateRequest struct {
+	DatabaseID  string `json:"databaseId" validate:"required"`
+	Name        string `json:"name" validate:"required,min=3"`
+	Description string `json:"description,omitempty"`
+}
+
+// DatabaseTemplateInstantiateRequest merepresentasikan request untuk membuat database dari template.
+type DatabaseTemplateInstantiateRequest struct {
+	ChannelID string `json:"channelId" validate:"required"`
+	Title     string `json:"title,omitempty"` // Kosong berarti nama template
+}
+
+// DatabaseTemplateResponse merepresentasikan template database yang dikirimkan sebagai respons API.
+// Kolom relation dengan databaseId kosong merujuk database yang dibuat dari template itu sendiri.
+type DatabaseTemplateResponse struct {
+	ID          string                   `json:"id"`
+	BusinessID  string                   `json:"businessId"`
+	AuthorID    string                   `json:"authorId"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description,omitempty"`
+	Columns     []DatabaseColumnResponse `json:"columns"`
+	Views       []DatabaseViewResponse   `json:"views"`
+	CreatedAt   time.Time                `json:"createdAt"`
+	UpdatedAt   time.Time                `json:"updatedAt"`
+}
+
+// DatabaseFileResponse merepresentasikan metadata file yang diunggah ke kolom database bertipe file.
+type DatabaseFileResponse struct {
+	ID          string    `json:"id"` // Ditulis ke nilai sel kolom file
+	DatabaseID  string    `json:"databaseId"`
+	ColumnID    string    `json:"columnId"`
+	UploaderID  string    `json:"uploaderId"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// DatabaseRealtimeEvent merepresentasikan satu perubahan database yang dikirim lewat WebSocket
+// /ws/databases/:id. Version naik satu untuk setiap event database yang sama, sehingga client dapat
+// mendeteksi event yang terlewat; Epoch berubah setiap server dijalankan ulang.
+type DatabaseRealtimeEvent struct {
+	Type       string      `json:"type"` // row_*, rows_changed, column_*, columns_reordered, select_option_*, file_deleted, database_updated, database_deleted
+	DatabaseID string      `json:"databaseId"`
+	Version    int64       `json:"version"`
+	Epoch      string      `json:"epoch"`
+	ActorID    string      `json:"actorId,omitempty"` // User yang melakukan perubahan
+	Payload    interface{} `json:"payload"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// DatabaseRealtimeRowsPayload adalah payload event rows_changed untuk perubahan banyak baris sekaligus
+// (batch, import, restore revisi, penghapusan file).
+type DatabaseRealtimeRowsPayload struct {
+	Rows          []DatabaseRowResponse `json:"rows"`          // Baris yang dibuat atau diubah, dengan nilai terbaru
+	DeletedRowIDs []string              `json:"deletedRowIds"` // Baris yang dihapus
+}
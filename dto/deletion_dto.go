@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+// DeletionStepResponse merepresentasikan satu langkah penghapusan berantai.
+type DeletionStepResponse struct {
+	Collection string `json:"collection"`                                      // Nama koleksi, misalnya "messages"
+	Action     string `json:"action" enums:"delete,delete_files,detach_users"` // detach_users: bisnis dilepas dari user, user tidak dihapus
+	Planned    int64  `json:"planned"`                                         // Jumlah dokumen yang akan terkena
+	Affected   int64  `json:"affected"`                                        // Jumlah dokumen yang sudah dihapus/diubah
+	Done       bool   `json:"done"`
+}
+
+// DeletionResultResponse merepresentasikan hasil penghapusan berantai channel atau bisnis.
+// Untuk mode "background", status penghapusan dipantau lewat Job.
+type DeletionResultResponse struct {
+	TargetType string                 `json:"targetType" enums:"channel,business"`
+	TargetID   string                 `json:"targetId"`
+	Mode       string                 `json:"mode" enums:"dry_run,transaction,background"`
+	Total      int64                  `json:"total"` // Jumlah seluruh dokumen yang direncanakan terkena
+	Steps      []DeletionStepResponse `json:"steps"`
+	Job        *DeletionJobResponse   `json:"job,omitempty"`
+}
+
+// DeletionJobResponse merepresentasikan job penghapusan yang berjalan di background.
+type DeletionJobResponse struct {
+	ID          string                 `json:"id"`
+	TargetType  string                 `json:"targetType" enums:"channel,business"`
+	TargetID    string                 `json:"targetId"`
+	RequestedBy string                 `json:"requestedBy"`
+	Status      string                 `json:"status" enums:"pending,running,completed,failed"`
+	Steps       []DeletionStepResponse `json:"steps"`
+	Error       string                 `json:"error,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+	CompletedAt *time.Time             `json:"completedAt,omitempty"`
+}
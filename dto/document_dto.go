@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// DocumentContentSubNode merepresentasikan sub-node konten dokumen untuk request dan response.
+type DocumentContentSubNode struct {
+	Type    string                   `json:"type"`
+	Text    string                   `json:"text,omitempty"`
+	Attrs   map[string]interface{}   `json:"attrs,omitempty"`
+	Content []DocumentContentSubNode `json:"content,omitempty"`
+}
+
+// DocumentContentNode merepresentasikan root node konten dokumen untuk request dan response.
+type DocumentContentNode struct {
+	Type    string                   `json:"type"`
+	Attrs   map[string]interface{}   `json:"attrs,omitempty"`
+	Content []DocumentContentSubNode `json:"content,omitempty"`
+}
+
+// DocumentCreateRequest merepresentasikan data yang diterima saat membuat dokumen baru.
+// AuthorID diambil dari token JWT, tidak dari request body.
+type DocumentCreateRequest struct {
+	ChannelID string               `json:"channelId" validate:"required"` // ID channel tempat dokumen berada
+	Title     string               `json:"title" validate:"required,min=3"`
+	Content   *DocumentContentNode `json:"content,omitempty"` // Opsional, default berupa node "doc" kosong
+}
+
+// DocumentUpdateRequest merepresentasikan data yang diterima saat memperbarui dokumen.
+// Content, jika dikirim, akan menggantikan seluruh isi dokumen.
+type DocumentUpdateRequest struct {
+	Title   string               `json:"title,omitempty" validate:"omitempty,min=3"`
+	Content *DocumentContentNode `json:"content,omitempty"`
+}
+
+// DocumentResponse merepresentasikan data dokumen yang dikirimkan sebagai respons API.
+type DocumentResponse struct {
+	ID        string              `json:"id"`
+	ChannelID string              `json:"channelId"`
+	AuthorID  string              `json:"authorId"`
+	Title     string              `json:"title"`
+	Content   DocumentContentNode `json:"content"`
+	CreatedAt time.Time           `json:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
@@ -0,0 +1,83 @@
+package dto
+
+import "time"
+
+// DrawingPointRequest merepresentasikan koordinat titik dalam gambar.
+type DrawingPointRequest struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// DrawingShapeRequest merepresentasikan data yang diterima saat menambahkan shape baru.
+type DrawingShapeRequest struct {
+	Type   string                `json:"type" validate:"required"` // e.g., "rectangle", "arrow"
+	X      int                   `json:"x,omitempty"`
+	Y      int                   `json:"y,omitempty"`
+	Width  int                   `json:"width,omitempty"`
+	Height int                   `json:"height,omitempty"`
+	Fill   string                `json:"fill,omitempty"`
+	Stroke string                `json:"stroke,omitempty"`
+	Text   string                `json:"text,omitempty"`
+	Points []DrawingPointRequest `json:"points,omitempty"` // Untuk type "arrow"
+}
+
+// DrawingShapeUpdateRequest merepresentasikan data yang diterima saat memperbarui shape.
+// Semua field opsional; hanya field yang dikirim yang akan diperbarui.
+type DrawingShapeUpdateRequest struct {
+	Type   *string               `json:"type,omitempty"`
+	X      *int                  `json:"x,omitempty"`
+	Y      *int                  `json:"y,omitempty"`
+	Width  *int                  `json:"width,omitempty"`
+	Height *int                  `json:"height,omitempty"`
+	Fill   *string               `json:"fill,omitempty"`
+	Stroke *string               `json:"stroke,omitempty"`
+	Text   *string               `json:"text,omitempty"`
+	Points []DrawingPointRequest `json:"points,omitempty"` // Jika dikirim, menggantikan seluruh titik
+}
+
+// DrawingShapeReorderRequest merepresentasikan urutan baru shape dalam gambar.
+// ShapeIDs harus berisi seluruh ID shape yang ada, dari layer paling bawah ke paling atas.
+type DrawingShapeReorderRequest struct {
+	ShapeIDs []string `json:"shapeIds" validate:"required,min=1"`
+}
+
+// DrawingCreateRequest merepresentasikan data yang diterima saat membuat gambar baru.
+// AuthorID diambil dari token JWT, tidak dari request body.
+type DrawingCreateRequest struct {
+	ChannelID string                `json:"channelId" validate:"required"`
+	Title     string                `json:"title" validate:"required,min=3"`
+	Version   string                `json:"version,omitempty"` // Default "1.0"
+	Shapes    []DrawingShapeRequest `json:"shapes,omitempty"`
+}
+
+// DrawingUpdateRequest merepresentasikan data yang diterima saat memperbarui metadata gambar.
+type DrawingUpdateRequest struct {
+	Title   string `json:"title,omitempty" validate:"omitempty,min=3"`
+	Version string `json:"version,omitempty"`
+}
+
+// DrawingShapeResponse merepresentasikan shape untuk response.
+type DrawingShapeResponse struct {
+	ID     string                `json:"id"`
+	Type   string                `json:"type"`
+	X      int                   `json:"x,omitempty"`
+	Y      int                   `json:"y,omitempty"`
+	Width  int                   `json:"width,omitempty"`
+	Height int                   `json:"height,omitempty"`
+	Fill   string                `json:"fill,omitempty"`
+	Stroke string                `json:"stroke,omitempty"`
+	Text   string                `json:"text,omitempty"`
+	Points []DrawingPointRequest `json:"points,omitempty"`
+}
+
+// DrawingResponse merepresentasikan data gambar yang dikirimkan sebagai respons API.
+type DrawingResponse struct {
+	ID        string                 `json:"id"`
+	ChannelID string                 `json:"channelId"`
+	AuthorID  string                 `json:"authorId"`
+	Title     string                 `json:"title"`
+	Version   string                 `json:"version"`
+	Shapes    []DrawingShapeResponse `json:"shapes"`
+	CreatedAt time.Time              `json:"createdAt"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
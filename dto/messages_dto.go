@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // MessageMediaMetadataRequest merepresentasikan metadata media untuk request.
 type MessageMediaMetadataRequest struct {
@@ -13,7 +16,7 @@ type MessageMediaMetadataRequest struct {
 // MessageReactionRequest merepresentasikan reaksi untuk request.
 type MessageReactionRequest struct {
 	Emoji  string `json:"emoji" validate:"required"`
-	UserID string `json:"userId" validate:"required"`
+	UserID string `json:"userId,omitempty"` // Diabaikan: user diambil dari koneksi terautentikasi
 }
 
 // MessageCreateRequest merepresentasikan data yang diterima saat membuat pesan baru.
@@ -38,13 +41,13 @@ type MessageUpdateRequest struct {
 // MessageReactionAddRequest merepresentasikan data untuk menambahkan reaksi.
 type MessageReactionAddRequest struct {
 	Emoji  string `json:"emoji" validate:"required"`
-	UserID string `json:"userId" validate:"required"`
+	UserID string `json:"userId,omitempty"` // Diabaikan: user diambil dari koneksi terautentikasi
 }
 
 // MessageReactionRemoveRequest merepresentasikan data untuk menghapus reaksi.
 type MessageReactionRemoveRequest struct {
 	Emoji  string `json:"emoji" validate:"required"`
-	UserID string `json:"userId" validate:"required"`
+	UserID string `json:"userId,omitempty"` // Diabaikan: user diambil dari koneksi terautentikasi
 }
 
 // MessageResponse merepresentasikan data pesan yang dikirimkan sebagai respons API.
@@ -60,4 +63,61 @@ type MessageResponse struct {
 	UpdatedAt     *time.Time                   `json:"updatedAt,omitempty"`
 	IsPinned      bool                         `json:"isPinned"`
 	Reactions     []MessageReactionRequest     `json:"reactions"`
+	ParentID      string                       `json:"parentId,omitempty"`
+	ReplyCount    int64                        `json:"replyCount"`
+	LastReplyAt   *time.Time                   `json:"lastReplyAt,omitempty"`
+	Mentions      *MessageMentionsResponse     `json:"mentions,omitempty"`
+}
+
+// MessageMentionsResponse merepresentasikan mention yang tersimpan pada pesan.
+type MessageMentionsResponse struct {
+	UserIDs []string `json:"userIds"`
+	RoleIDs []string `json:"roleIds"`
+	Channel bool     `json:"channel"`
+}
+
+// MessageReplyCreateRequest merepresentasikan data yang diterima saat membalas pesan di dalam thread.
+type MessageReplyCreateRequest struct {
+	ParentID      string                       `json:"parentId" validate:"required"`
+	Content       string                       `json:"content,omitempty"`
+	MessageType   string                       `json:"messageType" validate:"required" enums:"text,image,file,voice"`
+	MediaPath     string                       `json:"mediaPath,omitempty"`
+	MediaMetadata *MessageMediaMetadataRequest `json:"mediaMetadata,omitempty"`
+}
+
+// MessageThreadResponse merepresentasikan satu halaman thread: pesan utama beserta balasannya.
+type MessageThreadResponse struct {
+	Parent  MessageResponse   `json:"parent"`
+	Replies []MessageResponse `json:"replies"`
+	HasMore bool              `json:"hasMore"`
+}
+
+// MessageReplyEventResponse merepresentasikan event balasan baru beserta pesan utama yang sudah diperbarui.
+type MessageReplyEventResponse struct {
+	Reply  MessageResponse `json:"reply"`
+	Parent MessageResponse `json:"parent"`
+}
+
+// MessageSocketFrame merepresentasikan frame yang dikirim client melalui WebSocket pesan.
+// ID opsional dan dikembalikan apa adanya pada balasan serta error untuk frame tersebut.
+type MessageSocketFrame struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id,omitempty"`
+	ChannelID string          `json:"channelId,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+// MessageSocketEvent merepresentasikan event yang dikirim server melalui WebSocket pesan.
+// ID hanya terisi pada balasan langsung untuk frame client; event broadcast tidak membawa ID.
+type MessageSocketEvent struct {
+	Type      string      `json:"type"`
+	ID        string      `json:"id,omitempty"`
+	ChannelID string      `json:"channelId,omitempty"`
+	Payload   interface{} `json:"payload"`
+}
+
+// MessageSubscriptionResponse merepresentasikan hasil subscribe atau unsubscribe channel.
+type MessageSubscriptionResponse struct {
+	ChannelID string   `json:"channelId"`
+	Channels  []string `json:"channels"` // Semua channel yang sedang di-subscribe koneksi ini
 }
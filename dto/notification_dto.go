@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// NotificationResponse merepresentasikan data notifikasi yang dikirimkan sebagai respons API dan event WebSocket.
+type NotificationResponse struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"userId"`
+	ActorID     string     `json:"actorId"`
+	Type        string     `json:"type" enums:"mention"`
+	MentionKind string     `json:"mentionKind,omitempty" enums:"user,role,channel"`
+	BusinessID  string     `json:"businessId"`
+	ChannelID   string     `json:"channelId"`
+	MessageID   string     `json:"messageId"`
+	ParentID    string     `json:"parentId,omitempty"`
+	Preview     string     `json:"preview"`
+	IsRead      bool       `json:"isRead"`
+	ReadAt      *time.Time `json:"readAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// NotificationListResponse merepresentasikan satu halaman notifikasi beserta jumlah yang belum dibaca.
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	UnreadCount   int64                  `json:"unreadCount"`
+}
+
+// NotificationReadAllResponse merepresentasikan hasil menandai semua notifikasi sebagai dibaca.
+type NotificationReadAllResponse struct {
+	Updated     int64 `json:"updated"`
+	UnreadCount int64 `json:"unreadCount"`
+}
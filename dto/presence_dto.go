@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// PresenceResponse merepresentasikan status presence seorang user.
+type PresenceResponse struct {
+	UserID   string     `json:"userId"`
+	Username string     `json:"username,omitempty"`
+	Avatar   string     `json:"avatar,omitempty"`
+	Status   string     `json:"status" enums:"online,idle,offline"`
+	Since    *time.Time `json:"since,omitempty"` // Waktu status terakhir berubah; kosong jika user belum terhubung sejak server berjalan
+}
+
+// BusinessPresenceResponse merepresentasikan presence semua anggota sebuah bisnis.
+type BusinessPresenceResponse struct {
+	BusinessID string             `json:"businessId"`
+	Members    []PresenceResponse `json:"members"`
+}
+
+// TypingResponse merepresentasikan event typing_started dan typing_stopped.
+type TypingResponse struct {
+	UserID    string     `json:"userId"`
+	ChannelID string     `json:"channelId"`
+	ParentID  string     `json:"parentId,omitempty"`               // Diisi jika user mengetik di thread
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`              // Hanya untuk typing_started
+	Reason    string     `json:"reason,omitempty" enums:"expired"` // Hanya untuk typing_stopped yang terjadi otomatis
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// ChannelUnreadResponse merepresentasikan posisi baca dan hitungan belum dibaca user pada satu channel.
+type ChannelUnreadResponse struct {
+	ChannelID         string     `json:"channelId"`
+	UnreadCount       int64      `json:"unreadCount"`
+	MentionCount      int64      `json:"mentionCount"`
+	LastReadMessageID string     `json:"lastReadMessageId,omitempty"`
+	LastReadAt        *time.Time `json:"lastReadAt,omitempty"`
+}
+
+// BusinessUnreadResponse merepresentasikan hitungan belum dibaca untuk semua channel sebuah bisnis.
+type BusinessUnreadResponse struct {
+	BusinessID    string                  `json:"businessId"`
+	TotalUnread   int64                   `json:"totalUnread"`
+	TotalMentions int64                   `json:"totalMentions"`
+	Channels      []ChannelUnreadResponse `json:"channels"`
+}
@@ -0,0 +1,101 @@
+package dto
+
+import "time"
+
+// ReportVisualizationConfigRequest merepresentasikan konfigurasi visualisasi laporan pada request.
+// Skema ChartConfig, CardConfig, TableConfig dan FilterConfig dijelaskan di service/report_service.go.
+type ReportVisualizationConfigRequest struct {
+	ReportType       string                 `json:"reportType" validate:"required"`       // e.g., "card", "bar_chart", "table"
+	SourceDatabaseID string                 `json:"sourceDatabaseId" validate:"required"` // ID database sumber data
+	ChartConfig      map[string]interface{} `json:"chartConfig,omitempty"`
+	CardConfig       map[string]interface{} `json:"cardConfig,omitempty"`
+	TableConfig      map[string]interface{} `json:"tableConfig,omitempty"`
+	FilterConfig     map[string]interface{} `json:"filterConfig,omitempty"`
+}
+
+// ReportCreateRequest merepresentasikan data yang diterima saat membuat laporan baru.
+// AuthorID diambil dari token JWT, tidak dari request body.
+type ReportCreateRequest struct {
+	ChannelID     string                           `json:"channelId" validate:"required"`
+	Title         string                           `json:"title" validate:"required,min=3"`
+	Configuration ReportVisualizationConfigRequest `json:"configuration" validate:"required"`
+}
+
+// ReportUpdateRequest merepresentasikan data yang diterima saat memperbarui laporan.
+// Configuration, jika dikirim, akan menggantikan seluruh konfigurasi laporan.
+type ReportUpdateRequest struct {
+	Title         string                            `json:"title,omitempty" validate:"omitempty,min=3"`
+	Configuration *ReportVisualizationConfigRequest `json:"configuration,omitempty"`
+}
+
+// ReportVisualizationConfigResponse merepresentasikan konfigurasi visualisasi laporan pada response.
+type ReportVisualizationConfigResponse struct {
+	ReportType       string                 `json:"reportType"`
+	SourceDatabaseID string                 `json:"sourceDatabaseId"`
+	ChartConfig      map[string]interface{} `json:"chartConfig,omitempty"`
+	CardConfig       map[string]interface{} `json:"cardConfig,omitempty"`
+	TableConfig      map[string]interface{} `json:"tableConfig,omitempty"`
+	FilterConfig     map[string]interface{} `json:"filterConfig,omitempty"`
+}
+
+// ReportResponse merepresentasikan data laporan yang dikirim sebagai response.
+type ReportResponse struct {
+	ID            string                            `json:"id"`
+	ChannelID     string                            `json:"channelId"`
+	AuthorID      string                            `json:"authorId"`
+	Title         string                            `json:"title"`
+	Configuration ReportVisualizationConfigResponse `json:"configuration"`
+	CreatedAt     time.Time                         `json:"createdAt"`
+	UpdatedAt     time.Time                         `json:"updatedAt"`
+}
+
+// ReportChartSeries adalah satu seri data chart, satu nilai untuk setiap kategori.
+// Nilai null berarti agregasi tidak memiliki data (mis. avg tanpa angka).
+type ReportChartSeries struct {
+	Name        string     `json:"name"`
+	ColumnID    string     `json:"columnId,omitempty"`
+	Aggregation string     `json:"aggregation"`
+	Data        []*float64 `json:"data"`
+}
+
+// ReportChartData adalah hasil evaluasi laporan bertipe chart.
+type ReportChartData struct {
+	GroupBy    string              `json:"groupBy"`
+	Categories []string            `json:"categories"`
+	Series     []ReportChartSeries `json:"series"`
+}
+
+// ReportCardValue adalah satu nilai yang ditampilkan pada laporan bertipe card.
+type ReportCardValue struct {
+	Label       string   `json:"label"`
+	ColumnID    string   `json:"columnId,omitempty"`
+	Aggregation string   `json:"aggregation"`
+	Value       *float64 `json:"value"`
+}
+
+// ReportTableColumn adalah definisi kolom pada hasil laporan bertipe table.
+type ReportTableColumn struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ReportTableData adalah hasil evaluasi laporan bertipe table.
+type ReportTableData struct {
+	Columns []ReportTableColumn      `json:"columns"`
+	Rows    []map[string]interface{} `json:"rows"`
+}
+
+// ReportDataResponse merepresentasikan hasil evaluasi sebuah laporan terhadap database sumbernya.
+// Hanya salah satu dari Chart, Cards atau Table yang terisi, sesuai ReportType.
+type ReportDataResponse struct {
+	ReportID         string            `json:"reportId"`
+	ReportType       string            `json:"reportType"`
+	SourceDatabaseID string            `json:"sourceDatabaseId"`
+	TotalRows        int               `json:"totalRows"`   // Jumlah baris di database sumber
+	MatchedRows      int               `json:"matchedRows"` // Jumlah baris setelah filter diterapkan
+	Chart            *ReportChartData  `json:"chart,omitempty"`
+	Cards            []ReportCardValue `json:"cards,omitempty"`
+	Table            *ReportTableData  `json:"table,omitempty"`
+	GeneratedAt      time.Time         `json:"generatedAt"`
+}
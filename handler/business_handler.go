@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -30,13 +31,15 @@ type BusinessHandler interface {
 type businessHandlerImpl struct {
 	repo               repository.BusinessRepository
 	activityLogService service.ActivityLogService
+	deletionService    service.DeletionService
 }
 
 // NewBusinessHandler membuat instance baru dari BusinessHandler.
-func NewBusinessHandler(repo repository.BusinessRepository, activityLogService service.ActivityLogService) BusinessHandler {
+func NewBusinessHandler(repo repository.BusinessRepository, activityLogService service.ActivityLogService, deletionService service.DeletionService) BusinessHandler {
 	return &businessHandlerImpl{
 		repo:               repo,
 		activityLogService: activityLogService,
+		deletionService:    deletionService,
 	}
 }
 
@@ -285,12 +288,15 @@ func (h *businessHandlerImpl) UpdateBusiness(c *fiber.Ctx) error {
 }
 
 // @Summary Delete a business by ID
-// @Description Deletes a business by its ID.
+// @Description Deletes a business together with its channels (and everything inside them), channel categories, roles and database templates, and removes the business from its users. Small deletions run in a single transaction and return 200; large ones (or on a MongoDB server without transactions) run as a resumable background job and return 202 with the job. Use dryRun=true to only count what would be removed.
 // @Tags Businesses
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "Business ID"
-// @Success 200 {object} utils.APIResponse "Successfully deleted business"
+// @Param dryRun query bool false "Only report what would be removed"
+// @Success 200 {object} utils.APIResponse{data=dto.DeletionResultResponse} "Successfully deleted business, or dry-run report"
+// @Success 202 {object} utils.APIResponse{data=dto.DeletionResultResponse} "Deletion started as a background job"
+// @Failure 403 {object} utils.APIResponse "Forbidden - Only the business owner can delete the business"
 // @Failure 404 {object} utils.APIResponse "Not Found - Business not found"
 // @Failure 500 {object} utils.APIResponse "Internal Server Error"
 // @Router /businesses/{id} [delete]
@@ -310,7 +316,8 @@ func (h *businessHandlerImpl) DeleteBusiness(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "ID tidak valid", err.Error())
 	}
 
-	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	// Penghapusan berantai dapat menyentuh banyak koleksi
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
 	defer cancel()
 
 	// Get business before deleting to log its name
@@ -324,21 +331,32 @@ func (h *businessHandlerImpl) DeleteBusiness(c *fiber.Ctx) error {
 	if businessToDelete == nil {
 		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Bisnis tidak ditemukan", nil)
 	}
+	// Hanya pemilik bisnis yang boleh menghapus (atau melihat dry run) seluruh isi bisnis
+	if businessToDelete.OwnerID != userID {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Hanya pemilik bisnis yang dapat menghapus bisnis ini", nil)
+	}
 
-	if err := h.repo.DeleteBusiness(ctx, objectID); err != nil {
-		// Khusus untuk error mongo.ErrNoDocuments dari repository DeleteBusiness
-		if err == mongo.ErrNoDocuments {
+	requestedBy, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "User ID tidak valid", err.Error())
+	}
+	dryRun := c.QueryBool("dryRun")
+	result, err := h.deletionService.DeleteBusiness(ctx, objectID, requestedBy, dryRun)
+	if err != nil {
+		if errors.Is(err, service.ErrDeletionTargetNotFound) {
 			utils.LogWarning("Bisnis dengan ID %s tidak ditemukan untuk dihapus (handler)", id)
 			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Bisnis tidak ditemukan", nil)
 		}
-		utils.LogError(err, "Gagal menghapus bisnis di repository: %s", id)
+		utils.LogError(err, "Gagal menghapus bisnis beserta isinya: %s", id)
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal menghapus bisnis", err.Error())
 	}
 
 	// Log activity
-	go h.activityLogService.LogActivity(context.Background(), userID, fmt.Sprintf("Deleted business: %s", businessToDelete.Name), c.Method(), c.Path(), fiber.StatusOK, c.IP())
+	if !dryRun {
+		go h.activityLogService.LogActivity(context.Background(), userID, fmt.Sprintf("Deleted business: %s (mode: %s)", businessToDelete.Name, result.Mode), c.Method(), c.Path(), fiber.StatusOK, c.IP())
+	}
 
-	return utils.SendSuccessResponse(c, fiber.StatusOK, "Bisnis berhasil dihapus", nil)
+	return sendDeletionResult(c, service.DeletionTargetBusiness, objectID, result, "Bisnis berhasil dihapus")
 }
 
 /*
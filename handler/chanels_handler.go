@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -30,14 +31,18 @@ type ChannelHandler interface {
 // channelHandlerImpl adalah implementasi dari ChannelHandler.
 type channelHandlerImpl struct {
 	repo               repository.ChannelRepository
+	businessRepo       repository.BusinessRepository
 	activityLogService service.ActivityLogService
+	deletionService    service.DeletionService
 }
 
 // NewChannelHandler membuat instance baru dari ChannelHandler.
-func NewChannelHandler(repo repository.ChannelRepository, activityLogService service.ActivityLogService) ChannelHandler {
+func NewChannelHandler(repo repository.ChannelRepository, businessRepo repository.BusinessRepository, activityLogService service.ActivityLogService, deletionService service.DeletionService) ChannelHandler {
 	return &channelHandlerImpl{
 		repo:               repo,
+		businessRepo:       businessRepo,
 		activityLogService: activityLogService,
+		deletionService:    deletionService,
 	}
 }
 
@@ -310,13 +315,16 @@ func (h *channelHandlerImpl) UpdateChannel(c *fiber.Ctx) error {
 }
 
 // @Summary Delete a channel by ID
-// @Description Deletes a channel by its ID.
+// @Description Deletes a channel together with its messages, databases (rows, revisions and files), documents, drawings and reports. Small deletions run in a single transaction and return 200; large ones (or on a MongoDB server without transactions) run as a resumable background job and return 202 with the job. Use dryRun=true to only count what would be removed.
 // @Tags Channels
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "Channel ID"
-// @Success 200 {object} utils.APIResponse "Successfully deleted channel"
+// @Param dryRun query bool false "Only report what would be removed"
+// @Success 200 {object} utils.APIResponse{data=dto.DeletionResultResponse} "Successfully deleted channel, or dry-run report"
+// @Success 202 {object} utils.APIResponse{data=dto.DeletionResultResponse} "Deletion started as a background job"
 // @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID"
+// @Failure 403 {object} utils.APIResponse "Forbidden - Only the business owner can delete the channel"
 // @Failure 404 {object} utils.APIResponse "Not Found - Channel not found"
 // @Failure 500 {object} utils.APIResponse "Internal Server Error"
 // @Router /channels/{id} [delete]
@@ -336,7 +344,8 @@ func (h *channelHandlerImpl) DeleteChannel(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "ID tidak valid", err.Error())
 	}
 
-	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	// Penghapusan berantai dapat menyentuh banyak koleksi
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
 	defer cancel()
 
 	// Get channel before deleting to log its name
@@ -351,18 +360,34 @@ func (h *channelHandlerImpl) DeleteChannel(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Channel tidak ditemukan", nil)
 	}
 
-	if err := h.repo.DeleteChannel(ctx, objectID); err != nil {
-		if err == mongo.ErrNoDocuments {
-			utils.LogWarning("Channel dengan ID %s tidak ditemukan untuk dihapus (handler)", id)
+	// Hanya pemilik bisnis channel yang boleh menghapus (atau melihat dry run) seluruh isi channel
+	business, err := h.businessRepo.GetBusinessByID(ctx, channelToDelete.BusinessID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal memeriksa bisnis channel", err.Error())
+	}
+	if business == nil || business.OwnerID != userID {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Hanya pemilik bisnis yang dapat menghapus channel ini", nil)
+	}
+
+	requestedBy, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "User ID tidak valid", err.Error())
+	}
+	dryRun := c.QueryBool("dryRun")
+	result, err := h.deletionService.DeleteChannel(ctx, objectID, requestedBy, dryRun)
+	if err != nil {
+		if errors.Is(err, service.ErrDeletionTargetNotFound) {
 			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Channel tidak ditemukan", nil)
 		}
-		utils.LogError(err, "Gagal menghapus channel di repository: %s", id)
+		utils.LogError(err, "Gagal menghapus channel beserta isinya: %s", id)
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal menghapus channel", err.Error())
 	}
 
-	go h.activityLogService.LogActivity(context.Background(), userID, fmt.Sprintf("Deleted channel: %s (ID: %s)", channelToDelete.Name, id), c.Method(), c.Path(), fiber.StatusOK, c.IP())
+	if !dryRun {
+		go h.activityLogService.LogActivity(context.Background(), userID, fmt.Sprintf("Deleted channel: %s (ID: %s, mode: %s)", channelToDelete.Name, id, result.Mode), c.Method(), c.Path(), fiber.StatusOK, c.IP())
+	}
 
-	return utils.SendSuccessResponse(c, fiber.StatusOK, "Channel berhasil dihapus", nil)
+	return sendDeletionResult(c, service.DeletionTargetChannel, objectID, result, "Channel berhasil dihapus")
 }
 
 // @Summary Get all channels by businessId
@@ -415,3 +440,17 @@ func (h *channelHandlerImpl) GetChannelsByBusinessID(c *fiber.Ctx) error {
 
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Daftar channel by businessId berhasil diambil", resp)
 }
+
+// sendChannelAccessError memetakan error dari ChannelAccessService.ValidateContentChannel ke response HTTP:
+// channel tidak ada atau milik bisnis lain menjadi 404, tipe channel yang salah menjadi 409.
+func sendChannelAccessError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrChannelNotFound):
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Channel not found", err.Error())
+	case errors.Is(err, service.ErrNotBusinessMember):
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Channel not found", "Channel does not belong to a business you are a member of")
+	case errors.Is(err, service.ErrChannelTypeMismatch):
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "Channel type does not match the content type", err.Error())
+	}
+	return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to validate channel", err.Error())
+}
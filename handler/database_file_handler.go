@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxDatabaseFileSize adalah ukuran maksimum satu file, sama dengan batas body request default Fiber.
+const maxDatabaseFileSize = 4 * 1024 * 1024
+
+// DatabaseFileHandler menangani upload, download dan penghapusan file kolom database bertipe file.
+type DatabaseFileHandler interface {
+	UploadFile(c *fiber.Ctx) error
+	GetFile(c *fiber.Ctx) error
+	DownloadFile(c *fiber.Ctx) error
+	DeleteFile(c *fiber.Ctx) error
+}
+
+type databaseFileHandlerImpl struct {
+	fileRepo repository.DatabaseFileRepository
+	dbRepo   repository.DatabaseRepository
+	storage  service.FileStorage // nil jika storage gagal dikonfigurasi
+	realtime *DatabaseRealtimeHub
+}
+
+// NewDatabaseFileHandler membuat instance baru dari DatabaseFileHandler.
+func NewDatabaseFileHandler(fileRepo repository.DatabaseFileRepository, dbRepo repository.DatabaseRepository, storage service.FileStorage, realtime *DatabaseRealtimeHub) DatabaseFileHandler {
+	return &databaseFileHandlerImpl{fileRepo: fileRepo, dbRepo: dbRepo, storage: storage, realtime: realtime}
+}
+
+// UploadFile uploads a file to a file column of a database.
+// @Summary Upload a file to a database file column
+// @Description Upload a file for a column of type file. The response contains the file ID, which is then written to the column value of a row (an array of file IDs) with the row endpoints. Files can only be used in the database and column they were uploaded to. The maximum file size is 4 MB.
+// @Tags Databases
+// @Accept mpfd
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param columnId formData string true "ID of a column of type file"
+// @Param file formData file true "File to upload"
+// @Success 201 {object} utils.APIResponse{data=dto.DatabaseFileResponse} "File uploaded successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input, file too large or column is not a file column"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or Column not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Failure 503 {object} utils.APIResponse "Service Unavailable - File storage is not configured"
+// @Router /databases/{id}/files [post]
+func (h *databaseFileHandlerImpl) UploadFile(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	columnID, err := primitive.ObjectIDFromHex(c.FormValue("columnId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid column ID format", err.Error())
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "File is required", err.Error())
+	}
+	if fileHeader.Size > maxDatabaseFileSize {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "File too large", fmt.Sprintf("File must not be larger than %d bytes", maxDatabaseFileSize))
+	}
+	if h.storage == nil {
+		return utils.SendErrorResponse(c, fiber.StatusServiceUnavailable, "File storage is not configured", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
+	defer cancel()
+
+	database, errResp := h.getAuthorizedDatabase(ctx, c, databaseID, "upload files to")
+	if database == nil {
+		return errResp
+	}
+	var column *model.DatabaseColumn
+	for i := range database.DatabaseData.Columns {
+		if database.DatabaseData.Columns[i].ID == columnID {
+			column = &database.DatabaseData.Columns[i]
+			break
+		}
+	}
+	if column == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Column not found", nil)
+	}
+	if column.Type != "file" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Bad Request", "Column is not of 'file' type")
+	}
+	uploaderID, err := primitive.ObjectIDFromHex(c.Locals("userID").(string))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
+
+	contentType := fileHeader.Header.Get(fiber.HeaderContentType)
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(fileHeader.Filename))
+	}
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+	file := &model.DatabaseFile{
+		ID:          primitive.NewObjectID(),
+		DatabaseID:  databaseID,
+		ColumnID:    columnID,
+		UploaderID:  uploaderID,
+		FileName:    filepath.Base(fileHeader.Filename),
+		ContentType: contentType,
+		Size:        fileHeader.Size,
+	}
+	file.StorageKey = service.DatabaseFileStorageKey(databaseID, file.ID)
+
+	content, err := fileHeader.Open()
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Failed to read uploaded file", err.Error())
+	}
+	defer content.Close()
+	if err := h.storage.Put(ctx, file.StorageKey, content, file.Size, file.ContentType); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to store file", err.Error())
+	}
+	if err := h.fileRepo.CreateFile(ctx, file); err != nil {
+		// Isi file dihapus lagi agar tidak ada objek storage tanpa metadata
+		if delErr := h.storage.Delete(context.Background(), file.StorageKey); delErr != nil {
+			utils.LogError(delErr, "Gagal menghapus file storage %s setelah metadata gagal disimpan", file.StorageKey)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save file", err.Error())
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "File uploaded successfully", convertDatabaseFileToDTO(file))
+}
+
+// GetFile retrieves the metadata of a database file.
+// @Summary Get database file metadata
+// @Description Get the name, content type, size and uploader of a file uploaded to a database file column.
+// @Tags Databases
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param fileId path string true "File ID"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseFileResponse} "File retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or File not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/files/{fileId} [get]
+func (h *databaseFileHandlerImpl) GetFile(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	file, errResp := h.getAuthorizedFile(ctx, c, "access")
+	if file == nil {
+		return errResp
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "File retrieved successfully", convertDatabaseFileToDTO(file))
+}
+
+// DownloadFile downloads the content of a database file.
+// @Summary Download a database file
+// @Description Download the content of a file uploaded to a database file column as an attachment with its original file name and content type.
+// @Tags Databases
+// @Produce octet-stream
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param fileId path string true "File ID"
+// @Success 200 {file} file "File content"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or File not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Failure 503 {object} utils.APIResponse "Service Unavailable - File storage is not configured"
+// @Router /databases/{id}/files/{fileId}/download [get]
+func (h *databaseFileHandlerImpl) DownloadFile(c *fiber.Ctx) error {
+	if h.storage == nil {
+		return utils.SendErrorResponse(c, fiber.StatusServiceUnavailable, "File storage is not configured", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
+	defer cancel()
+
+	file, errResp := h.getAuthorizedFile(ctx, c, "access")
+	if file == nil {
+		return errResp
+	}
+
+	// Isi file dibaca penuh sebelum handler selesai karena ctx dibatalkan saat handler kembali
+	content, err := h.storage.Get(ctx, file.StorageKey)
+	if err != nil {
+		if errors.Is(err, service.ErrFileNotFound) {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "File content not found", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to read file", err.Error())
+	}
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to read file", err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": file.FileName}))
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// DeleteFile deletes a database file.
+// @Summary Delete a database file
+// @Description Delete a file from storage and remove its ID from every row of the database that references it. The removal is not recorded in the row history.
+// @Tags Databases
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param fileId path string true "File ID"
+// @Success 200 {object} utils.APIResponse "File deleted successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to delete files from this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or File not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Failure 503 {object} utils.APIResponse "Service Unavailable - File storage is not configured"
+// @Router /databases/{id}/files/{fileId} [delete]
+func (h *databaseFileHandlerImpl) DeleteFile(c *fiber.Ctx) error {
+	if h.storage == nil {
+		return utils.SendErrorResponse(c, fiber.StatusServiceUnavailable, "File storage is not configured", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
+	defer cancel()
+
+	file, errResp := h.getAuthorizedFile(ctx, c, "delete files from")
+	if file == nil {
+		return errResp
+	}
+
+	// Isi file dihapus lebih dulu; jika gagal, metadata dan referensi baris tetap utuh
+	if err := h.storage.Delete(ctx, file.StorageKey); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete file from storage", err.Error())
+	}
+	if err := h.fileRepo.DeleteFile(ctx, file.ID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "File not found", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete file", err.Error())
+	}
+	if err := h.dbRepo.RemoveFileReferences(ctx, file.DatabaseID, file.ColumnID, file.ID.Hex()); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to remove file from rows", err.Error())
+	}
+	// Client realtime menghapus ID file dari sel kolom tersebut tanpa memuat ulang baris
+	actorID, _ := c.Locals("userID").(string)
+	h.realtime.Publish(file.DatabaseID.Hex(), "file_deleted", actorID, map[string]string{"columnId": file.ColumnID.Hex(), "fileId": file.ID.Hex()})
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "File deleted successfully", nil)
+}
+
+// getAuthorizedDatabase mengambil database dan memastikan user dari token dapat mengaksesnya.
+// Jika gagal, database bernilai nil dan error berisi respons yang sudah dikirim ke client.
+func (h *databaseFileHandlerImpl) getAuthorizedDatabase(ctx context.Context, c *fiber.Ctx, databaseID primitive.ObjectID, action string) (*model.Database, error) {
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return nil, utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	database, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database", err.Error())
+	}
+	if database == nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+
+	// Otorisasi: sama dengan endpoint database lainnya, hanya author yang dapat mengakses
+	// TODO: Tambahkan otorisasi lebih kompleks (misal: admin bisnis, anggota channel dengan izin)
+	if database.AuthorID.Hex() != userIDStr {
+		return nil, utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to "+action+" this database", nil)
+	}
+	return database, nil
+}
+
+// getAuthorizedFile membaca parameter :id dan :fileId, memastikan user dapat mengakses database dan
+// file tersebut milik database itu. Jika gagal, file bernilai nil dan error berisi respons yang sudah dikirim.
+func (h *databaseFileHandlerImpl) getAuthorizedFile(ctx context.Context, c *fiber.Ctx, action string) (*model.DatabaseFile, error) {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	fileID, err := primitive.ObjectIDFromHex(c.Params("fileId"))
+	if err != nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid file ID format", err.Error())
+	}
+
+	if database, errResp := h.getAuthorizedDatabase(ctx, c, databaseID, action); database == nil {
+		return nil, errResp
+	}
+	file, err := h.fileRepo.GetFileByID(ctx, fileID)
+	if err != nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get file", err.Error())
+	}
+	if file == nil || file.DatabaseID != databaseID {
+		return nil, utils.SendErrorResponse(c, fiber.StatusNotFound, "File not found", nil)
+	}
+	return file, nil
+}
+
+// convertDatabaseFileToDTO mengonversi model.DatabaseFile menjadi dto.DatabaseFileResponse.
+func convertDatabaseFileToDTO(file *model.DatabaseFile) dto.DatabaseFileResponse {
+	return dto.DatabaseFileResponse{
+		ID:          file.ID.Hex(),
+		DatabaseID:  file.DatabaseID.Hex(),
+		ColumnID:    file.ColumnID.Hex(),
+		UploaderID:  file.UploaderID.Hex(),
+		FileName:    file.FileName,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		CreatedAt:   file.CreatedAt,
+	}
+}
@@ -1,14 +1,21 @@
 package handler
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"backend_my_manajer/dto"
 	"backend_my_manajer/model"
 	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
 	"backend_my_manajer/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -24,12 +31,16 @@ type DatabaseHandler interface {
 	GetDatabasesByChannelID(c *fiber.Ctx) error
 	UpdateDatabase(c *fiber.Ctx) error
 	DeleteDatabase(c *fiber.Ctx) error
+	DuplicateDatabase(c *fiber.Ctx) error
 
 	AddRowToDatabase(c *fiber.Ctx) error
 	UpdateRowInDatabase(c *fiber.Ctx) error
 	DeleteRowFromDatabase(c *fiber.Ctx) error
+	BatchRowOperations(c *fiber.Ctx) error
 
 	// Handler untuk operasi Kolom
+	AddColumnToDatabase(c *fiber.Ctx) error
+	ReorderColumnsInDatabase(c *fiber.Ctx) error
 	UpdateColumnInDatabase(c *fiber.Ctx) error
 	DeleteColumnFromDatabase(c *fiber.Ctx) error
 
@@ -41,21 +52,42 @@ type DatabaseHandler interface {
 	GetSelectOptionInColumn(c *fiber.Ctx) error
 	GetRowInDatabase(c *fiber.Ctx) error
 	GetRowsByDatabaseID(c *fiber.Ctx) error
+
+	// Handler untuk import dan export CSV/XLSX
+	ExportDatabase(c *fiber.Ctx) error
+	ImportDatabase(c *fiber.Ctx) error
+	ImportRowsToDatabase(c *fiber.Ctx) error
+
+	// Handler untuk view tersimpan (table, kanban, calendar)
+	GetDatabaseViews(c *fiber.Ctx) error
+	CreateDatabaseView(c *fiber.Ctx) error
+	UpdateDatabaseView(c *fiber.Ctx) error
+	DeleteDatabaseView(c *fiber.Ctx) error
+	GetDatabaseViewRows(c *fiber.Ctx) error
+
+	// Handler untuk riwayat perubahan baris
+	GetRowRevisions(c *fiber.Ctx) error
+	GetDeletedRows(c *fiber.Ctx) error
+	RestoreRowRevision(c *fiber.Ctx) error
 }
 
 type databaseHandlerImpl struct {
 	dbRepo   repository.DatabaseRepository
-	userRepo repository.UserRepository // Untuk otorisasi, mungkin perlu cek peran
+	userRepo repository.UserRepository         // Untuk otorisasi, mungkin perlu cek peran
+	fileRepo repository.DatabaseFileRepository // Untuk memvalidasi nilai kolom file
+	realtime *DatabaseRealtimeHub              // Menyiarkan perubahan ke client WebSocket database
+	channels service.ChannelAccessService      // Memvalidasi channel tujuan database baru
+	storage  service.FileStorage               // Menghapus isi file saat database dihapus; boleh nil
 }
 
 // NewDatabaseHandler membuat instance baru dari DatabaseHandler.
-func NewDatabaseHandler(dbRepo repository.DatabaseRepository, userRepo repository.UserRepository) DatabaseHandler {
-	return &databaseHandlerImpl{dbRepo: dbRepo, userRepo: userRepo}
+func NewDatabaseHandler(dbRepo repository.DatabaseRepository, userRepo repository.UserRepository, fileRepo repository.DatabaseFileRepository, realtime *DatabaseRealtimeHub, channels service.ChannelAccessService, storage service.FileStorage) DatabaseHandler {
+	return &databaseHandlerImpl{dbRepo: dbRepo, userRepo: userRepo, fileRepo: fileRepo, realtime: realtime, channels: channels, storage: storage}
 }
 
 // CreateDatabase creates a new database entry.
 // @Summary Create a new database
-// @Description Create a new custom database for a specific channel.
+// @Description Create a new custom database for a specific channel. The channel must exist, belong to a business the user owns or is a member of, and have type "databases".
 // @Tags Databases
 // @Accept json
 // @Produce json
@@ -65,6 +97,8 @@ func NewDatabaseHandler(dbRepo repository.DatabaseRepository, userRepo repositor
 // @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
 // @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
 // @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized"
+// @Failure 404 {object} utils.APIResponse "Not Found - Channel not found or not in a business of the user"
+// @Failure 409 {object} utils.APIResponse "Conflict - Channel is not a databases channel"
 // @Failure 500 {object} utils.APIResponse "Internal Server Error"
 // @Router /databases [post]
 func (h *databaseHandlerImpl) CreateDatabase(c *fiber.Ctx) error {
@@ -98,9 +132,9 @@ func (h *databaseHandlerImpl) CreateDatabase(c *fiber.Ctx) error {
 		if colReq.Type == "" {
 			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Column Type is required")
 		}
-		validTypes := map[string]bool{"date": true, "text": true, "select": true, "boolean": true, "number": true}
+		validTypes := map[string]bool{"date": true, "text": true, "select": true, "boolean": true, "number": true, "formula": true, "relation": true, "rollup": true, "file": true}
 		if !validTypes[colReq.Type] {
-			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Invalid Column Type. Allowed types: date, text, select, boolean, number")
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Invalid Column Type. Allowed types: date, text, select, boolean, number, formula, relation, rollup, file")
 		}
 
 		var selectOptions []model.SelectOption
@@ -119,13 +153,34 @@ func (h *databaseHandlerImpl) CreateDatabase(c *fiber.Ctx) error {
 			}
 		}
 
-		columns = append(columns, model.DatabaseColumn{
-			ID:      primitive.NewObjectID(),
-			Name:    colReq.Name,
-			Type:    colReq.Type,
-			Options: selectOptions,
-			Order:   i + 1,
-		})
+		var formula string
+		if colReq.Type == "formula" {
+			formula = colReq.Formula
+		}
+		relation, rollup, err := convertColumnLinksToModel(colReq.Type, colReq.Relation, colReq.Rollup)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", err.Error())
+		}
+
+		column := model.DatabaseColumn{
+			ID:          primitive.NewObjectID(),
+			Name:        colReq.Name,
+			Type:        colReq.Type,
+			Options:     selectOptions,
+			Order:       i + 1,
+			Formula:     formula,
+			Relation:    relation,
+			Rollup:      rollup,
+			Constraints: convertColumnConstraintsToModel(colReq.Constraints),
+		}
+		if err := service.NormalizeColumnConstraints(&column); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid column constraints", err.Error())
+		}
+		columns = append(columns, column)
+	}
+	// Formula divalidasi setelah semua kolom dibuat karena dapat merujuk kolom lain
+	if err := service.ValidateFormulaColumns(columns); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid formula", err.Error())
 	}
 
 	userIDStr, ok := c.Locals("userID").(string)
@@ -162,28 +217,20 @@ func (h *databaseHandlerImpl) CreateDatabase(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
 	defer cancel()
 
+	if _, err := h.channels.ValidateContentChannel(ctx, channelID, authorID, service.ChannelTypeDatabases); err != nil {
+		return sendChannelAccessError(c, err)
+	}
+	if ok, err := h.resolveLinkedColumns(ctx, c, newDatabase, newDatabase.DatabaseData.Columns); !ok {
+		return err
+	}
+
 	if err := h.dbRepo.CreateDatabase(ctx, newDatabase); err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create database", err.Error())
 	}
 
 	respColumns := make([]dto.DatabaseColumnResponse, len(newDatabase.DatabaseData.Columns))
 	for i, col := range newDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
 	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Database created successfully", dto.DatabaseResponse{
@@ -191,6 +238,7 @@ func (h *databaseHandlerImpl) CreateDatabase(c *fiber.Ctx) error {
 		ChannelID: newDatabase.ChannelID.Hex(),
 		AuthorID:  newDatabase.AuthorID.Hex(),
 		Title:     newDatabase.Title,
+		Version:   newDatabase.Version,
 		Columns:   respColumns,
 		Rows:      []dto.DatabaseRowResponse{}, // Awalnya kosong
 		CreatedAt: newDatabase.CreatedAt,
@@ -206,6 +254,7 @@ func (h *databaseHandlerImpl) CreateDatabase(c *fiber.Ctx) error {
 // @Security ApiKeyAuth
 // @Param id path string true "Database ID"
 // @Success 200 {object} utils.APIResponse{data=dto.DatabaseResponse} "Database retrieved successfully"
+// @Header 200 {string} ETag "Database version, usable as If-Match on updates"
 // @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
 // @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
 // @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
@@ -246,37 +295,26 @@ func (h *databaseHandlerImpl) GetDatabaseByID(c *fiber.Ctx) error {
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(database.DatabaseData.Columns))
 	for i, col := range database.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, database)
 	respRows := make([]dto.DatabaseRowResponse, len(database.DatabaseData.Rows))
 	for i, row := range database.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
+	setVersionETag(c, database.Version)
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Database retrieved successfully", dto.DatabaseResponse{
 		ID:        database.ID.Hex(),
 		ChannelID: database.ChannelID.Hex(),
 		AuthorID:  database.AuthorID.Hex(),
 		Title:     database.Title,
+		Version:   database.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: database.CreatedAt,
@@ -329,29 +367,16 @@ func (h *databaseHandlerImpl) GetDatabasesByChannelID(c *fiber.Ctx) error {
 	for _, db := range databases {
 		respColumns := make([]dto.DatabaseColumnResponse, len(db.DatabaseData.Columns))
 		for i, col := range db.DatabaseData.Columns {
-			respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-			for j, opt := range col.Options {
-				respOptions[j] = dto.SelectOptionResponse{
-					ID:        opt.ID.Hex(),
-					Value:     opt.Value,
-					Order:     opt.Order,
-					CreatedAt: opt.CreatedAt,
-				}
-			}
-			respColumns[i] = dto.DatabaseColumnResponse{
-				ID:      col.ID.Hex(),
-				Name:    col.Name,
-				Type:    col.Type,
-				Options: respOptions,
-				Order:   col.Order,
-			}
+			respColumns[i] = convertDatabaseColumnToDTO(col)
 		}
 
+		service.ComputeDerivedColumns(ctx, h.dbRepo, &db)
 		respRows := make([]dto.DatabaseRowResponse, len(db.DatabaseData.Rows))
 		for i, row := range db.DatabaseData.Rows {
 			respRows[i] = dto.DatabaseRowResponse{
-				ID:     row.ID.Hex(),
-				Values: dto.DatabaseRowValueResponse(row.Values),
+				ID:      row.ID.Hex(),
+				Values:  dto.DatabaseRowValueResponse(row.Values),
+				Version: row.Version,
 			}
 		}
 
@@ -360,6 +385,7 @@ func (h *databaseHandlerImpl) GetDatabasesByChannelID(c *fiber.Ctx) error {
 			ChannelID: db.ChannelID.Hex(),
 			AuthorID:  db.AuthorID.Hex(),
 			Title:     db.Title,
+			Version:   db.Version,
 			Columns:   respColumns,
 			Rows:      respRows,
 			CreatedAt: db.CreatedAt,
@@ -379,11 +405,14 @@ func (h *databaseHandlerImpl) GetDatabasesByChannelID(c *fiber.Ctx) error {
 // @Security ApiKeyAuth
 // @Param id path string true "Database ID"
 // @Param database body dto.DatabaseUpdateRequest true "Database Update Details"
+// @Param If-Match header string false "ETag (database version) from a previous read; the update is rejected with 412 if the database changed since"
 // @Success 200 {object} utils.APIResponse{data=dto.DatabaseResponse} "Database updated successfully"
+// @Header 200 {string} ETag "New database version"
 // @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
 // @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
 // @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update this database"
 // @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 412 {object} utils.APIResponse{error=dto.DatabaseResponse} "Precondition Failed - Database was modified; the current database is returned"
 // @Failure 500 {object} utils.APIResponse "Internal Server Error"
 // @Router /databases/{id} [put]
 func (h *databaseHandlerImpl) UpdateDatabase(c *fiber.Ctx) error {
@@ -402,6 +431,10 @@ func (h *databaseHandlerImpl) UpdateDatabase(c *fiber.Ctx) error {
 	if req.Title != "" && len(req.Title) < 3 {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long if provided")
 	}
+	expectedVersion, err := parseIfMatchVersion(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
 
 	// Ambil UserID dari Locals (dari JWT Middleware) untuk otorisasi
 	userIDStr, ok := c.Locals("userID").(string)
@@ -426,6 +459,13 @@ func (h *databaseHandlerImpl) UpdateDatabase(c *fiber.Ctx) error {
 	if existingDB.AuthorID.Hex() != userIDStr {
 		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update this database", nil)
 	}
+	if expectedVersion > 0 && existingDB.Version != expectedVersion {
+		return h.sendDatabaseVersionConflict(ctx, c, objectID, existingDB)
+	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
 
 	updateMap := bson.M{
 		"$set": bson.M{"updatedAt": time.Now()},
@@ -446,9 +486,9 @@ func (h *databaseHandlerImpl) UpdateDatabase(c *fiber.Ctx) error {
 			if colReq.Type == "" {
 				return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Column Type is required if Columns are provided")
 			}
-			validTypes := map[string]bool{"date": true, "text": true, "select": true, "boolean": true, "number": true}
+			validTypes := map[string]bool{"date": true, "text": true, "select": true, "boolean": true, "number": true, "formula": true, "relation": true, "rollup": true, "file": true}
 			if !validTypes[colReq.Type] {
-				return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Invalid Column Type. Allowed types: date, text, select, boolean, number")
+				return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Invalid Column Type. Allowed types: date, text, select, boolean, number, formula, relation, rollup, file")
 			}
 
 			colID, err := primitive.ObjectIDFromHex(colReq.ID)
@@ -472,34 +512,83 @@ func (h *databaseHandlerImpl) UpdateDatabase(c *fiber.Ctx) error {
 				}
 			}
 
-			newColumns = append(newColumns, model.DatabaseColumn{
-				ID:      colID,
-				Name:    colReq.Name,
-				Type:    colReq.Type,
-				Options: selectOptions,
-				Order:   i + 1,
-			})
+			var formula string
+			if colReq.Type == "formula" {
+				formula = colReq.Formula
+			}
+			relation, rollup, err := convertColumnLinksToModel(colReq.Type, colReq.Relation, colReq.Rollup)
+			if err != nil {
+				return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", err.Error())
+			}
+
+			column := model.DatabaseColumn{
+				ID:          colID,
+				Name:        colReq.Name,
+				Type:        colReq.Type,
+				Options:     selectOptions,
+				Order:       i + 1,
+				Formula:     formula,
+				Relation:    relation,
+				Rollup:      rollup,
+				Constraints: convertColumnConstraintsToModel(colReq.Constraints),
+			}
+			if err := service.NormalizeColumnConstraints(&column); err != nil {
+				return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid column constraints", err.Error())
+			}
+			newColumns = append(newColumns, column)
+		}
+		if err := service.ValidateFormulaColumns(newColumns); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid formula", err.Error())
+		}
+		if ok, err := h.resolveLinkedColumns(ctx, c, existingDB, newColumns); !ok {
+			return err
+		}
+		// Jika baris ikut diganti, batasan unique diperiksa terhadap baris baru di bawah
+		if req.Rows == nil {
+			for i := range newColumns {
+				if ok, err := checkUniqueConflicts(c, &newColumns[i], existingDB.DatabaseData.Rows); !ok {
+					return err
+				}
+			}
 		}
 		setMap["databaseData.columns"] = newColumns
 	}
 
-	// Update Rows (ini akan menimpa seluruh array rows jika disediakan)
+	// Update Rows (ini akan menimpa seluruh baris jika disediakan)
 	// Biasanya, update rows dilakukan melalui endpoint terpisah (AddRow, UpdateRow, DeleteRow)
-	// Jika ini dimaksudkan untuk mengganti seluruh array rows, ini bisa berbahaya.
-	// Saya akan asumsikan req.Rows hanya untuk update/replace seluruh array. Jika tidak, perlu endpoint terpisah.
+	// Jika ini dimaksudkan untuk mengganti seluruh baris, ini bisa berbahaya.
+	// Saya akan asumsikan req.Rows hanya untuk update/replace seluruh baris. Jika tidak, perlu endpoint terpisah.
+	var newRows []model.DatabaseRow
 	if req.Rows != nil {
-		var newRows []model.DatabaseRow
-		for _, rowReq := range req.Rows {
-			rowID, err := primitive.ObjectIDFromHex(rowReq.ID) // Asumsikan ID baris dikirim jika sudah ada
+		// Baris divalidasi terhadap kolom baru jika kolom ikut diperbarui
+		targetColumns := existingDB.DatabaseData.Columns
+		if newColumns, ok := setMap["databaseData.columns"].([]model.DatabaseColumn); ok {
+			targetColumns = newColumns
+		}
+
+		newRows = make([]model.DatabaseRow, 0, len(req.Rows))
+		checker := service.NewRowConstraintChecker(targetColumns, nil)
+		for i, rowReq := range req.Rows {
+			// Asumsikan ID baris dikirim jika sudah ada; ID kosong/tidak valid berarti baris baru
+			rowID, _ := primitive.ObjectIDFromHex(rowReq.ID)
+			values, err := service.ValidateRowValues(targetColumns, rowReq.Values)
 			if err != nil {
-				rowID = primitive.NewObjectID() // Generate baru jika tidak valid atau tidak ada
+				return sendRowValidationError(c, fmt.Sprintf("Validation error in rows[%d]", i), err)
+			}
+			if ok, err := h.validateReferenceValues(ctx, c, objectID, targetColumns, values, fmt.Sprintf("Validation error in rows[%d]", i)); !ok {
+				return err
+			}
+			if values, err = checker.Check(rowID, values); err != nil {
+				return sendRowValidationError(c, fmt.Sprintf("Validation error in rows[%d]", i), err)
+			}
+			if rowID.IsZero() {
+				rowID = primitive.NewObjectID()
 			}
 			newRows = append(newRows, model.DatabaseRow{
 				ID:     rowID,
-				Values: model.DatabaseRowValue(rowReq.Values),
+				Values: values,
 			})
 		}
-		setMap["databaseData.rows"] = newRows
 	}
 
 	// Periksa apakah ada data yang akan diupdate selain updatedAt
@@ -507,48 +596,53 @@ func (h *databaseHandlerImpl) UpdateDatabase(c *fiber.Ctx) error {
 	// return utils.SendErrorResponse(c, fiber.StatusBadRequest, "No valid update data provided", nil)
 	// }
 
-	updatedDatabase, err := h.dbRepo.UpdateDatabase(ctx, objectID, updateMap)
+	updatedDatabase, err := h.dbRepo.UpdateDatabase(ctx, objectID, updateMap, expectedVersion)
 	if err != nil {
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return h.sendDatabaseVersionConflict(ctx, c, objectID, nil)
+		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update database", err.Error())
 	}
 	if updatedDatabase == nil {
 		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found after update (unlikely)", nil)
 	}
 
+	// Baris disimpan di koleksi terpisah, sehingga penggantian baris dilakukan setelah dokumen database diperbarui
+	if req.Rows != nil {
+		updatedDatabase, err = h.dbRepo.ReplaceRowsInDatabase(ctx, objectID, newRows, authorID)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to replace database rows", err.Error())
+		}
+		if updatedDatabase == nil {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found after update (unlikely)", nil)
+		}
+	}
+
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(updatedDatabase.DatabaseData.Columns))
 	for i, col := range updatedDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
 	respRows := make([]dto.DatabaseRowResponse, len(updatedDatabase.DatabaseData.Rows))
 	for i, row := range updatedDatabase.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
+	h.publishEvent(c, objectID, "database_updated", convertDatabaseToDTO(updatedDatabase))
+	setVersionETag(c, updatedDatabase.Version)
+
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Database updated successfully", dto.DatabaseResponse{
 		ID:        updatedDatabase.ID.Hex(),
 		ChannelID: updatedDatabase.ChannelID.Hex(),
 		AuthorID:  updatedDatabase.AuthorID.Hex(),
 		Title:     updatedDatabase.Title,
+		Version:   updatedDatabase.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: updatedDatabase.CreatedAt,
@@ -558,7 +652,7 @@ func (h *databaseHandlerImpl) UpdateDatabase(c *fiber.Ctx) error {
 
 // DeleteDatabase deletes a database entry.
 // @Summary Delete a database
-// @Description Delete a custom database by its ID. Only author or authorized users can delete.
+// @Description Delete a custom database by its ID together with its rows, row history and uploaded files. Only author or authorized users can delete.
 // @Tags Databases
 // @Produce json
 // @Security ApiKeyAuth
@@ -587,7 +681,7 @@ func (h *databaseHandlerImpl) DeleteDatabase(c *fiber.Ctx) error {
 	defer cancel()
 
 	// Dapatkan database yang ada untuk otorisasi
-	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, objectID)
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, objectID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for deletion", err.Error())
 	}
@@ -601,19 +695,119 @@ func (h *databaseHandlerImpl) DeleteDatabase(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to delete this database", nil)
 	}
 
-	if err := h.dbRepo.DeleteDatabase(ctx, objectID); err != nil {
+	files, err := h.dbRepo.DeleteDatabase(ctx, objectID)
+	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found for deletion", nil)
 		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete database", err.Error())
 	}
+	// Metadata file sudah terhapus, sehingga kegagalan menghapus isinya dari storage hanya dicatat
+	if h.storage != nil {
+		for _, file := range files {
+			if err := h.storage.Delete(context.Background(), file.StorageKey); err != nil {
+				utils.LogWarning("Gagal menghapus isi file %s dari storage: %v", file.StorageKey, err)
+			}
+		}
+	}
 
+	h.publishEvent(c, objectID, "database_deleted", nil)
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Database deleted successfully", nil)
 }
 
+// DuplicateDatabase creates a copy of a database.
+// @Summary Duplicate a database
+// @Description Create a copy of a database with the same columns, select options and views, optionally including its rows. The copy can be placed in another channel; relation columns that point to the database itself point to the copy, and copied rows link to the copied rows. Relation columns to other databases must stay in the same channel.
+// @Tags Databases
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param request body dto.DatabaseDuplicateRequest false "Duplicate options"
+// @Success 201 {object} utils.APIResponse{data=dto.DatabaseResponse} "Database duplicated successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or relation columns cannot be copied to the target channel"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to duplicate this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or target channel not found"
+// @Failure 409 {object} utils.APIResponse "Conflict - Target channel is not a databases channel"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/duplicate [post]
+func (h *databaseHandlerImpl) DuplicateDatabase(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	var req dto.DatabaseDuplicateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+		}
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
+	defer cancel()
+
+	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for duplication", err.Error())
+	}
+	if existingDB == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if existingDB.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to duplicate this database", nil)
+	}
+
+	channelID := existingDB.ChannelID
+	if req.ChannelID != "" {
+		channelID, err = primitive.ObjectIDFromHex(req.ChannelID)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Channel ID format", err.Error())
+		}
+		if _, err := h.channels.ValidateContentChannel(ctx, channelID, existingDB.AuthorID, service.ChannelTypeDatabases); err != nil {
+			return sendChannelAccessError(c, err)
+		}
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = existingDB.Title + " (copy)"
+	}
+	if len(title) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long")
+	}
+
+	newDatabase := &model.Database{
+		ID:        primitive.NewObjectID(),
+		ChannelID: channelID,
+		AuthorID:  existingDB.AuthorID,
+		Title:     title,
+	}
+	columns, views := service.CopyDatabaseSchema(existingDB.DatabaseData.Columns, existingDB.Views, existingDB.ID, newDatabase.ID)
+	newDatabase.DatabaseData.Columns, newDatabase.Views = columns, views
+	newDatabase.DatabaseData.Rows = []model.DatabaseRow{}
+	if req.IncludeRows {
+		newDatabase.DatabaseData.Rows = service.CopyDatabaseRows(columns, existingDB.DatabaseData.Rows, newDatabase.ID)
+	}
+	if ok, err := h.resolveLinkedColumns(ctx, c, newDatabase, newDatabase.DatabaseData.Columns); !ok {
+		return err
+	}
+
+	if err := h.dbRepo.CreateDatabase(ctx, newDatabase); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to duplicate database", err.Error())
+	}
+
+	service.ComputeDerivedColumns(ctx, h.dbRepo, newDatabase)
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Database duplicated successfully", convertDatabaseToDTO(newDatabase))
+}
+
 // AddRowToDatabase adds a new row to a specific database.
 // @Summary Add a row to database
-// @Description Add a new data row to an existing custom database.
+// @Description Add a new data row to an existing custom database. Values are validated against the column types (number, date, boolean, select option ID, text) and unknown column keys are rejected. Column constraints are enforced: missing columns get their default value, then required, min/max, pattern and unique are checked. Every invalid column is listed in the error. The response contains the database with only the new row.
 // @Tags Databases
 // @Accept json
 // @Produce json
@@ -654,7 +848,7 @@ func (h *databaseHandlerImpl) AddRowToDatabase(c *fiber.Ctx) error {
 	defer cancel()
 
 	// Dapatkan database yang ada untuk otorisasi
-	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for adding row", err.Error())
 	}
@@ -667,50 +861,62 @@ func (h *databaseHandlerImpl) AddRowToDatabase(c *fiber.Ctx) error {
 	if existingDB.AuthorID.Hex() != userIDStr {
 		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to add rows to this database", nil)
 	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
+
+	// Validasi nilai terhadap tipe kolom; semua kolom yang bermasalah dilaporkan sekaligus
+	values, err := service.ValidateRowValues(existingDB.DatabaseData.Columns, req.Values)
+	if err != nil {
+		return sendRowValidationError(c, "Validation error", err)
+	}
+	if ok, err := h.validateReferenceValues(ctx, c, databaseID, existingDB.DatabaseData.Columns, values, "Validation error"); !ok {
+		return err
+	}
+	values, err = service.NewStoredRowConstraintChecker(ctx, h.dbRepo, databaseID, existingDB.DatabaseData.Columns).Check(primitive.NilObjectID, values)
+	if err != nil {
+		return sendRowValidationError(c, "Validation error", err)
+	}
 
 	newRow := &model.DatabaseRow{
-		Values: model.DatabaseRowValue(req.Values),
+		Values: values,
 	}
 
-	updatedDatabase, err := h.dbRepo.AddRowToDatabase(ctx, databaseID, newRow)
+	updatedDatabase, err := h.dbRepo.AddRowToDatabase(ctx, databaseID, newRow, authorID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to add row to database", err.Error())
 	}
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
 
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(updatedDatabase.DatabaseData.Columns))
 	for i, col := range updatedDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
 	respRows := make([]dto.DatabaseRowResponse, len(updatedDatabase.DatabaseData.Rows))
 	for i, row := range updatedDatabase.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
+	if row := findRowDTO(updatedDatabase, newRow.ID); row != nil {
+		h.publishEvent(c, databaseID, "row_created", row)
+	}
+
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Row added successfully", dto.DatabaseResponse{
 		ID:        updatedDatabase.ID.Hex(),
 		ChannelID: updatedDatabase.ChannelID.Hex(),
 		AuthorID:  updatedDatabase.AuthorID.Hex(),
 		Title:     updatedDatabase.Title,
+		Version:   updatedDatabase.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: updatedDatabase.CreatedAt,
@@ -720,7 +926,7 @@ func (h *databaseHandlerImpl) AddRowToDatabase(c *fiber.Ctx) error {
 
 // UpdateRowInDatabase updates a specific row within a database.
 // @Summary Update a row in database
-// @Description Update an existing data row within a custom database.
+// @Description Replace the values of an existing data row within a custom database. Values are validated against the column types and constraints (required, min/max, pattern, unique; default values only apply to new rows) and unknown column keys are rejected; every invalid column is listed in the error. The response contains the database with only the updated row.
 // @Tags Databases
 // @Accept json
 // @Produce json
@@ -728,11 +934,14 @@ func (h *databaseHandlerImpl) AddRowToDatabase(c *fiber.Ctx) error {
 // @Param id path string true "Database ID"
 // @Param rowId path string true "Row ID"
 // @Param row body dto.DatabaseRowRequest true "Updated Row Details"
+// @Param If-Match header string false "ETag (row version) from a previous read; the update is rejected with 412 if the row changed since"
 // @Success 200 {object} utils.APIResponse{data=dto.DatabaseResponse} "Row updated successfully"
+// @Header 200 {string} ETag "New row version"
 // @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
 // @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
 // @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update rows in this database"
 // @Failure 404 {object} utils.APIResponse "Not Found - Database or Row not found"
+// @Failure 412 {object} utils.APIResponse{error=dto.DatabaseRowResponse} "Precondition Failed - Row was modified; the current row is returned"
 // @Failure 500 {object} utils.APIResponse "Internal Server Error"
 // @Router /databases/{id}/rows/{rowId} [put]
 func (h *databaseHandlerImpl) UpdateRowInDatabase(c *fiber.Ctx) error {
@@ -757,6 +966,10 @@ func (h *databaseHandlerImpl) UpdateRowInDatabase(c *fiber.Ctx) error {
 	if len(req.Values) == 0 {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Row values are required")
 	}
+	expectedVersion, err := parseIfMatchVersion(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
 
 	// Ambil UserID dari Locals (dari JWT Middleware) untuk otorisasi
 	userIDStr, ok := c.Locals("userID").(string)
@@ -768,7 +981,7 @@ func (h *databaseHandlerImpl) UpdateRowInDatabase(c *fiber.Ctx) error {
 	defer cancel()
 
 	// Dapatkan database yang ada untuk otorisasi
-	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for updating row", err.Error())
 	}
@@ -781,49 +994,74 @@ func (h *databaseHandlerImpl) UpdateRowInDatabase(c *fiber.Ctx) error {
 	if existingDB.AuthorID.Hex() != userIDStr {
 		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update rows in this database", nil)
 	}
+	if expectedVersion > 0 {
+		current, err := h.dbRepo.GetRowsByIDs(ctx, databaseID, []primitive.ObjectID{rowID})
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve row for updating", err.Error())
+		}
+		if len(current) > 0 && current[0].Version != expectedVersion {
+			return h.sendRowVersionConflict(ctx, c, existingDB, rowID)
+		}
+	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
+
+	// Validasi nilai terhadap tipe kolom; semua kolom yang bermasalah dilaporkan sekaligus
+	values, err := service.ValidateRowValues(existingDB.DatabaseData.Columns, req.Values)
+	if err != nil {
+		return sendRowValidationError(c, "Validation error", err)
+	}
+	if ok, err := h.validateReferenceValues(ctx, c, databaseID, existingDB.DatabaseData.Columns, values, "Validation error"); !ok {
+		return err
+	}
+	values, err = service.NewStoredRowConstraintChecker(ctx, h.dbRepo, databaseID, existingDB.DatabaseData.Columns).Check(rowID, values)
+	if err != nil {
+		return sendRowValidationError(c, "Validation error", err)
+	}
 
-	updatedDatabase, err := h.dbRepo.UpdateRowInDatabase(ctx, databaseID, rowID, model.DatabaseRowValue(req.Values))
+	updatedDatabase, err := h.dbRepo.UpdateRowInDatabase(ctx, databaseID, rowID, values, authorID, expectedVersion)
 	if err != nil {
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return h.sendRowVersionConflict(ctx, c, existingDB, rowID)
+		}
 		if err == mongo.ErrNoDocuments {
 			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database or Row not found", nil)
 		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update row in database", err.Error())
 	}
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database or Row not found", nil)
+	}
 
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(updatedDatabase.DatabaseData.Columns))
 	for i, col := range updatedDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
 	respRows := make([]dto.DatabaseRowResponse, len(updatedDatabase.DatabaseData.Rows))
 	for i, row := range updatedDatabase.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
+	if row := findRowDTO(updatedDatabase, rowID); row != nil {
+		h.publishEvent(c, databaseID, "row_updated", row)
+		setVersionETag(c, row.Version)
+	}
+
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Row updated successfully", dto.DatabaseResponse{
 		ID:        updatedDatabase.ID.Hex(),
 		ChannelID: updatedDatabase.ChannelID.Hex(),
 		AuthorID:  updatedDatabase.AuthorID.Hex(),
 		Title:     updatedDatabase.Title,
+		Version:   updatedDatabase.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: updatedDatabase.CreatedAt,
@@ -833,7 +1071,7 @@ func (h *databaseHandlerImpl) UpdateRowInDatabase(c *fiber.Ctx) error {
 
 // DeleteRowFromDatabase deletes a specific row from a database.
 // @Summary Delete a row from database
-// @Description Delete a specific data row from an existing custom database.
+// @Description Delete a specific data row from an existing custom database. The response contains the database without rows.
 // @Tags Databases
 // @Produce json
 // @Security ApiKeyAuth
@@ -869,7 +1107,7 @@ func (h *databaseHandlerImpl) DeleteRowFromDatabase(c *fiber.Ctx) error {
 	defer cancel()
 
 	// Dapatkan database yang ada untuk otorisasi
-	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for deleting row", err.Error())
 	}
@@ -882,41 +1120,39 @@ func (h *databaseHandlerImpl) DeleteRowFromDatabase(c *fiber.Ctx) error {
 	if existingDB.AuthorID.Hex() != userIDStr {
 		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to delete rows from this database", nil)
 	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
 
-	updatedDatabase, err := h.dbRepo.DeleteRowFromDatabase(ctx, databaseID, rowID)
+	updatedDatabase, err := h.dbRepo.DeleteRowFromDatabase(ctx, databaseID, rowID, authorID)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database or Row not found", nil)
 		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete row from database", err.Error())
 	}
+	// Repository mengembalikan nil jika database terhapus di antara pengecekan otorisasi dan penghapusan baris
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database or Row not found", nil)
+	}
+	// Baris yang tidak ada sudah dijawab 404 di atas, sehingga event dan versi hub hanya berubah untuk
+	// penghapusan yang dikonfirmasi repository
+	h.publishEvent(c, databaseID, "row_deleted", map[string]string{"rowId": rowID.Hex()})
 
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(updatedDatabase.DatabaseData.Columns))
 	for i, col := range updatedDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
 	respRows := make([]dto.DatabaseRowResponse, len(updatedDatabase.DatabaseData.Rows))
 	for i, row := range updatedDatabase.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
@@ -925,6 +1161,7 @@ func (h *databaseHandlerImpl) DeleteRowFromDatabase(c *fiber.Ctx) error {
 		ChannelID: updatedDatabase.ChannelID.Hex(),
 		AuthorID:  updatedDatabase.AuthorID.Hex(),
 		Title:     updatedDatabase.Title,
+		Version:   updatedDatabase.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: updatedDatabase.CreatedAt,
@@ -932,54 +1169,92 @@ func (h *databaseHandlerImpl) DeleteRowFromDatabase(c *fiber.Ctx) error {
 	})
 }
 
-// UpdateColumnInDatabase memperbarui kolom tertentu dalam database.
-// @Summary Update a column in database
-// @Description Update an existing column within a custom database.
+// AddColumnToDatabase menambahkan kolom baru ke database.
+// @Summary Add a column to database
+// @Description Add a new column to an existing custom database. Existing rows are backfilled with defaultValue, then constraints.defaultValue, or with the type default when both are omitted (text "", number 0, boolean false, date and select null; null for unique columns). Constraints apply to rows written afterwards; a unique column whose backfill value would be shared by several rows is rejected with 409. When order is set, columns at or after that position are shifted by one; otherwise the column is appended. Formula columns store no values and are evaluated on read.
 // @Tags Databases
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "Database ID"
-// @Param columnId path string true "Column ID"
-// @Param column body dto.DatabaseColumnUpdateRequest true "Updated Column Details"
-// @Success 200 {object} utils.APIResponse{data=dto.DatabaseResponse} "Column updated successfully"
+// @Param column body dto.DatabaseColumnCreateRequest true "New Column Details"
+// @Success 201 {object} utils.APIResponse{data=dto.DatabaseResponse} "Column added successfully"
 // @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
 // @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
-// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update columns in this database"
-// @Failure 404 {object} utils.APIResponse "Not Found - Database or Column not found"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to add columns to this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 409 {object} utils.APIResponse{error=[]dto.DatabaseColumnUniqueConflictResponse} "Conflict - Unique column would hold duplicate values"
 // @Failure 500 {object} utils.APIResponse "Internal Server Error"
-// @Router /databases/{id}/columns/{columnId} [put]
-func (h *databaseHandlerImpl) UpdateColumnInDatabase(c *fiber.Ctx) error {
-	databaseIDStr := c.Params("id")
-	columnIDStr := c.Params("columnId")
-
-	databaseID, err := primitive.ObjectIDFromHex(databaseIDStr)
+// @Router /databases/{id}/columns [post]
+func (h *databaseHandlerImpl) AddColumnToDatabase(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
 	}
-	columnID, err := primitive.ObjectIDFromHex(columnIDStr)
-	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid column ID format", err.Error())
-	}
 
-	var req dto.DatabaseColumnUpdateRequest
+	var req dto.DatabaseColumnCreateRequest
 	if err := c.BodyParser(&req); err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
 	}
 
 	// Validasi manual
-	if req.Name != "" && len(req.Name) < 1 {
-		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Column Name cannot be empty")
+	if req.Name == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Column Name is required")
+	}
+	if req.Type == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Column Type is required")
+	}
+	validTypes := map[string]bool{"date": true, "text": true, "select": true, "boolean": true, "number": true, "formula": true, "relation": true, "rollup": true, "file": true}
+	if !validTypes[req.Type] {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Invalid Column Type. Allowed types: date, text, select, boolean, number, formula, relation, rollup, file")
+	}
+	if req.Order < 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Column Order must be a positive number")
 	}
 
-	if req.Type != "" {
-		validTypes := map[string]bool{"date": true, "text": true, "select": true, "boolean": true, "number": true}
-		if !validTypes[req.Type] {
-			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Invalid Column Type. Allowed types: date, text, select, boolean, number")
+	newColumn := &model.DatabaseColumn{
+		Name:        req.Name,
+		Type:        req.Type,
+		Order:       req.Order,
+		Constraints: convertColumnConstraintsToModel(req.Constraints),
+	}
+	if req.Type == "formula" {
+		newColumn.Formula = req.Formula
+	}
+	newColumn.Relation, newColumn.Rollup, err = convertColumnLinksToModel(req.Type, req.Relation, req.Rollup)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", err.Error())
+	}
+	if req.Type == "select" {
+		newColumn.Options = make([]model.SelectOption, 0, len(req.Options))
+		for optIdx, optValue := range req.Options {
+			newColumn.Options = append(newColumn.Options, model.SelectOption{
+				ID:        primitive.NewObjectID(),
+				Value:     optValue,
+				Order:     optIdx + 1,
+				CreatedAt: time.Now(),
+			})
+		}
+	}
+
+	if err := service.NormalizeColumnConstraints(newColumn); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid column constraints", err.Error())
+	}
+
+	// Nilai pengisi baris lama: defaultValue request, lalu default dari batasan kolom, lalu default tipe.
+	// Kolom unique tanpa default diisi null karena default tipe akan sama untuk semua baris.
+	backfillValue := req.DefaultValue
+	if backfillValue == nil && newColumn.Constraints != nil {
+		backfillValue = newColumn.Constraints.DefaultValue
+	}
+	var defaultValue interface{}
+	if backfillValue != nil || newColumn.Constraints == nil || !newColumn.Constraints.Unique {
+		defaultValue, err = service.ResolveColumnDefaultValue(newColumn, backfillValue)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", fmt.Sprintf("Invalid defaultValue: %s", err.Error()))
 		}
 	}
 
-	// Ambil UserID dari Locals (dari JWT Middleware) untuk otorisasi
 	userIDStr, ok := c.Locals("userID").(string)
 	if !ok || userIDStr == "" {
 		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
@@ -988,69 +1263,355 @@ func (h *databaseHandlerImpl) UpdateColumnInDatabase(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
 	defer cancel()
 
-	// Dapatkan database yang ada untuk otorisasi
 	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for updating column", err.Error())
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for adding column", err.Error())
 	}
 	if existingDB == nil {
 		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
 	}
 
-	// Otorisasi: Hanya author atau user dengan izin yang dapat memperbarui kolom
+	// Otorisasi: Hanya author yang dapat menambahkan kolom
 	// TODO: Tambahkan otorisasi lebih kompleks
 	if existingDB.AuthorID.Hex() != userIDStr {
-		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update columns in this database", nil)
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to add columns to this database", nil)
 	}
 
-	// Cari kolom yang akan diperbarui
-	var existingColumn *model.DatabaseColumn
-	for _, col := range existingDB.DatabaseData.Columns {
-		if col.ID == columnID {
-			existingColumn = &col
-			break
-		}
+	// Formula, relation dan rollup kolom baru divalidasi bersama kolom yang sudah ada
+	newColumn.ID = primitive.NewObjectID()
+	candidateColumns := append(append([]model.DatabaseColumn{}, existingDB.DatabaseData.Columns...), *newColumn)
+	if err := service.ValidateFormulaColumns(candidateColumns); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid formula", err.Error())
+	}
+	if ok, err := h.resolveLinkedColumns(ctx, c, existingDB, candidateColumns); !ok {
+		return err
 	}
+	*newColumn = candidateColumns[len(candidateColumns)-1]
 
-	if existingColumn == nil {
-		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Column not found", nil)
+	// Semua baris lama diisi nilai yang sama, sehingga kolom unique hanya valid jika nilainya kosong atau barisnya satu
+	backfilledRows := make([]model.DatabaseRow, len(existingDB.DatabaseData.Rows))
+	for i, row := range existingDB.DatabaseData.Rows {
+		backfilledRows[i] = model.DatabaseRow{ID: row.ID, Values: model.DatabaseRowValue{newColumn.ID.Hex(): defaultValue}}
+	}
+	if ok, err := checkUniqueConflicts(c, newColumn, backfilledRows); !ok {
+		return err
 	}
 
-	// Buat map updateData untuk repository
-	updateData := bson.M{
-		"$set": bson.M{},
+	updatedDatabase, err := h.dbRepo.AddColumnToDatabase(ctx, databaseID, newColumn, defaultValue)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to add column to database", err.Error())
+	}
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
 	}
-	setMap := updateData["$set"].(bson.M)
 
-	if req.Name != "" {
-		setMap["databaseData.columns.$.name"] = req.Name
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
+	h.publishEvent(c, databaseID, "column_created", findColumnDTO(updatedDatabase, newColumn.ID))
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Column added successfully", convertDatabaseToDTO(updatedDatabase))
+}
+
+// ReorderColumnsInDatabase menyusun ulang urutan kolom database.
+// @Summary Reorder columns in database
+// @Description Rewrite the order of all columns in a single atomic update. columnIds must contain every column ID of the database exactly once; the first ID gets order 1. Returns 409 when columns were added or removed concurrently.
+// @Tags Databases
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param order body dto.DatabaseColumnReorderRequest true "New Column Order"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseResponse} "Columns reordered successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or column IDs do not match the database columns"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to reorder columns in this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 409 {object} utils.APIResponse "Conflict - Columns changed while reordering"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/columns/reorder [patch]
+func (h *databaseHandlerImpl) ReorderColumnsInDatabase(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
 	}
-	if req.Type != "" {
-		setMap["databaseData.columns.$.type"] = req.Type
+
+	var req dto.DatabaseColumnReorderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
 	}
-	if req.Order != 0 {
-		setMap["databaseData.columns.$.order"] = req.Order
+	if len(req.ColumnIDs) == 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "columnIds is required")
 	}
 
-	// Jika tipe kolom berubah menjadi 'select' dan ada opsi, atau tipe tetap 'select' dan opsi diperbarui
-	if req.Type == "select" || (existingColumn.Type == "select" && req.Options != nil) {
-		var selectOptions []model.SelectOption
-		for optIdx, optValue := range req.Options {
-			selectOptions = append(selectOptions, model.SelectOption{
-				ID:        primitive.NewObjectID(), // Akan diganti jika ID opsi disediakan
-				Value:     optValue,
-				Order:     optIdx + 1,
-				CreatedAt: time.Now(),
-			})
+	columnIDs := make([]primitive.ObjectID, len(req.ColumnIDs))
+	seen := make(map[primitive.ObjectID]bool, len(req.ColumnIDs))
+	for i, idStr := range req.ColumnIDs {
+		columnID, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid column ID format", fmt.Sprintf("columnIds[%d]: %s", i, err.Error()))
 		}
-		setMap["databaseData.columns.$.options"] = selectOptions
-	} else if req.Type != "select" && existingColumn.Type == "select" && req.Options == nil {
-		// Jika tipe berubah dari 'select' ke non-select, hapus opsi yang ada
-		setMap["databaseData.columns.$.options"] = []model.SelectOption{}
+		if seen[columnID] {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", fmt.Sprintf("Duplicate column ID: %s", idStr))
+		}
+		seen[columnID] = true
+		columnIDs[i] = columnID
 	}
 
-	updatedDatabase, err := h.dbRepo.UpdateColumnInDatabase(ctx, databaseID, columnID, updateData)
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for reordering columns", err.Error())
+	}
+	if existingDB == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+
+	// Otorisasi: Hanya author yang dapat menyusun ulang kolom
+	// TODO: Tambahkan otorisasi lebih kompleks
+	if existingDB.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to reorder columns in this database", nil)
+	}
+
+	// columnIds harus mencakup semua kolom database, tidak lebih dan tidak kurang
+	if len(columnIDs) != len(existingDB.DatabaseData.Columns) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", fmt.Sprintf("columnIds must contain all %d columns of the database", len(existingDB.DatabaseData.Columns)))
+	}
+	for _, col := range existingDB.DatabaseData.Columns {
+		if !seen[col.ID] {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", fmt.Sprintf("columnIds is missing column %s", col.ID.Hex()))
+		}
+	}
+
+	updatedDatabase, err := h.dbRepo.ReorderColumnsInDatabase(ctx, databaseID, columnIDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrDatabaseColumnsChanged) {
+			return utils.SendErrorResponse(c, fiber.StatusConflict, "Columns changed while reordering, please reload and try again", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to reorder columns", err.Error())
+	}
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
+	orderedIDs := make([]string, len(updatedDatabase.DatabaseData.Columns))
+	for i, col := range updatedDatabase.DatabaseData.Columns {
+		orderedIDs[i] = col.ID.Hex()
+	}
+	h.publishEvent(c, databaseID, "columns_reordered", map[string]interface{}{"columnIds": orderedIDs})
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Columns reordered successfully", convertDatabaseToDTO(updatedDatabase))
+}
+
+// UpdateColumnInDatabase memperbarui kolom tertentu dalam database.
+// @Summary Update a column in database
+// @Description Update an existing column within a custom database. When constraints is sent it replaces all constraints of the column ({} removes them); existing constraints are re-validated when the type or options change. Making a column unique fails with 409 listing the conflicting rows when it already holds duplicate values.
+// @Tags Databases
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param columnId path string true "Column ID"
+// @Param column body dto.DatabaseColumnUpdateRequest true "Updated Column Details"
+// @Param If-Match header string false "ETag (database version) from a previous read; the update is rejected with 412 if the database changed since"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseResponse} "Column updated successfully"
+// @Header 200 {string} ETag "New database version"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input, validation error or invalid formula (including formulas of other columns broken by this change)"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update columns in this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or Column not found"
+// @Failure 409 {object} utils.APIResponse "Conflict - Column type cannot change while it groups a kanban/calendar view, or a unique column holds duplicate values"
+// @Failure 412 {object} utils.APIResponse{error=dto.DatabaseResponse} "Precondition Failed - Database was modified; the current database is returned"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/columns/{columnId} [put]
+func (h *databaseHandlerImpl) UpdateColumnInDatabase(c *fiber.Ctx) error {
+	databaseIDStr := c.Params("id")
+	columnIDStr := c.Params("columnId")
+
+	databaseID, err := primitive.ObjectIDFromHex(databaseIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	columnID, err := primitive.ObjectIDFromHex(columnIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid column ID format", err.Error())
+	}
+
+	var req dto.DatabaseColumnUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	// Validasi manual
+	if req.Name != "" && len(req.Name) < 1 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Column Name cannot be empty")
+	}
+	expectedVersion, err := parseIfMatchVersion(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid If-Match header", err.Error())
+	}
+
+	if req.Type != "" {
+		validTypes := map[string]bool{"date": true, "text": true, "select": true, "boolean": true, "number": true, "formula": true, "relation": true, "rollup": true, "file": true}
+		if !validTypes[req.Type] {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Invalid Column Type. Allowed types: date, text, select, boolean, number, formula, relation, rollup, file")
+		}
+	}
+
+	// Ambil UserID dari Locals (dari JWT Middleware) untuk otorisasi
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	// Dapatkan database yang ada untuk otorisasi
+	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for updating column", err.Error())
+	}
+	if existingDB == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+
+	// Otorisasi: Hanya author atau user dengan izin yang dapat memperbarui kolom
+	// TODO: Tambahkan otorisasi lebih kompleks
+	if existingDB.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update columns in this database", nil)
+	}
+	if expectedVersion > 0 && existingDB.Version != expectedVersion {
+		return h.sendDatabaseVersionConflict(ctx, c, databaseID, existingDB)
+	}
+
+	// Cari kolom yang akan diperbarui
+	var existingColumn *model.DatabaseColumn
+	for _, col := range existingDB.DatabaseData.Columns {
+		if col.ID == columnID {
+			existingColumn = &col
+			break
+		}
+	}
+
+	if existingColumn == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Column not found", nil)
+	}
+
+	// Kolom pengelompokan view kanban/calendar harus tetap bertipe select/date
+	if req.Type != "" && req.Type != existingColumn.Type {
+		if viewName := service.ViewReferencingColumn(existingDB.Views, columnID.Hex()); viewName != "" {
+			return utils.SendErrorResponse(c, fiber.StatusConflict, "Column is referenced by a view", fmt.Sprintf("Column type cannot change while it groups view '%s'", viewName))
+		}
+	}
+
+	// Buat map updateData untuk repository
+	updateData := bson.M{
+		"$set": bson.M{},
+	}
+	setMap := updateData["$set"].(bson.M)
+
+	if req.Name != "" {
+		setMap["databaseData.columns.$.name"] = req.Name
+	}
+	if req.Type != "" {
+		setMap["databaseData.columns.$.type"] = req.Type
+	}
+	if req.Order != 0 {
+		setMap["databaseData.columns.$.order"] = req.Order
+	}
+
+	// Jika tipe kolom berubah menjadi 'select' dan ada opsi, atau tipe tetap 'select' dan opsi diperbarui
+	if req.Type == "select" || (existingColumn.Type == "select" && req.Options != nil) {
+		var selectOptions []model.SelectOption
+		for optIdx, optValue := range req.Options {
+			selectOptions = append(selectOptions, model.SelectOption{
+				ID:        primitive.NewObjectID(), // Akan diganti jika ID opsi disediakan
+				Value:     optValue,
+				Order:     optIdx + 1,
+				CreatedAt: time.Now(),
+			})
+		}
+		setMap["databaseData.columns.$.options"] = selectOptions
+	} else if req.Type != "select" && existingColumn.Type == "select" && req.Options == nil {
+		// Jika tipe berubah dari 'select' ke non-select, hapus opsi yang ada
+		setMap["databaseData.columns.$.options"] = []model.SelectOption{}
+	}
+
+	// Formula: periksa ulang seluruh kolom formula karena perubahan nama atau tipe kolom ini
+	// dapat merusak formula lain yang merujuknya
+	updatedColumn := *existingColumn
+	if options, ok := setMap["databaseData.columns.$.options"].([]model.SelectOption); ok {
+		updatedColumn.Options = options
+	}
+	if req.Name != "" {
+		updatedColumn.Name = req.Name
+	}
+	if req.Type != "" {
+		updatedColumn.Type = req.Type
+	}
+	if updatedColumn.Type == "formula" {
+		if req.Formula != "" {
+			updatedColumn.Formula = req.Formula
+		}
+	} else {
+		updatedColumn.Formula = ""
+	}
+	if updatedColumn.Formula != existingColumn.Formula {
+		setMap["databaseData.columns.$.formula"] = updatedColumn.Formula
+	}
+	if req.Relation != nil || req.Rollup != nil || (req.Type != "" && req.Type != existingColumn.Type) {
+		relation, rollup, err := convertColumnLinksToModel(updatedColumn.Type, req.Relation, req.Rollup)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", err.Error())
+		}
+		updatedColumn.Relation, updatedColumn.Rollup = relation, rollup
+	}
+
+	// Batasan diperiksa ulang terhadap tipe dan opsi kolom yang baru
+	if req.Constraints != nil {
+		updatedColumn.Constraints = convertColumnConstraintsToModel(req.Constraints)
+	} else if existingColumn.Constraints != nil {
+		constraints := *existingColumn.Constraints
+		updatedColumn.Constraints = &constraints
+	}
+	if err := service.NormalizeColumnConstraints(&updatedColumn); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid column constraints", err.Error())
+	}
+	if ok, err := checkUniqueConflicts(c, &updatedColumn, existingDB.DatabaseData.Rows); !ok {
+		return err
+	}
+	setMap["databaseData.columns.$.constraints"] = updatedColumn.Constraints
+
+	candidateColumns := make([]model.DatabaseColumn, len(existingDB.DatabaseData.Columns))
+	for i, col := range existingDB.DatabaseData.Columns {
+		if col.ID == columnID {
+			col = updatedColumn
+		}
+		candidateColumns[i] = col
+	}
+	if err := service.ValidateFormulaColumns(candidateColumns); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid formula", err.Error())
+	}
+	if ok, err := h.resolveLinkedColumns(ctx, c, existingDB, candidateColumns); !ok {
+		return err
+	}
+	for _, col := range candidateColumns {
+		if col.ID == columnID {
+			setMap["databaseData.columns.$.relation"] = col.Relation
+			setMap["databaseData.columns.$.rollup"] = col.Rollup
+		}
+	}
+
+	updatedDatabase, err := h.dbRepo.UpdateColumnInDatabase(ctx, databaseID, columnID, updateData, expectedVersion)
 	if err != nil {
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return h.sendDatabaseVersionConflict(ctx, c, databaseID, nil)
+		}
 		if err == mongo.ErrNoDocuments {
 			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database or Column not found during update", nil)
 		}
@@ -1060,37 +1621,28 @@ func (h *databaseHandlerImpl) UpdateColumnInDatabase(c *fiber.Ctx) error {
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(updatedDatabase.DatabaseData.Columns))
 	for i, col := range updatedDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
 	respRows := make([]dto.DatabaseRowResponse, len(updatedDatabase.DatabaseData.Rows))
 	for i, row := range updatedDatabase.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
+	h.publishEvent(c, databaseID, "column_updated", findColumnDTO(updatedDatabase, columnID))
+	setVersionETag(c, updatedDatabase.Version)
+
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Column updated successfully", dto.DatabaseResponse{
 		ID:        updatedDatabase.ID.Hex(),
 		ChannelID: updatedDatabase.ChannelID.Hex(),
 		AuthorID:  updatedDatabase.AuthorID.Hex(),
 		Title:     updatedDatabase.Title,
+		Version:   updatedDatabase.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: updatedDatabase.CreatedAt,
@@ -1111,6 +1663,7 @@ func (h *databaseHandlerImpl) UpdateColumnInDatabase(c *fiber.Ctx) error {
 // @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
 // @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to delete columns from this database"
 // @Failure 404 {object} utils.APIResponse "Not Found - Database or Column not found"
+// @Failure 409 {object} utils.APIResponse "Conflict - Column is referenced by a formula or rollup column, or groups a kanban/calendar view"
 // @Failure 500 {object} utils.APIResponse "Internal Server Error"
 // @Router /databases/{id}/columns/{columnId} [delete]
 func (h *databaseHandlerImpl) DeleteColumnFromDatabase(c *fiber.Ctx) error {
@@ -1136,7 +1689,7 @@ func (h *databaseHandlerImpl) DeleteColumnFromDatabase(c *fiber.Ctx) error {
 	defer cancel()
 
 	// Dapatkan database yang ada untuk otorisasi
-	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for deleting column", err.Error())
 	}
@@ -1150,6 +1703,17 @@ func (h *databaseHandlerImpl) DeleteColumnFromDatabase(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to delete columns from this database", nil)
 	}
 
+	// Kolom yang masih dirujuk formula lain tidak boleh dihapus agar formula tersebut tetap valid
+	if formulaColumn := service.FormulaReferencesColumn(existingDB.DatabaseData.Columns, columnID.Hex()); formulaColumn != "" {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "Column is referenced by a formula", fmt.Sprintf("Column is used by formula column '%s'", formulaColumn))
+	}
+	if rollupColumn := service.LinkedColumnDependent(existingDB.DatabaseData.Columns, columnID.Hex()); rollupColumn != "" {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "Column is referenced by a rollup", fmt.Sprintf("Column is used by rollup column '%s'", rollupColumn))
+	}
+	if viewName := service.ViewReferencingColumn(existingDB.Views, columnID.Hex()); viewName != "" {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "Column is referenced by a view", fmt.Sprintf("Column is used to group view '%s'", viewName))
+	}
+
 	updatedDatabase, err := h.dbRepo.DeleteColumnFromDatabase(ctx, databaseID, columnID)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -1161,37 +1725,27 @@ func (h *databaseHandlerImpl) DeleteColumnFromDatabase(c *fiber.Ctx) error {
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(updatedDatabase.DatabaseData.Columns))
 	for i, col := range updatedDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
 	respRows := make([]dto.DatabaseRowResponse, len(updatedDatabase.DatabaseData.Rows))
 	for i, row := range updatedDatabase.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
+	h.publishEvent(c, databaseID, "column_deleted", map[string]string{"columnId": columnID.Hex()})
+
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Column deleted successfully", dto.DatabaseResponse{
 		ID:        updatedDatabase.ID.Hex(),
 		ChannelID: updatedDatabase.ChannelID.Hex(),
 		AuthorID:  updatedDatabase.AuthorID.Hex(),
 		Title:     updatedDatabase.Title,
+		Version:   updatedDatabase.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: updatedDatabase.CreatedAt,
@@ -1249,7 +1803,7 @@ func (h *databaseHandlerImpl) AddSelectOptionToColumn(c *fiber.Ctx) error {
 	defer cancel()
 
 	// Dapatkan database yang ada untuk otorisasi dan validasi kolom
-	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for adding select option", err.Error())
 	}
@@ -1296,37 +1850,27 @@ func (h *databaseHandlerImpl) AddSelectOptionToColumn(c *fiber.Ctx) error {
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(updatedDatabase.DatabaseData.Columns))
 	for i, col := range updatedDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
 	respRows := make([]dto.DatabaseRowResponse, len(updatedDatabase.DatabaseData.Rows))
 	for i, row := range updatedDatabase.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
+	h.publishEvent(c, databaseID, "select_option_created", map[string]interface{}{"columnId": columnID.Hex(), "optionId": newOption.ID.Hex(), "column": findColumnDTO(updatedDatabase, columnID)})
+
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Select option added successfully", dto.DatabaseResponse{
 		ID:        updatedDatabase.ID.Hex(),
 		ChannelID: updatedDatabase.ChannelID.Hex(),
 		AuthorID:  updatedDatabase.AuthorID.Hex(),
 		Title:     updatedDatabase.Title,
+		Version:   updatedDatabase.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: updatedDatabase.CreatedAt,
@@ -1390,7 +1934,7 @@ func (h *databaseHandlerImpl) UpdateSelectOptionInColumn(c *fiber.Ctx) error {
 	defer cancel()
 
 	// Dapatkan database yang ada untuk otorisasi dan validasi kolom/opsi
-	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for updating select option", err.Error())
 	}
@@ -1457,37 +2001,27 @@ func (h *databaseHandlerImpl) UpdateSelectOptionInColumn(c *fiber.Ctx) error {
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(updatedDatabase.DatabaseData.Columns))
 	for i, col := range updatedDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
 	respRows := make([]dto.DatabaseRowResponse, len(updatedDatabase.DatabaseData.Rows))
 	for i, row := range updatedDatabase.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
+	h.publishEvent(c, databaseID, "select_option_updated", map[string]interface{}{"columnId": columnID.Hex(), "optionId": optionID.Hex(), "column": findColumnDTO(updatedDatabase, columnID)})
+
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Select option updated successfully", dto.DatabaseResponse{
 		ID:        updatedDatabase.ID.Hex(),
 		ChannelID: updatedDatabase.ChannelID.Hex(),
 		AuthorID:  updatedDatabase.AuthorID.Hex(),
 		Title:     updatedDatabase.Title,
+		Version:   updatedDatabase.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: updatedDatabase.CreatedAt,
@@ -1539,7 +2073,7 @@ func (h *databaseHandlerImpl) DeleteSelectOptionFromColumn(c *fiber.Ctx) error {
 	defer cancel()
 
 	// Dapatkan database yang ada untuk otorisasi dan validasi kolom/opsi
-	existingDB, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for deleting select option", err.Error())
 	}
@@ -1594,37 +2128,27 @@ func (h *databaseHandlerImpl) DeleteSelectOptionFromColumn(c *fiber.Ctx) error {
 	// Konversi ke DatabaseResponse
 	respColumns := make([]dto.DatabaseColumnResponse, len(updatedDatabase.DatabaseData.Columns))
 	for i, col := range updatedDatabase.DatabaseData.Columns {
-		respOptions := make([]dto.SelectOptionResponse, len(col.Options))
-		for j, opt := range col.Options {
-			respOptions[j] = dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			}
-		}
-		respColumns[i] = dto.DatabaseColumnResponse{
-			ID:      col.ID.Hex(),
-			Name:    col.Name,
-			Type:    col.Type,
-			Options: respOptions,
-			Order:   col.Order,
-		}
+		respColumns[i] = convertDatabaseColumnToDTO(col)
 	}
 
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
 	respRows := make([]dto.DatabaseRowResponse, len(updatedDatabase.DatabaseData.Rows))
 	for i, row := range updatedDatabase.DatabaseData.Rows {
 		respRows[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
 		}
 	}
 
+	h.publishEvent(c, databaseID, "select_option_deleted", map[string]interface{}{"columnId": columnID.Hex(), "optionId": optionID.Hex(), "column": findColumnDTO(updatedDatabase, columnID)})
+
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Select option deleted successfully", dto.DatabaseResponse{
 		ID:        updatedDatabase.ID.Hex(),
 		ChannelID: updatedDatabase.ChannelID.Hex(),
 		AuthorID:  updatedDatabase.AuthorID.Hex(),
 		Title:     updatedDatabase.Title,
+		Version:   updatedDatabase.Version,
 		Columns:   respColumns,
 		Rows:      respRows,
 		CreatedAt: updatedDatabase.CreatedAt,
@@ -1648,7 +2172,7 @@ func (handler *databaseHandlerImpl) GetColumnInDatabase(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Tidak terautentikasi", err.Error())
 	}
 
-	database, err := handler.dbRepo.GetDatabaseByID(c.Context(), databaseID)
+	database, err := handler.dbRepo.GetDatabaseSchemaByID(c.Context(), databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal mengambil database", err.Error())
 	}
@@ -1665,22 +2189,7 @@ func (handler *databaseHandlerImpl) GetColumnInDatabase(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal mengambil kolom", err.Error())
 	}
 
-	columnResponse := dto.DatabaseColumnResponse{
-		ID:    column.ID.Hex(),
-		Name:  column.Name,
-		Type:  column.Type,
-		Order: column.Order,
-	}
-	if column.Type == "select" && len(column.Options) > 0 {
-		for _, opt := range column.Options {
-			columnResponse.Options = append(columnResponse.Options, dto.SelectOptionResponse{
-				ID:        opt.ID.Hex(),
-				Value:     opt.Value,
-				Order:     opt.Order,
-				CreatedAt: opt.CreatedAt,
-			})
-		}
-	}
+	columnResponse := convertDatabaseColumnToDTO(*column)
 
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Kolom berhasil diambil", columnResponse)
 }
@@ -1706,7 +2215,7 @@ func (handler *databaseHandlerImpl) GetSelectOptionInColumn(c *fiber.Ctx) error
 		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Tidak terautentikasi", err.Error())
 	}
 
-	database, err := handler.dbRepo.GetDatabaseByID(c.Context(), databaseID)
+	database, err := handler.dbRepo.GetDatabaseSchemaByID(c.Context(), databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal mengambil database", err.Error())
 	}
@@ -1749,7 +2258,7 @@ func (handler *databaseHandlerImpl) GetRowInDatabase(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Tidak terautentikasi", err.Error())
 	}
 
-	database, err := handler.dbRepo.GetDatabaseByID(c.Context(), databaseID)
+	database, err := handler.dbRepo.GetDatabaseSchemaByID(c.Context(), databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal mengambil database", err.Error())
 	}
@@ -1766,49 +2275,1579 @@ func (handler *databaseHandlerImpl) GetRowInDatabase(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal mengambil baris", err.Error())
 	}
 
+	// Hitung nilai kolom rollup dan formula untuk baris ini
+	evaluated := *database
+	evaluated.DatabaseData.Rows = []model.DatabaseRow{*row}
+	service.ComputeDerivedColumns(c.Context(), handler.dbRepo, &evaluated)
+	row = &evaluated.DatabaseData.Rows[0]
+
 	rowResponse := dto.DatabaseRowResponse{
-		ID:     row.ID.Hex(),
-		Values: dto.DatabaseRowValueResponse(row.Values),
+		ID:      row.ID.Hex(),
+		Values:  dto.DatabaseRowValueResponse(row.Values),
+		Version: row.Version,
 	}
 
+	setVersionETag(c, row.Version)
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Baris berhasil diambil", rowResponse)
 }
 
+// GetRowsByDatabaseID retrieves the rows of a database with filtering, sorting and pagination.
+// @Summary Query database rows
+// @Description Get the rows of a database. Filters are typed according to DatabaseColumn.Type, so number and date comparisons are numeric/chronological.
+// @Description filters: JSON object {"match":"all|any","conditions":[{"columnId":"...","operator":"equals","value":...}]} or an array of conditions. Operators: equals, not_equals, contains, gt, gte, lt, lte, between (value [min,max], null = open bound), is_empty, is_not_empty, in (select options, by ID or value).
+// @Description sort: comma separated "columnId:asc|desc". Use either page or cursor (nextCursor from the previous page) for pagination.
+// @Description Without any of filters, sort, page, limit or cursor, data is the plain array of all rows ([]dto.DatabaseRowResponse) as in earlier versions of this endpoint; with at least one of them, data is a dto.DatabaseRowsPageResponse.
+// @Tags Databases
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param filters query string false "Filter JSON"
+// @Param sort query string false "Sort, e.g. colA:desc,colB:asc"
+// @Param page query int false "Page number (starts at 1)"
+// @Param limit query int false "Rows per page (default 100, max 1000)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseRowsPageResponse} "Rows retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format or query parameters"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/rows [get]
 func (handler *databaseHandlerImpl) GetRowsByDatabaseID(c *fiber.Ctx) error {
 	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "ID database tidak valid", err.Error())
 	}
 
-	userID, err := utils.GetUserIDFromToken(c)
+	userID, ok := c.Locals("userID").(string)
+	if !ok || userID == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Tidak terautentikasi", nil)
+	}
+
+	page, err := parseOptionalIntQuery(c, "page")
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Tidak terautentikasi", err.Error())
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Parameter page tidak valid", err.Error())
+	}
+	limit, err := parseOptionalIntQuery(c, "limit")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Parameter limit tidak valid", err.Error())
 	}
 
-	database, err := handler.dbRepo.GetDatabaseByID(c.Context(), databaseID)
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	// Baris tidak dimuat di sini; ExecuteRowQuery hanya mengambil baris halaman yang diminta
+	database, err := handler.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal mengambil database", err.Error())
 	}
+	if database == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database tidak ditemukan", nil)
+	}
 
 	if database.AuthorID.Hex() != userID {
 		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Anda tidak memiliki izin untuk mengakses database ini", nil)
 	}
 
-	rows, err := handler.dbRepo.GetRowsByDatabaseID(c.Context(), databaseID)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) || strings.Contains(err.Error(), "tidak ditemukan") {
-			return utils.SendErrorResponse(c, fiber.StatusNotFound, err.Error(), nil)
+	columns := database.DatabaseData.Columns
+
+	// Tanpa parameter query, respons tetap berupa array semua baris agar client lama tidak rusak
+	if !hasRowQueryParams(c) {
+		rows, err := handler.dbRepo.GetRowsByDatabaseID(ctx, databaseID)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal mengambil baris", err.Error())
 		}
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal mengambil baris", err.Error())
+		database.DatabaseData.Rows = rows
+		service.ComputeDerivedColumns(ctx, handler.dbRepo, database)
+		return utils.SendSuccessResponse(c, fiber.StatusOK, "Baris berhasil diambil", convertRowsToDTO(database.DatabaseData.Rows))
 	}
 
-	rowsResponse := make([]dto.DatabaseRowResponse, len(rows))
-	for i, row := range rows {
-		rowsResponse[i] = dto.DatabaseRowResponse{
-			ID:     row.ID.Hex(),
-			Values: dto.DatabaseRowValueResponse(row.Values),
+	filter, err := service.ParseRowFilterParam(c.Query("filters"), columns)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Parameter filters tidak valid", err.Error())
+	}
+	sorts, err := service.ParseRowSort(c.Query("sort"), columns)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Parameter sort tidak valid", err.Error())
+	}
+
+	result, err := service.ExecuteRowQuery(ctx, handler.dbRepo, database, service.RowQuery{
+		Filter: filter,
+		Sort:   sorts,
+		Page:   page,
+		Limit:  limit,
+		Cursor: c.Query("cursor"),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRowQuery) {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Parameter query tidak valid", err.Error())
 		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Gagal mengambil baris", err.Error())
 	}
 
-	return utils.SendSuccessResponse(c, fiber.StatusOK, "Baris berhasil diambil", rowsResponse)
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Baris berhasil diambil", dto.DatabaseRowsPageResponse{
+		Rows:       convertRowsToDTO(result.Rows),
+		Total:      result.Total,
+		Page:       result.Page,
+		Limit:      result.Limit,
+		HasMore:    result.HasMore,
+		NextCursor: result.NextCursor,
+	})
+}
+
+// ExportDatabase exports all rows of a database as a CSV or XLSX file.
+// @Summary Export database to CSV or XLSX
+// @Description Download the database as a spreadsheet. Columns are written in their configured order with the column names as header; select values are written as option values, relation values as comma-separated row IDs, and formula/rollup columns as their computed values.
+// @Tags Databases
+// @Produce octet-stream
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param format query string false "File format: csv (default) or xlsx"
+// @Success 200 {file} file "Spreadsheet file"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID or format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/export [get]
+func (h *databaseHandlerImpl) ExportDatabase(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	format := strings.ToLower(c.Query("format", service.SpreadsheetFormatCSV))
+	if format != service.SpreadsheetFormatCSV && format != service.SpreadsheetFormatXLSX {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid export format", "format must be csv or xlsx")
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
+	defer cancel()
+
+	database, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get database", err.Error())
+	}
+	if database == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if database.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to access this database", nil)
+	}
+
+	service.ComputeDerivedColumns(ctx, h.dbRepo, database)
+	header, records := service.BuildExportTable(database.DatabaseData.Columns, database.DatabaseData.Rows)
+
+	var buf bytes.Buffer
+	if err := service.WriteSpreadsheet(&buf, format, database.Title, header, records); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to export database", err.Error())
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == service.SpreadsheetFormatXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, exportFileName(database.Title, format)))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// ImportDatabase creates a new database from a CSV or XLSX file.
+// @Summary Import a new database from CSV or XLSX
+// @Description Create a new database from a spreadsheet in a channel of type "databases" that belongs to a business the user owns or is a member of. The first row is the header; column types (boolean, number, date, select or text) are inferred from the values. Invalid rows are skipped and reported per row instead of failing the whole file.
+// @Tags Databases
+// @Accept mpfd
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "CSV or XLSX file"
+// @Param channelId formData string true "Channel ID"
+// @Param title formData string false "Database title (defaults to the file name)"
+// @Param format formData string false "File format: csv or xlsx (defaults to the file extension)"
+// @Success 201 {object} utils.APIResponse{data=dto.DatabaseImportResponse} "Database imported successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid file or input"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Channel not found or not in a business of the user"
+// @Failure 409 {object} utils.APIResponse "Conflict - Channel is not a databases channel"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/import [post]
+func (h *databaseHandlerImpl) ImportDatabase(c *fiber.Ctx) error {
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Author ID format", err.Error())
+	}
+	channelID, err := primitive.ObjectIDFromHex(c.FormValue("channelId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Channel ID format", err.Error())
+	}
+
+	fileName, header, records, ok, err := readImportFile(c)
+	if !ok {
+		return err
+	}
+	title := strings.TrimSpace(c.FormValue("title"))
+	if title == "" {
+		title = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	}
+	if len(title) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long")
+	}
+
+	columns := service.InferImportColumns(header, records)
+	targets := make([]*model.DatabaseColumn, len(columns))
+	for i := range columns {
+		targets[i] = &columns[i]
+	}
+
+	// Import dapat berisi ribuan baris, sehingga batas waktunya lebih longgar dari operasi biasa
+	ctx, cancel := context.WithTimeout(c.Context(), 60*time.Second)
+	defer cancel()
+
+	if _, err := h.channels.ValidateContentChannel(ctx, channelID, authorID, service.ChannelTypeDatabases); err != nil {
+		return sendChannelAccessError(c, err)
+	}
+	rows, rowErrors, err := service.BuildImportRows(ctx, h.dbRepo, columns, targets, primitive.NilObjectID, records)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to import rows", err.Error())
+	}
+
+	newDatabase := &model.Database{
+		ID:        primitive.NewObjectID(),
+		ChannelID: channelID,
+		AuthorID:  authorID,
+		Title:     title,
+		DatabaseData: model.DatabaseData{
+			Columns: columns,
+			Rows:    rows,
+		},
+	}
+	if err := h.dbRepo.CreateDatabase(ctx, newDatabase); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create database", err.Error())
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Database imported successfully", newImportResponse(newDatabase, len(rows), rowErrors, nil))
+}
+
+// ImportRowsToDatabase appends rows from a CSV or XLSX file to an existing database.
+// @Summary Import rows into a database from CSV or XLSX
+// @Description Append the rows of a spreadsheet to an existing database. The optional mapping is a JSON object of file header -> column ID or name (an empty value ignores the header); without it, headers are matched to column names case-insensitively. Formula, rollup and file columns cannot be imported. Invalid rows are skipped and reported per row instead of failing the whole file. The response contains the database with only the imported rows.
+// @Tags Databases
+// @Accept mpfd
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param file formData file true "CSV or XLSX file"
+// @Param mapping formData string false "JSON object mapping file headers to column IDs or names"
+// @Param format formData string false "File format: csv or xlsx (defaults to the file extension)"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseImportResponse} "Rows imported successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid file or mapping"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to add rows to this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/import [post]
+func (h *databaseHandlerImpl) ImportRowsToDatabase(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	var mapping map[string]string
+	if rawMapping := strings.TrimSpace(c.FormValue("mapping")); rawMapping != "" {
+		if err := json.Unmarshal([]byte(rawMapping), &mapping); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid mapping", err.Error())
+		}
+		if mapping == nil {
+			mapping = map[string]string{}
+		}
+	}
+
+	_, header, records, ok, err := readImportFile(c)
+	if !ok {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 60*time.Second)
+	defer cancel()
+
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for import", err.Error())
+	}
+	if existingDB == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if existingDB.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to add rows to this database", nil)
+	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
+
+	columns := existingDB.DatabaseData.Columns
+	targets, ignored, err := service.ResolveImportMapping(columns, header, mapping)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid mapping", err.Error())
+	}
+	if len(ignored) == len(header) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid mapping", "No file header is mapped to a database column")
+	}
+
+	rows, rowErrors, err := service.BuildImportRows(ctx, h.dbRepo, columns, targets, databaseID, records)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to import rows", err.Error())
+	}
+
+	updatedDatabase := existingDB
+	if len(rows) > 0 {
+		updatedDatabase, err = h.dbRepo.AddRowsToDatabase(ctx, databaseID, rows, authorID)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to add rows to database", err.Error())
+		}
+		if updatedDatabase == nil {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+		}
+	}
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
+
+	if len(rows) > 0 {
+		rowIDs := make([]primitive.ObjectID, len(rows))
+		for i, row := range rows {
+			rowIDs[i] = row.ID
+		}
+		h.publishEvent(c, databaseID, "rows_changed", rowsChangedPayload(updatedDatabase, rowIDs, nil))
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Rows imported successfully", newImportResponse(updatedDatabase, len(rows), rowErrors, ignored))
+}
+
+// readImportFile membaca file spreadsheet dari form field "file" dan memisahkan header dari baris data.
+// Mengembalikan false jika response error sudah dikirim.
+func readImportFile(c *fiber.Ctx) (string, []string, [][]string, bool, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return "", nil, nil, false, utils.SendErrorResponse(c, fiber.StatusBadRequest, "File is required", err.Error())
+	}
+	format := strings.ToLower(strings.TrimSpace(c.FormValue("format")))
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(fileHeader.Filename), "."))
+	}
+	if format != service.SpreadsheetFormatCSV && format != service.SpreadsheetFormatXLSX {
+		return "", nil, nil, false, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Unsupported file format", "format must be csv or xlsx")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", nil, nil, false, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Failed to read file", err.Error())
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", nil, nil, false, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Failed to read file", err.Error())
+	}
+
+	records, err := service.ReadSpreadsheet(format, data)
+	if err != nil {
+		return "", nil, nil, false, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid spreadsheet file", err.Error())
+	}
+	// Baris kosong di akhir file diabaikan
+	for len(records) > 0 && strings.TrimSpace(strings.Join(records[len(records)-1], "")) == "" {
+		records = records[:len(records)-1]
+	}
+	if len(records) == 0 {
+		return "", nil, nil, false, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid spreadsheet file", "The file has no header row")
+	}
+	if len(records)-1 > service.MaxImportRows {
+		return "", nil, nil, false, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid spreadsheet file", fmt.Sprintf("The file has more than %d data rows", service.MaxImportRows))
+	}
+	return fileHeader.Filename, records[0], records[1:], true, nil
+}
+
+// newImportResponse menyusun response hasil import.
+func newImportResponse(database *model.Database, imported int, rowErrors []service.ImportRowError, ignored []string) dto.DatabaseImportResponse {
+	respErrors := make([]dto.DatabaseImportRowError, len(rowErrors))
+	for i, rowErr := range rowErrors {
+		respErrors[i] = dto.DatabaseImportRowError{Row: rowErr.Row, Errors: convertRowFieldErrorsToDTO(rowErr.Errors)}
+	}
+	return dto.DatabaseImportResponse{
+		Database:       convertDatabaseToDTO(database),
+		ImportedRows:   imported,
+		FailedRows:     len(rowErrors),
+		IgnoredHeaders: ignored,
+		Errors:         respErrors,
+	}
+}
+
+// convertRowFieldErrorsToDTO mengonversi daftar kolom yang nilainya tidak valid menjadi DTO.
+func convertRowFieldErrorsToDTO(fieldErrors []service.RowFieldError) []dto.DatabaseImportFieldError {
+	if fieldErrors == nil {
+		return nil
+	}
+	respErrors := make([]dto.DatabaseImportFieldError, len(fieldErrors))
+	for i, fieldErr := range fieldErrors {
+		respErrors[i] = dto.DatabaseImportFieldError{
+			ColumnID:   fieldErr.ColumnID,
+			ColumnName: fieldErr.ColumnName,
+			Message:    fieldErr.Message,
+		}
+	}
+	return respErrors
+}
+
+// exportFileName membuat nama file export yang aman dari judul database.
+func exportFileName(title, format string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || strings.ContainsRune(`"\/:*?<>|;`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if name == "" {
+		name = "database"
+	}
+	return name + "." + format
+}
+
+// GetDatabaseViews retrieves the saved views of a database.
+// @Summary Get database views
+// @Description Get all saved views (table, kanban, calendar) of a database.
+// @Tags Databases
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Success 200 {object} utils.APIResponse{data=[]dto.DatabaseViewResponse} "Views retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/views [get]
+func (h *databaseHandlerImpl) GetDatabaseViews(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	database, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get database", err.Error())
+	}
+	if database == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if database.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to access this database", nil)
+	}
+
+	respViews := make([]dto.DatabaseViewResponse, len(database.Views))
+	for i, view := range database.Views {
+		respViews[i] = convertDatabaseViewToDTO(view)
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Views retrieved successfully", respViews)
+}
+
+// CreateDatabaseView adds a saved view to a database.
+// @Summary Create a database view
+// @Description Save a named view with its own filter, sort, visible columns and layout type. Kanban views require groupByColumnId (a select column); calendar views require dateColumnId (a date column).
+// @Tags Databases
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param view body dto.DatabaseViewRequest true "View Details"
+// @Success 201 {object} utils.APIResponse{data=dto.DatabaseViewResponse} "View created successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or view configuration"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/views [post]
+func (h *databaseHandlerImpl) CreateDatabaseView(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+
+	var req dto.DatabaseViewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for creating view", err.Error())
+	}
+	if existingDB == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if existingDB.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update this database", nil)
+	}
+
+	view := convertViewRequestToModel(req)
+	if err := service.ValidateDatabaseView(existingDB.DatabaseData.Columns, &view); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid view", err.Error())
+	}
+
+	updatedDatabase, err := h.dbRepo.AddViewToDatabase(ctx, databaseID, &view)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create view", err.Error())
+	}
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "View created successfully", convertDatabaseViewToDTO(view))
+}
+
+// UpdateDatabaseView replaces the configuration of a saved view.
+// @Summary Update a database view
+// @Description Replace the name, layout type, filter, sort and visible columns of a saved view.
+// @Tags Databases
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param viewId path string true "View ID"
+// @Param view body dto.DatabaseViewRequest true "View Details"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseViewResponse} "View updated successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or view configuration"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or View not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/views/{viewId} [put]
+func (h *databaseHandlerImpl) UpdateDatabaseView(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	viewID, err := primitive.ObjectIDFromHex(c.Params("viewId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid view ID format", err.Error())
+	}
+
+	var req dto.DatabaseViewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for updating view", err.Error())
+	}
+	if existingDB == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if existingDB.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update this database", nil)
+	}
+	existingView := findDatabaseView(existingDB.Views, viewID)
+	if existingView == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "View not found", nil)
+	}
+
+	view := convertViewRequestToModel(req)
+	if err := service.ValidateDatabaseView(existingDB.DatabaseData.Columns, &view); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid view", err.Error())
+	}
+	view.CreatedAt = existingView.CreatedAt
+
+	updatedDatabase, err := h.dbRepo.UpdateViewInDatabase(ctx, databaseID, viewID, &view)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update view", err.Error())
+	}
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database or View not found", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "View updated successfully", convertDatabaseViewToDTO(view))
+}
+
+// DeleteDatabaseView removes a saved view from a database.
+// @Summary Delete a database view
+// @Description Delete a saved view. Rows and columns of the database are not affected.
+// @Tags Databases
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param viewId path string true "View ID"
+// @Success 200 {object} utils.APIResponse "View deleted successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or View not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/views/{viewId} [delete]
+func (h *databaseHandlerImpl) DeleteDatabaseView(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	viewID, err := primitive.ObjectIDFromHex(c.Params("viewId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid view ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for deleting view", err.Error())
+	}
+	if existingDB == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if existingDB.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update this database", nil)
+	}
+
+	updatedDatabase, err := h.dbRepo.DeleteViewFromDatabase(ctx, databaseID, viewID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete view", err.Error())
+	}
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "View not found", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "View deleted successfully", nil)
+}
+
+// GetDatabaseViewRows retrieves the rows of a database shaped for a saved view.
+// @Summary Get rows shaped for a view
+// @Description Apply the view's filter, sort and visible columns and return rows shaped for its layout: a page of rows for table views, one group per select option (in option order, plus an "(empty)" group) for kanban views, and one bucket per day (UTC, YYYY-MM-DD) for calendar views.
+// @Tags Databases
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param viewId path string true "View ID"
+// @Param page query int false "Table: page number (starting at 1)"
+// @Param limit query int false "Table: rows per page; kanban: rows per group (default 100, max 1000)"
+// @Param cursor query string false "Table: cursor from the previous page's nextCursor"
+// @Param from query string false "Calendar: first date to include (YYYY-MM-DD or RFC3339)"
+// @Param to query string false "Calendar: last date to include (YYYY-MM-DD or RFC3339)"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseViewRowsResponse} "View rows retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID, query parameter or view configuration"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or View not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/views/{viewId}/rows [get]
+func (h *databaseHandlerImpl) GetDatabaseViewRows(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	viewID, err := primitive.ObjectIDFromHex(c.Params("viewId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid view ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	opts := service.ViewRowsOptions{Cursor: c.Query("cursor")}
+	if opts.Page, err = parseOptionalIntQuery(c, "page"); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid page parameter", err.Error())
+	}
+	if opts.Limit, err = parseOptionalIntQuery(c, "limit"); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid limit parameter", err.Error())
+	}
+	if opts.From, err = parseOptionalDateQuery(c, "from"); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid from parameter", err.Error())
+	}
+	if opts.To, err = parseOptionalDateQuery(c, "to"); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid to parameter", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	database, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get database", err.Error())
+	}
+	if database == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if database.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to access this database", nil)
+	}
+	view := findDatabaseView(database.Views, viewID)
+	if view == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "View not found", nil)
+	}
+
+	// Kolom rollup dan formula dihitung lebih dulu agar dapat difilter, diurutkan dan ditampilkan
+	service.ComputeDerivedColumns(ctx, h.dbRepo, database)
+	result, err := service.QueryViewRows(database.DatabaseData.Columns, database.DatabaseData.Rows, view, opts)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRowQuery) {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid query parameter", err.Error())
+		}
+		if errors.Is(err, service.ErrInvalidDatabaseView) {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid view", err.Error())
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get view rows", err.Error())
+	}
+
+	resp := dto.DatabaseViewRowsResponse{
+		View:    convertDatabaseViewToDTO(*view),
+		Columns: make([]dto.DatabaseColumnResponse, len(result.Columns)),
+		Undated: result.Undated,
+	}
+	for i, col := range result.Columns {
+		resp.Columns[i] = convertDatabaseColumnToDTO(col)
+	}
+	switch {
+	case result.Table != nil:
+		resp.Table = &dto.DatabaseRowsPageResponse{
+			Rows:       convertRowsToDTO(result.Table.Rows),
+			Total:      result.Table.Total,
+			Page:       result.Table.Page,
+			Limit:      result.Table.Limit,
+			HasMore:    result.Table.HasMore,
+			NextCursor: result.Table.NextCursor,
+		}
+	case result.Groups != nil:
+		resp.Groups = make([]dto.DatabaseViewGroupResponse, len(result.Groups))
+		for i, group := range result.Groups {
+			resp.Groups[i] = dto.DatabaseViewGroupResponse{
+				Key:   group.Key,
+				Label: group.Label,
+				Rows:  convertRowsToDTO(group.Rows),
+				Total: group.Total,
+			}
+		}
+	default:
+		resp.Buckets = make([]dto.DatabaseViewDateBucketResponse, len(result.Buckets))
+		for i, bucket := range result.Buckets {
+			resp.Buckets[i] = dto.DatabaseViewDateBucketResponse{
+				Date: bucket.Date,
+				Rows: convertRowsToDTO(bucket.Rows),
+			}
+		}
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "View rows retrieved successfully", resp)
+}
+
+// GetRowRevisions retrieves the change history of a row.
+// @Summary Get row history
+// @Description Get the revisions of a row, newest first. Every create, update, delete and restore is recorded with its author, timestamp and field-level changes. History remains available after the row is deleted.
+// @Tags Databases
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param rowId path string true "Row ID"
+// @Param limit query int false "Maximum number of revisions (default 100, max 1000)"
+// @Success 200 {object} utils.APIResponse{data=[]dto.DatabaseRowRevisionResponse} "Row history retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format or limit"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/rows/{rowId}/revisions [get]
+func (h *databaseHandlerImpl) GetRowRevisions(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	rowID, err := primitive.ObjectIDFromHex(c.Params("rowId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid row ID format", err.Error())
+	}
+	limit, err := parseOptionalIntQuery(c, "limit")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid limit parameter", err.Error())
+	}
+	if limit == 0 {
+		limit = service.DefaultRowQueryLimit
+	}
+	if limit > service.MaxRowQueryLimit {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid limit parameter", fmt.Sprintf("limit must not exceed %d", service.MaxRowQueryLimit))
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	database, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get database", err.Error())
+	}
+	if database == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if database.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to access this database", nil)
+	}
+
+	revisions, err := h.dbRepo.GetRowRevisions(ctx, databaseID, rowID, limit)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get row history", err.Error())
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Row history retrieved successfully", convertRowRevisionsToDTO(revisions))
+}
+
+// GetDeletedRows retrieves the rows of a database that have been deleted.
+// @Summary Get deleted rows
+// @Description Get the latest delete revision of every row that no longer exists, newest first. The values of each revision are the row contents just before deletion and can be restored with the restore endpoint.
+// @Tags Databases
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Success 200 {object} utils.APIResponse{data=[]dto.DatabaseRowRevisionResponse} "Deleted rows retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/rows/deleted [get]
+func (h *databaseHandlerImpl) GetDeletedRows(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	database, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get database", err.Error())
+	}
+	if database == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if database.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to access this database", nil)
+	}
+
+	revisions, err := h.dbRepo.GetDeletedRows(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get deleted rows", err.Error())
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Deleted rows retrieved successfully", convertRowRevisionsToDTO(revisions))
+}
+
+// RestoreRowRevision restores a row, or a deleted row, to a chosen revision.
+// @Summary Restore a row to a revision
+// @Description Replace the values of a row with the values of one of its revisions; a deleted row is recreated with the same ID. Values of columns that no longer exist are skipped, remaining values are validated against the current column types and constraints. The restore itself is recorded as a new revision. The response contains the database with only the restored row.
+// @Tags Databases
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param rowId path string true "Row ID"
+// @Param revisionId path string true "Revision ID"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseResponse} "Row restored successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format or revision values no longer valid for the current columns"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update rows in this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or Revision not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/rows/{rowId}/revisions/{revisionId}/restore [post]
+func (h *databaseHandlerImpl) RestoreRowRevision(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+	rowID, err := primitive.ObjectIDFromHex(c.Params("rowId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid row ID format", err.Error())
+	}
+	revisionID, err := primitive.ObjectIDFromHex(c.Params("revisionId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid revision ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for restoring row", err.Error())
+	}
+	if existingDB == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if existingDB.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update rows in this database", nil)
+	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
+
+	revision, err := h.dbRepo.GetRowRevision(ctx, databaseID, revisionID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get revision", err.Error())
+	}
+	if revision == nil || revision.RowID != rowID {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Revision not found", nil)
+	}
+
+	// Nilai revisi divalidasi ulang karena kolom database dapat berubah sejak revisi dibuat
+	columns := existingDB.DatabaseData.Columns
+	revisionValues, _ := service.RevisionRowValues(columns, revision.Values)
+	values, err := service.ValidateRowValues(columns, revisionValues)
+	if err != nil {
+		return sendRowValidationError(c, "Revision values are no longer valid", err)
+	}
+	if ok, err := h.validateReferenceValues(ctx, c, databaseID, columns, values, "Revision values are no longer valid"); !ok {
+		return err
+	}
+	// Revisi lama dapat bertabrakan dengan nilai unik yang sudah dipakai baris lain sejak revisi dibuat
+	values, err = service.NewStoredRowConstraintChecker(ctx, h.dbRepo, databaseID, columns).Check(rowID, values)
+	if err != nil {
+		return sendRowValidationError(c, "Revision values are no longer valid", err)
+	}
+
+	updatedDatabase, err := h.dbRepo.RestoreRow(ctx, databaseID, rowID, values, revisionID, authorID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to restore row", err.Error())
+	}
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
+	h.publishEvent(c, databaseID, "rows_changed", rowsChangedPayload(updatedDatabase, []primitive.ObjectID{rowID}, nil))
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Row restored successfully", convertDatabaseToDTO(updatedDatabase))
+}
+
+// maxRowBatchOperations adalah jumlah maksimum operasi dalam satu request batch baris.
+const maxRowBatchOperations = 500
+
+// Status hasil operasi batch baris.
+const (
+	rowBatchStatusApplied = "applied"
+	rowBatchStatusInvalid = "invalid"
+	rowBatchStatusFailed  = "failed"
+	rowBatchStatusSkipped = "skipped"
+)
+
+// BatchRowOperations applies mixed insert, update and delete operations to a database atomically.
+// @Summary Apply bulk row operations
+// @Description Apply up to 500 insert, update and delete operations to one database in a single MongoDB transaction (requires a replica set). Every operation is validated first with the same rules as the single-row endpoints; if any operation is invalid nothing is applied and the error lists a result per operation (status invalid or skipped). Operations run in request order and each row ID may appear in at most one update or delete. On success every result has status applied and inserts report the new row ID. The response contains the database with only the inserted and updated rows.
+// @Tags Databases
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Database ID"
+// @Param batch body dto.DatabaseRowBatchRequest true "Row operations"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseRowBatchResponse} "Batch operations applied successfully"
+// @Failure 400 {object} utils.APIResponse{error=[]dto.DatabaseRowBatchResult} "Bad Request - Invalid input or one or more invalid operations"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update rows in this database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database not found"
+// @Failure 409 {object} utils.APIResponse{error=[]dto.DatabaseRowBatchResult} "Conflict - A row was changed by another request; nothing was applied"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /databases/{id}/rows/batch [post]
+func (h *databaseHandlerImpl) BatchRowOperations(c *fiber.Ctx) error {
+	databaseID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+
+	var req dto.DatabaseRowBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if len(req.Operations) == 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "At least one operation is required")
+	}
+	if len(req.Operations) > maxRowBatchOperations {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", fmt.Sprintf("A batch may contain at most %d operations", maxRowBatchOperations))
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
+	defer cancel()
+
+	existingDB, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database for batch operations", err.Error())
+	}
+	if existingDB == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if existingDB.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update rows in this database", nil)
+	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
+
+	// Validasi semua operasi lebih dulu; satu operasi tidak valid membatalkan seluruh batch
+	columns := existingDB.DatabaseData.Columns
+	// Hanya baris yang dirujuk operasi update dan delete yang diambil untuk memastikan barisnya ada
+	var referencedIDs []primitive.ObjectID
+	for _, reqOp := range req.Operations {
+		if rowID, err := primitive.ObjectIDFromHex(reqOp.RowID); err == nil && reqOp.Op != repository.RowBatchInsert {
+			referencedIDs = append(referencedIDs, rowID)
+		}
+	}
+	referencedRows, err := h.dbRepo.GetRowsByIDs(ctx, databaseID, referencedIDs)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve rows for batch operations", err.Error())
+	}
+	existingRows := make(map[primitive.ObjectID]bool, len(referencedRows))
+	for _, row := range referencedRows {
+		existingRows[row.ID] = true
+	}
+	touchedRows := make(map[primitive.ObjectID]int)
+	checker := service.NewStoredRowConstraintChecker(ctx, h.dbRepo, databaseID, columns)
+	ops := make([]repository.RowBatchOperation, len(req.Operations))
+	results := make([]dto.DatabaseRowBatchResult, len(req.Operations))
+	invalid := false
+	for i, reqOp := range req.Operations {
+		result := dto.DatabaseRowBatchResult{Index: i, Op: reqOp.Op, RowID: reqOp.RowID, Status: rowBatchStatusSkipped}
+		op, opErr := repository.RowBatchOperation{Op: reqOp.Op}, ""
+		var fieldErrors []service.RowFieldError
+
+		switch reqOp.Op {
+		case repository.RowBatchInsert, repository.RowBatchUpdate, repository.RowBatchDelete:
+		default:
+			opErr = "op must be one of insert, update, delete"
+		}
+		if opErr == "" && reqOp.Op != repository.RowBatchInsert {
+			rowID, err := primitive.ObjectIDFromHex(reqOp.RowID)
+			switch {
+			case err != nil:
+				opErr = "Invalid row ID format"
+			case !existingRows[rowID]:
+				opErr = "Row not found"
+			default:
+				if previous, seen := touchedRows[rowID]; seen {
+					opErr = fmt.Sprintf("Row is already changed by operation %d", previous)
+				} else {
+					touchedRows[rowID] = i
+				}
+				op.RowID = rowID
+			}
+		}
+		if opErr == "" && reqOp.Op == repository.RowBatchUpdate && len(reqOp.Values) == 0 {
+			opErr = "Row values are required"
+		}
+		if opErr == "" && reqOp.Op != repository.RowBatchDelete {
+			values, err := service.ValidateRowValues(columns, reqOp.Values)
+			if err == nil {
+				err = service.ValidateRelationValues(ctx, h.dbRepo, columns, values)
+			}
+			if err == nil {
+				err = service.ValidateFileValues(ctx, h.fileRepo, databaseID, columns, values)
+			}
+			if err == nil {
+				// Batasan diperiksa berurutan, termasuk terhadap operasi sebelumnya dalam batch
+				values, err = checker.Check(op.RowID, values)
+			}
+			var validationErr *service.RowValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				opErr, fieldErrors = "Validation error", validationErr.Errors
+			case err != nil:
+				return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to validate row values", err.Error())
+			}
+			op.Values = values
+		}
+		if opErr == "" && reqOp.Op == repository.RowBatchDelete {
+			checker.Forget(op.RowID)
+		}
+
+		if opErr != "" {
+			invalid = true
+			result.Status = rowBatchStatusInvalid
+			result.Error = opErr
+			result.Errors = convertRowFieldErrorsToDTO(fieldErrors)
+		}
+		ops[i] = op
+		results[i] = result
+	}
+	if invalid {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Batch contains invalid operations; nothing was applied", results)
+	}
+
+	updatedDatabase, err := h.dbRepo.ApplyRowBatch(ctx, databaseID, ops, authorID)
+	if err != nil {
+		var batchErr *repository.RowBatchError
+		if errors.As(err, &batchErr) && errors.Is(err, repository.ErrRowNotFound) {
+			results[batchErr.Index].Status = rowBatchStatusFailed
+			results[batchErr.Index].Error = "Row not found"
+			return utils.SendErrorResponse(c, fiber.StatusConflict, "A row was changed by another request; nothing was applied", results)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to apply batch operations", err.Error())
+	}
+	if updatedDatabase == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+
+	for i := range results {
+		results[i].Status = rowBatchStatusApplied
+		results[i].RowID = ops[i].RowID.Hex()
+	}
+	service.ComputeDerivedColumns(ctx, h.dbRepo, updatedDatabase)
+	var changedRowIDs []primitive.ObjectID
+	deletedRowIDs := make([]string, 0)
+	for _, op := range ops {
+		if op.Op == repository.RowBatchDelete {
+			deletedRowIDs = append(deletedRowIDs, op.RowID.Hex())
+		} else {
+			changedRowIDs = append(changedRowIDs, op.RowID)
+		}
+	}
+	h.publishEvent(c, databaseID, "rows_changed", rowsChangedPayload(updatedDatabase, changedRowIDs, deletedRowIDs))
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Batch operations applied successfully", dto.DatabaseRowBatchResponse{
+		Results:  results,
+		Database: convertDatabaseToDTO(updatedDatabase),
+	})
+}
+
+// parseOptionalIntQuery membaca query parameter bilangan bulat non-negatif. Mengembalikan 0 jika tidak dikirim.
+// hasRowQueryParams memeriksa apakah request GET rows memakai parameter filter, sort atau pagination.
+func hasRowQueryParams(c *fiber.Ctx) bool {
+	for _, key := range []string{"filters", "sort", "page", "limit", "cursor"} {
+		if c.Query(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseOptionalIntQuery(c *fiber.Ctx, key string) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%s harus berupa bilangan bulat non-negatif", key)
+	}
+	return value, nil
+}
+
+// parseOptionalDateQuery membaca query parameter tanggal (YYYY-MM-DD atau RFC3339). Mengembalikan nil jika tidak dikirim.
+func parseOptionalDateQuery(c *fiber.Ctx, key string) (*time.Time, error) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%s harus berupa tanggal YYYY-MM-DD atau RFC3339", key)
+}
+
+// sendRowValidationError mengirim response 400 berisi daftar kolom yang nilainya tidak valid. Kegagalan
+// mencari nilai unique di database dikirim sebagai 500.
+func sendRowValidationError(c *fiber.Ctx, message string, err error) error {
+	var validationErr *service.RowValidationError
+	if errors.As(err, &validationErr) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, message, validationErr.Errors)
+	}
+	if errors.Is(err, service.ErrUniqueLookupFailed) {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to check unique values", err.Error())
+	}
+	return utils.SendErrorResponse(c, fiber.StatusBadRequest, message, err.Error())
+}
+
+// resolveLinkedColumns memvalidasi konfigurasi kolom relation dan rollup. Mengembalikan false
+// jika response error sudah dikirim.
+func (h *databaseHandlerImpl) resolveLinkedColumns(ctx context.Context, c *fiber.Ctx, database *model.Database, columns []model.DatabaseColumn) (bool, error) {
+	if err := service.ResolveLinkedColumns(ctx, h.dbRepo, database, columns); err != nil {
+		if errors.Is(err, service.ErrInvalidColumnLink) {
+			return false, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid relation or rollup column", err.Error())
+		}
+		return false, utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to validate relation columns", err.Error())
+	}
+	return true, nil
+}
+
+// validateReferenceValues memastikan baris yang dirujuk kolom relation ada di database target dan
+// file pada kolom file diunggah ke kolom yang sama di database databaseID.
+// Mengembalikan false jika response error sudah dikirim.
+func (h *databaseHandlerImpl) validateReferenceValues(ctx context.Context, c *fiber.Ctx, databaseID primitive.ObjectID, columns []model.DatabaseColumn, values model.DatabaseRowValue, message string) (bool, error) {
+	err := service.ValidateRelationValues(ctx, h.dbRepo, columns, values)
+	if err == nil {
+		err = service.ValidateFileValues(ctx, h.fileRepo, databaseID, columns, values)
+	}
+	if err == nil {
+		return true, nil
+	}
+	var validationErr *service.RowValidationError
+	if errors.As(err, &validationErr) {
+		return false, sendRowValidationError(c, message, err)
+	}
+	return false, utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to validate relation or file values", err.Error())
+}
+
+// parseIfMatchVersion membaca header If-Match berisi ETag version ("3" atau W/"3"). Mengembalikan 0
+// jika header tidak dikirim atau bernilai "*", yang berarti update dilakukan tanpa syarat.
+func parseIfMatchVersion(c *fiber.Ctx) (int64, error) {
+	raw := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if raw == "" || raw == "*" {
+		return 0, nil
+	}
+	tag := strings.TrimPrefix(raw, "W/")
+	if len(tag) < 2 || !strings.HasPrefix(tag, "\"") || !strings.HasSuffix(tag, "\"") {
+		return 0, fmt.Errorf("If-Match must be a single ETag such as \"3\"")
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("If-Match must be a single ETag such as \"3\"")
+	}
+	return version, nil
+}
+
+// setVersionETag menulis version database atau baris sebagai header ETag.
+func setVersionETag(c *fiber.Ctx, version int64) {
+	c.Set(fiber.HeaderETag, fmt.Sprintf("\"%d\"", version))
+}
+
+// sendDatabaseVersionConflict mengirim 412 beserta isi database terbaru agar client dapat menggabungkan
+// perubahannya. Jika current nil, database diambil ulang (update bersyarat gagal di repository).
+func (h *databaseHandlerImpl) sendDatabaseVersionConflict(ctx context.Context, c *fiber.Ctx, databaseID primitive.ObjectID, current *model.Database) error {
+	if current == nil {
+		var err error
+		current, err = h.dbRepo.GetDatabaseByID(ctx, databaseID)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database", err.Error())
+		}
+		if current == nil {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+		}
+	}
+	service.ComputeDerivedColumns(ctx, h.dbRepo, current)
+	setVersionETag(c, current.Version)
+	return utils.SendErrorResponse(c, fiber.StatusPreconditionFailed, "Database was modified by another request", convertDatabaseToDTO(current))
+}
+
+// sendRowVersionConflict mengirim 412 beserta isi baris terbaru. schema adalah database tanpa baris; hanya
+// baris rowID yang diambil ulang.
+func (h *databaseHandlerImpl) sendRowVersionConflict(ctx context.Context, c *fiber.Ctx, schema *model.Database, rowID primitive.ObjectID) error {
+	rows, err := h.dbRepo.GetRowsByIDs(ctx, schema.ID, []primitive.ObjectID{rowID})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve row", err.Error())
+	}
+	current := *schema
+	current.DatabaseData.Rows = rows
+	service.ComputeDerivedColumns(ctx, h.dbRepo, &current)
+	row := findRowDTO(&current, rowID)
+	if row == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Row not found", nil)
+	}
+	setVersionETag(c, row.Version)
+	return utils.SendErrorResponse(c, fiber.StatusPreconditionFailed, "Row was modified by another request", row)
+}
+
+// publishEvent menyiarkan perubahan database ke client WebSocket yang terhubung ke database tersebut.
+func (h *databaseHandlerImpl) publishEvent(c *fiber.Ctx, databaseID primitive.ObjectID, eventType string, payload interface{}) {
+	actorID, _ := c.Locals("userID").(string)
+	h.realtime.Publish(databaseID.Hex(), eventType, actorID, payload)
+}
+
+// convertDatabaseToDTO mengonversi model.Database menjadi dto.DatabaseResponse.
+// Nilai kolom turunan (rollup dan formula) harus sudah dihitung dengan service.ComputeDerivedColumns.
+func convertDatabaseToDTO(database *model.Database) dto.DatabaseResponse {
+	respColumns := make([]dto.DatabaseColumnResponse, len(database.DatabaseData.Columns))
+	for i, col := range database.DatabaseData.Columns {
+		respColumns[i] = convertDatabaseColumnToDTO(col)
+	}
+
+	respRows := make([]dto.DatabaseRowResponse, len(database.DatabaseData.Rows))
+	for i, row := range database.DatabaseData.Rows {
+		respRows[i] = dto.DatabaseRowResponse{
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
+		}
+	}
+
+	return dto.DatabaseResponse{
+		ID:        database.ID.Hex(),
+		ChannelID: database.ChannelID.Hex(),
+		AuthorID:  database.AuthorID.Hex(),
+		Title:     database.Title,
+		Version:   database.Version,
+		Columns:   respColumns,
+		Rows:      respRows,
+		CreatedAt: database.CreatedAt,
+		UpdatedAt: database.UpdatedAt,
+	}
+}
+
+// convertDatabaseColumnToDTO mengonversi model.DatabaseColumn menjadi dto.DatabaseColumnResponse.
+func convertDatabaseColumnToDTO(col model.DatabaseColumn) dto.DatabaseColumnResponse {
+	respOptions := make([]dto.SelectOptionResponse, len(col.Options))
+	for j, opt := range col.Options {
+		respOptions[j] = dto.SelectOptionResponse{
+			ID:        opt.ID.Hex(),
+			Value:     opt.Value,
+			Order:     opt.Order,
+			CreatedAt: opt.CreatedAt,
+		}
+	}
+	resp := dto.DatabaseColumnResponse{
+		ID:      col.ID.Hex(),
+		Name:    col.Name,
+		Type:    col.Type,
+		Options: respOptions,
+		Order:   col.Order,
+		Formula: col.Formula,
+	}
+	if col.Relation != nil {
+		resp.Relation = &dto.DatabaseRelationResponse{DatabaseID: col.Relation.DatabaseID.Hex()}
+	}
+	if col.Rollup != nil {
+		resp.Rollup = &dto.DatabaseRollupResponse{
+			RelationColumnID: col.Rollup.RelationColumnID,
+			TargetColumnID:   col.Rollup.TargetColumnID,
+			Aggregation:      col.Rollup.Aggregation,
+		}
+	}
+	if col.Constraints != nil {
+		resp.Constraints = &dto.DatabaseColumnConstraintsResponse{
+			Required:     col.Constraints.Required,
+			Unique:       col.Constraints.Unique,
+			DefaultValue: col.Constraints.DefaultValue,
+			Min:          col.Constraints.Min,
+			Max:          col.Constraints.Max,
+			Pattern:      col.Constraints.Pattern,
+		}
+	}
+	return resp
+}
+
+// convertRowsToDTO mengonversi daftar model.DatabaseRow menjadi dto.DatabaseRowResponse.
+func convertRowsToDTO(rows []model.DatabaseRow) []dto.DatabaseRowResponse {
+	respRows := make([]dto.DatabaseRowResponse, len(rows))
+	for i, row := range rows {
+		respRows[i] = dto.DatabaseRowResponse{
+			ID:      row.ID.Hex(),
+			Values:  dto.DatabaseRowValueResponse(row.Values),
+			Version: row.Version,
+		}
+	}
+	return respRows
+}
+
+// convertRowRevisionsToDTO mengonversi daftar model.DatabaseRowRevision menjadi dto.DatabaseRowRevisionResponse.
+func convertRowRevisionsToDTO(revisions []model.DatabaseRowRevision) []dto.DatabaseRowRevisionResponse {
+	resp := make([]dto.DatabaseRowRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		changes := make([]dto.DatabaseRowFieldChangeResponse, len(revision.Changes))
+		for j, change := range revision.Changes {
+			changes[j] = dto.DatabaseRowFieldChangeResponse{
+				ColumnID: change.ColumnID,
+				OldValue: change.OldValue,
+				NewValue: change.NewValue,
+			}
+		}
+		resp[i] = dto.DatabaseRowRevisionResponse{
+			ID:        revision.ID.Hex(),
+			RowID:     revision.RowID.Hex(),
+			Action:    revision.Action,
+			Values:    dto.DatabaseRowValueResponse(revision.Values),
+			Changes:   changes,
+			CreatedAt: revision.CreatedAt,
+		}
+		if !revision.AuthorID.IsZero() {
+			resp[i].AuthorID = revision.AuthorID.Hex()
+		}
+		if !revision.RestoredFrom.IsZero() {
+			resp[i].RestoredFrom = revision.RestoredFrom.Hex()
+		}
+	}
+	return resp
+}
+
+// findDatabaseView mencari view berdasarkan ID. Mengembalikan nil jika tidak ditemukan.
+func findDatabaseView(views []model.DatabaseView, viewID primitive.ObjectID) *model.DatabaseView {
+	for i := range views {
+		if views[i].ID == viewID {
+			return &views[i]
+		}
+	}
+	return nil
+}
+
+// convertViewRequestToModel mengonversi dto.DatabaseViewRequest menjadi model.DatabaseView.
+// Validasi terhadap kolom database dilakukan oleh service.ValidateDatabaseView.
+func convertViewRequestToModel(req dto.DatabaseViewRequest) model.DatabaseView {
+	view := model.DatabaseView{
+		Name:            req.Name,
+		Type:            req.Type,
+		VisibleColumns:  req.VisibleColumns,
+		GroupByColumnID: req.GroupByColumnID,
+		DateColumnID:    req.DateColumnID,
+	}
+	if req.Filter != nil {
+		view.Filter = &model.DatabaseViewFilter{
+			Match:      req.Filter.Match,
+			Conditions: make([]model.DatabaseViewFilterCondition, len(req.Filter.Conditions)),
+		}
+		for i, cond := range req.Filter.Conditions {
+			view.Filter.Conditions[i] = model.DatabaseViewFilterCondition{ColumnID: cond.ColumnID, Operator: cond.Operator, Value: cond.Value}
+		}
+	}
+	for _, s := range req.Sort {
+		view.Sort = append(view.Sort, model.DatabaseViewSort{ColumnID: s.ColumnID, Direction: s.Direction})
+	}
+	return view
+}
+
+// convertDatabaseViewToDTO mengonversi model.DatabaseView menjadi dto.DatabaseViewResponse.
+func convertDatabaseViewToDTO(view model.DatabaseView) dto.DatabaseViewResponse {
+	resp := dto.DatabaseViewResponse{
+		ID:              view.ID.Hex(),
+		Name:            view.Name,
+		Type:            view.Type,
+		VisibleColumns:  view.VisibleColumns,
+		GroupByColumnID: view.GroupByColumnID,
+		DateColumnID:    view.DateColumnID,
+		CreatedAt:       view.CreatedAt,
+		UpdatedAt:       view.UpdatedAt,
+	}
+	if view.Filter != nil {
+		resp.Filter = &dto.DatabaseViewFilter{
+			Match:      view.Filter.Match,
+			Conditions: make([]dto.DatabaseViewFilterCondition, len(view.Filter.Conditions)),
+		}
+		for i, cond := range view.Filter.Conditions {
+			resp.Filter.Conditions[i] = dto.DatabaseViewFilterCondition{ColumnID: cond.ColumnID, Operator: cond.Operator, Value: cond.Value}
+		}
+	}
+	for _, s := range view.Sort {
+		resp.Sort = append(resp.Sort, dto.DatabaseViewSort{ColumnID: s.ColumnID, Direction: s.Direction})
+	}
+	return resp
+}
+
+// convertColumnLinksToModel mengonversi konfigurasi relation/rollup dari request sesuai tipe kolom.
+// Konfigurasi yang tidak sesuai tipe kolom diabaikan; kelengkapannya diperiksa oleh service.ResolveLinkedColumns.
+func convertColumnLinksToModel(columnType string, relationReq *dto.DatabaseRelationRequest, rollupReq *dto.DatabaseRollupRequest) (*model.RelationConfig, *model.RollupConfig, error) {
+	switch columnType {
+	case "relation":
+		if relationReq == nil {
+			return nil, nil, errors.New("relation.databaseId is required for relation columns")
+		}
+		targetID, err := primitive.ObjectIDFromHex(relationReq.DatabaseID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid relation.databaseId: %s", err.Error())
+		}
+		return &model.RelationConfig{DatabaseID: targetID}, nil, nil
+	case "rollup":
+		if rollupReq == nil {
+			return nil, nil, errors.New("rollup configuration is required for rollup columns")
+		}
+		return nil, &model.RollupConfig{
+			RelationColumnID: rollupReq.RelationColumnID,
+			TargetColumnID:   rollupReq.TargetColumnID,
+			Aggregation:      rollupReq.Aggregation,
+		}, nil
+	}
+	return nil, nil, nil
+}
+
+// checkUniqueConflicts mengirim 409 jika kolom unique sudah berisi nilai duplikat pada rows.
+// Mengembalikan false jika response error sudah dikirim.
+func checkUniqueConflicts(c *fiber.Ctx, col *model.DatabaseColumn, rows []model.DatabaseRow) (bool, error) {
+	if col.Constraints == nil || !col.Constraints.Unique {
+		return true, nil
+	}
+	if conflicts := service.FindUniqueConflicts(col, rows); len(conflicts) > 0 {
+		return false, utils.SendErrorResponse(c, fiber.StatusConflict, fmt.Sprintf("Column '%s' has duplicate values", col.Name), convertUniqueConflictsToDTO(conflicts))
+	}
+	return true, nil
+}
+
+// convertColumnConstraintsToModel mengonversi batasan kolom dari request. Nilainya diperiksa dan
+// dikonversi ke tipe kolom oleh service.NormalizeColumnConstraints.
+func convertColumnConstraintsToModel(req *dto.DatabaseColumnConstraintsRequest) *model.ColumnConstraints {
+	if req == nil {
+		return nil
+	}
+	return &model.ColumnConstraints{
+		Required:     req.Required,
+		Unique:       req.Unique,
+		DefaultValue: req.DefaultValue,
+		Min:          req.Min,
+		Max:          req.Max,
+		Pattern:      req.Pattern,
+	}
+}
+
+// convertUniqueConflictsToDTO mengonversi daftar service.UniqueConflict menjadi DTO response.
+func convertUniqueConflictsToDTO(conflicts []service.UniqueConflict) []dto.DatabaseColumnUniqueConflictResponse {
+	resp := make([]dto.DatabaseColumnUniqueConflictResponse, len(conflicts))
+	for i, conflict := range conflicts {
+		resp[i] = dto.DatabaseColumnUniqueConflictResponse{Value: conflict.Value, RowIDs: conflict.RowIDs}
+	}
+	return resp
 }
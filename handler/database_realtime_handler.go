@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+
+	"github.com/gofiber/contrib/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// databaseRealtimeHistorySize adalah jumlah event terakhir per database yang disimpan untuk replay.
+	databaseRealtimeHistorySize = 100
+	// databaseRealtimeWriteTimeout membatasi waktu tulis ke satu client agar client lambat tidak menahan broadcast.
+	databaseRealtimeWriteTimeout = 5 * time.Second
+)
+
+// DatabaseRealtimeHub menyimpan koneksi WebSocket per database dan menyiarkan perubahan baris, kolom
+// dan select option. Setiap event diberi version berurutan per database; event terakhir disimpan
+// di memori agar client yang terputus sebentar dapat meminta replay sejak version terakhir yang diterima.
+// Hub hanya berlaku untuk satu proses server; epoch berubah setiap server dijalankan ulang.
+type DatabaseRealtimeHub struct {
+	// Koneksi aktif per database ID, mengikuti pola registry di messageHandlerImpl
+	activeConnections map[string]map[*websocket.Conn]bool
+	versions          map[string]int64
+	history           map[string][]dto.DatabaseRealtimeEvent
+	epoch             string
+	// Semua penulisan ke koneksi dilakukan di bawah mu, sehingga event terkirim sesuai urutan version
+	// dan tidak ada dua goroutine yang menulis ke koneksi yang sama secara bersamaan.
+	mu sync.Mutex
+}
+
+// NewDatabaseRealtimeHub membuat hub realtime database baru.
+func NewDatabaseRealtimeHub() *DatabaseRealtimeHub {
+	return &DatabaseRealtimeHub{
+		activeConnections: make(map[string]map[*websocket.Conn]bool),
+		versions:          make(map[string]int64),
+		history:           make(map[string][]dto.DatabaseRealtimeEvent),
+		epoch:             primitive.NewObjectID().Hex(),
+	}
+}
+
+// Publish memberi version baru pada event lalu menyiarkannya ke semua client database tersebut.
+// Hub nil diabaikan agar handler tetap dapat dipakai tanpa realtime.
+func (hub *DatabaseRealtimeHub) Publish(databaseID, eventType, actorID string, payload interface{}) {
+	if hub == nil {
+		return
+	}
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	hub.versions[databaseID]++
+	event := dto.DatabaseRealtimeEvent{
+		Type:       eventType,
+		DatabaseID: databaseID,
+		Version:    hub.versions[databaseID],
+		Epoch:      hub.epoch,
+		ActorID:    actorID,
+		Payload:    payload,
+		CreatedAt:  time.Now(),
+	}
+	history := append(hub.history[databaseID], event)
+	if len(history) > databaseRealtimeHistorySize {
+		history = history[len(history)-databaseRealtimeHistorySize:]
+	}
+	hub.history[databaseID] = history
+
+	jsonMsg, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error marshalling database event: %v\n", err)
+		return
+	}
+	for conn := range hub.activeConnections[databaseID] {
+		if err := hub.write(conn, jsonMsg); err != nil {
+			log.Printf("Error writing to websocket for database %s: %v\n", databaseID, err)
+			// Koneksi rusak dilepas dan ditutup agar loop baca di handler-nya berhenti
+			hub.removeLocked(databaseID, conn)
+			conn.Close()
+		}
+	}
+
+	// Database yang dihapus tidak akan menerima event lagi
+	if eventType == "database_deleted" {
+		delete(hub.history, databaseID)
+	}
+}
+
+// join mendaftarkan koneksi, mengirim konfirmasi berisi version saat ini, lalu me-replay event sejak
+// version since (jika since >= 0). Semuanya dilakukan di bawah lock agar tidak ada event yang terselip.
+func (hub *DatabaseRealtimeHub) join(databaseID string, conn *websocket.Conn, since int64, epoch string) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if hub.activeConnections[databaseID] == nil {
+		hub.activeConnections[databaseID] = make(map[*websocket.Conn]bool)
+	}
+	hub.activeConnections[databaseID][conn] = true
+
+	hub.writeJSON(conn, map[string]interface{}{"type": "database_joined", "payload": hub.stateLocked(databaseID)})
+	if since >= 0 {
+		hub.replayLocked(databaseID, conn, since, epoch)
+	}
+}
+
+// replay mengirim ulang event sejak version since ke satu koneksi.
+func (hub *DatabaseRealtimeHub) replay(databaseID string, conn *websocket.Conn, since int64, epoch string) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.replayLocked(databaseID, conn, since, epoch)
+}
+
+// leave melepas koneksi dari registry database.
+func (hub *DatabaseRealtimeHub) leave(databaseID string, conn *websocket.Conn) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.removeLocked(databaseID, conn)
+}
+
+// replayLocked mengirim event dengan version > since. Jika event tersebut sudah tidak ada di memori,
+// epoch berbeda (server dijalankan ulang) atau since melebihi version saat ini, client diminta
+// memuat ulang database lewat REST dengan event resync_required.
+func (hub *DatabaseRealtimeHub) replayLocked(databaseID string, conn *websocket.Conn, since int64, epoch string) {
+	current := hub.versions[databaseID]
+	history := hub.history[databaseID]
+	oldest := current + 1
+	if len(history) > 0 {
+		oldest = history[0].Version
+	}
+	if (epoch != "" && epoch != hub.epoch) || since > current || since+1 < oldest {
+		hub.writeJSON(conn, map[string]interface{}{"type": "resync_required", "payload": hub.stateLocked(databaseID)})
+		return
+	}
+	for _, event := range history {
+		if event.Version > since {
+			hub.writeJSON(conn, event)
+		}
+	}
+}
+
+// send menulis satu pesan ke koneksi tanpa bertabrakan dengan broadcast.
+func (hub *DatabaseRealtimeHub) send(conn *websocket.Conn, data interface{}) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.writeJSON(conn, data)
+}
+
+// stateLocked mengembalikan version dan epoch database saat ini.
+func (hub *DatabaseRealtimeHub) stateLocked(databaseID string) map[string]interface{} {
+	return map[string]interface{}{"databaseId": databaseID, "version": hub.versions[databaseID], "epoch": hub.epoch}
+}
+
+func (hub *DatabaseRealtimeHub) removeLocked(databaseID string, conn *websocket.Conn) {
+	delete(hub.activeConnections[databaseID], conn)
+	if len(hub.activeConnections[databaseID]) == 0 {
+		delete(hub.activeConnections, databaseID)
+	}
+}
+
+func (hub *DatabaseRealtimeHub) writeJSON(conn *websocket.Conn, data interface{}) {
+	jsonMsg, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshalling database websocket message: %v\n", err)
+		return
+	}
+	if err := hub.write(conn, jsonMsg); err != nil {
+		log.Printf("Error writing database websocket message: %v\n", err)
+	}
+}
+
+func (hub *DatabaseRealtimeHub) write(conn *websocket.Conn, jsonMsg []byte) error {
+	conn.SetWriteDeadline(time.Now().Add(databaseRealtimeWriteTimeout))
+	return conn.WriteMessage(websocket.TextMessage, jsonMsg)
+}
+
+// DatabaseRealtimeHandler menangani koneksi WebSocket untuk kolaborasi realtime pada database.
+type DatabaseRealtimeHandler interface {
+	HandleDatabaseWebSocket(c *websocket.Conn)
+}
+
+type databaseRealtimeHandlerImpl struct {
+	dbRepo repository.DatabaseRepository
+	hub    *DatabaseRealtimeHub
+}
+
+// NewDatabaseRealtimeHandler membuat instance baru dari DatabaseRealtimeHandler.
+func NewDatabaseRealtimeHandler(dbRepo repository.DatabaseRepository, hub *DatabaseRealtimeHub) DatabaseRealtimeHandler {
+	return &databaseRealtimeHandlerImpl{dbRepo: dbRepo, hub: hub}
+}
+
+// HandleDatabaseWebSocket menangani koneksi /ws/databases/:id. Client menerima event perubahan
+// database beserta version-nya. Query since=<version>&epoch=<epoch> (atau pesan {"type":"replay",
+// "payload":{"since":N,"epoch":"..."}}) meminta event yang terlewat sejak version tersebut.
+func (h *databaseRealtimeHandlerImpl) HandleDatabaseWebSocket(c *websocket.Conn) {
+	databaseIDStr := c.Params("id")
+	defer func() {
+		h.hub.leave(databaseIDStr, c)
+		log.Printf("Client disconnected from database %s: %s\n", databaseIDStr, c.LocalAddr().String())
+		c.Close()
+	}()
+
+	// Periksa hasil autentikasi dari middleware
+	authFailed, ok := c.Locals("authFailed").(bool)
+	if ok && authFailed {
+		authError, _ := c.Locals("authError").(string)
+		if authError == "" {
+			authError = "Autentikasi gagal"
+		}
+		log.Printf("WebSocket authentication failed for database %s: %s\n", databaseIDStr, authError)
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": authError})
+		return
+	}
+	if database := h.authorizeDatabase(c, databaseIDStr); database == nil {
+		return
+	}
+
+	since := int64(-1)
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			c.WriteJSON(map[string]interface{}{"type": "error", "payload": "Parameter since harus berupa angka >= 0"})
+			return
+		}
+		since = parsed
+	}
+	h.hub.join(databaseIDStr, c, since, c.Query("epoch"))
+	log.Printf("Client connected to database %s: %s\n", databaseIDStr, c.LocalAddr().String())
+
+	for {
+		mt, msg, err := c.ReadMessage()
+		if err != nil {
+			log.Println("read error:", err)
+			break
+		}
+		if mt != websocket.TextMessage {
+			continue
+		}
+
+		var wsMessage struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(msg, &wsMessage); err != nil {
+			h.hub.send(c, map[string]interface{}{"type": "error", "payload": "Invalid message format"})
+			continue
+		}
+
+		switch wsMessage.Type {
+		case "replay":
+			var req struct {
+				Since int64  `json:"since"`
+				Epoch string `json:"epoch"`
+			}
+			if err := json.Unmarshal(wsMessage.Payload, &req); err != nil || req.Since < 0 {
+				h.hub.send(c, map[string]interface{}{"type": "error", "payload": "Invalid replay payload"})
+				continue
+			}
+			h.hub.replay(databaseIDStr, c, req.Since, req.Epoch)
+		default:
+			h.hub.send(c, map[string]interface{}{"type": "error", "payload": "Unknown message type"})
+		}
+	}
+}
+
+// authorizeDatabase memastikan database ada dan user dari token boleh mengaksesnya. Jika tidak,
+// pesan error dikirim ke client dan nil dikembalikan.
+func (h *databaseRealtimeHandlerImpl) authorizeDatabase(c *websocket.Conn, databaseIDStr string) *model.Database {
+	databaseID, err := primitive.ObjectIDFromHex(databaseIDStr)
+	if err != nil {
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": "Invalid database ID"})
+		return nil
+	}
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": "User ID tidak ditemukan di koneksi terautentikasi"})
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	database, err := h.dbRepo.GetDatabaseByID(ctx, databaseID)
+	if err != nil {
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": "Gagal mengambil database"})
+		return nil
+	}
+	if database == nil {
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": "Database tidak ditemukan"})
+		return nil
+	}
+	// Otorisasi sama dengan endpoint REST database: hanya author yang dapat mengakses
+	// TODO: Tambahkan otorisasi lebih kompleks (misal: admin bisnis, anggota channel dengan izin)
+	if database.AuthorID.Hex() != userIDStr {
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": "Tidak diizinkan: Anda tidak memiliki akses ke database ini"})
+		return nil
+	}
+	return database
+}
+
+// findRowDTO mencari baris dengan ID tertentu di database dan mengonversinya ke DTO.
+func findRowDTO(database *model.Database, rowID primitive.ObjectID) *dto.DatabaseRowResponse {
+	for _, row := range database.DatabaseData.Rows {
+		if row.ID == rowID {
+			return &dto.DatabaseRowResponse{ID: row.ID.Hex(), Values: dto.DatabaseRowValueResponse(row.Values), Version: row.Version}
+		}
+	}
+	return nil
+}
+
+// findColumnDTO mencari kolom dengan ID tertentu di database dan mengonversinya ke DTO.
+func findColumnDTO(database *model.Database, columnID primitive.ObjectID) *dto.DatabaseColumnResponse {
+	for _, col := range database.DatabaseData.Columns {
+		if col.ID == columnID {
+			resp := convertDatabaseColumnToDTO(col)
+			return &resp
+		}
+	}
+	return nil
+}
+
+// rowsChangedPayload menyusun payload event rows_changed dari baris database yang sudah diperbarui.
+func rowsChangedPayload(database *model.Database, rowIDs []primitive.ObjectID, deletedRowIDs []string) dto.DatabaseRealtimeRowsPayload {
+	payload := dto.DatabaseRealtimeRowsPayload{Rows: make([]dto.DatabaseRowResponse, 0, len(rowIDs)), DeletedRowIDs: deletedRowIDs}
+	if payload.DeletedRowIDs == nil {
+		payload.DeletedRowIDs = []string{}
+	}
+	for _, rowID := range rowIDs {
+		if row := findRowDTO(database, rowID); row != nil {
+			payload.Rows = append(payload.Rows, *row)
+		}
+	}
+	return payload
+}
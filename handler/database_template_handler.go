@@ -0,0 +1,380 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DatabaseTemplateHandler menangani logika terkait template database.
+type DatabaseTemplateHandler interface {
+	CreateTemplate(c *fiber.Ctx) error
+	GetTemplatesByBusinessID(c *fiber.Ctx) error
+	GetTemplateByID(c *fiber.Ctx) error
+	DeleteTemplate(c *fiber.Ctx) error
+	InstantiateTemplate(c *fiber.Ctx) error
+}
+
+type databaseTemplateHandlerImpl struct {
+	templateRepo repository.DatabaseTemplateRepository
+	dbRepo       repository.DatabaseRepository
+	channelRepo  repository.ChannelRepository
+	channels     service.ChannelAccessService
+}
+
+// NewDatabaseTemplateHandler membuat instance baru dari DatabaseTemplateHandler.
+func NewDatabaseTemplateHandler(templateRepo repository.DatabaseTemplateRepository, dbRepo repository.DatabaseRepository, channelRepo repository.ChannelRepository, channels service.ChannelAccessService) DatabaseTemplateHandler {
+	return &databaseTemplateHandlerImpl{
+		templateRepo: templateRepo,
+		dbRepo:       dbRepo,
+		channelRepo:  channelRepo,
+		channels:     channels,
+	}
+}
+
+// CreateTemplate saves the schema of a database as a business-scoped template.
+// @Summary Save a database as a template
+// @Description Save the columns, select options and views of an existing database as a template for the business that owns the database's channel. Rows are not included. Relation columns may only point to the database itself; they point to the new database when the template is instantiated.
+// @Tags Database Templates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param template body dto.DatabaseTemplateCreateRequest true "Template Details"
+// @Success 201 {object} utils.APIResponse{data=dto.DatabaseTemplateResponse} "Template created successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or database cannot be used as a template"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User is not the author of the database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Database or its channel not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /database-templates [post]
+func (h *databaseTemplateHandlerImpl) CreateTemplate(c *fiber.Ctx) error {
+	var req dto.DatabaseTemplateCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if len(req.Name) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Name must be at least 3 characters long")
+	}
+	databaseID, err := primitive.ObjectIDFromHex(req.DatabaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid database ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	database, err := h.dbRepo.GetDatabaseSchemaByID(ctx, databaseID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve database", err.Error())
+	}
+	if database == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Database not found", nil)
+	}
+	if database.AuthorID != authorID {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to create a template from this database", nil)
+	}
+	channel, err := h.channelRepo.GetChannelByID(ctx, database.ChannelID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve channel", err.Error())
+	}
+	if channel == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Channel of the database not found", nil)
+	}
+
+	columns, views, err := service.TemplateSchema(database)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDatabaseTemplate) {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Database cannot be saved as a template", err.Error())
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create template", err.Error())
+	}
+	template := &model.DatabaseTemplate{
+		BusinessID:  channel.BusinessID,
+		AuthorID:    authorID,
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+		Columns:     columns,
+		Views:       views,
+	}
+	if err := h.templateRepo.CreateTemplate(ctx, template); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create template", err.Error())
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Template created successfully", convertDatabaseTemplateToDTO(template))
+}
+
+// GetTemplatesByBusinessID lists the database templates of a business.
+// @Summary Get database templates by business ID
+// @Description Get all database templates saved for a business, sorted by name. Only the owner and members of the business can list them.
+// @Tags Database Templates
+// @Produce json
+// @Security ApiKeyAuth
+// @Param businessId path string true "Business ID"
+// @Success 200 {object} utils.APIResponse{data=[]dto.DatabaseTemplateResponse} "Templates retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User is not a member of the business"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /database-templates/business/{businessId} [get]
+func (h *databaseTemplateHandlerImpl) GetTemplatesByBusinessID(c *fiber.Ctx) error {
+	businessID, err := primitive.ObjectIDFromHex(c.Params("businessId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid business ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	if ok, err := h.authorizeBusinessMember(ctx, c, businessID); !ok {
+		return err
+	}
+
+	templates, err := h.templateRepo.GetTemplatesByBusinessID(ctx, businessID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get templates", err.Error())
+	}
+	resp := make([]dto.DatabaseTemplateResponse, len(templates))
+	for i := range templates {
+		resp[i] = convertDatabaseTemplateToDTO(&templates[i])
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Templates retrieved successfully", resp)
+}
+
+// GetTemplateByID retrieves a database template.
+// @Summary Get database template by ID
+// @Description Get a database template by its ID. Only the owner and members of the template's business can read it.
+// @Tags Database Templates
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} utils.APIResponse{data=dto.DatabaseTemplateResponse} "Template retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User is not a member of the business"
+// @Failure 404 {object} utils.APIResponse "Not Found - Template not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /database-templates/{id} [get]
+func (h *databaseTemplateHandlerImpl) GetTemplateByID(c *fiber.Ctx) error {
+	templateID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid template ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	template, err := h.templateRepo.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get template", err.Error())
+	}
+	if template == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Template not found", nil)
+	}
+	if ok, err := h.authorizeBusinessMember(ctx, c, template.BusinessID); !ok {
+		return err
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Template retrieved successfully", convertDatabaseTemplateToDTO(template))
+}
+
+// DeleteTemplate deletes a database template.
+// @Summary Delete a database template
+// @Description Delete a database template. Only the author of the template can delete it; databases created from it are not affected.
+// @Tags Database Templates
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} utils.APIResponse "Template deleted successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User is not the author of the template"
+// @Failure 404 {object} utils.APIResponse "Not Found - Template not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /database-templates/{id} [delete]
+func (h *databaseTemplateHandlerImpl) DeleteTemplate(c *fiber.Ctx) error {
+	templateID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid template ID format", err.Error())
+	}
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	template, err := h.templateRepo.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get template", err.Error())
+	}
+	if template == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Template not found", nil)
+	}
+	if template.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to delete this template", nil)
+	}
+
+	if err := h.templateRepo.DeleteTemplate(ctx, templateID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Template not found", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete template", err.Error())
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Template deleted successfully", nil)
+}
+
+// InstantiateTemplate creates a new database in a channel from a template.
+// @Summary Create a database from a template
+// @Description Create a new, empty database in a channel from a template. The channel must have type "databases" and belong to the template's business, and the user must be a member of that business. The authenticated user becomes the author of the new database.
+// @Tags Database Templates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Template ID"
+// @Param request body dto.DatabaseTemplateInstantiateRequest true "Target channel and title"
+// @Success 201 {object} utils.APIResponse{data=dto.DatabaseResponse} "Database created from template successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or channel belongs to another business"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Template not found, or channel not found or not in a business of the user"
+// @Failure 409 {object} utils.APIResponse "Conflict - Channel is not a databases channel"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /database-templates/{id}/instantiate [post]
+func (h *databaseTemplateHandlerImpl) InstantiateTemplate(c *fiber.Ctx) error {
+	templateID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid template ID format", err.Error())
+	}
+	var req dto.DatabaseTemplateInstantiateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	channelID, err := primitive.ObjectIDFromHex(req.ChannelID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Channel ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	template, err := h.templateRepo.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get template", err.Error())
+	}
+	if template == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Template not found", nil)
+	}
+	// Keanggotaan bisnis channel sekaligus menjadi syarat akses ke template karena bisnisnya harus sama
+	channel, err := h.channels.ValidateContentChannel(ctx, channelID, authorID, service.ChannelTypeDatabases)
+	if err != nil {
+		return sendChannelAccessError(c, err)
+	}
+	if channel.BusinessID != template.BusinessID {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Channel does not belong to the template's business")
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = template.Name
+	}
+	if len(title) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long")
+	}
+
+	newDatabase := &model.Database{
+		ID:        primitive.NewObjectID(),
+		ChannelID: channelID,
+		AuthorID:  authorID,
+		Title:     title,
+	}
+	newDatabase.DatabaseData.Columns, newDatabase.Views = service.CopyDatabaseSchema(template.Columns, template.Views, primitive.NilObjectID, newDatabase.ID)
+	newDatabase.DatabaseData.Rows = []model.DatabaseRow{}
+	if err := service.ResolveLinkedColumns(ctx, h.dbRepo, newDatabase, newDatabase.DatabaseData.Columns); err != nil {
+		if errors.Is(err, service.ErrInvalidColumnLink) {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid relation or rollup column", err.Error())
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to validate relation columns", err.Error())
+	}
+
+	if err := h.dbRepo.CreateDatabase(ctx, newDatabase); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create database", err.Error())
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Database created from template successfully", convertDatabaseToDTO(newDatabase))
+}
+
+// authorizeBusinessMember memastikan user yang login adalah pemilik atau anggota bisnis.
+// Mengembalikan false jika response error sudah dikirim.
+func (h *databaseTemplateHandlerImpl) authorizeBusinessMember(ctx context.Context, c *fiber.Ctx, businessID primitive.ObjectID) (bool, error) {
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return false, utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return false, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID format", err.Error())
+	}
+
+	member, err := h.channels.IsBusinessMember(ctx, businessID, userID)
+	if err != nil {
+		return false, utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to check business membership", err.Error())
+	}
+	if member {
+		return true, nil
+	}
+	return false, utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not a member of this business", nil)
+}
+
+// convertDatabaseTemplateToDTO mengonversi model.DatabaseTemplate menjadi dto.DatabaseTemplateResponse.
+func convertDatabaseTemplateToDTO(template *model.DatabaseTemplate) dto.DatabaseTemplateResponse {
+	respColumns := make([]dto.DatabaseColumnResponse, len(template.Columns))
+	for i, col := range template.Columns {
+		respColumns[i] = convertDatabaseColumnToDTO(col)
+		if col.Relation != nil && col.Relation.DatabaseID.IsZero() {
+			respColumns[i].Relation.DatabaseID = ""
+		}
+	}
+	respViews := make([]dto.DatabaseViewResponse, len(template.Views))
+	for i, view := range template.Views {
+		respViews[i] = convertDatabaseViewToDTO(view)
+	}
+	return dto.DatabaseTemplateResponse{
+		ID:          template.ID.Hex(),
+		BusinessID:  template.BusinessID.Hex(),
+		AuthorID:    template.AuthorID.Hex(),
+		Name:        template.Name,
+		Description: template.Description,
+		Columns:     respColumns,
+		Views:       respViews,
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeletionJobHandler menangani status job penghapusan berantai channel dan bisnis.
+type DeletionJobHandler interface {
+	GetDeletionJob(c *fiber.Ctx) error
+}
+
+type deletionJobHandlerImpl struct {
+	deletionService service.DeletionService
+}
+
+// NewDeletionJobHandler membuat instance baru dari DeletionJobHandler.
+func NewDeletionJobHandler(deletionService service.DeletionService) DeletionJobHandler {
+	return &deletionJobHandlerImpl{deletionService: deletionService}
+}
+
+// GetDeletionJob retrieves the progress of a background deletion job.
+// @Summary Get deletion job status
+// @Description Get the status and per-collection progress of a background cascade deletion started by deleting a large channel or business. Only the user who requested the deletion can see the job.
+// @Tags Deletion Jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Deletion Job ID"
+// @Success 200 {object} utils.APIResponse{data=dto.DeletionJobResponse} "Deletion job retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - Job was requested by another user"
+// @Failure 404 {object} utils.APIResponse "Not Found - Deletion job not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /deletion-jobs/{id} [get]
+func (h *deletionJobHandlerImpl) GetDeletionJob(c *fiber.Ctx) error {
+	jobID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid deletion job ID format", err.Error())
+	}
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	job, err := h.deletionService.GetJob(ctx, jobID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get deletion job", err.Error())
+	}
+	if job == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Deletion job not found", nil)
+	}
+	if job.RequestedBy.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to access this deletion job", nil)
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Deletion job retrieved successfully", convertDeletionJobToDTO(job))
+}
+
+// sendDeletionResult mengirim hasil DeletionService: 202 beserta job untuk mode background, 200 untuk
+// dry run dan transaksi. message dipakai untuk penghapusan yang sudah selesai.
+func sendDeletionResult(c *fiber.Ctx, targetType string, targetID primitive.ObjectID, result *service.DeletionResult, message string) error {
+	resp := dto.DeletionResultResponse{
+		TargetType: targetType,
+		TargetID:   targetID.Hex(),
+		Mode:       result.Mode,
+		Steps:      convertDeletionStepsToDTO(result.Steps),
+	}
+	for _, step := range result.Steps {
+		resp.Total += step.Planned
+	}
+
+	switch result.Mode {
+	case service.DeletionModeDryRun:
+		return utils.SendSuccessResponse(c, fiber.StatusOK, "Pratinjau penghapusan, tidak ada data yang dihapus", resp)
+	case service.DeletionModeBackground:
+		job := convertDeletionJobToDTO(result.Job)
+		resp.Job = &job
+		return utils.SendSuccessResponse(c, fiber.StatusAccepted, "Penghapusan dijalankan di background", resp)
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, message, resp)
+}
+
+// convertDeletionStepsToDTO mengonversi langkah penghapusan; nama koleksi diambil dari konfigurasi database.
+func convertDeletionStepsToDTO(steps []model.DeletionStep) []dto.DeletionStepResponse {
+	resp := make([]dto.DeletionStepResponse, len(steps))
+	for i, step := range steps {
+		collection := config.DBConfig.Collections[step.Collection]
+		if collection == "" {
+			collection = step.Collection
+		}
+		resp[i] = dto.DeletionStepResponse{
+			Collection: collection,
+			Action:     step.Action,
+			Planned:    step.Planned,
+			Affected:   step.Affected,
+			Done:       step.Done,
+		}
+	}
+	return resp
+}
+
+// convertDeletionJobToDTO mengonversi model.DeletionJob menjadi dto.DeletionJobResponse.
+func convertDeletionJobToDTO(job *model.DeletionJob) dto.DeletionJobResponse {
+	return dto.DeletionJobResponse{
+		ID:          job.ID.Hex(),
+		TargetType:  job.TargetType,
+		TargetID:    job.TargetID.Hex(),
+		RequestedBy: job.RequestedBy.Hex(),
+		Status:      job.Status,
+		Steps:       convertDeletionStepsToDTO(job.Steps),
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		CompletedAt: job.CompletedAt,
+	}
+}
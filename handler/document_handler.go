@@ -0,0 +1,387 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DocumentHandler menangani logika terkait entitas Document.
+type DocumentHandler interface {
+	CreateDocument(c *fiber.Ctx) error
+	GetDocumentByID(c *fiber.Ctx) error
+	GetDocumentsByChannelID(c *fiber.Ctx) error
+	UpdateDocument(c *fiber.Ctx) error
+	DeleteDocument(c *fiber.Ctx) error
+}
+
+type documentHandlerImpl struct {
+	docRepo  repository.DocumentRepository
+	channels service.ChannelAccessService
+}
+
+// NewDocumentHandler membuat instance baru dari DocumentHandler.
+func NewDocumentHandler(docRepo repository.DocumentRepository, channels service.ChannelAccessService) DocumentHandler {
+	return &documentHandlerImpl{docRepo: docRepo, channels: channels}
+}
+
+// CreateDocument creates a new document entry.
+// @Summary Create a new document
+// @Description Create a new rich-text document for a specific channel. The channel must exist, belong to a business the user owns or is a member of, and have type "documents". The author is taken from the JWT token.
+// @Tags Documents
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param document body dto.DocumentCreateRequest true "Document Creation Details"
+// @Success 201 {object} utils.APIResponse{data=dto.DocumentResponse} "Document created successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Channel not found or not in a business of the user"
+// @Failure 409 {object} utils.APIResponse "Conflict - Channel is not a documents channel"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /documents [post]
+func (h *documentHandlerImpl) CreateDocument(c *fiber.Ctx) error {
+	var req dto.DocumentCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	// Validasi manual
+	if req.ChannelID == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "ChannelID is required")
+	}
+	if len(req.Title) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long")
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Author ID format", err.Error())
+	}
+	channelID, err := primitive.ObjectIDFromHex(req.ChannelID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Channel ID format", err.Error())
+	}
+
+	// Dokumen baru tanpa konten dimulai dengan root node "doc" yang kosong
+	content := model.DocumentContentNode{Type: "doc", Content: []model.DocumentContentSubNode{}}
+	if req.Content != nil {
+		content = convertDocumentContentToModel(*req.Content)
+	}
+
+	newDocument := &model.Document{
+		ID:        primitive.NewObjectID(),
+		ChannelID: channelID,
+		AuthorID:  authorID,
+		Title:     req.Title,
+		Content:   content,
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	if _, err := h.channels.ValidateContentChannel(ctx, channelID, authorID, service.ChannelTypeDocuments); err != nil {
+		return sendChannelAccessError(c, err)
+	}
+	if err := h.docRepo.CreateDocument(ctx, newDocument); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create document", err.Error())
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Document created successfully", convertDocumentToDTO(newDocument))
+}
+
+// GetDocumentByID retrieves a document by its ID.
+// @Summary Get document by ID
+// @Description Get a specific document by its ID.
+// @Tags Documents
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Document ID"
+// @Success 200 {object} utils.APIResponse{data=dto.DocumentResponse} "Document retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this document"
+// @Failure 404 {object} utils.APIResponse "Not Found - Document not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /documents/{id} [get]
+func (h *documentHandlerImpl) GetDocumentByID(c *fiber.Ctx) error {
+	objectID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid document ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	document, err := h.docRepo.GetDocumentByID(ctx, objectID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get document", err.Error())
+	}
+	if document == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Document not found", nil)
+	}
+
+	// Otorisasi: sama seperti Database, saat ini hanya author yang boleh mengakses.
+	// TODO: Tambahkan logika otorisasi yang lebih kompleks (cek peran, anggota channel)
+	if document.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to access this document", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Document retrieved successfully", convertDocumentToDTO(document))
+}
+
+// GetDocumentsByChannelID retrieves all documents associated with a specific channel ID.
+// @Summary Get documents by channel ID
+// @Description Get a list of documents for a given channel ID, most recently updated first. The channel must belong to a business the user owns or is a member of.
+// @Tags Documents
+// @Produce json
+// @Security ApiKeyAuth
+// @Param channelId path string true "Channel ID"
+// @Success 200 {object} utils.APIResponse{data=[]dto.DocumentResponse} "Documents retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid Channel ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Channel not found or not in a business of the user"
+// @Failure 409 {object} utils.APIResponse "Conflict - Channel is not a documents channel"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /documents/channel/{channelId} [get]
+func (h *documentHandlerImpl) GetDocumentsByChannelID(c *fiber.Ctx) error {
+	channelID, err := primitive.ObjectIDFromHex(c.Params("channelId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Channel ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid User ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	// Hanya pemilik atau anggota bisnis channel yang boleh melihat dokumen di dalamnya
+	if _, err := h.channels.ValidateContentChannel(ctx, channelID, userID, service.ChannelTypeDocuments); err != nil {
+		return sendChannelAccessError(c, err)
+	}
+	documents, err := h.docRepo.GetDocumentsByChannelID(ctx, channelID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get documents by channel ID", err.Error())
+	}
+
+	respDocuments := make([]dto.DocumentResponse, 0, len(documents))
+	for i := range documents {
+		respDocuments = append(respDocuments, convertDocumentToDTO(&documents[i]))
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Documents retrieved successfully", respDocuments)
+}
+
+// UpdateDocument updates an existing document.
+// @Summary Update a document
+// @Description Update the title and/or content of a document. Content replaces the whole document body. Only the author can update.
+// @Tags Documents
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Document ID"
+// @Param document body dto.DocumentUpdateRequest true "Document Update Details"
+// @Success 200 {object} utils.APIResponse{data=dto.DocumentResponse} "Document updated successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update this document"
+// @Failure 404 {object} utils.APIResponse "Not Found - Document not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /documents/{id} [put]
+func (h *documentHandlerImpl) UpdateDocument(c *fiber.Ctx) error {
+	objectID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid document ID format", err.Error())
+	}
+
+	var req dto.DocumentUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	// Validasi manual
+	if req.Title != "" && len(req.Title) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long if provided")
+	}
+	if req.Title == "" && req.Content == nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "At least 'title' or 'content' must be provided for update")
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	existingDocument, err := h.docRepo.GetDocumentByID(ctx, objectID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve document for update", err.Error())
+	}
+	if existingDocument == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Document not found", nil)
+	}
+
+	// Otorisasi: Hanya author yang dapat mengupdate
+	// TODO: Tambahkan otorisasi lebih kompleks (misal: admin bisnis, anggota channel dengan izin)
+	if existingDocument.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to update this document", nil)
+	}
+
+	setMap := bson.M{}
+	if req.Title != "" {
+		setMap["title"] = req.Title
+	}
+	if req.Content != nil {
+		setMap["content"] = convertDocumentContentToModel(*req.Content)
+	}
+
+	updatedDocument, err := h.docRepo.UpdateDocument(ctx, objectID, bson.M{"$set": setMap})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update document", err.Error())
+	}
+	if updatedDocument == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Document not found after update (unlikely)", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Document updated successfully", convertDocumentToDTO(updatedDocument))
+}
+
+// DeleteDocument deletes a document.
+// @Summary Delete a document
+// @Description Delete a document by its ID. Only the author can delete.
+// @Tags Documents
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Document ID"
+// @Success 200 {object} utils.APIResponse "Document deleted successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to delete this document"
+// @Failure 404 {object} utils.APIResponse "Not Found - Document not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /documents/{id} [delete]
+func (h *documentHandlerImpl) DeleteDocument(c *fiber.Ctx) error {
+	objectID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid document ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	existingDocument, err := h.docRepo.GetDocumentByID(ctx, objectID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve document for deletion", err.Error())
+	}
+	if existingDocument == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Document not found", nil)
+	}
+
+	// Otorisasi: Hanya author yang dapat menghapus
+	// TODO: Tambahkan otorisasi lebih kompleks (misal: admin bisnis, anggota channel dengan izin)
+	if existingDocument.AuthorID.Hex() != userIDStr {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to delete this document", nil)
+	}
+
+	if err := h.docRepo.DeleteDocument(ctx, objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Document not found for deletion", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete document", err.Error())
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Document deleted successfully", nil)
+}
+
+// convertDocumentToDTO mengonversi model.Document menjadi dto.DocumentResponse.
+func convertDocumentToDTO(document *model.Document) dto.DocumentResponse {
+	return dto.DocumentResponse{
+		ID:        document.ID.Hex(),
+		ChannelID: document.ChannelID.Hex(),
+		AuthorID:  document.AuthorID.Hex(),
+		Title:     document.Title,
+		Content: dto.DocumentContentNode{
+			Type:    document.Content.Type,
+			Attrs:   document.Content.Attrs,
+			Content: convertDocumentSubNodesToDTO(document.Content.Content),
+		},
+		CreatedAt: document.CreatedAt,
+		UpdatedAt: document.UpdatedAt,
+	}
+}
+
+func convertDocumentSubNodesToDTO(nodes []model.DocumentContentSubNode) []dto.DocumentContentSubNode {
+	if nodes == nil {
+		return nil
+	}
+	result := make([]dto.DocumentContentSubNode, len(nodes))
+	for i, node := range nodes {
+		result[i] = dto.DocumentContentSubNode{
+			Type:    node.Type,
+			Text:    node.Text,
+			Attrs:   node.Attrs,
+			Content: convertDocumentSubNodesToDTO(node.Content),
+		}
+	}
+	return result
+}
+
+// convertDocumentContentToModel mengonversi root node dari request menjadi model.DocumentContentNode.
+func convertDocumentContentToModel(node dto.DocumentContentNode) model.DocumentContentNode {
+	return model.DocumentContentNode{
+		Type:    node.Type,
+		Attrs:   node.Attrs,
+		Content: convertDocumentSubNodesToModel(node.Content),
+	}
+}
+
+func convertDocumentSubNodesToModel(nodes []dto.DocumentContentSubNode) []model.DocumentContentSubNode {
+	if nodes == nil {
+		return nil
+	}
+	result := make([]model.DocumentContentSubNode, len(nodes))
+	for i, node := range nodes {
+		result[i] = model.DocumentContentSubNode{
+			Type:    node.Type,
+			Text:    node.Text,
+			Attrs:   node.Attrs,
+			Content: convertDocumentSubNodesToModel(node.Content),
+		}
+	}
+	return result
+}
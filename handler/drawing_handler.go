@@ -0,0 +1,658 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DrawingHandler menangani logika terkait entitas Drawing.
+type DrawingHandler interface {
+	CreateDrawing(c *fiber.Ctx) error
+	GetDrawingByID(c *fiber.Ctx) error
+	GetDrawingsByChannelID(c *fiber.Ctx) error
+	UpdateDrawing(c *fiber.Ctx) error
+	DeleteDrawing(c *fiber.Ctx) error
+
+	// Handler untuk operasi Shape
+	AddShapeToDrawing(c *fiber.Ctx) error
+	UpdateShapeInDrawing(c *fiber.Ctx) error
+	DeleteShapeFromDrawing(c *fiber.Ctx) error
+	ReorderShapesInDrawing(c *fiber.Ctx) error
+}
+
+type drawingHandlerImpl struct {
+	drawingRepo repository.DrawingRepository
+	channels    service.ChannelAccessService
+}
+
+// NewDrawingHandler membuat instance baru dari DrawingHandler.
+func NewDrawingHandler(drawingRepo repository.DrawingRepository, channels service.ChannelAccessService) DrawingHandler {
+	return &drawingHandlerImpl{drawingRepo: drawingRepo, channels: channels}
+}
+
+// CreateDrawing creates a new drawing.
+// @Summary Create a new drawing
+// @Description Create a new whiteboard drawing for a specific channel, optionally with initial shapes. The channel must exist, belong to a business the user owns or is a member of, and have type "drawings". The author is taken from the JWT token.
+// @Tags Drawings
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param drawing body dto.DrawingCreateRequest true "Drawing Creation Details"
+// @Success 201 {object} utils.APIResponse{data=dto.DrawingResponse} "Drawing created successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Channel not found or not in a business of the user"
+// @Failure 409 {object} utils.APIResponse "Conflict - Channel is not a drawings channel"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /drawings [post]
+func (h *drawingHandlerImpl) CreateDrawing(c *fiber.Ctx) error {
+	var req dto.DrawingCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	// Validasi manual
+	if req.ChannelID == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "ChannelID is required")
+	}
+	if len(req.Title) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long")
+	}
+	for _, shapeReq := range req.Shapes {
+		if shapeReq.Type == "" {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Shape Type is required")
+		}
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Author ID format", err.Error())
+	}
+	channelID, err := primitive.ObjectIDFromHex(req.ChannelID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Channel ID format", err.Error())
+	}
+
+	version := req.Version
+	if version == "" {
+		version = "1.0"
+	}
+
+	shapes := make([]model.DrawingShape, 0, len(req.Shapes))
+	for _, shapeReq := range req.Shapes {
+		shape := convertDrawingShapeToModel(shapeReq)
+		shape.ID = primitive.NewObjectID()
+		shapes = append(shapes, shape)
+	}
+
+	newDrawing := &model.Drawing{
+		ID:        primitive.NewObjectID(),
+		ChannelID: channelID,
+		AuthorID:  authorID,
+		Title:     req.Title,
+		DrawingData: model.DrawingData{
+			Version: version,
+			Shapes:  shapes,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	if _, err := h.channels.ValidateContentChannel(ctx, channelID, authorID, service.ChannelTypeDrawings); err != nil {
+		return sendChannelAccessError(c, err)
+	}
+	if err := h.drawingRepo.CreateDrawing(ctx, newDrawing); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create drawing", err.Error())
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Drawing created successfully", convertDrawingToDTO(newDrawing))
+}
+
+// GetDrawingByID retrieves a drawing by its ID.
+// @Summary Get drawing by ID
+// @Description Get a specific drawing, including its shapes in layer order.
+// @Tags Drawings
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Drawing ID"
+// @Success 200 {object} utils.APIResponse{data=dto.DrawingResponse} "Drawing retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this drawing"
+// @Failure 404 {object} utils.APIResponse "Not Found - Drawing not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /drawings/{id} [get]
+func (h *drawingHandlerImpl) GetDrawingByID(c *fiber.Ctx) error {
+	drawingID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid drawing ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	drawing, errResp := h.getAuthorizedDrawing(ctx, c, drawingID, "access")
+	if drawing == nil {
+		return errResp
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Drawing retrieved successfully", convertDrawingToDTO(drawing))
+}
+
+// GetDrawingsByChannelID retrieves all drawings associated with a specific channel ID.
+// @Summary Get drawings by channel ID
+// @Description Get a list of drawings for a given channel ID, most recently updated first. The channel must belong to a business the user owns or is a member of.
+// @Tags Drawings
+// @Produce json
+// @Security ApiKeyAuth
+// @Param channelId path string true "Channel ID"
+// @Success 200 {object} utils.APIResponse{data=[]dto.DrawingResponse} "Drawings retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid Channel ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Channel not found or not in a business of the user"
+// @Failure 409 {object} utils.APIResponse "Conflict - Channel is not a drawings channel"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /drawings/channel/{channelId} [get]
+func (h *drawingHandlerImpl) GetDrawingsByChannelID(c *fiber.Ctx) error {
+	channelID, err := primitive.ObjectIDFromHex(c.Params("channelId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Channel ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid User ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	// Hanya pemilik atau anggota bisnis channel yang boleh melihat gambar di dalamnya
+	if _, err := h.channels.ValidateContentChannel(ctx, channelID, userID, service.ChannelTypeDrawings); err != nil {
+		return sendChannelAccessError(c, err)
+	}
+	drawings, err := h.drawingRepo.GetDrawingsByChannelID(ctx, channelID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get drawings by channel ID", err.Error())
+	}
+
+	respDrawings := make([]dto.DrawingResponse, 0, len(drawings))
+	for i := range drawings {
+		respDrawings = append(respDrawings, convertDrawingToDTO(&drawings[i]))
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Drawings retrieved successfully", respDrawings)
+}
+
+// UpdateDrawing updates drawing metadata.
+// @Summary Update a drawing
+// @Description Update the title and/or version of a drawing. Shapes are modified through the shape endpoints.
+// @Tags Drawings
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Drawing ID"
+// @Param drawing body dto.DrawingUpdateRequest true "Drawing Update Details"
+// @Success 200 {object} utils.APIResponse{data=dto.DrawingResponse} "Drawing updated successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update this drawing"
+// @Failure 404 {object} utils.APIResponse "Not Found - Drawing not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /drawings/{id} [put]
+func (h *drawingHandlerImpl) UpdateDrawing(c *fiber.Ctx) error {
+	drawingID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid drawing ID format", err.Error())
+	}
+
+	var req dto.DrawingUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	// Validasi manual
+	if req.Title != "" && len(req.Title) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long if provided")
+	}
+	if req.Title == "" && req.Version == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "At least 'title' or 'version' must be provided for update")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	if drawing, errResp := h.getAuthorizedDrawing(ctx, c, drawingID, "update"); drawing == nil {
+		return errResp
+	}
+
+	setMap := bson.M{}
+	if req.Title != "" {
+		setMap["title"] = req.Title
+	}
+	if req.Version != "" {
+		setMap["drawingData.version"] = req.Version
+	}
+
+	updatedDrawing, err := h.drawingRepo.UpdateDrawing(ctx, drawingID, bson.M{"$set": setMap})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update drawing", err.Error())
+	}
+	if updatedDrawing == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Drawing not found after update (unlikely)", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Drawing updated successfully", convertDrawingToDTO(updatedDrawing))
+}
+
+// DeleteDrawing deletes a drawing.
+// @Summary Delete a drawing
+// @Description Delete a drawing and all of its shapes. Only the author can delete.
+// @Tags Drawings
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Drawing ID"
+// @Success 200 {object} utils.APIResponse "Drawing deleted successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to delete this drawing"
+// @Failure 404 {object} utils.APIResponse "Not Found - Drawing not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /drawings/{id} [delete]
+func (h *drawingHandlerImpl) DeleteDrawing(c *fiber.Ctx) error {
+	drawingID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid drawing ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	if drawing, errResp := h.getAuthorizedDrawing(ctx, c, drawingID, "delete"); drawing == nil {
+		return errResp
+	}
+
+	if err := h.drawingRepo.DeleteDrawing(ctx, drawingID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Drawing not found for deletion", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete drawing", err.Error())
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Drawing deleted successfully", nil)
+}
+
+// AddShapeToDrawing adds a shape on top of a drawing.
+// @Summary Add a shape to drawing
+// @Description Add a new shape to a drawing. The shape is placed on the top-most layer.
+// @Tags Drawings
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Drawing ID"
+// @Param shape body dto.DrawingShapeRequest true "New Shape Details"
+// @Success 200 {object} utils.APIResponse{data=dto.DrawingResponse} "Shape added successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to modify this drawing"
+// @Failure 404 {object} utils.APIResponse "Not Found - Drawing not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /drawings/{id}/shapes [post]
+func (h *drawingHandlerImpl) AddShapeToDrawing(c *fiber.Ctx) error {
+	drawingID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid drawing ID format", err.Error())
+	}
+
+	var req dto.DrawingShapeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if req.Type == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Shape Type is required")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	if drawing, errResp := h.getAuthorizedDrawing(ctx, c, drawingID, "modify"); drawing == nil {
+		return errResp
+	}
+
+	newShape := convertDrawingShapeToModel(req)
+	updatedDrawing, err := h.drawingRepo.AddShapeToDrawing(ctx, drawingID, &newShape)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to add shape to drawing", err.Error())
+	}
+	if updatedDrawing == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Drawing not found", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Shape added successfully", convertDrawingToDTO(updatedDrawing))
+}
+
+// UpdateShapeInDrawing updates a single shape by its ID.
+// @Summary Update a shape in drawing
+// @Description Partially update a shape. Only the fields present in the body are changed.
+// @Tags Drawings
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Drawing ID"
+// @Param shapeId path string true "Shape ID"
+// @Param shape body dto.DrawingShapeUpdateRequest true "Updated Shape Details"
+// @Success 200 {object} utils.APIResponse{data=dto.DrawingResponse} "Shape updated successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or validation error"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to modify this drawing"
+// @Failure 404 {object} utils.APIResponse "Not Found - Drawing or Shape not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /drawings/{id}/shapes/{shapeId} [put]
+func (h *drawingHandlerImpl) UpdateShapeInDrawing(c *fiber.Ctx) error {
+	drawingID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid drawing ID format", err.Error())
+	}
+	shapeID, err := primitive.ObjectIDFromHex(c.Params("shapeId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid shape ID format", err.Error())
+	}
+
+	var req dto.DrawingShapeUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	setMap := bson.M{}
+	if req.Type != nil {
+		if *req.Type == "" {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Shape Type cannot be empty")
+		}
+		setMap["type"] = *req.Type
+	}
+	if req.X != nil {
+		setMap["x"] = *req.X
+	}
+	if req.Y != nil {
+		setMap["y"] = *req.Y
+	}
+	if req.Width != nil {
+		setMap["width"] = *req.Width
+	}
+	if req.Height != nil {
+		setMap["height"] = *req.Height
+	}
+	if req.Fill != nil {
+		setMap["fill"] = *req.Fill
+	}
+	if req.Stroke != nil {
+		setMap["stroke"] = *req.Stroke
+	}
+	if req.Text != nil {
+		setMap["text"] = *req.Text
+	}
+	if req.Points != nil {
+		setMap["points"] = convertDrawingPointsToModel(req.Points)
+	}
+	if len(setMap) == 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "No shape fields provided for update")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	drawing, errResp := h.getAuthorizedDrawing(ctx, c, drawingID, "modify")
+	if drawing == nil {
+		return errResp
+	}
+	if findDrawingShape(drawing, shapeID) == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Shape not found in drawing", nil)
+	}
+
+	updatedDrawing, err := h.drawingRepo.UpdateShapeInDrawing(ctx, drawingID, shapeID, bson.M{"$set": setMap})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update shape in drawing", err.Error())
+	}
+	if updatedDrawing == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Drawing or Shape not found", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Shape updated successfully", convertDrawingToDTO(updatedDrawing))
+}
+
+// DeleteShapeFromDrawing removes a single shape by its ID.
+// @Summary Delete a shape from drawing
+// @Description Delete a specific shape from a drawing.
+// @Tags Drawings
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Drawing ID"
+// @Param shapeId path string true "Shape ID"
+// @Success 200 {object} utils.APIResponse{data=dto.DrawingResponse} "Shape deleted successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to modify this drawing"
+// @Failure 404 {object} utils.APIResponse "Not Found - Drawing or Shape not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /drawings/{id}/shapes/{shapeId} [delete]
+func (h *drawingHandlerImpl) DeleteShapeFromDrawing(c *fiber.Ctx) error {
+	drawingID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid drawing ID format", err.Error())
+	}
+	shapeID, err := primitive.ObjectIDFromHex(c.Params("shapeId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid shape ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	if drawing, errResp := h.getAuthorizedDrawing(ctx, c, drawingID, "modify"); drawing == nil {
+		return errResp
+	}
+
+	updatedDrawing, err := h.drawingRepo.DeleteShapeFromDrawing(ctx, drawingID, shapeID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete shape from drawing", err.Error())
+	}
+	if updatedDrawing == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Drawing or Shape not found", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Shape deleted successfully", convertDrawingToDTO(updatedDrawing))
+}
+
+// ReorderShapesInDrawing rewrites the layer order of all shapes.
+// @Summary Reorder shapes in drawing
+// @Description Reorder all shapes of a drawing. shapeIds must contain every existing shape ID exactly once, bottom layer first.
+// @Tags Drawings
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Drawing ID"
+// @Param order body dto.DrawingShapeReorderRequest true "New Shape Order"
+// @Success 200 {object} utils.APIResponse{data=dto.DrawingResponse} "Shapes reordered successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or shape list mismatch"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to modify this drawing"
+// @Failure 404 {object} utils.APIResponse "Not Found - Drawing not found"
+// @Failure 409 {object} utils.APIResponse "Conflict - Shapes changed concurrently"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /drawings/{id}/shapes/reorder [patch]
+func (h *drawingHandlerImpl) ReorderShapesInDrawing(c *fiber.Ctx) error {
+	drawingID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid drawing ID format", err.Error())
+	}
+
+	var req dto.DrawingShapeReorderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if len(req.ShapeIDs) == 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "shapeIds is required")
+	}
+
+	shapeIDs := make([]primitive.ObjectID, 0, len(req.ShapeIDs))
+	for _, idStr := range req.ShapeIDs {
+		shapeID, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid shape ID format", idStr)
+		}
+		shapeIDs = append(shapeIDs, shapeID)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	drawing, errResp := h.getAuthorizedDrawing(ctx, c, drawingID, "modify")
+	if drawing == nil {
+		return errResp
+	}
+
+	// Pastikan daftar ID berisi seluruh shape tepat satu kali
+	if len(shapeIDs) != len(drawing.DrawingData.Shapes) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "shapeIds must contain every shape in the drawing exactly once")
+	}
+	seen := make(map[primitive.ObjectID]bool, len(shapeIDs))
+	for _, shapeID := range shapeIDs {
+		if seen[shapeID] || findDrawingShape(drawing, shapeID) == nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "shapeIds must contain every shape in the drawing exactly once")
+		}
+		seen[shapeID] = true
+	}
+
+	updatedDrawing, err := h.drawingRepo.ReorderShapesInDrawing(ctx, drawingID, shapeIDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrDrawingShapesChanged) {
+			return utils.SendErrorResponse(c, fiber.StatusConflict, "Shapes changed while reordering", err.Error())
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to reorder shapes", err.Error())
+	}
+	if updatedDrawing == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Drawing not found", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Shapes reordered successfully", convertDrawingToDTO(updatedDrawing))
+}
+
+// getAuthorizedDrawing mengambil gambar dan memastikan user dari token adalah author-nya.
+// Jika gagal, drawing bernilai nil dan error berisi respons yang sudah dikirim ke client.
+func (h *drawingHandlerImpl) getAuthorizedDrawing(ctx context.Context, c *fiber.Ctx, drawingID primitive.ObjectID, action string) (*model.Drawing, error) {
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return nil, utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	drawing, err := h.drawingRepo.GetDrawingByID(ctx, drawingID)
+	if err != nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve drawing", err.Error())
+	}
+	if drawing == nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusNotFound, "Drawing not found", nil)
+	}
+
+	// Otorisasi: Hanya author yang dapat mengakses gambar
+	// TODO: Tambahkan otorisasi lebih kompleks (misal: admin bisnis, anggota channel dengan izin)
+	if drawing.AuthorID.Hex() != userIDStr {
+		return nil, utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to "+action+" this drawing", nil)
+	}
+	return drawing, nil
+}
+
+// findDrawingShape mencari shape berdasarkan ID di dalam gambar.
+func findDrawingShape(drawing *model.Drawing, shapeID primitive.ObjectID) *model.DrawingShape {
+	for i := range drawing.DrawingData.Shapes {
+		if drawing.DrawingData.Shapes[i].ID == shapeID {
+			return &drawing.DrawingData.Shapes[i]
+		}
+	}
+	return nil
+}
+
+// convertDrawingToDTO mengonversi model.Drawing menjadi dto.DrawingResponse.
+func convertDrawingToDTO(drawing *model.Drawing) dto.DrawingResponse {
+	shapes := make([]dto.DrawingShapeResponse, len(drawing.DrawingData.Shapes))
+	for i, shape := range drawing.DrawingData.Shapes {
+		var points []dto.DrawingPointRequest
+		if shape.Points != nil {
+			points = make([]dto.DrawingPointRequest, len(shape.Points))
+			for j, p := range shape.Points {
+				points[j] = dto.DrawingPointRequest{X: p.X, Y: p.Y}
+			}
+		}
+		shapes[i] = dto.DrawingShapeResponse{
+			ID:     shape.ID.Hex(),
+			Type:   shape.Type,
+			X:      shape.X,
+			Y:      shape.Y,
+			Width:  shape.Width,
+			Height: shape.Height,
+			Fill:   shape.Fill,
+			Stroke: shape.Stroke,
+			Text:   shape.Text,
+			Points: points,
+		}
+	}
+
+	return dto.DrawingResponse{
+		ID:        drawing.ID.Hex(),
+		ChannelID: drawing.ChannelID.Hex(),
+		AuthorID:  drawing.AuthorID.Hex(),
+		Title:     drawing.Title,
+		Version:   drawing.DrawingData.Version,
+		Shapes:    shapes,
+		CreatedAt: drawing.CreatedAt,
+		UpdatedAt: drawing.UpdatedAt,
+	}
+}
+
+// convertDrawingShapeToModel mengonversi request shape menjadi model.DrawingShape (tanpa ID).
+func convertDrawingShapeToModel(req dto.DrawingShapeRequest) model.DrawingShape {
+	return model.DrawingShape{
+		Type:   req.Type,
+		X:      req.X,
+		Y:      req.Y,
+		Width:  req.Width,
+		Height: req.Height,
+		Fill:   req.Fill,
+		Stroke: req.Stroke,
+		Text:   req.Text,
+		Points: convertDrawingPointsToModel(req.Points),
+	}
+}
+
+func convertDrawingPointsToModel(points []dto.DrawingPointRequest) []model.DrawingPoint {
+	if points == nil {
+		return nil
+	}
+	result := make([]model.DrawingPoint, len(points))
+	for i, p := range points {
+		result[i] = model.DrawingPoint{X: p.X, Y: p.Y}
+	}
+	return result
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+const (
+	// messageSocketWriteTimeout membatasi waktu tulis ke satu client agar client lambat tidak menahan broadcast.
+	messageSocketWriteTimeout = 5 * time.Second
+	// messageSocketPongWait adalah batas waktu tanpa frame maupun pong sebelum koneksi dianggap mati.
+	messageSocketPongWait = 60 * time.Second
+	// messageSocketPingPeriod adalah interval ping server; harus lebih pendek dari messageSocketPongWait.
+	messageSocketPingPeriod = messageSocketPongWait * 9 / 10
+	// maxSubscriptionsPerConnection membatasi jumlah channel yang dapat di-subscribe satu koneksi.
+	maxSubscriptionsPerConnection = 200
+)
+
+// messageClient adalah satu koneksi WebSocket pesan milik user terautentikasi. Semua tulis ke koneksi
+// melewati writeMu karena broadcast, timer typing dan balasan request berjalan di goroutine berbeda.
+type messageClient struct {
+	conn    *websocket.Conn
+	userID  string
+	writeMu sync.Mutex
+	// channels yang sedang di-subscribe koneksi ini; dijaga oleh subscriptionRegistry.mu
+	channels map[string]bool
+}
+
+func newMessageClient(conn *websocket.Conn, userID string) *messageClient {
+	return &messageClient{conn: conn, userID: userID, channels: make(map[string]bool)}
+}
+
+// send mengirim satu event ke client.
+func (cl *messageClient) send(event dto.MessageSocketEvent) error {
+	jsonMsg, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return cl.write(jsonMsg)
+}
+
+func (cl *messageClient) write(jsonMsg []byte) error {
+	cl.writeMu.Lock()
+	defer cl.writeMu.Unlock()
+	cl.conn.SetWriteDeadline(time.Now().Add(messageSocketWriteTimeout))
+	return cl.conn.WriteMessage(websocket.TextMessage, jsonMsg)
+}
+
+// heartbeat mengirim ping secara berkala sampai done ditutup. Browser membalas ping dengan pong secara
+// otomatis; pong (dan setiap frame lain) memperpanjang batas baca koneksi di HandleWebSocketMessage.
+func (cl *messageClient) heartbeat(done <-chan struct{}) {
+	ticker := time.NewTicker(messageSocketPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			cl.writeMu.Lock()
+			err := cl.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(messageSocketWriteTimeout))
+			cl.writeMu.Unlock()
+			if err != nil {
+				log.Printf("Error sending ping to user %s: %v\n", cl.userID, err)
+				// Tutup koneksi agar loop baca berhenti dan subscription dibersihkan
+				cl.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// subscriptionRegistry memetakan channel ke koneksi yang men-subscribe-nya. Satu koneksi dapat
+// men-subscribe banyak channel sehingga client cukup membuka satu WebSocket.
+type subscriptionRegistry struct {
+	channels map[string]map[*messageClient]bool
+	mu       sync.RWMutex
+}
+
+func newSubscriptionRegistry() *subscriptionRegistry {
+	return &subscriptionRegistry{channels: make(map[string]map[*messageClient]bool)}
+}
+
+// subscribe mendaftarkan client ke channel. Subscribe ulang ke channel yang sama tidak dianggap error.
+func (r *subscriptionRegistry) subscribe(client *messageClient, channelID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if client.channels[channelID] {
+		return nil
+	}
+	if len(client.channels) >= maxSubscriptionsPerConnection {
+		return fmt.Errorf("maksimal %d channel per koneksi", maxSubscriptionsPerConnection)
+	}
+	if r.channels[channelID] == nil {
+		r.channels[channelID] = make(map[*messageClient]bool)
+	}
+	r.channels[channelID][client] = true
+	client.channels[channelID] = true
+	return nil
+}
+
+// unsubscribe melepas client dari channel dan mengembalikan false jika client memang tidak men-subscribe-nya.
+func (r *subscriptionRegistry) unsubscribe(client *messageClient, channelID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !client.channels[channelID] {
+		return false
+	}
+	r.removeLocked(client, channelID)
+	return true
+}
+
+// unsubscribeAll melepas client dari semua channel dan mengembalikan channel yang dilepas.
+func (r *subscriptionRegistry) unsubscribeAll(client *messageClient) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	channelIDs := make([]string, 0, len(client.channels))
+	for channelID := range client.channels {
+		channelIDs = append(channelIDs, channelID)
+	}
+	for _, channelID := range channelIDs {
+		r.removeLocked(client, channelID)
+	}
+	return channelIDs
+}
+
+// isSubscribed memeriksa apakah client men-subscribe channel.
+func (r *subscriptionRegistry) isSubscribed(client *messageClient, channelID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return client.channels[channelID]
+}
+
+// subscribedChannels mengembalikan channel yang di-subscribe client, terurut.
+func (r *subscriptionRegistry) subscribedChannels(client *messageClient) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	channelIDs := make([]string, 0, len(client.channels))
+	for channelID := range client.channels {
+		channelIDs = append(channelIDs, channelID)
+	}
+	sort.Strings(channelIDs)
+	return channelIDs
+}
+
+// subscribers mengembalikan salinan daftar client pada channel agar penulisan dilakukan di luar lock.
+func (r *subscriptionRegistry) subscribers(channelID string) []*messageClient {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	clients := make([]*messageClient, 0, len(r.channels[channelID]))
+	for client := range r.channels[channelID] {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+func (r *subscriptionRegistry) removeLocked(client *messageClient, channelID string) {
+	delete(client.channels, channelID)
+	delete(r.channels[channelID], client)
+	if len(r.channels[channelID]) == 0 {
+		delete(r.channels, channelID)
+	}
+}
+
+// messageFrame adalah satu frame dari client beserta koneksi asalnya. ChannelID sudah diisi dengan
+// channel dari URL untuk koneksi lama /ws/messages/:channelId yang tidak mengirim channelId per frame.
+type messageFrame struct {
+	dto.MessageSocketFrame
+	client *messageClient
+}
+
+// reply mengirim balasan untuk frame ini dengan ID dan channel yang sama.
+func (f *messageFrame) reply(eventType string, payload interface{}) {
+	event := dto.MessageSocketEvent{Type: eventType, ID: f.ID, ChannelID: f.ChannelID, Payload: payload}
+	if err := f.client.send(event); err != nil {
+		log.Printf("Error emitting %s: %v\n", eventType, err)
+	}
+}
+
+// logAndEmitErrorWS mencatat error lalu mengirim event error yang membawa ID frame penyebabnya.
+func logAndEmitErrorWS(frame *messageFrame, message string, err error) {
+	logMsg := message
+	if err != nil {
+		logMsg = fmt.Sprintf("%s: %v", message, err)
+		utils.LogError(err, message)
+	}
+	log.Println(logMsg)
+	frame.reply("error", logMsg)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -11,6 +12,7 @@ import (
 	"backend_my_manajer/dto"
 	"backend_my_manajer/model"
 	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
 	"backend_my_manajer/utils"
 
 	"github.com/gofiber/contrib/websocket" // Use Fiber WebSocket
@@ -27,54 +29,41 @@ type MessageHandler interface {
 // messageHandlerImpl implements MessageHandler
 type messageHandlerImpl struct {
 	repo repository.MessageRepository
-	// Use a map to manage active WebSocket connections by channel ID
-	activeConnections map[string]map[*websocket.Conn]bool
-	mu                sync.RWMutex // Mutex for thread-safe access to activeConnections
+	// channels memeriksa keanggotaan bisnis dan tipe channel sebelum koneksi boleh subscribe
+	channels service.ChannelAccessService
+	// notifications mengubah mention menjadi notifikasi, notificationHub mengirimkannya secara live
+	notifications   service.NotificationService
+	notificationHub *NotificationHub
+	readStates      service.ReadStateService
+	// presence melacak online/idle/offline dari koneksi ini, typing menyimpan indikator mengetik aktif
+	presence *PresenceHub
+	typing   map[typingKey]*typingState
+	typingMu sync.Mutex
+	// subscriptions memetakan channel ke koneksi yang men-subscribe-nya
+	subscriptions *subscriptionRegistry
 }
 
 // NewMessageHandler creates a new instance of MessageHandler.
-func NewMessageHandler(repo repository.MessageRepository) MessageHandler {
+func NewMessageHandler(repo repository.MessageRepository, channels service.ChannelAccessService, notifications service.NotificationService, notificationHub *NotificationHub, readStates service.ReadStateService, presence *PresenceHub) MessageHandler {
 	return &messageHandlerImpl{
-		repo:              repo,
-		activeConnections: make(map[string]map[*websocket.Conn]bool),
+		repo:            repo,
+		channels:        channels,
+		notifications:   notifications,
+		notificationHub: notificationHub,
+		readStates:      readStates,
+		presence:        presence,
+		typing:          make(map[typingKey]*typingState),
+		subscriptions:   newSubscriptionRegistry(),
 	}
 }
 
 // HandleWebSocketMessage handles WebSocket connections and messages.
+// Satu koneksi /ws/messages dapat men-subscribe banyak channel lewat frame subscribe/unsubscribe dengan
+// channelId. Frame lain membawa channelId tujuan (harus sudah di-subscribe) dan id opsional yang
+// dikembalikan pada balasan maupun error-nya. Koneksi lama /ws/messages/:channelId langsung di-subscribe
+// ke channel pada URL, dan frame tanpa channelId diarahkan ke channel tersebut.
 func (h *messageHandlerImpl) HandleWebSocketMessage(c *websocket.Conn) {
-	defer func() {
-		// Remove connection from active connections when it closes
-		channelID := c.Params("channelId")
-		h.mu.Lock()
-		delete(h.activeConnections[channelID], c)
-		if len(h.activeConnections[channelID]) == 0 {
-			delete(h.activeConnections, channelID)
-		}
-		h.mu.Unlock()
-		log.Printf("Client disconnected from channel %s: %s\n", channelID, c.LocalAddr().String())
-		c.Close()
-	}()
-
-	channelID := c.Params("channelId")
-	if channelID == "" {
-		log.Println("Error: Channel ID not provided in WebSocket URL.")
-		// Optionally send an error message back to the client before closing
-		c.WriteMessage(websocket.TextMessage, []byte("Error: Channel ID required."))
-		return
-	}
-
-	// Add the new connection to our active connections map
-	h.mu.Lock()
-	if h.activeConnections[channelID] == nil {
-		h.activeConnections[channelID] = make(map[*websocket.Conn]bool)
-	}
-	h.activeConnections[channelID][c] = true
-	h.mu.Unlock()
-
-	log.Printf("Client connected to channel %s: %s\n", channelID, c.LocalAddr().String())
-
-	// Send a confirmation to the client that they joined the channel
-	// c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Joined channel: %s", channelID)))
+	defer c.Close()
 
 	// Periksa hasil autentikasi dari middleware
 	authFailed, ok := c.Locals("authFailed").(bool)
@@ -83,14 +72,48 @@ func (h *messageHandlerImpl) HandleWebSocketMessage(c *websocket.Conn) {
 		if authError == "" {
 			authError = "Autentikasi gagal"
 		}
-		log.Printf("WebSocket authentication failed for channel %s: %s\n", channelID, authError)
+		log.Printf("WebSocket authentication failed for messages: %s\n", authError)
 		// Kirim pesan error melalui WebSocket, lalu tutup koneksi
 		c.WriteJSON(map[string]interface{}{"type": "error", "payload": authError})
 		return // Penting: Jangan lanjutkan loop pesan, langsung keluar dari handler
 	}
+	userID, _ := c.Locals("userID").(string)
+	if userID == "" {
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": "User ID tidak ditemukan di koneksi terautentikasi"})
+		return
+	}
 
-	// Kirim konfirmasi hanya jika autentikasi berhasil
-	c.WriteJSON(map[string]interface{}{"type": "server_log", "payload": fmt.Sprintf("Joined channel: %s", channelID)})
+	client := newMessageClient(c, userID)
+	h.presence.Connect(userID)
+	defer func() {
+		// Lepas semua subscription koneksi ini dan hentikan indikator mengetik user di channel tersebut
+		for _, channelID := range h.subscriptions.unsubscribeAll(client) {
+			h.stopAllTyping(channelID, userID)
+		}
+		h.presence.Disconnect(userID)
+		log.Printf("Client disconnected from messages of user %s: %s\n", userID, c.LocalAddr().String())
+	}()
+
+	// Heartbeat: server mengirim ping berkala; koneksi ditutup jika tidak ada pong atau frame lain
+	// dalam messageSocketPongWait
+	c.SetReadDeadline(time.Now().Add(messageSocketPongWait))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(messageSocketPongWait))
+	})
+	done := make(chan struct{})
+	defer close(done)
+	go client.heartbeat(done)
+
+	// Kompatibilitas koneksi per channel: subscribe otomatis ke channel pada URL
+	defaultChannelID := c.Params("channelId")
+	if defaultChannelID != "" {
+		frame := &messageFrame{MessageSocketFrame: dto.MessageSocketFrame{ChannelID: defaultChannelID}, client: client}
+		if !h.subscribeChannel(frame) {
+			return
+		}
+		frame.reply("server_log", fmt.Sprintf("Joined channel: %s", defaultChannelID))
+	}
+	log.Printf("Client connected to messages of user %s: %s\n", userID, c.LocalAddr().String())
 
 	// Loop to read messages from the client
 	for {
@@ -100,99 +123,200 @@ func (h *messageHandlerImpl) HandleWebSocketMessage(c *websocket.Conn) {
 			log.Println("read error:", err)
 			break
 		}
+		c.SetReadDeadline(time.Now().Add(messageSocketPongWait))
 
 		if mt != websocket.TextMessage {
 			log.Println("Received non-text message type, skipping.")
 			continue
 		}
 
-		// Handle different message types based on a 'type' field in the JSON payload
-		var wsMessage struct {
-			Type    string          `json:"type"`
-			Payload json.RawMessage `json:"payload"`
-		}
-
-		if err := json.Unmarshal(msg, &wsMessage); err != nil {
-			logAndEmitErrorWS(c, "Invalid message format", err)
+		frame := &messageFrame{client: client}
+		if err := json.Unmarshal(msg, &frame.MessageSocketFrame); err != nil {
+			logAndEmitErrorWS(frame, "Invalid message format", err)
 			continue
 		}
-
-		switch wsMessage.Type {
-		case "client_message":
-			h.handleCreateMessage(c, channelID, wsMessage.Payload)
-		case "get_message_history":
-			h.handleGetMessageHistory(c, channelID, wsMessage.Payload)
-		case "update_message":
-			h.handleUpdateMessage(c, channelID, wsMessage.Payload)
-		case "delete_message":
-			h.handleDeleteMessage(c, channelID, wsMessage.Payload)
-		case "add_reaction":
-			h.handleAddReaction(c, channelID, wsMessage.Payload)
-		case "remove_reaction":
-			h.handleRemoveReaction(c, channelID, wsMessage.Payload)
-		default:
-			logAndEmitErrorWS(c, "Unknown message type", nil)
+		if frame.ChannelID == "" {
+			frame.ChannelID = defaultChannelID
 		}
+		// Ping aplikasi hanya menjaga koneksi, bukan aktivitas user
+		if frame.Type != "ping" {
+			h.presence.Touch(userID)
+		}
+		h.dispatch(frame)
+	}
+}
+
+// dispatch menjalankan handler sesuai tipe frame. Selain ping, subscribe dan unsubscribe, frame hanya
+// diproses untuk channel yang sudah di-subscribe koneksi ini.
+func (h *messageHandlerImpl) dispatch(frame *messageFrame) {
+	switch frame.Type {
+	case "ping":
+		frame.reply("pong", map[string]interface{}{"serverTime": time.Now()})
+		return
+	case "subscribe":
+		h.handleSubscribe(frame)
+		return
+	case "unsubscribe":
+		h.handleUnsubscribe(frame)
+		return
 	}
+
+	handle := h.channelFrameHandler(frame.Type)
+	if handle == nil {
+		logAndEmitErrorWS(frame, "Unknown message type", nil)
+		return
+	}
+	if frame.ChannelID == "" {
+		logAndEmitErrorWS(frame, "channelId is required", nil)
+		return
+	}
+	if !h.subscriptions.isSubscribed(frame.client, frame.ChannelID) {
+		logAndEmitErrorWS(frame, "Not subscribed to this channel, send subscribe first", nil)
+		return
+	}
+	handle(frame)
 }
 
-// Helper to broadcast messages to all connections in a specific channel
+// channelFrameHandler mengembalikan handler untuk frame yang berlaku pada satu channel, atau nil jika tipe tidak dikenal.
+func (h *messageHandlerImpl) channelFrameHandler(frameType string) func(*messageFrame) {
+	switch frameType {
+	case "client_message":
+		return h.handleCreateMessage
+	case "get_message_history":
+		return h.handleGetMessageHistory
+	case "update_message":
+		return h.handleUpdateMessage
+	case "delete_message":
+		return h.handleDeleteMessage
+	case "add_reaction":
+		return h.handleAddReaction
+	case "remove_reaction":
+		return h.handleRemoveReaction
+	case "reply_message":
+		return h.handleCreateReply
+	case "get_thread":
+		return h.handleGetThread
+	case "mark_read":
+		return h.handleMarkRead
+	case "typing_start":
+		return func(frame *messageFrame) { h.handleTyping(frame, true) }
+	case "typing_stop":
+		return func(frame *messageFrame) { h.handleTyping(frame, false) }
+	}
+	return nil
+}
+
+// Helper to broadcast messages to all connections subscribed to a specific channel. Event membawa
+// channelId agar client dengan banyak subscription dapat mengarahkannya ke channel yang benar.
 func (h *messageHandlerImpl) broadcastToChannel(channelID string, messageType string, data interface{}) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	clients := h.subscriptions.subscribers(channelID)
+	if len(clients) == 0 {
+		return
+	}
+	jsonMsg, err := json.Marshal(dto.MessageSocketEvent{Type: messageType, ChannelID: channelID, Payload: data})
+	if err != nil {
+		log.Printf("Error marshalling broadcast message: %v\n", err)
+		return
+	}
 
-	if connections, ok := h.activeConnections[channelID]; ok {
-		jsonMsg, err := json.Marshal(map[string]interface{}{
-			"type":    messageType,
-			"payload": data,
-		})
-		if err != nil {
-			log.Printf("Error marshalling broadcast message: %v\n", err)
-			return
+	for _, client := range clients {
+		if err := client.write(jsonMsg); err != nil {
+			log.Printf("Error writing to websocket for channel %s: %v\n", channelID, err)
+			// Koneksi rusak ditutup agar loop bacanya berhenti dan semua subscription-nya dilepas
+			client.conn.Close()
 		}
+	}
+}
+
+// subscribeChannel memastikan channel bertipe messages dan user adalah anggota bisnisnya, lalu
+// mendaftarkan koneksi ke channel. Mengembalikan false setelah mengirim error ke client jika gagal.
+func (h *messageHandlerImpl) subscribeChannel(frame *messageFrame) bool {
+	if frame.ChannelID == "" {
+		logAndEmitErrorWS(frame, "channelId is required", nil)
+		return false
+	}
+	channelID, err := primitive.ObjectIDFromHex(frame.ChannelID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Invalid channel ID", err)
+		return false
+	}
+	userID, err := primitive.ObjectIDFromHex(frame.client.userID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Invalid user ID dari token", err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-		for conn := range connections {
-			if err := conn.WriteMessage(websocket.TextMessage, jsonMsg); err != nil {
-				log.Printf("Error writing to websocket for channel %s: %v\n", channelID, err)
-				// Remove broken connection
-				func(brokenConn *websocket.Conn) {
-					h.mu.Lock()
-					delete(h.activeConnections[channelID], brokenConn)
-					if len(h.activeConnections[channelID]) == 0 {
-						delete(h.activeConnections, channelID)
-					}
-					h.mu.Unlock()
-				}(conn)
-			}
+	if _, err := h.channels.ValidateContentChannel(ctx, channelID, userID, service.ChannelTypeMessages); err != nil {
+		switch {
+		// Non-anggota diperlakukan sama dengan channel yang tidak ada
+		case errors.Is(err, service.ErrChannelNotFound), errors.Is(err, service.ErrNotBusinessMember):
+			logAndEmitErrorWS(frame, "Channel not found", nil)
+		case errors.Is(err, service.ErrChannelTypeMismatch):
+			logAndEmitErrorWS(frame, "Channel is not a messages channel", nil)
+		default:
+			utils.LogError(err, "Failed to validate channel subscription")
+			logAndEmitErrorWS(frame, "Failed to subscribe to channel", err)
 		}
+		return false
+	}
+
+	if err := h.subscriptions.subscribe(frame.client, frame.ChannelID); err != nil {
+		logAndEmitErrorWS(frame, "Too many channel subscriptions", err)
+		return false
+	}
+	return true
+}
+
+func (h *messageHandlerImpl) handleSubscribe(frame *messageFrame) {
+	if !h.subscribeChannel(frame) {
+		return
 	}
+	frame.reply("subscribed", dto.MessageSubscriptionResponse{
+		ChannelID: frame.ChannelID,
+		Channels:  h.subscriptions.subscribedChannels(frame.client),
+	})
+}
+
+func (h *messageHandlerImpl) handleUnsubscribe(frame *messageFrame) {
+	if frame.ChannelID == "" {
+		logAndEmitErrorWS(frame, "channelId is required", nil)
+		return
+	}
+	if !h.subscriptions.unsubscribe(frame.client, frame.ChannelID) {
+		logAndEmitErrorWS(frame, "Not subscribed to this channel", nil)
+		return
+	}
+	h.stopAllTyping(frame.ChannelID, frame.client.userID)
+	frame.reply("unsubscribed", dto.MessageSubscriptionResponse{
+		ChannelID: frame.ChannelID,
+		Channels:  h.subscriptions.subscribedChannels(frame.client),
+	})
 }
 
 // --- Individual handler functions for different WebSocket message types ---
 
-func (h *messageHandlerImpl) handleCreateMessage(c *websocket.Conn, channelIDStr string, payload json.RawMessage) {
+func (h *messageHandlerImpl) handleCreateMessage(frame *messageFrame) {
 	var req dto.MessageCreateRequest
-	if err := json.Unmarshal(payload, &req); err != nil {
-		logAndEmitErrorWS(c, "Invalid message payload", err)
+	if err := json.Unmarshal(frame.Payload, &req); err != nil {
+		logAndEmitErrorWS(frame, "Invalid message payload", err)
 		return
 	}
 
-	channelID, err := primitive.ObjectIDFromHex(channelIDStr)
+	channelID, err := primitive.ObjectIDFromHex(frame.ChannelID)
 	if err != nil {
-		logAndEmitErrorWS(c, "Invalid channel ID", err)
+		logAndEmitErrorWS(frame, "Invalid channel ID", err)
 		return
 	}
 
-	// Ambil userID dari locals yang sudah diautentikasi, JANGAN PERCAYA PAYLOAD
-	userIDStr, ok := c.Locals("userID").(string)
-	if !ok || userIDStr == "" {
-		logAndEmitErrorWS(c, "User ID tidak ditemukan di koneksi terautentikasi", nil)
-		return
-	}
+	// Ambil userID dari koneksi yang sudah diautentikasi, JANGAN PERCAYA PAYLOAD
+	userIDStr := frame.client.userID
 
 	userID, err := primitive.ObjectIDFromHex(userIDStr)
 	if err != nil {
-		logAndEmitErrorWS(c, "Invalid user ID dari token", err)
+		logAndEmitErrorWS(frame, "Invalid user ID dari token", err)
 		return
 	}
 
@@ -218,38 +342,34 @@ func (h *messageHandlerImpl) handleCreateMessage(c *websocket.Conn, channelIDStr
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	newMessage.Mentions = h.resolveMentions(ctx, channelID, req.Content)
 	if err := h.repo.CreateMessage(ctx, newMessage); err != nil {
 		utils.LogError(err, "Failed to save message to database")
-		logAndEmitErrorWS(c, "Failed to create message", err)
+		logAndEmitErrorWS(frame, "Failed to create message", err)
 		return
 	}
 
-	resp := dto.MessageResponse{
-		ID:            newMessage.ID.Hex(),
-		ChannelID:     newMessage.ChannelID.Hex(),
-		UserID:        newMessage.UserID.Hex(),
-		Content:       newMessage.Content,
-		MessageType:   newMessage.MessageType,
-		MediaPath:     newMessage.MediaPath,
-		MediaMetadata: convertMediaMetadataToDTO(newMessage.MediaMetadata),
-		CreatedAt:     newMessage.CreatedAt,
-		UpdatedAt:     newMessage.UpdatedAt,
-		IsPinned:      newMessage.IsPinned,
-		Reactions:     convertMessageReactionsToDTO(newMessage.Reactions),
-	}
+	resp := convertMessageToDTO(newMessage)
 
 	// Emit success back to the sender
-	if err := c.WriteJSON(map[string]interface{}{"type": "message_created", "payload": resp}); err != nil {
-		log.Printf("Error emitting message_created: %v\n", err)
-	}
+	frame.reply("message_created", resp)
 	// Broadcast to all other clients in the channel room
-	h.broadcastToChannel(channelIDStr, "new_message", resp)
+	h.broadcastToChannel(frame.ChannelID, "new_message", resp)
+	h.stopTyping(typingKey{channelID: frame.ChannelID, userID: userIDStr})
+	h.notifyMentions(newMessage)
+
+	// Pesan sendiri tidak dihitung sebagai belum dibaca: majukan posisi baca pengirim
+	if h.readStates != nil {
+		if _, err := h.readStates.MarkRead(ctx, userID, channelID, &newMessage.ID); err != nil {
+			utils.LogError(err, "Gagal memajukan posisi baca pengirim pesan %s", newMessage.ID.Hex())
+		}
+	}
 }
 
-func (h *messageHandlerImpl) handleGetMessageHistory(c *websocket.Conn, channelIDStr string, payload json.RawMessage) {
-	channelID, err := primitive.ObjectIDFromHex(channelIDStr)
+func (h *messageHandlerImpl) handleGetMessageHistory(frame *messageFrame) {
+	channelID, err := primitive.ObjectIDFromHex(frame.ChannelID)
 	if err != nil {
-		logAndEmitErrorWS(c, "Invalid channel ID", err)
+		logAndEmitErrorWS(frame, "Invalid channel ID", err)
 		return
 	}
 
@@ -257,8 +377,8 @@ func (h *messageHandlerImpl) handleGetMessageHistory(c *websocket.Conn, channelI
 		Limit int64 `json:"limit,omitempty"`
 		Skip  int64 `json:"skip,omitempty"`
 	}
-	if err := json.Unmarshal(payload, &req); err != nil {
-		logAndEmitErrorWS(c, "Invalid get_message_history payload", err)
+	if err := json.Unmarshal(frame.Payload, &req); err != nil {
+		logAndEmitErrorWS(frame, "Invalid get_message_history payload", err)
 		return
 	}
 
@@ -275,53 +395,35 @@ func (h *messageHandlerImpl) handleGetMessageHistory(c *websocket.Conn, channelI
 	messages, err := h.repo.GetMessagesByChannelID(ctx, channelID, req.Limit, req.Skip)
 	if err != nil {
 		utils.LogError(err, "Failed to fetch message history")
-		logAndEmitErrorWS(c, "Failed to retrieve message history", err)
+		logAndEmitErrorWS(frame, "Failed to retrieve message history", err)
 		return
 	}
 
 	respMessages := []dto.MessageResponse{}
 	for _, msg := range messages {
-		respMessages = append(respMessages, dto.MessageResponse{
-			ID:            msg.ID.Hex(),
-			ChannelID:     msg.ChannelID.Hex(),
-			UserID:        msg.UserID.Hex(),
-			Content:       msg.Content,
-			MessageType:   msg.MessageType,
-			MediaPath:     msg.MediaPath,
-			MediaMetadata: convertMediaMetadataToDTO(msg.MediaMetadata),
-			CreatedAt:     msg.CreatedAt,
-			UpdatedAt:     msg.UpdatedAt,
-			IsPinned:      msg.IsPinned,
-			Reactions:     convertMessageReactionsToDTO(msg.Reactions),
-		})
-	}
-	if err := c.WriteJSON(map[string]interface{}{"type": "message_history", "payload": respMessages}); err != nil {
-		log.Printf("Error emitting message_history: %v\n", err)
+		respMessages = append(respMessages, convertMessageToDTO(&msg))
 	}
+	frame.reply("message_history", respMessages)
 }
 
-func (h *messageHandlerImpl) handleUpdateMessage(c *websocket.Conn, channelIDStr string, payload json.RawMessage) {
+func (h *messageHandlerImpl) handleUpdateMessage(frame *messageFrame) {
 	var updatePayload struct {
 		ID string `json:"id"`
 		dto.MessageUpdateRequest
 	}
-	if err := json.Unmarshal(payload, &updatePayload); err != nil {
-		logAndEmitErrorWS(c, "Invalid update_message payload", err)
+	if err := json.Unmarshal(frame.Payload, &updatePayload); err != nil {
+		logAndEmitErrorWS(frame, "Invalid update_message payload", err)
 		return
 	}
 	messageIDStr := updatePayload.ID
 	req := updatePayload.MessageUpdateRequest
 
-	// Ambil userID dari locals
-	userIDStr, ok := c.Locals("userID").(string)
-	if !ok || userIDStr == "" {
-		logAndEmitErrorWS(c, "User ID tidak ditemukan di koneksi terautentikasi", nil)
-		return
-	}
+	// Ambil userID dari koneksi terautentikasi
+	userIDStr := frame.client.userID
 
 	msgObjectID, err := primitive.ObjectIDFromHex(messageIDStr)
 	if err != nil {
-		logAndEmitErrorWS(c, "Invalid message ID for update", err)
+		logAndEmitErrorWS(frame, "Invalid message ID for update", err)
 		return
 	}
 
@@ -355,7 +457,7 @@ func (h *messageHandlerImpl) handleUpdateMessage(c *websocket.Conn, channelIDStr
 	}
 
 	if len(updateMap) == 0 {
-		logAndEmitErrorWS(c, "No data to update", nil)
+		logAndEmitErrorWS(frame, "No data to update", nil)
 		return
 	}
 
@@ -366,68 +468,54 @@ func (h *messageHandlerImpl) handleUpdateMessage(c *websocket.Conn, channelIDStr
 	// Cek otorisasi: apakah user yang request adalah pemilik pesan?
 	existingMessage, err := h.repo.GetMessageByID(updateCtx, msgObjectID)
 	if err != nil {
-		logAndEmitErrorWS(c, "Gagal memeriksa pesan untuk otorisasi update", err)
+		logAndEmitErrorWS(frame, "Gagal memeriksa pesan untuk otorisasi update", err)
 		return
 	}
 	if existingMessage == nil {
-		logAndEmitErrorWS(c, "Pesan tidak ditemukan untuk diupdate", nil)
+		logAndEmitErrorWS(frame, "Pesan tidak ditemukan untuk diupdate", nil)
+		return
+	}
+	if !messageInFrameChannel(frame, existingMessage) {
+		logAndEmitErrorWS(frame, "Message not found in this channel", nil)
 		return
 	}
 	if existingMessage.UserID.Hex() != userIDStr {
-		logAndEmitErrorWS(c, "Tidak diizinkan: Anda bukan pemilik pesan ini", nil)
+		logAndEmitErrorWS(frame, "Tidak diizinkan: Anda bukan pemilik pesan ini", nil)
 		return
 	}
 
 	updatedMessage, err := h.repo.UpdateMessage(updateCtx, msgObjectID, updateMap)
 	if err != nil {
 		utils.LogError(err, "Failed to update message in database")
-		logAndEmitErrorWS(c, "Failed to update message", err)
+		logAndEmitErrorWS(frame, "Failed to update message", err)
 		return
 	}
 
 	if updatedMessage == nil {
-		logAndEmitErrorWS(c, "Message not found for update", nil)
+		logAndEmitErrorWS(frame, "Message not found for update", nil)
 		return
 	}
 
-	resp := dto.MessageResponse{
-		ID:            updatedMessage.ID.Hex(),
-		ChannelID:     updatedMessage.ChannelID.Hex(),
-		UserID:        updatedMessage.UserID.Hex(),
-		Content:       updatedMessage.Content,
-		MessageType:   updatedMessage.MessageType,
-		MediaPath:     updatedMessage.MediaPath,
-		MediaMetadata: convertMediaMetadataToDTO(updatedMessage.MediaMetadata),
-		CreatedAt:     updatedMessage.CreatedAt,
-		UpdatedAt:     updatedMessage.UpdatedAt,
-		IsPinned:      updatedMessage.IsPinned,
-		Reactions:     convertMessageReactionsToDTO(updatedMessage.Reactions),
-	}
-	if err := c.WriteJSON(map[string]interface{}{"type": "message_updated", "payload": resp}); err != nil {
-		log.Printf("Error emitting message_updated: %v\n", err)
-	}
-	h.broadcastToChannel(channelIDStr, "message_updated", resp)
+	resp := convertMessageToDTO(updatedMessage)
+	frame.reply("message_updated", resp)
+	h.broadcastToChannel(frame.ChannelID, "message_updated", resp)
 }
 
-func (h *messageHandlerImpl) handleDeleteMessage(c *websocket.Conn, channelIDStr string, payload json.RawMessage) {
+func (h *messageHandlerImpl) handleDeleteMessage(frame *messageFrame) {
 	var req struct {
 		ID string `json:"id"`
 	}
-	if err := json.Unmarshal(payload, &req); err != nil {
-		logAndEmitErrorWS(c, "Invalid delete_message payload", err)
+	if err := json.Unmarshal(frame.Payload, &req); err != nil {
+		logAndEmitErrorWS(frame, "Invalid delete_message payload", err)
 		return
 	}
 
-	// Ambil userID dari locals
-	userIDStr, ok := c.Locals("userID").(string)
-	if !ok || userIDStr == "" {
-		logAndEmitErrorWS(c, "User ID tidak ditemukan di koneksi terautentikasi", nil)
-		return
-	}
+	// Ambil userID dari koneksi terautentikasi
+	userIDStr := frame.client.userID
 
 	msgObjectID, err := primitive.ObjectIDFromHex(req.ID)
 	if err != nil {
-		logAndEmitErrorWS(c, "Invalid message ID for delete", err)
+		logAndEmitErrorWS(frame, "Invalid message ID for delete", err)
 		return
 	}
 
@@ -437,15 +525,19 @@ func (h *messageHandlerImpl) handleDeleteMessage(c *websocket.Conn, channelIDStr
 	// Cek otorisasi: apakah user yang request adalah pemilik pesan?
 	existingMessage, err := h.repo.GetMessageByID(ctx, msgObjectID)
 	if err != nil {
-		logAndEmitErrorWS(c, "Gagal memeriksa pesan untuk otorisasi delete", err)
+		logAndEmitErrorWS(frame, "Gagal memeriksa pesan untuk otorisasi delete", err)
 		return
 	}
 	if existingMessage == nil {
-		logAndEmitErrorWS(c, "Pesan tidak ditemukan untuk dihapus", nil)
+		logAndEmitErrorWS(frame, "Pesan tidak ditemukan untuk dihapus", nil)
+		return
+	}
+	if !messageInFrameChannel(frame, existingMessage) {
+		logAndEmitErrorWS(frame, "Message not found in this channel", nil)
 		return
 	}
 	if existingMessage.UserID.Hex() != userIDStr {
-		logAndEmitErrorWS(c, "Tidak diizinkan: Anda bukan pemilik pesan ini", nil)
+		logAndEmitErrorWS(frame, "Tidak diizinkan: Anda bukan pemilik pesan ini", nil)
 		return
 	}
 
@@ -455,27 +547,198 @@ func (h *messageHandlerImpl) handleDeleteMessage(c *websocket.Conn, channelIDStr
 
 	if err := h.repo.DeleteMessage(deleteCtx, msgObjectID); err != nil {
 		if err == mongo.ErrNoDocuments {
-			logAndEmitErrorWS(c, "Message not found for deletion", nil)
+			logAndEmitErrorWS(frame, "Message not found for deletion", nil)
 		} else {
 			utils.LogError(err, "Failed to delete message from database")
-			logAndEmitErrorWS(c, "Failed to delete message", err)
+			logAndEmitErrorWS(frame, "Failed to delete message", err)
+		}
+		return
+	}
+
+	deletedPayload := map[string]string{"id": req.ID}
+	if existingMessage.ParentID != nil {
+		deletedPayload["parentId"] = existingMessage.ParentID.Hex()
+	}
+	frame.reply("message_deleted", deletedPayload)
+	h.broadcastToChannel(frame.ChannelID, "message_deleted", deletedPayload)
+
+	// Balasan thread dihapus: hitung ulang ringkasan thread pada pesan utama
+	if existingMessage.ParentID != nil {
+		parent, err := h.repo.RefreshThreadSummary(deleteCtx, *existingMessage.ParentID)
+		if err != nil {
+			utils.LogError(err, "Failed to refresh thread summary after reply deletion")
+			return
+		}
+		if parent != nil {
+			h.broadcastToChannel(frame.ChannelID, "thread_updated", convertMessageToDTO(parent))
+		}
+	}
+}
+
+func (h *messageHandlerImpl) handleCreateReply(frame *messageFrame) {
+	var req dto.MessageReplyCreateRequest
+	if err := json.Unmarshal(frame.Payload, &req); err != nil {
+		logAndEmitErrorWS(frame, "Invalid reply_message payload", err)
+		return
+	}
+
+	channelID, err := primitive.ObjectIDFromHex(frame.ChannelID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Invalid channel ID", err)
+		return
+	}
+
+	parentID, err := primitive.ObjectIDFromHex(req.ParentID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Invalid parent message ID", err)
+		return
+	}
+
+	// Ambil userID dari koneksi yang sudah diautentikasi, JANGAN PERCAYA PAYLOAD
+	userIDStr := frame.client.userID
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Invalid user ID dari token", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Pesan utama harus berada di channel yang sama dan bukan balasan (thread hanya satu tingkat)
+	parent, err := h.repo.GetMessageByID(ctx, parentID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Gagal memeriksa pesan utama thread", err)
+		return
+	}
+	if parent == nil || parent.ChannelID != channelID {
+		logAndEmitErrorWS(frame, "Parent message not found in this channel", nil)
+		return
+	}
+	if parent.ParentID != nil {
+		logAndEmitErrorWS(frame, "Cannot reply to a reply, reply to the root message instead", nil)
+		return
+	}
+
+	var mediaMetadata *model.MessageMediaMetadata
+	if req.MediaMetadata != nil {
+		mediaMetadata = &model.MessageMediaMetadata{
+			Filename: req.MediaMetadata.Filename,
+			Size:     req.MediaMetadata.Size,
+			Width:    req.MediaMetadata.Width,
+			Height:   req.MediaMetadata.Height,
+		}
+	}
+
+	reply := &model.Message{
+		ChannelID:     channelID,
+		UserID:        userID,
+		Content:       req.Content,
+		MessageType:   req.MessageType,
+		MediaPath:     req.MediaPath,
+		MediaMetadata: mediaMetadata,
+		ParentID:      &parentID,
+		Mentions:      h.resolveMentions(ctx, channelID, req.Content),
+	}
+
+	updatedParent, err := h.repo.CreateReply(ctx, reply)
+	if err != nil {
+		if errors.Is(err, repository.ErrParentMessageNotFound) {
+			logAndEmitErrorWS(frame, "Parent message not found in this channel", nil)
+			return
 		}
+		utils.LogError(err, "Failed to save reply to database")
+		logAndEmitErrorWS(frame, "Failed to create reply", err)
 		return
 	}
 
-	if err := c.WriteJSON(map[string]interface{}{"type": "message_deleted", "payload": map[string]string{"id": req.ID}}); err != nil {
-		log.Printf("Error emitting message_deleted: %v\n", err)
+	resp := dto.MessageReplyEventResponse{
+		Reply:  convertMessageToDTO(reply),
+		Parent: convertMessageToDTO(updatedParent),
 	}
-	h.broadcastToChannel(channelIDStr, "message_deleted", map[string]string{"id": req.ID})
+
+	// Emit success back to the sender
+	frame.reply("reply_created", resp)
+	// Broadcast balasan dan ringkasan thread terbaru ke semua client di channel
+	h.broadcastToChannel(frame.ChannelID, "new_reply", resp)
+	h.broadcastToChannel(frame.ChannelID, "thread_updated", resp.Parent)
+	h.stopTyping(typingKey{channelID: frame.ChannelID, userID: userIDStr, parentID: req.ParentID})
+	h.notifyMentions(reply)
 }
 
-func (h *messageHandlerImpl) handleAddReaction(c *websocket.Conn, channelIDStr string, payload json.RawMessage) {
+func (h *messageHandlerImpl) handleGetThread(frame *messageFrame) {
+	channelID, err := primitive.ObjectIDFromHex(frame.ChannelID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Invalid channel ID", err)
+		return
+	}
+
+	var req struct {
+		ParentID string `json:"parentId"`
+		Limit    int64  `json:"limit,omitempty"`
+		Skip     int64  `json:"skip,omitempty"`
+	}
+	if err := json.Unmarshal(frame.Payload, &req); err != nil {
+		logAndEmitErrorWS(frame, "Invalid get_thread payload", err)
+		return
+	}
+
+	parentID, err := primitive.ObjectIDFromHex(req.ParentID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Invalid parent message ID", err)
+		return
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+	if req.Skip < 0 {
+		req.Skip = 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	parent, err := h.repo.GetMessageByID(ctx, parentID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Failed to retrieve thread", err)
+		return
+	}
+	if parent == nil || parent.ChannelID != channelID || parent.ParentID != nil {
+		logAndEmitErrorWS(frame, "Parent message not found in this channel", nil)
+		return
+	}
+
+	// Ambil satu balasan lebih banyak untuk mengetahui apakah masih ada halaman berikutnya
+	replies, err := h.repo.GetThreadReplies(ctx, parentID, req.Limit+1, req.Skip)
+	if err != nil {
+		utils.LogError(err, "Failed to fetch thread replies")
+		logAndEmitErrorWS(frame, "Failed to retrieve thread", err)
+		return
+	}
+
+	resp := dto.MessageThreadResponse{
+		Parent:  convertMessageToDTO(parent),
+		Replies: []dto.MessageResponse{},
+	}
+	if int64(len(replies)) > req.Limit {
+		replies = replies[:req.Limit]
+		resp.HasMore = true
+	}
+	for _, reply := range replies {
+		resp.Replies = append(resp.Replies, convertMessageToDTO(&reply))
+	}
+	frame.reply("thread_history", resp)
+}
+
+func (h *messageHandlerImpl) handleAddReaction(frame *messageFrame) {
 	var reactionPayload struct {
 		MessageID string `json:"messageId"`
 		dto.MessageReactionAddRequest
 	}
-	if err := json.Unmarshal(payload, &reactionPayload); err != nil {
-		logAndEmitErrorWS(c, "Invalid add_reaction payload", err)
+	if err := json.Unmarshal(frame.Payload, &reactionPayload); err != nil {
+		logAndEmitErrorWS(frame, "Invalid add_reaction payload", err)
 		return
 	}
 	messageIDStr := reactionPayload.MessageID
@@ -483,57 +746,55 @@ func (h *messageHandlerImpl) handleAddReaction(c *websocket.Conn, channelIDStr s
 
 	msgObjectID, err := primitive.ObjectIDFromHex(messageIDStr)
 	if err != nil {
-		logAndEmitErrorWS(c, "Invalid message ID for reaction", err)
+		logAndEmitErrorWS(frame, "Invalid message ID for reaction", err)
 		return
 	}
 
-	userID, err := primitive.ObjectIDFromHex(req.UserID)
+	// Ambil userID dari koneksi terautentikasi, JANGAN PERCAYA PAYLOAD
+	userID, err := primitive.ObjectIDFromHex(frame.client.userID)
 	if err != nil {
-		logAndEmitErrorWS(c, "Invalid user ID for reaction", err)
+		logAndEmitErrorWS(frame, "Invalid user ID dari token", err)
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// Pesan harus berada di channel frame agar reaksi tidak dapat diberikan ke channel lain
+	existingMessage, err := h.repo.GetMessageByID(ctx, msgObjectID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Gagal memeriksa pesan untuk reaksi", err)
+		return
+	}
+	if existingMessage == nil || !messageInFrameChannel(frame, existingMessage) {
+		logAndEmitErrorWS(frame, "Message not found in this channel", nil)
+		return
+	}
+
 	updatedMessage, err := h.repo.AddMessageReaction(ctx, msgObjectID, userID, req.Emoji)
 	if err != nil {
 		utils.LogError(err, "Failed to add reaction to message")
-		logAndEmitErrorWS(c, "Failed to add reaction", err)
+		logAndEmitErrorWS(frame, "Failed to add reaction", err)
 		return
 	}
 
 	if updatedMessage == nil {
-		logAndEmitErrorWS(c, "Message not found to add reaction", nil)
+		logAndEmitErrorWS(frame, "Message not found to add reaction", nil)
 		return
 	}
 
-	resp := dto.MessageResponse{
-		ID:            updatedMessage.ID.Hex(),
-		ChannelID:     updatedMessage.ChannelID.Hex(),
-		UserID:        updatedMessage.UserID.Hex(),
-		Content:       updatedMessage.Content,
-		MessageType:   updatedMessage.MessageType,
-		MediaPath:     updatedMessage.MediaPath,
-		MediaMetadata: convertMediaMetadataToDTO(updatedMessage.MediaMetadata),
-		CreatedAt:     updatedMessage.CreatedAt,
-		UpdatedAt:     updatedMessage.UpdatedAt,
-		IsPinned:      updatedMessage.IsPinned,
-		Reactions:     convertMessageReactionsToDTO(updatedMessage.Reactions),
-	}
-	if err := c.WriteJSON(map[string]interface{}{"type": "reaction_added", "payload": resp}); err != nil {
-		log.Printf("Error emitting reaction_added: %v\n", err)
-	}
-	h.broadcastToChannel(channelIDStr, "reaction_added", resp)
+	resp := convertMessageToDTO(updatedMessage)
+	frame.reply("reaction_added", resp)
+	h.broadcastToChannel(frame.ChannelID, "reaction_added", resp)
 }
 
-func (h *messageHandlerImpl) handleRemoveReaction(c *websocket.Conn, channelIDStr string, payload json.RawMessage) {
+func (h *messageHandlerImpl) handleRemoveReaction(frame *messageFrame) {
 	var reactionPayload struct {
 		MessageID string `json:"messageId"`
 		dto.MessageReactionRemoveRequest
 	}
-	if err := json.Unmarshal(payload, &reactionPayload); err != nil {
-		logAndEmitErrorWS(c, "Invalid remove_reaction payload", err)
+	if err := json.Unmarshal(frame.Payload, &reactionPayload); err != nil {
+		logAndEmitErrorWS(frame, "Invalid remove_reaction payload", err)
 		return
 	}
 	messageIDStr := reactionPayload.MessageID
@@ -541,65 +802,300 @@ func (h *messageHandlerImpl) handleRemoveReaction(c *websocket.Conn, channelIDSt
 
 	msgObjectID, err := primitive.ObjectIDFromHex(messageIDStr)
 	if err != nil {
-		logAndEmitErrorWS(c, "Invalid message ID for reaction removal", err)
+		logAndEmitErrorWS(frame, "Invalid message ID for reaction removal", err)
 		return
 	}
 
-	userID, err := primitive.ObjectIDFromHex(req.UserID)
+	// Ambil userID dari koneksi terautentikasi, JANGAN PERCAYA PAYLOAD
+	userID, err := primitive.ObjectIDFromHex(frame.client.userID)
 	if err != nil {
-		logAndEmitErrorWS(c, "Invalid user ID for reaction removal", err)
+		logAndEmitErrorWS(frame, "Invalid user ID dari token", err)
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// Pesan harus berada di channel frame agar reaksi tidak dapat diberikan ke channel lain
+	existingMessage, err := h.repo.GetMessageByID(ctx, msgObjectID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Gagal memeriksa pesan untuk menghapus reaksi", err)
+		return
+	}
+	if existingMessage == nil || !messageInFrameChannel(frame, existingMessage) {
+		logAndEmitErrorWS(frame, "Message not found in this channel", nil)
+		return
+	}
+
 	updatedMessage, err := h.repo.RemoveMessageReaction(ctx, msgObjectID, userID, req.Emoji)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			logAndEmitErrorWS(c, "Message or reaction not found for removal", nil)
+			logAndEmitErrorWS(frame, "Message or reaction not found for removal", nil)
 		} else {
 			utils.LogError(err, "Failed to remove reaction from message")
-			logAndEmitErrorWS(c, "Failed to remove reaction", err)
+			logAndEmitErrorWS(frame, "Failed to remove reaction", err)
 		}
 		return
 	}
 
 	if updatedMessage == nil {
-		logAndEmitErrorWS(c, "Message not found to remove reaction", nil)
+		logAndEmitErrorWS(frame, "Message not found to remove reaction", nil)
 		return
 	}
 
-	resp := dto.MessageResponse{
-		ID:            updatedMessage.ID.Hex(),
-		ChannelID:     updatedMessage.ChannelID.Hex(),
-		UserID:        updatedMessage.UserID.Hex(),
-		Content:       updatedMessage.Content,
-		MessageType:   updatedMessage.MessageType,
-		MediaPath:     updatedMessage.MediaPath,
-		MediaMetadata: convertMediaMetadataToDTO(updatedMessage.MediaMetadata),
-		CreatedAt:     updatedMessage.CreatedAt,
-		UpdatedAt:     updatedMessage.UpdatedAt,
-		IsPinned:      updatedMessage.IsPinned,
-		Reactions:     convertMessageReactionsToDTO(updatedMessage.Reactions),
+	resp := convertMessageToDTO(updatedMessage)
+	frame.reply("reaction_removed", resp)
+	h.broadcastToChannel(frame.ChannelID, "reaction_removed", resp)
+}
+
+// messageInFrameChannel memeriksa apakah pesan berada di channel tujuan frame. Broadcast dan
+// pengecekan akses channel memakai frame.ChannelID, sehingga pesan dari channel lain harus ditolak.
+func messageInFrameChannel(frame *messageFrame, message *model.Message) bool {
+	return message.ChannelID.Hex() == frame.ChannelID
+}
+
+func (h *messageHandlerImpl) handleMarkRead(frame *messageFrame) {
+	channelID, err := primitive.ObjectIDFromHex(frame.ChannelID)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Invalid channel ID", err)
+		return
+	}
+
+	// messageId opsional; jika kosong posisi baca dimajukan ke pesan terbaru
+	var req struct {
+		MessageID string `json:"messageId,omitempty"`
+	}
+	if len(frame.Payload) > 0 {
+		if err := json.Unmarshal(frame.Payload, &req); err != nil {
+			logAndEmitErrorWS(frame, "Invalid mark_read payload", err)
+			return
+		}
 	}
-	if err := c.WriteJSON(map[string]interface{}{"type": "reaction_removed", "payload": resp}); err != nil {
-		log.Printf("Error emitting reaction_removed: %v\n", err)
+	var messageID *primitive.ObjectID
+	if req.MessageID != "" {
+		id, err := primitive.ObjectIDFromHex(req.MessageID)
+		if err != nil {
+			logAndEmitErrorWS(frame, "Invalid message ID for mark_read", err)
+			return
+		}
+		messageID = &id
+	}
+
+	userIDStr := frame.client.userID
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		logAndEmitErrorWS(frame, "Invalid user ID dari token", err)
+		return
 	}
-	h.broadcastToChannel(channelIDStr, "reaction_removed", resp)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	unread, err := h.readStates.MarkRead(ctx, userID, channelID, messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrChannelNotFound):
+			logAndEmitErrorWS(frame, "Channel not found", nil)
+		case errors.Is(err, service.ErrMessageNotInChannel):
+			logAndEmitErrorWS(frame, "Message not found in this channel or is a thread reply", nil)
+		default:
+			utils.LogError(err, "Failed to update read state")
+			logAndEmitErrorWS(frame, "Failed to mark channel as read", err)
+		}
+		return
+	}
+
+	resp := convertChannelUnreadToDTO(unread)
+	frame.reply("read_state_updated", resp)
+	// Sesi lain milik user (tab atau perangkat lain) memperbarui badge lewat WebSocket notifikasi
+	h.notificationHub.publish(userIDStr, "channel_read", resp)
+
+	if unread.LastReadMessageID != nil {
+		h.broadcastToChannel(frame.ChannelID, "read_receipt", map[string]interface{}{
+			"userId":            userIDStr,
+			"channelId":         frame.ChannelID,
+			"lastReadMessageId": resp.LastReadMessageID,
+			"lastReadAt":        resp.LastReadAt,
+		})
+	}
+}
+
+func (h *messageHandlerImpl) handleTyping(frame *messageFrame, typing bool) {
+	// parentId opsional; diisi jika user mengetik balasan di thread
+	var req struct {
+		ParentID string `json:"parentId,omitempty"`
+	}
+	if len(frame.Payload) > 0 {
+		if err := json.Unmarshal(frame.Payload, &req); err != nil {
+			logAndEmitErrorWS(frame, "Invalid typing payload", err)
+			return
+		}
+	}
+	if req.ParentID != "" {
+		if _, err := primitive.ObjectIDFromHex(req.ParentID); err != nil {
+			logAndEmitErrorWS(frame, "Invalid parent message ID", err)
+			return
+		}
+	}
+
+	userIDStr := frame.client.userID
+
+	key := typingKey{channelID: frame.ChannelID, userID: userIDStr, parentID: req.ParentID}
+	if typing {
+		h.startTyping(key)
+	} else {
+		h.stopTyping(key)
+	}
+}
+
+// typingKey mengidentifikasi indikator mengetik satu user di channel (atau thread) tertentu.
+type typingKey struct {
+	channelID string
+	userID    string
+	parentID  string
 }
 
-// Helper function to log and emit errors over WebSocket
-func logAndEmitErrorWS(conn *websocket.Conn, message string, err error) {
-	logMsg := message
+// typingState menyimpan timer kedaluwarsa indikator mengetik.
+type typingState struct {
+	timer *time.Timer
+}
+
+// typingIndicatorTTL adalah masa berlaku typing_start. Client mengirim ulang typing_start selama user
+// masih mengetik; jika tidak, server mengirim typing_stopped dengan reason "expired".
+const typingIndicatorTTL = 6 * time.Second
+
+// startTyping menyiarkan typing_started dan memperpanjang masa berlaku indikator.
+func (h *messageHandlerImpl) startTyping(key typingKey) {
+	state := &typingState{}
+	h.typingMu.Lock()
+	if previous, ok := h.typing[key]; ok {
+		previous.timer.Stop()
+	}
+	h.typing[key] = state
+	state.timer = time.AfterFunc(typingIndicatorTTL, func() {
+		h.typingMu.Lock()
+		// Timer lama yang sudah diganti typing_start berikutnya diabaikan
+		if h.typing[key] != state {
+			h.typingMu.Unlock()
+			return
+		}
+		delete(h.typing, key)
+		h.typingMu.Unlock()
+		h.broadcastToChannel(key.channelID, "typing_stopped", typingResponse(key, nil, "expired"))
+	})
+	h.typingMu.Unlock()
+
+	expiresAt := time.Now().Add(typingIndicatorTTL)
+	h.broadcastToChannel(key.channelID, "typing_started", typingResponse(key, &expiresAt, ""))
+}
+
+// stopTyping menghapus indikator dan menyiarkan typing_stopped jika indikator masih aktif.
+func (h *messageHandlerImpl) stopTyping(key typingKey) {
+	h.typingMu.Lock()
+	state, ok := h.typing[key]
+	if ok {
+		state.timer.Stop()
+		delete(h.typing, key)
+	}
+	h.typingMu.Unlock()
+
+	if ok {
+		h.broadcastToChannel(key.channelID, "typing_stopped", typingResponse(key, nil, ""))
+	}
+}
+
+// stopAllTyping menghentikan semua indikator user di channel, termasuk di thread, saat koneksi ditutup.
+func (h *messageHandlerImpl) stopAllTyping(channelID, userID string) {
+	var keys []typingKey
+	h.typingMu.Lock()
+	for key := range h.typing {
+		if key.channelID == channelID && key.userID == userID {
+			keys = append(keys, key)
+		}
+	}
+	h.typingMu.Unlock()
+
+	for _, key := range keys {
+		h.stopTyping(key)
+	}
+}
+
+func typingResponse(key typingKey, expiresAt *time.Time, reason string) dto.TypingResponse {
+	return dto.TypingResponse{
+		UserID:    key.userID,
+		ChannelID: key.channelID,
+		ParentID:  key.parentID,
+		ExpiresAt: expiresAt,
+		Reason:    reason,
+	}
+}
+
+// resolveMentions mengubah @user, @role dan @channel pada isi pesan menjadi ID. Kegagalan hanya dicatat
+// agar pesan tetap terkirim tanpa mention.
+func (h *messageHandlerImpl) resolveMentions(ctx context.Context, channelID primitive.ObjectID, content string) *model.MessageMentions {
+	if h.notifications == nil || content == "" {
+		return nil
+	}
+	mentions, err := h.notifications.ResolveMentions(ctx, channelID, content)
 	if err != nil {
-		logMsg = fmt.Sprintf("%s: %v", message, err)
-		utils.LogError(err, message)
+		utils.LogError(err, "Gagal memproses mention pada pesan di channel %s", channelID.Hex())
+		return nil
 	}
-	log.Println(logMsg)
-	if err := conn.WriteJSON(map[string]interface{}{"type": "error", "payload": logMsg}); err != nil {
-		log.Printf("Error emitting error message over WS: %v\n", err)
+	return mentions
+}
+
+// notifyMentions membuat notifikasi untuk mention pada pesan dan mengirimkannya ke penerima yang terhubung.
+// Dijalankan di goroutine karena @channel dapat menjangkau seluruh anggota bisnis.
+func (h *messageHandlerImpl) notifyMentions(message *model.Message) {
+	if h.notifications == nil || message.Mentions == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		notifications, err := h.notifications.NotifyMentions(ctx, message)
+		if err != nil {
+			utils.LogError(err, "Gagal membuat notifikasi mention untuk pesan %s", message.ID.Hex())
+			return
+		}
+		h.notificationHub.PublishNotifications(notifications)
+	}()
+}
+
+// convertMessageToDTO converts a model.Message into the response sent over WebSocket
+func convertMessageToDTO(msg *model.Message) dto.MessageResponse {
+	resp := dto.MessageResponse{
+		ID:            msg.ID.Hex(),
+		ChannelID:     msg.ChannelID.Hex(),
+		UserID:        msg.UserID.Hex(),
+		Content:       msg.Content,
+		MessageType:   msg.MessageType,
+		MediaPath:     msg.MediaPath,
+		MediaMetadata: convertMediaMetadataToDTO(msg.MediaMetadata),
+		CreatedAt:     msg.CreatedAt,
+		UpdatedAt:     msg.UpdatedAt,
+		IsPinned:      msg.IsPinned,
+		Reactions:     convertMessageReactionsToDTO(msg.Reactions),
+		ReplyCount:    msg.ReplyCount,
+		LastReplyAt:   msg.LastReplyAt,
+	}
+	if msg.ParentID != nil {
+		resp.ParentID = msg.ParentID.Hex()
+	}
+	if msg.Mentions != nil {
+		resp.Mentions = &dto.MessageMentionsResponse{
+			UserIDs: make([]string, len(msg.Mentions.UserIDs)),
+			RoleIDs: make([]string, len(msg.Mentions.RoleIDs)),
+			Channel: msg.Mentions.Channel,
+		}
+		for i, id := range msg.Mentions.UserIDs {
+			resp.Mentions.UserIDs[i] = id.Hex()
+		}
+		for i, id := range msg.Mentions.RoleIDs {
+			resp.Mentions.RoleIDs[i] = id.Hex()
+		}
 	}
+	return resp
 }
 
 // convertMediaMetadataToDTO remains the same
@@ -0,0 +1,392 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// notificationWriteTimeout membatasi waktu tulis ke satu client agar client lambat tidak menahan pengiriman.
+const notificationWriteTimeout = 5 * time.Second
+
+// NotificationHub menyimpan koneksi WebSocket per user dan mengirim notifikasi baru secara live.
+// Satu user dapat memiliki beberapa koneksi (misalnya beberapa tab atau perangkat).
+type NotificationHub struct {
+	// Koneksi aktif per user ID, mengikuti pola registry di DatabaseRealtimeHub
+	activeConnections map[string]map[*websocket.Conn]bool
+	mu                sync.Mutex
+}
+
+// NewNotificationHub membuat hub notifikasi baru.
+func NewNotificationHub() *NotificationHub {
+	return &NotificationHub{activeConnections: make(map[string]map[*websocket.Conn]bool)}
+}
+
+// PublishNotifications mengirim event notification_created ke setiap penerima yang sedang terhubung.
+// Hub nil diabaikan agar handler tetap dapat dipakai tanpa notifikasi live.
+func (hub *NotificationHub) PublishNotifications(notifications []model.Notification) {
+	if hub == nil {
+		return
+	}
+	for i := range notifications {
+		hub.publish(notifications[i].UserID.Hex(), "notification_created", convertNotificationToDTO(&notifications[i]))
+	}
+}
+
+// publish mengirim satu event ke semua koneksi milik user.
+func (hub *NotificationHub) publish(userID, eventType string, payload interface{}) {
+	if hub == nil {
+		return
+	}
+	jsonMsg, err := json.Marshal(map[string]interface{}{"type": eventType, "payload": payload})
+	if err != nil {
+		log.Printf("Error marshalling notification event: %v\n", err)
+		return
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for conn := range hub.activeConnections[userID] {
+		if err := hub.write(conn, jsonMsg); err != nil {
+			log.Printf("Error writing to notification websocket for user %s: %v\n", userID, err)
+			// Koneksi rusak dilepas dan ditutup agar loop baca di handler-nya berhenti
+			hub.removeLocked(userID, conn)
+			conn.Close()
+		}
+	}
+}
+
+// join mendaftarkan koneksi milik user.
+func (hub *NotificationHub) join(userID string, conn *websocket.Conn) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if hub.activeConnections[userID] == nil {
+		hub.activeConnections[userID] = make(map[*websocket.Conn]bool)
+	}
+	hub.activeConnections[userID][conn] = true
+}
+
+// leave melepas koneksi dari registry user.
+func (hub *NotificationHub) leave(userID string, conn *websocket.Conn) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.removeLocked(userID, conn)
+}
+
+// send menulis satu pesan ke koneksi tanpa bertabrakan dengan publish.
+func (hub *NotificationHub) send(conn *websocket.Conn, data interface{}) {
+	jsonMsg, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshalling notification websocket message: %v\n", err)
+		return
+	}
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if err := hub.write(conn, jsonMsg); err != nil {
+		log.Printf("Error writing notification websocket message: %v\n", err)
+	}
+}
+
+// sendToConnections menulis pesan yang sama ke beberapa koneksi notifikasi, misalnya subscriber presence.
+func (hub *NotificationHub) sendToConnections(conns []*websocket.Conn, data interface{}) {
+	if len(conns) == 0 {
+		return
+	}
+	jsonMsg, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshalling notification websocket message: %v\n", err)
+		return
+	}
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for _, conn := range conns {
+		if err := hub.write(conn, jsonMsg); err != nil {
+			log.Printf("Error writing notification websocket message: %v\n", err)
+		}
+	}
+}
+
+func (hub *NotificationHub) removeLocked(userID string, conn *websocket.Conn) {
+	delete(hub.activeConnections[userID], conn)
+	if len(hub.activeConnections[userID]) == 0 {
+		delete(hub.activeConnections, userID)
+	}
+}
+
+func (hub *NotificationHub) write(conn *websocket.Conn, jsonMsg []byte) error {
+	conn.SetWriteDeadline(time.Now().Add(notificationWriteTimeout))
+	return conn.WriteMessage(websocket.TextMessage, jsonMsg)
+}
+
+// NotificationHandler mendefinisikan interface untuk handler notifikasi in-app.
+type NotificationHandler interface {
+	GetNotifications(c *fiber.Ctx) error
+	MarkNotificationRead(c *fiber.Ctx) error
+	MarkAllNotificationsRead(c *fiber.Ctx) error
+	HandleNotificationWebSocket(c *websocket.Conn)
+}
+
+type notificationHandlerImpl struct {
+	repo     repository.NotificationRepository
+	hub      *NotificationHub
+	presence *PresenceHub
+}
+
+// NewNotificationHandler membuat instance baru dari NotificationHandler.
+func NewNotificationHandler(repo repository.NotificationRepository, hub *NotificationHub, presence *PresenceHub) NotificationHandler {
+	return &notificationHandlerImpl{repo: repo, hub: hub, presence: presence}
+}
+
+// GetNotifications lists the notifications of the authenticated user.
+// @Summary List notifications
+// @Description Get the notifications of the authenticated user, newest first, together with the number of unread notifications.
+// @Tags Notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param unreadOnly query bool false "Only return unread notifications"
+// @Param limit query int false "Maximum number of notifications (default 50, max 200)"
+// @Param skip query int false "Number of notifications to skip"
+// @Success 200 {object} utils.APIResponse{data=dto.NotificationListResponse} "Notifications retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid query parameter"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /notifications [get]
+func (h *notificationHandlerImpl) GetNotifications(c *fiber.Ctx) error {
+	userID, err := notificationUserID(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	limit, err := parseOptionalIntQuery(c, "limit")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid limit", err.Error())
+	}
+	skip, err := parseOptionalIntQuery(c, "skip")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid skip", err.Error())
+	}
+	if limit == 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	notifications, err := h.repo.GetNotificationsByUserID(ctx, userID, c.QueryBool("unreadOnly"), int64(limit), int64(skip))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get notifications", err.Error())
+	}
+	unreadCount, err := h.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to count unread notifications", err.Error())
+	}
+
+	resp := dto.NotificationListResponse{
+		Notifications: make([]dto.NotificationResponse, len(notifications)),
+		UnreadCount:   unreadCount,
+	}
+	for i := range notifications {
+		resp.Notifications[i] = convertNotificationToDTO(&notifications[i])
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Notifications retrieved successfully", resp)
+}
+
+// MarkNotificationRead marks a single notification as read.
+// @Summary Mark notification as read
+// @Description Mark one notification of the authenticated user as read. Other open notification sockets of the user receive a notifications_read event.
+// @Tags Notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} utils.APIResponse{data=dto.NotificationResponse} "Notification marked as read"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Notification not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /notifications/{id}/read [patch]
+func (h *notificationHandlerImpl) MarkNotificationRead(c *fiber.Ctx) error {
+	notificationID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid notification ID format", err.Error())
+	}
+	userID, err := notificationUserID(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	notification, err := h.repo.MarkAsRead(ctx, userID, notificationID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to mark notification as read", err.Error())
+	}
+	if notification == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Notification not found", nil)
+	}
+
+	h.publishReadState(ctx, userID, []string{notificationID.Hex()})
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Notification marked as read", convertNotificationToDTO(notification))
+}
+
+// MarkAllNotificationsRead marks every unread notification of the user as read.
+// @Summary Mark all notifications as read
+// @Description Mark all unread notifications of the authenticated user as read.
+// @Tags Notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.APIResponse{data=dto.NotificationReadAllResponse} "All notifications marked as read"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /notifications/read-all [patch]
+func (h *notificationHandlerImpl) MarkAllNotificationsRead(c *fiber.Ctx) error {
+	userID, err := notificationUserID(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	updated, err := h.repo.MarkAllAsRead(ctx, userID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to mark notifications as read", err.Error())
+	}
+
+	unreadCount := h.publishReadState(ctx, userID, nil)
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "All notifications marked as read", dto.NotificationReadAllResponse{
+		Updated:     updated,
+		UnreadCount: unreadCount,
+	})
+}
+
+// HandleNotificationWebSocket menangani koneksi /ws/notifications. Setelah terhubung, client menerima
+// notifications_joined berisi jumlah notifikasi belum dibaca, lalu notification_created untuk setiap
+// notifikasi baru dan notifications_read saat notifikasi ditandai dibaca dari sesi lain. Client juga
+// dapat mengirim subscribe_presence / unsubscribe_presence ({"businessId": "..."}) untuk menerima
+// presence_snapshot lalu presence_changed anggota bisnis tersebut.
+func (h *notificationHandlerImpl) HandleNotificationWebSocket(c *websocket.Conn) {
+	userIDStr, _ := c.Locals("userID").(string)
+	defer func() {
+		h.presence.unsubscribe("", c)
+		h.hub.leave(userIDStr, c)
+		log.Printf("Client disconnected from notifications of user %s: %s\n", userIDStr, c.LocalAddr().String())
+		c.Close()
+	}()
+
+	// Periksa hasil autentikasi dari middleware
+	authFailed, ok := c.Locals("authFailed").(bool)
+	if ok && authFailed {
+		authError, _ := c.Locals("authError").(string)
+		if authError == "" {
+			authError = "Autentikasi gagal"
+		}
+		log.Printf("WebSocket authentication failed for notifications: %s\n", authError)
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": authError})
+		return
+	}
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": "User ID tidak valid di koneksi terautentikasi"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	unreadCount, err := h.repo.CountUnread(ctx, userID)
+	cancel()
+	if err != nil {
+		c.WriteJSON(map[string]interface{}{"type": "error", "payload": "Gagal menghitung notifikasi belum dibaca"})
+		return
+	}
+
+	h.hub.join(userIDStr, c)
+	h.hub.send(c, map[string]interface{}{"type": "notifications_joined", "payload": map[string]interface{}{"unreadCount": unreadCount}})
+	log.Printf("Client connected to notifications of user %s: %s\n", userIDStr, c.LocalAddr().String())
+
+	for {
+		mt, msg, err := c.ReadMessage()
+		if err != nil {
+			log.Println("read error:", err)
+			break
+		}
+		if mt != websocket.TextMessage {
+			continue
+		}
+
+		var wsMessage struct {
+			Type    string `json:"type"`
+			Payload struct {
+				BusinessID string `json:"businessId"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(msg, &wsMessage); err != nil {
+			h.hub.send(c, map[string]interface{}{"type": "error", "payload": "Invalid message format"})
+			continue
+		}
+
+		switch wsMessage.Type {
+		case "subscribe_presence", "unsubscribe_presence":
+			if h.presence == nil {
+				h.hub.send(c, map[string]interface{}{"type": "error", "payload": "Presence is not available"})
+				continue
+			}
+			h.presence.handlePresenceSubscription(c, userID, wsMessage.Type == "subscribe_presence", wsMessage.Payload.BusinessID)
+		default:
+			h.hub.send(c, map[string]interface{}{"type": "error", "payload": "Unknown message type"})
+		}
+	}
+}
+
+// publishReadState mengirim notifications_read ke semua koneksi user. ids kosong berarti semua notifikasi
+// ditandai dibaca. Mengembalikan jumlah notifikasi yang masih belum dibaca.
+func (h *notificationHandlerImpl) publishReadState(ctx context.Context, userID primitive.ObjectID, ids []string) int64 {
+	unreadCount, err := h.repo.CountUnread(ctx, userID)
+	if err != nil {
+		utils.LogError(err, "Gagal menghitung notifikasi belum dibaca setelah ditandai dibaca")
+		return 0
+	}
+	h.hub.publish(userID.Hex(), "notifications_read", map[string]interface{}{"ids": ids, "unreadCount": unreadCount})
+	return unreadCount
+}
+
+// notificationUserID mengambil ID user terautentikasi dari locals.
+func notificationUserID(c *fiber.Ctx) (primitive.ObjectID, error) {
+	userIDStr, _ := c.Locals("userID").(string)
+	return primitive.ObjectIDFromHex(userIDStr)
+}
+
+// convertNotificationToDTO mengonversi model.Notification menjadi dto.NotificationResponse.
+func convertNotificationToDTO(notification *model.Notification) dto.NotificationResponse {
+	resp := dto.NotificationResponse{
+		ID:          notification.ID.Hex(),
+		UserID:      notification.UserID.Hex(),
+		ActorID:     notification.ActorID.Hex(),
+		Type:        notification.Type,
+		MentionKind: notification.MentionKind,
+		BusinessID:  notification.BusinessID.Hex(),
+		ChannelID:   notification.ChannelID.Hex(),
+		MessageID:   notification.MessageID.Hex(),
+		Preview:     notification.Preview,
+		IsRead:      notification.IsRead,
+		ReadAt:      notification.ReadAt,
+		CreatedAt:   notification.CreatedAt,
+	}
+	if notification.ParentID != nil {
+		resp.ParentID = notification.ParentID.Hex()
+	}
+	return resp
+}
@@ -0,0 +1,449 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// presenceIdleAfter adalah lama tanpa aktivitas di WebSocket pesan sebelum user dianggap idle.
+	presenceIdleAfter = 5 * time.Minute
+	// presenceCheckInterval adalah interval pemeriksaan user yang sudah melewati presenceIdleAfter.
+	presenceCheckInterval = 30 * time.Second
+)
+
+// presenceEntry menyimpan presence satu user yang sedang memiliki koneksi WebSocket pesan.
+type presenceEntry struct {
+	connections  int
+	status       string
+	since        time.Time
+	lastActivity time.Time
+	businessIDs  []string // Bisnis yang subscriber-nya menerima perubahan presence user ini
+}
+
+// PresenceHub melacak presence online, idle dan offline dari siklus koneksi WebSocket pesan dan
+// mengirim presence_changed ke koneksi notifikasi yang berlangganan presence sebuah bisnis.
+// Seperti hub lain, presence hanya berlaku untuk satu proses server.
+type PresenceHub struct {
+	userRepo        repository.UserRepository
+	businessRepo    repository.BusinessRepository
+	channels        service.ChannelAccessService
+	notificationHub *NotificationHub // Pengiriman ke subscriber memakai lock hub notifikasi
+
+	users map[string]*presenceEntry
+	// Koneksi notifikasi yang berlangganan presence per business ID
+	subscribers map[string]map[*websocket.Conn]bool
+	// Status terbaru per user yang belum disimpan ke User.Status, ditulis berurutan oleh writeStatuses
+	pendingStatus map[string]string
+	statusSignal  chan struct{}
+	mu            sync.Mutex
+}
+
+// NewPresenceHub membuat hub presence dan menjalankan pemeriksaan idle serta penyimpanan status di background.
+// Status presence yang tersimpan dari proses sebelumnya direset ke offline lebih dulu karena koneksi
+// WebSocket-nya sudah tidak ada.
+func NewPresenceHub(userRepo repository.UserRepository, businessRepo repository.BusinessRepository, channels service.ChannelAccessService, notificationHub *NotificationHub) *PresenceHub {
+	resetCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if reset, err := userRepo.ResetPresenceStatuses(resetCtx); err != nil {
+		utils.LogError(err, "Gagal mereset status presence user")
+	} else if reset > 0 {
+		utils.LogInfo("Status presence direset ke offline. Total user: %d", reset)
+	}
+
+	hub := &PresenceHub{
+		userRepo:        userRepo,
+		businessRepo:    businessRepo,
+		channels:        channels,
+		notificationHub: notificationHub,
+		users:           make(map[string]*presenceEntry),
+		subscribers:     make(map[string]map[*websocket.Conn]bool),
+		pendingStatus:   make(map[string]string),
+		statusSignal:    make(chan struct{}, 1),
+	}
+	go hub.watchIdle()
+	go hub.writeStatuses()
+	return hub
+}
+
+// Connect mencatat koneksi WebSocket pesan baru milik user. Koneksi pertama membuat user online.
+func (hub *PresenceHub) Connect(userID string) {
+	if hub == nil {
+		return
+	}
+	// Daftar bisnis dimuat di luar lock karena membutuhkan query database
+	businessIDs := hub.loadBusinessIDs(userID)
+
+	hub.mu.Lock()
+	entry, ok := hub.users[userID]
+	if !ok {
+		entry = &presenceEntry{}
+		hub.users[userID] = entry
+	}
+	entry.connections++
+	entry.lastActivity = time.Now()
+	entry.businessIDs = businessIDs
+	changed := hub.setStatusLocked(userID, entry, model.UserStatusOnline)
+	hub.mu.Unlock()
+
+	hub.publish(changed)
+}
+
+// Disconnect mencatat koneksi yang ditutup. Setelah koneksi terakhir ditutup user menjadi offline.
+func (hub *PresenceHub) Disconnect(userID string) {
+	if hub == nil {
+		return
+	}
+	hub.mu.Lock()
+	entry, ok := hub.users[userID]
+	if !ok {
+		hub.mu.Unlock()
+		return
+	}
+	entry.connections--
+	var changed *presenceChange
+	if entry.connections <= 0 {
+		changed = hub.setStatusLocked(userID, entry, model.UserStatusOffline)
+		delete(hub.users, userID)
+	}
+	hub.mu.Unlock()
+
+	hub.publish(changed)
+}
+
+// Touch mencatat aktivitas user; user yang idle kembali online.
+func (hub *PresenceHub) Touch(userID string) {
+	if hub == nil {
+		return
+	}
+	hub.mu.Lock()
+	entry, ok := hub.users[userID]
+	if !ok {
+		hub.mu.Unlock()
+		return
+	}
+	entry.lastActivity = time.Now()
+	changed := hub.setStatusLocked(userID, entry, model.UserStatusOnline)
+	hub.mu.Unlock()
+
+	hub.publish(changed)
+}
+
+// BusinessPresence mengembalikan presence semua anggota bisnis (pemilik dan user dengan bisnis di
+// BusinessIDs). requesterID harus anggota bisnis; jika tidak, service.ErrNotBusinessMember dikembalikan.
+func (hub *PresenceHub) BusinessPresence(ctx context.Context, businessID, requesterID primitive.ObjectID) ([]dto.PresenceResponse, error) {
+	member, err := hub.channels.IsBusinessMember(ctx, businessID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("%w: %s", service.ErrNotBusinessMember, businessID.Hex())
+	}
+
+	members, err := hub.userRepo.FindUsersByBusinessID(ctx, businessID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	business, err := hub.businessRepo.GetBusinessByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business != nil {
+		ownerListed := false
+		for _, user := range members {
+			if user.ID.Hex() == business.OwnerID {
+				ownerListed = true
+				break
+			}
+		}
+		if ownerID, err := primitive.ObjectIDFromHex(business.OwnerID); err == nil && !ownerListed {
+			owner, err := hub.userRepo.FindUserByID(ctx, ownerID)
+			if err != nil {
+				return nil, err
+			}
+			if owner != nil {
+				members = append(members, *owner)
+			}
+		}
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	resp := make([]dto.PresenceResponse, len(members))
+	for i, user := range members {
+		resp[i] = dto.PresenceResponse{
+			UserID:   user.ID.Hex(),
+			Username: user.Username,
+			Avatar:   user.Avatar,
+			Status:   model.UserStatusOffline,
+		}
+		if entry, ok := hub.users[user.ID.Hex()]; ok {
+			since := entry.since
+			resp[i].Status = entry.status
+			resp[i].Since = &since
+		}
+	}
+	return resp, nil
+}
+
+// subscribe mendaftarkan koneksi notifikasi sebagai subscriber presence bisnis.
+func (hub *PresenceHub) subscribe(businessID string, conn *websocket.Conn) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if hub.subscribers[businessID] == nil {
+		hub.subscribers[businessID] = make(map[*websocket.Conn]bool)
+	}
+	hub.subscribers[businessID][conn] = true
+}
+
+// unsubscribe melepas langganan presence satu bisnis. businessID kosong melepas semua langganan koneksi.
+func (hub *PresenceHub) unsubscribe(businessID string, conn *websocket.Conn) {
+	if hub == nil {
+		return
+	}
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for id, conns := range hub.subscribers {
+		if businessID != "" && id != businessID {
+			continue
+		}
+		delete(conns, conn)
+		if len(conns) == 0 {
+			delete(hub.subscribers, id)
+		}
+	}
+}
+
+// presenceChange adalah perubahan status yang perlu dikirim ke subscriber dan disimpan ke User.Status.
+type presenceChange struct {
+	presence    dto.PresenceResponse
+	subscribers []*websocket.Conn
+}
+
+// setStatusLocked mengubah status entry dan mengantrekan penyimpanannya. Mengembalikan nil jika status tidak berubah.
+func (hub *PresenceHub) setStatusLocked(userID string, entry *presenceEntry, status string) *presenceChange {
+	if entry.status == status {
+		return nil
+	}
+	entry.status = status
+	entry.since = time.Now()
+	hub.pendingStatus[userID] = status
+
+	since := entry.since
+	change := &presenceChange{presence: dto.PresenceResponse{UserID: userID, Status: status, Since: &since}}
+	seen := make(map[*websocket.Conn]bool)
+	for _, businessID := range entry.businessIDs {
+		for conn := range hub.subscribers[businessID] {
+			if !seen[conn] {
+				seen[conn] = true
+				change.subscribers = append(change.subscribers, conn)
+			}
+		}
+	}
+	return change
+}
+
+// publish mengirim presence_changed ke subscriber dan membangunkan writeStatuses untuk menyimpan
+// status yang sudah diantrekan oleh setStatusLocked. Dipanggil di luar hub.mu.
+func (hub *PresenceHub) publish(change *presenceChange) {
+	if change == nil {
+		return
+	}
+	hub.notificationHub.sendToConnections(change.subscribers, map[string]interface{}{"type": "presence_changed", "payload": change.presence})
+
+	select {
+	case hub.statusSignal <- struct{}{}:
+	default: // writeStatuses sudah dibangunkan dan akan mengambil status ini
+	}
+}
+
+// writeStatuses menyimpan status presence ke User.Status dari satu goroutine. Hanya status terbaru per user
+// yang disimpan, sehingga status lama tidak pernah menimpa status yang lebih baru.
+func (hub *PresenceHub) writeStatuses() {
+	for range hub.statusSignal {
+		hub.mu.Lock()
+		pending := hub.pendingStatus
+		hub.pendingStatus = make(map[string]string)
+		hub.mu.Unlock()
+
+		for userIDStr, status := range pending {
+			userID, err := primitive.ObjectIDFromHex(userIDStr)
+			if err != nil {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if _, err := hub.userRepo.UpdateUser(ctx, userID, bson.M{"status": status}); err != nil {
+				utils.LogError(err, "Gagal menyimpan status presence user %s", userIDStr)
+			}
+			cancel()
+		}
+	}
+}
+
+// watchIdle menandai user online sebagai idle jika tidak ada aktivitas selama presenceIdleAfter.
+func (hub *PresenceHub) watchIdle() {
+	ticker := time.NewTicker(presenceCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		var changes []*presenceChange
+		hub.mu.Lock()
+		for userID, entry := range hub.users {
+			if entry.status == model.UserStatusOnline && time.Since(entry.lastActivity) >= presenceIdleAfter {
+				changes = append(changes, hub.setStatusLocked(userID, entry, model.UserStatusIdle))
+			}
+		}
+		hub.mu.Unlock()
+
+		for _, change := range changes {
+			hub.publish(change)
+		}
+	}
+}
+
+// loadBusinessIDs mengambil bisnis yang diikuti atau dimiliki user. Kegagalan hanya dicatat; user tetap
+// dilacak tetapi perubahannya tidak dikirim ke subscriber.
+func (hub *PresenceHub) loadBusinessIDs(userIDStr string) []string {
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := hub.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil bisnis user %s untuk presence", userIDStr)
+		return nil
+	}
+	var businessIDs []string
+	seen := make(map[string]bool)
+	if user != nil {
+		for _, id := range user.BusinessIDs {
+			if !seen[id] {
+				seen[id] = true
+				businessIDs = append(businessIDs, id)
+			}
+		}
+	}
+	owned, err := hub.businessRepo.GetBusinessesByOwnerID(ctx, userIDStr)
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil bisnis milik user %s untuk presence", userIDStr)
+		return businessIDs
+	}
+	for _, business := range owned {
+		if id := business.ID.Hex(); !seen[id] {
+			seen[id] = true
+			businessIDs = append(businessIDs, id)
+		}
+	}
+	return businessIDs
+}
+
+// PresenceHandler menangani query presence anggota bisnis.
+type PresenceHandler interface {
+	GetBusinessPresence(c *fiber.Ctx) error
+}
+
+type presenceHandlerImpl struct {
+	hub *PresenceHub
+}
+
+// NewPresenceHandler membuat instance baru dari PresenceHandler.
+func NewPresenceHandler(hub *PresenceHub) PresenceHandler {
+	return &presenceHandlerImpl{hub: hub}
+}
+
+// GetBusinessPresence returns the presence of every member of a business.
+// @Summary Get presence of business members
+// @Description Get the online, idle or offline status of every member of a business. Presence is derived from the messaging WebSocket connections; subscribe to changes with a "subscribe_presence" message on /ws/notifications.
+// @Tags Businesses
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Business ID"
+// @Success 200 {object} utils.APIResponse{data=dto.BusinessPresenceResponse} "Presence retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Business not found or user is not a member"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /businesses/{id}/presence [get]
+func (h *presenceHandlerImpl) GetBusinessPresence(c *fiber.Ctx) error {
+	businessID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid business ID format", err.Error())
+	}
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user ID in token", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	members, err := h.hub.BusinessPresence(ctx, businessID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotBusinessMember) {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Business not found", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get presence", err.Error())
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Presence retrieved successfully", dto.BusinessPresenceResponse{
+		BusinessID: businessID.Hex(),
+		Members:    members,
+	})
+}
+
+// handlePresenceSubscription memproses subscribe_presence dan unsubscribe_presence dari koneksi notifikasi.
+// Subscribe mengirim presence_snapshot berisi presence semua anggota bisnis saat itu.
+func (hub *PresenceHub) handlePresenceSubscription(conn *websocket.Conn, userID primitive.ObjectID, subscribe bool, businessIDStr string) {
+	businessID, err := primitive.ObjectIDFromHex(businessIDStr)
+	if err != nil {
+		hub.notificationHub.send(conn, map[string]interface{}{"type": "error", "payload": "Invalid business ID"})
+		return
+	}
+	if !subscribe {
+		hub.unsubscribe(businessIDStr, conn)
+		hub.notificationHub.send(conn, map[string]interface{}{"type": "presence_unsubscribed", "payload": map[string]string{"businessId": businessIDStr}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	member, err := hub.channels.IsBusinessMember(ctx, businessID, userID)
+	if err != nil || !member {
+		hub.notificationHub.send(conn, map[string]interface{}{"type": "error", "payload": "Business not found"})
+		return
+	}
+
+	// Subscribe sebelum snapshot agar perubahan di antara keduanya tidak terlewat
+	hub.subscribe(businessIDStr, conn)
+	members, err := hub.BusinessPresence(ctx, businessID, userID)
+	if err != nil {
+		hub.unsubscribe(businessIDStr, conn)
+		utils.LogError(err, "Gagal mengambil presence bisnis %s", businessIDStr)
+		hub.notificationHub.send(conn, map[string]interface{}{"type": "error", "payload": "Failed to get presence"})
+		return
+	}
+	hub.notificationHub.send(conn, map[string]interface{}{"type": "presence_snapshot", "payload": dto.BusinessPresenceResponse{
+		BusinessID: businessIDStr,
+		Members:    members,
+	}})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReadStateHandler menangani hitungan pesan dan mention belum dibaca per channel.
+type ReadStateHandler interface {
+	GetBusinessUnreadCounts(c *fiber.Ctx) error
+}
+
+type readStateHandlerImpl struct {
+	readStates service.ReadStateService
+}
+
+// NewReadStateHandler membuat instance baru dari ReadStateHandler.
+func NewReadStateHandler(readStates service.ReadStateService) ReadStateHandler {
+	return &readStateHandlerImpl{readStates: readStates}
+}
+
+// GetBusinessUnreadCounts returns unread and mention counts for every channel of a business.
+// @Summary Get unread counts of a business
+// @Description Get, for the authenticated user, the number of unread root messages and unread mentions for every channel of a business, together with the read cursor of each channel. Read cursors are moved with the "mark_read" WebSocket message.
+// @Tags Businesses
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Business ID"
+// @Success 200 {object} utils.APIResponse{data=dto.BusinessUnreadResponse} "Unread counts retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Business not found or user is not a member"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /businesses/{id}/unread [get]
+func (h *readStateHandlerImpl) GetBusinessUnreadCounts(c *fiber.Ctx) error {
+	businessID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid business ID format", err.Error())
+	}
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user ID in token", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	channels, err := h.readStates.GetBusinessUnreadCounts(ctx, businessID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotBusinessMember) {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Business not found", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get unread counts", err.Error())
+	}
+
+	resp := dto.BusinessUnreadResponse{
+		BusinessID: businessID.Hex(),
+		Channels:   make([]dto.ChannelUnreadResponse, len(channels)),
+	}
+	for i := range channels {
+		resp.Channels[i] = convertChannelUnreadToDTO(&channels[i])
+		resp.TotalUnread += channels[i].UnreadCount
+		resp.TotalMentions += channels[i].MentionCount
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Unread counts retrieved successfully", resp)
+}
+
+// convertChannelUnreadToDTO mengonversi service.ChannelUnread menjadi dto.ChannelUnreadResponse.
+func convertChannelUnreadToDTO(unread *service.ChannelUnread) dto.ChannelUnreadResponse {
+	resp := dto.ChannelUnreadResponse{
+		ChannelID:    unread.ChannelID.Hex(),
+		UnreadCount:  unread.UnreadCount,
+		MentionCount: unread.MentionCount,
+		LastReadAt:   unread.LastReadAt,
+	}
+	if unread.LastReadMessageID != nil {
+		resp.LastReadMessageID = unread.LastReadMessageID.Hex()
+	}
+	return resp
+}
@@ -0,0 +1,413 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReportHandler menangani logika terkait entitas Report.
+type ReportHandler interface {
+	CreateReport(c *fiber.Ctx) error
+	GetReportByID(c *fiber.Ctx) error
+	GetReportsByChannelID(c *fiber.Ctx) error
+	UpdateReport(c *fiber.Ctx) error
+	DeleteReport(c *fiber.Ctx) error
+	GetReportData(c *fiber.Ctx) error
+}
+
+type reportHandlerImpl struct {
+	reportRepo    repository.ReportRepository
+	reportService service.ReportService
+	channels      service.ChannelAccessService
+}
+
+// NewReportHandler membuat instance baru dari ReportHandler.
+func NewReportHandler(reportRepo repository.ReportRepository, reportService service.ReportService, channels service.ChannelAccessService) ReportHandler {
+	return &reportHandlerImpl{
+		reportRepo:    reportRepo,
+		reportService: reportService,
+		channels:      channels,
+	}
+}
+
+// CreateReport creates a new report entry.
+// @Summary Create a new report
+// @Description Create a new report for a specific channel. The channel must exist, belong to a business the user owns or is a member of, and have type "reports". The configuration is validated against the columns of the source database. The author is taken from the JWT token.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param report body dto.ReportCreateRequest true "Report Creation Details"
+// @Success 201 {object} utils.APIResponse{data=dto.ReportResponse} "Report created successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or invalid report configuration"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to read the source database"
+// @Failure 404 {object} utils.APIResponse "Not Found - Source database not found, or channel not found or not in a business of the user"
+// @Failure 409 {object} utils.APIResponse "Conflict - Channel is not a reports channel"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /reports [post]
+func (h *reportHandlerImpl) CreateReport(c *fiber.Ctx) error {
+	var req dto.ReportCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	// Validasi manual
+	if req.ChannelID == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "ChannelID is required")
+	}
+	if len(req.Title) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long")
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	authorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Author ID format", err.Error())
+	}
+	channelID, err := primitive.ObjectIDFromHex(req.ChannelID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Channel ID format", err.Error())
+	}
+	configuration, err := convertReportConfigToModel(req.Configuration)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Source Database ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	if _, err := h.channels.ValidateContentChannel(ctx, channelID, authorID, service.ChannelTypeReports); err != nil {
+		return sendChannelAccessError(c, err)
+	}
+	if ok, err := h.validateReportConfiguration(ctx, c, &configuration, userIDStr); !ok {
+		return err
+	}
+
+	newReport := &model.Report{
+		ID:            primitive.NewObjectID(),
+		ChannelID:     channelID,
+		AuthorID:      authorID,
+		Title:         req.Title,
+		Configuration: configuration,
+	}
+
+	if err := h.reportRepo.CreateReport(ctx, newReport); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create report", err.Error())
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Report created successfully", convertReportToDTO(newReport))
+}
+
+// GetReportByID retrieves a report by its ID.
+// @Summary Get report by ID
+// @Description Get a specific report and its visualization configuration by ID.
+// @Tags Reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Report ID"
+// @Success 200 {object} utils.APIResponse{data=dto.ReportResponse} "Report retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this report"
+// @Failure 404 {object} utils.APIResponse "Not Found - Report not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /reports/{id} [get]
+func (h *reportHandlerImpl) GetReportByID(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	report, err := h.getAuthorizedReport(ctx, c, "access")
+	if report == nil {
+		return err
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Report retrieved successfully", convertReportToDTO(report))
+}
+
+// GetReportsByChannelID retrieves all reports associated with a specific channel ID.
+// @Summary Get reports by channel ID
+// @Description Get a list of reports for a given channel ID, most recently updated first. The channel must belong to a business the user owns or is a member of.
+// @Tags Reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param channelId path string true "Channel ID"
+// @Success 200 {object} utils.APIResponse{data=[]dto.ReportResponse} "Reports retrieved successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid Channel ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 404 {object} utils.APIResponse "Not Found - Channel not found or not in a business of the user"
+// @Failure 409 {object} utils.APIResponse "Conflict - Channel is not a reports channel"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /reports/channel/{channelId} [get]
+func (h *reportHandlerImpl) GetReportsByChannelID(c *fiber.Ctx) error {
+	channelID, err := primitive.ObjectIDFromHex(c.Params("channelId"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Channel ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid User ID format", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	// Hanya pemilik atau anggota bisnis channel yang boleh melihat laporan di dalamnya
+	if _, err := h.channels.ValidateContentChannel(ctx, channelID, userID, service.ChannelTypeReports); err != nil {
+		return sendChannelAccessError(c, err)
+	}
+	reports, err := h.reportRepo.GetReportsByChannelID(ctx, channelID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get reports by channel ID", err.Error())
+	}
+
+	respReports := make([]dto.ReportResponse, 0, len(reports))
+	for i := range reports {
+		respReports = append(respReports, convertReportToDTO(&reports[i]))
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Reports retrieved successfully", respReports)
+}
+
+// UpdateReport updates an existing report.
+// @Summary Update a report
+// @Description Update the title and/or configuration of a report. Configuration replaces the whole visualization config and is re-validated. Only the author can update.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Report ID"
+// @Param report body dto.ReportUpdateRequest true "Report Update Details"
+// @Success 200 {object} utils.APIResponse{data=dto.ReportResponse} "Report updated successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid input or invalid report configuration"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to update this report"
+// @Failure 404 {object} utils.APIResponse "Not Found - Report or source database not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /reports/{id} [put]
+func (h *reportHandlerImpl) UpdateReport(c *fiber.Ctx) error {
+	var req dto.ReportUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	// Validasi manual
+	if req.Title != "" && len(req.Title) < 3 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "Title must be at least 3 characters long if provided")
+	}
+	if req.Title == "" && req.Configuration == nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation error", "At least 'title' or 'configuration' must be provided for update")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	existingReport, err := h.getAuthorizedReport(ctx, c, "update")
+	if existingReport == nil {
+		return err
+	}
+
+	setMap := bson.M{}
+	if req.Title != "" {
+		setMap["title"] = req.Title
+	}
+	if req.Configuration != nil {
+		configuration, err := convertReportConfigToModel(*req.Configuration)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid Source Database ID format", err.Error())
+		}
+		if ok, err := h.validateReportConfiguration(ctx, c, &configuration, existingReport.AuthorID.Hex()); !ok {
+			return err
+		}
+		setMap["configuration"] = configuration
+	}
+
+	updatedReport, err := h.reportRepo.UpdateReport(ctx, existingReport.ID, bson.M{"$set": setMap})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update report", err.Error())
+	}
+	if updatedReport == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Report not found after update (unlikely)", nil)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Report updated successfully", convertReportToDTO(updatedReport))
+}
+
+// DeleteReport deletes a report.
+// @Summary Delete a report
+// @Description Delete a report by its ID. The source database is not affected. Only the author can delete.
+// @Tags Reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Report ID"
+// @Success 200 {object} utils.APIResponse "Report deleted successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to delete this report"
+// @Failure 404 {object} utils.APIResponse "Not Found - Report not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /reports/{id} [delete]
+func (h *reportHandlerImpl) DeleteReport(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	existingReport, err := h.getAuthorizedReport(ctx, c, "delete")
+	if existingReport == nil {
+		return err
+	}
+
+	if err := h.reportRepo.DeleteReport(ctx, existingReport.ID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Report not found for deletion", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete report", err.Error())
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Report deleted successfully", nil)
+}
+
+// GetReportData evaluates a report against its source database.
+// @Summary Get report data
+// @Description Load the source database of a report, apply its filter config, then group and aggregate (sum, avg, count, min, max) the rows. Returns chart series for *_chart reports, card values for "card" reports and table rows for "table" reports.
+// @Tags Reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Report ID"
+// @Success 200 {object} utils.APIResponse{data=dto.ReportDataResponse} "Report data generated successfully"
+// @Failure 400 {object} utils.APIResponse "Bad Request - Invalid ID format or report configuration no longer matches the source database"
+// @Failure 401 {object} utils.APIResponse "Unauthorized - Token not provided or invalid"
+// @Failure 403 {object} utils.APIResponse "Forbidden - User not authorized to access this report"
+// @Failure 404 {object} utils.APIResponse "Not Found - Report or source database not found"
+// @Failure 500 {object} utils.APIResponse "Internal Server Error"
+// @Router /reports/{id}/data [get]
+func (h *reportHandlerImpl) GetReportData(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	report, err := h.getAuthorizedReport(ctx, c, "access")
+	if report == nil {
+		return err
+	}
+
+	data, err := h.reportService.GenerateReportData(ctx, report)
+	if err != nil {
+		return sendReportServiceError(c, err)
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Report data generated successfully", data)
+}
+
+// getAuthorizedReport mengambil laporan dari parameter ":id" dan memastikan user adalah author-nya.
+// Jika gagal, response error sudah dikirim dan laporan yang dikembalikan bernilai nil.
+func (h *reportHandlerImpl) getAuthorizedReport(ctx context.Context, c *fiber.Ctx, action string) (*model.Report, error) {
+	reportID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid report ID format", err.Error())
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok || userIDStr == "" {
+		return nil, utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User ID not found in token", nil)
+	}
+
+	report, err := h.reportRepo.GetReportByID(ctx, reportID)
+	if err != nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to get report", err.Error())
+	}
+	if report == nil {
+		return nil, utils.SendErrorResponse(c, fiber.StatusNotFound, "Report not found", nil)
+	}
+
+	// Otorisasi: sama seperti Database, saat ini hanya author yang boleh mengakses.
+	// TODO: Tambahkan logika otorisasi yang lebih kompleks (cek peran, anggota channel)
+	if report.AuthorID.Hex() != userIDStr {
+		return nil, utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to "+action+" this report", nil)
+	}
+	return report, nil
+}
+
+// validateReportConfiguration memvalidasi konfigurasi laporan terhadap database sumber dan memastikan
+// user berhak membaca database tersebut. Jika gagal, response error sudah dikirim dan ok bernilai false.
+func (h *reportHandlerImpl) validateReportConfiguration(ctx context.Context, c *fiber.Ctx, configuration *model.ReportVisualizationConfig, userIDStr string) (bool, error) {
+	database, err := h.reportService.ValidateConfiguration(ctx, configuration)
+	if err != nil {
+		return false, sendReportServiceError(c, err)
+	}
+
+	// Otorisasi: laporan hanya boleh dibuat dari database yang dapat diakses user (saat ini: author database).
+	// TODO: Samakan dengan otorisasi Database ketika sudah mendukung anggota channel.
+	if database.AuthorID.Hex() != userIDStr {
+		return false, utils.SendErrorResponse(c, fiber.StatusForbidden, "Forbidden: You are not authorized to read the source database", nil)
+	}
+	return true, nil
+}
+
+// sendReportServiceError memetakan error dari ReportService ke status HTTP yang sesuai.
+func sendReportServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidReportConfig):
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid report configuration", err.Error())
+	case errors.Is(err, service.ErrReportSourceNotFound):
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Source database not found", nil)
+	default:
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to evaluate report", err.Error())
+	}
+}
+
+// convertReportConfigToModel mengonversi konfigurasi laporan dari request menjadi model.
+func convertReportConfigToModel(req dto.ReportVisualizationConfigRequest) (model.ReportVisualizationConfig, error) {
+	sourceDatabaseID, err := primitive.ObjectIDFromHex(req.SourceDatabaseID)
+	if err != nil {
+		return model.ReportVisualizationConfig{}, err
+	}
+	return model.ReportVisualizationConfig{
+		ReportType:       req.ReportType,
+		SourceDatabaseID: sourceDatabaseID,
+		ChartConfig:      req.ChartConfig,
+		CardConfig:       req.CardConfig,
+		TableConfig:      req.TableConfig,
+		FilterConfig:     req.FilterConfig,
+	}, nil
+}
+
+// convertReportToDTO mengonversi model.Report menjadi dto.ReportResponse.
+func convertReportToDTO(report *model.Report) dto.ReportResponse {
+	return dto.ReportResponse{
+		ID:        report.ID.Hex(),
+		ChannelID: report.ChannelID.Hex(),
+		AuthorID:  report.AuthorID.Hex(),
+		Title:     report.Title,
+		Configuration: dto.ReportVisualizationConfigResponse{
+			ReportType:       report.Configuration.ReportType,
+			SourceDatabaseID: report.Configuration.SourceDatabaseID.Hex(),
+			ChartConfig:      report.Configuration.ChartConfig,
+			CardConfig:       report.Configuration.CardConfig,
+			TableConfig:      report.Configuration.TableConfig,
+			FilterConfig:     report.Configuration.FilterConfig,
+		},
+		CreatedAt: report.CreatedAt,
+		UpdatedAt: report.UpdatedAt,
+	}
+}
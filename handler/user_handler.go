@@ -109,7 +109,7 @@ func (h *userHandlerImpl) RegisterUser(c *fiber.Ctx) error {
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
 		Avatar:       req.Avatar,
-		Status:       "offline",
+		Status:       model.UserStatusOffline,
 		IsActive:     true,
 		Roles:        make(map[string][]string), // Default roles
 		CreatedAt:    time.Now(),
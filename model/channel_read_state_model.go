@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChannelReadState merepresentasikan posisi baca (read cursor) seorang user pada satu channel.
+// Pesan utama dengan _id lebih besar dari LastReadMessageID dianggap belum dibaca.
+type ChannelReadState struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID            primitive.ObjectID `bson:"userId" json:"userId"`
+	ChannelID         primitive.ObjectID `bson:"channelId" json:"channelId"`
+	BusinessID        primitive.ObjectID `bson:"businessId" json:"businessId"`
+	LastReadMessageID primitive.ObjectID `bson:"lastReadMessageId" json:"lastReadMessageId"`
+	LastReadAt        time.Time          `json:"lastReadAt" bson:"lastReadAt"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DatabaseFile adalah metadata file yang diunggah ke kolom database bertipe "file". Isi file disimpan
+// di FileStorage (lihat service/file_storage.go) dengan StorageKey; sel kolom file berisi daftar ID DatabaseFile.
+type DatabaseFile struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DatabaseID  primitive.ObjectID `bson:"databaseId" json:"databaseId"`
+	ColumnID    primitive.ObjectID `bson:"columnId" json:"columnId"`
+	UploaderID  primitive.ObjectID `bson:"uploaderId" json:"uploaderId"`
+	FileName    string             `bson:"fileName" json:"fileName"`
+	ContentType string             `bson:"contentType" json:"contentType"`
+	Size        int64              `bson:"size" json:"size"`
+	StorageKey  string             `bson:"storageKey" json:"-"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+}
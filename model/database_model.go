@@ -14,13 +14,42 @@ type SelectOption struct {
 	CreatedAt time.Time          `json:"createdAt"`
 }
 
+// RelationConfig adalah konfigurasi kolom bertipe "relation": nilai sel berisi daftar ID baris
+// dari database target (dalam channel yang sama).
+type RelationConfig struct {
+	DatabaseID primitive.ObjectID `bson:"databaseId" json:"databaseId"`
+}
+
+// RollupConfig adalah konfigurasi kolom bertipe "rollup": agregasi TargetColumnID pada baris-baris
+// yang dirujuk oleh kolom relation RelationColumnID. TargetColumnID boleh kosong untuk aggregation "count".
+type RollupConfig struct {
+	RelationColumnID string `bson:"relationColumnId" json:"relationColumnId"`
+	TargetColumnID   string `bson:"targetColumnId,omitempty" json:"targetColumnId,omitempty"`
+	Aggregation      string `bson:"aggregation" json:"aggregation"` // count, sum, avg, min, max
+}
+
 // DatabaseColumn merepresentasikan kolom dalam sebuah database.
 type DatabaseColumn struct {
-	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name    string             `json:"name"`
-	Type    string             `json:"type"`              // e.g., "date", "text", "select", "boolean"
-	Options []SelectOption     `json:"options,omitempty"` // Only for type "select"
-	Order   int                `json:"order"`             // Menambahkan field order
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `json:"name"`
+	Type        string             `json:"type"`                                               // e.g., "date", "text", "select", "boolean", "formula", "relation", "rollup", "file"
+	Options     []SelectOption     `json:"options,omitempty"`                                  // Only for type "select"
+	Order       int                `json:"order"`                                              // Menambahkan field order
+	Formula     string             `bson:"formula,omitempty" json:"formula,omitempty"`         // Only for type "formula", lihat service/database_formula.go
+	Relation    *RelationConfig    `bson:"relation,omitempty" json:"relation,omitempty"`       // Only for type "relation"
+	Rollup      *RollupConfig      `bson:"rollup,omitempty" json:"rollup,omitempty"`           // Only for type "rollup"
+	Constraints *ColumnConstraints `bson:"constraints,omitempty" json:"constraints,omitempty"` // Batasan nilai, lihat service/database_column_constraint.go
+}
+
+// ColumnConstraints adalah batasan nilai kolom yang diperiksa saat baris ditambah atau diubah.
+// Min, Max dan DefaultValue disimpan dalam tipe kanonik kolom (float64 untuk number, time.Time untuk date).
+type ColumnConstraints struct {
+	Required     bool        `bson:"required,omitempty" json:"required,omitempty"`
+	Unique       bool        `bson:"unique,omitempty" json:"unique,omitempty"`             // Unik di dalam database; nilai kosong tidak dihitung
+	DefaultValue interface{} `bson:"defaultValue,omitempty" json:"defaultValue,omitempty"` // Diisikan ke baris baru yang tidak mengisi kolom
+	Min          interface{} `bson:"min,omitempty" json:"min,omitempty"`                   // Only for type "number" dan "date"
+	Max          interface{} `bson:"max,omitempty" json:"max,omitempty"`                   // Only for type "number" dan "date"
+	Pattern      string      `bson:"pattern,omitempty" json:"pattern,omitempty"`           // Only for type "text", regex yang harus cocok dengan seluruh teks
 }
 
 // DatabaseRowValue merepresentasikan nilai-nilai dalam satu baris data.
@@ -28,15 +57,86 @@ type DatabaseColumn struct {
 type DatabaseRowValue map[string]interface{}
 
 // DatabaseRow merepresentasikan satu baris data dalam database.
+// Baris disimpan di koleksi tersendiri ("DatabaseRows") dan dihubungkan ke Database melalui DatabaseID.
 type DatabaseRow struct {
-	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Values DatabaseRowValue   `json:"values"`
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DatabaseID primitive.ObjectID `bson:"databaseId,omitempty" json:"databaseId,omitempty"`
+	Values     DatabaseRowValue   `bson:"values" json:"values"`
+	Version    int64              `bson:"version" json:"version"` // Naik setiap nilai baris berubah; dipakai sebagai ETag
+	CreatedAt  time.Time          `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+	UpdatedAt  time.Time          `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
+
+// Aksi yang dicatat pada DatabaseRowRevision.
+const (
+	RowRevisionCreate  = "create"
+	RowRevisionUpdate  = "update"
+	RowRevisionDelete  = "delete"
+	RowRevisionRestore = "restore"
+)
+
+// DatabaseRowFieldChange adalah perubahan nilai satu kolom pada sebuah revisi baris.
+// OldValue null berarti kolom sebelumnya kosong, NewValue null berarti kolom dikosongkan.
+type DatabaseRowFieldChange struct {
+	ColumnID string      `bson:"columnId" json:"columnId"`
+	OldValue interface{} `bson:"oldValue" json:"oldValue"`
+	NewValue interface{} `bson:"newValue" json:"newValue"`
+}
+
+// DatabaseRowRevision adalah catatan satu perubahan baris, disimpan di koleksi "DatabaseRowRevisions".
+// Values adalah isi baris setelah perubahan; untuk aksi "delete", Values adalah isi baris sesaat sebelum dihapus
+// sehingga baris dapat dipulihkan dari revisi mana pun.
+type DatabaseRowRevision struct {
+	ID           primitive.ObjectID       `bson:"_id,omitempty" json:"id"`
+	DatabaseID   primitive.ObjectID       `bson:"databaseId" json:"databaseId"`
+	RowID        primitive.ObjectID       `bson:"rowId" json:"rowId"`
+	Action       string                   `bson:"action" json:"action"` // "create", "update", "delete", "restore"
+	AuthorID     primitive.ObjectID       `bson:"authorId,omitempty" json:"authorId,omitempty"`
+	Values       DatabaseRowValue         `bson:"values" json:"values"`
+	Changes      []DatabaseRowFieldChange `bson:"changes" json:"changes"`
+	RestoredFrom primitive.ObjectID       `bson:"restoredFrom,omitempty" json:"restoredFrom,omitempty"` // Only for action "restore"
+	CreatedAt    time.Time                `bson:"createdAt" json:"createdAt"`
 }
 
 // DatabaseData merepresentasikan struktur data internal dari database.
+// Rows tidak disimpan di dokumen database; repository mengisinya dari koleksi baris saat membaca.
 type DatabaseData struct {
-	Columns []DatabaseColumn `json:"columns"`
-	Rows    []DatabaseRow    `json:"rows"`
+	Columns []DatabaseColumn `bson:"columns" json:"columns"`
+	Rows    []DatabaseRow    `bson:"-" json:"rows"`
+}
+
+// DatabaseViewFilterCondition adalah satu kondisi filter pada view (lihat service.RowFilterCondition).
+type DatabaseViewFilterCondition struct {
+	ColumnID string      `bson:"columnId" json:"columnId"`
+	Operator string      `bson:"operator" json:"operator"`
+	Value    interface{} `bson:"value,omitempty" json:"value,omitempty"`
+}
+
+// DatabaseViewFilter adalah filter tersimpan pada view. Match bernilai "all" (default) atau "any".
+type DatabaseViewFilter struct {
+	Match      string                        `bson:"match,omitempty" json:"match,omitempty"`
+	Conditions []DatabaseViewFilterCondition `bson:"conditions" json:"conditions"`
+}
+
+// DatabaseViewSort adalah satu kriteria pengurutan pada view.
+type DatabaseViewSort struct {
+	ColumnID  string `bson:"columnId" json:"columnId"`
+	Direction string `bson:"direction" json:"direction"` // asc atau desc
+}
+
+// DatabaseView adalah tampilan tersimpan dari sebuah database: filter, sort, kolom yang terlihat dan tipe layout.
+// View disimpan di dokumen database; baris yang sudah dibentuk sesuai view dihasilkan oleh service/database_view.go.
+type DatabaseView struct {
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name            string              `bson:"name" json:"name"`
+	Type            string              `bson:"type" json:"type"` // "table", "kanban", "calendar"
+	Filter          *DatabaseViewFilter `bson:"filter,omitempty" json:"filter,omitempty"`
+	Sort            []DatabaseViewSort  `bson:"sort,omitempty" json:"sort,omitempty"`
+	VisibleColumns  []string            `bson:"visibleColumns,omitempty" json:"visibleColumns,omitempty"`   // Kosong berarti semua kolom
+	GroupByColumnID string              `bson:"groupByColumnId,omitempty" json:"groupByColumnId,omitempty"` // Only for type "kanban", kolom select
+	DateColumnID    string              `bson:"dateColumnId,omitempty" json:"dateColumnId,omitempty"`       // Only for type "calendar", kolom date
+	CreatedAt       time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt       time.Time           `bson:"updatedAt" json:"updatedAt"`
 }
 
 // Database merepresentasikan struktur dokumen database di database.
@@ -44,10 +144,12 @@ type Database struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	ChannelID    primitive.ObjectID `bson:"channelId,omitempty" json:"channelId"`
 	AuthorID     primitive.ObjectID `bson:"authorId,omitempty" json:"authorId"`
-	Title        string             `json:"title"`
-	DatabaseData DatabaseData       `json:"databaseData"`
-	CreatedAt    time.Time          `json:"createdAt"`
-	UpdatedAt    time.Time          `json:"updatedAt"`
+	Title        string             `bson:"title" json:"title"`
+	DatabaseData DatabaseData       `bson:"databaseData" json:"databaseData"`
+	Views        []DatabaseView     `bson:"views,omitempty" json:"views,omitempty"`
+	Version      int64              `bson:"version" json:"version"` // Naik setiap dokumen database (judul, kolom, opsi, view) berubah; dipakai sebagai ETag
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
 
 /*
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DatabaseTemplate merepresentasikan skema database (kolom, select options, dan view) yang disimpan
+// untuk sebuah bisnis agar dapat dibuat ulang di channel mana pun milik bisnis tersebut.
+// Kolom relation yang merujuk database sumbernya sendiri disimpan dengan Relation.DatabaseID kosong.
+type DatabaseTemplate struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID `bson:"businessId" json:"businessId"`
+	AuthorID    primitive.ObjectID `bson:"authorId" json:"authorId"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Columns     []DatabaseColumn   `bson:"columns" json:"columns"`
+	Views       []DatabaseView     `bson:"views,omitempty" json:"views,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
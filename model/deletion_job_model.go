@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Status DeletionJob.
+const (
+	DeletionJobPending   = "pending"
+	DeletionJobRunning   = "running"
+	DeletionJobCompleted = "completed"
+	DeletionJobFailed    = "failed"
+)
+
+// Aksi DeletionStep.
+const (
+	DeletionActionDelete         = "delete"          // Menghapus dokumen yang cocok
+	DeletionActionDeleteFiles    = "delete_files"    // Menghapus isi file di FileStorage lalu metadata DatabaseFiles
+	DeletionActionDetachUsers    = "detach_users"    // Melepas bisnis dari businessIds dan roles user (user tidak dihapus)
+	DeletionActionClearRelations = "clear_relations" // Mengosongkan sel kolom relation di database lain yang merujuk database yang dihapus
+)
+
+// DeletionStep adalah satu langkah penghapusan berantai: dokumen di Collection yang Field-nya berisi
+// salah satu IDs. Untuk DeletionActionDetachUsers, IDs berisi ID bisnis. Untuk DeletionActionClearRelations,
+// IDs berisi ID database yang dihapus dan langkah mengenai baris database lain yang merujuknya.
+type DeletionStep struct {
+	Collection string               `bson:"collection" json:"collection"` // Key di config.DBConfig.Collections
+	Field      string               `bson:"field" json:"field"`
+	IDs        []primitive.ObjectID `bson:"ids" json:"-"`
+	Action     string               `bson:"action" json:"action"`
+	Planned    int64                `bson:"planned" json:"planned"`   // Jumlah dokumen saat rencana dibuat
+	Affected   int64                `bson:"affected" json:"affected"` // Jumlah dokumen yang sudah dihapus/diubah
+	Done       bool                 `bson:"done" json:"done"`
+}
+
+// DeletionJob mencatat penghapusan berantai channel atau bisnis yang dijalankan di background.
+// Langkah yang sudah Done dilewati saat job dilanjutkan setelah server restart.
+type DeletionJob struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TargetType  string             `bson:"targetType" json:"targetType"` // "channel" atau "business"
+	TargetID    primitive.ObjectID `bson:"targetId" json:"targetId"`
+	RequestedBy primitive.ObjectID `bson:"requestedBy" json:"requestedBy"`
+	Status      string             `bson:"status" json:"status"`
+	Steps       []DeletionStep     `bson:"steps" json:"steps"`
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+	CompletedAt *time.Time         `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
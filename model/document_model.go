@@ -10,17 +10,18 @@ import (
 // Ini adalah struktur rekursif yang bisa berisi heading, paragraph, bulletList, dll.
 // Menggunakan interface{} untuk 'content' karena bisa berupa array dari node lain atau text.
 type DocumentContentNode struct {
-	Type    string                   `json:"type"`
-	Attrs   map[string]interface{}   `json:"attrs,omitempty"`   // Atribut opsional (misal: level untuk heading)
-	Content []DocumentContentSubNode `json:"content,omitempty"` // Bisa berisi array of sub-nodes atau teks
+	Type    string                   `json:"type" bson:"type"`
+	Attrs   map[string]interface{}   `json:"attrs,omitempty" bson:"attrs,omitempty"`     // Atribut opsional (misal: level untuk heading)
+	Content []DocumentContentSubNode `json:"content,omitempty" bson:"content,omitempty"` // Bisa berisi array of sub-nodes atau teks
 }
 
 // DocumentContentSubNode merepresentasikan sub-node dari DocumentContentNode.
 // Digunakan karena 'content' di DocumentContentNode bisa berisi kombinasi object atau text.
 type DocumentContentSubNode struct {
-	Type    string                   `json:"type"`
-	Text    string                   `json:"text,omitempty"`
-	Content []DocumentContentSubNode `json:"content,omitempty"` // Rekursif untuk nested lists
+	Type    string                   `json:"type" bson:"type"`
+	Text    string                   `json:"text,omitempty" bson:"text,omitempty"`
+	Attrs   map[string]interface{}   `json:"attrs,omitempty" bson:"attrs,omitempty"`     // Atribut opsional untuk sub-node (misal: level heading)
+	Content []DocumentContentSubNode `json:"content,omitempty" bson:"content,omitempty"` // Rekursif untuk nested lists
 }
 
 // Document merepresentasikan struktur dokumen di database.
@@ -28,10 +29,10 @@ type Document struct {
 	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
 	ChannelID primitive.ObjectID  `bson:"channelId,omitempty" json:"channelId"`
 	AuthorID  primitive.ObjectID  `bson:"authorId,omitempty" json:"authorId"`
-	Title     string              `json:"title"`
-	Content   DocumentContentNode `json:"content"` // Root node of the document content
-	CreatedAt time.Time           `json:"createdAt"`
-	UpdatedAt time.Time           `json:"updatedAt"`
+	Title     string              `json:"title" bson:"title"`
+	Content   DocumentContentNode `json:"content" bson:"content"` // Root node of the document content
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt" bson:"updatedAt"`
 }
 
 /*
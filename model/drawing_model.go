@@ -8,29 +8,29 @@ import (
 
 // DrawingPoint merepresentasikan koordinat titik dalam gambar.
 type DrawingPoint struct {
-	X int `json:"x"`
-	Y int `json:"y"`
+	X int `json:"x" bson:"x"`
+	Y int `json:"y" bson:"y"`
 }
 
 // DrawingShape merepresentasikan bentuk dalam gambar.
 // Menggunakan interface{} untuk 'points' karena bisa array of points atau tidak ada (tergantung type).
 type DrawingShape struct {
 	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Type   string             `json:"type"` // e.g., "rectangle", "arrow"
-	X      int                `json:"x,omitempty"`
-	Y      int                `json:"y,omitempty"`
-	Width  int                `json:"width,omitempty"`
-	Height int                `json:"height,omitempty"`
-	Fill   string             `json:"fill,omitempty"`
-	Stroke string             `json:"stroke,omitempty"`
-	Text   string             `json:"text,omitempty"`
-	Points []DrawingPoint     `json:"points,omitempty"` // Untuk type "arrow"
+	Type   string             `json:"type" bson:"type"` // e.g., "rectangle", "arrow"
+	X      int                `json:"x,omitempty" bson:"x,omitempty"`
+	Y      int                `json:"y,omitempty" bson:"y,omitempty"`
+	Width  int                `json:"width,omitempty" bson:"width,omitempty"`
+	Height int                `json:"height,omitempty" bson:"height,omitempty"`
+	Fill   string             `json:"fill,omitempty" bson:"fill,omitempty"`
+	Stroke string             `json:"stroke,omitempty" bson:"stroke,omitempty"`
+	Text   string             `json:"text,omitempty" bson:"text,omitempty"`
+	Points []DrawingPoint     `json:"points,omitempty" bson:"points,omitempty"` // Untuk type "arrow"
 }
 
 // DrawingData merepresentasikan data internal dari gambar.
 type DrawingData struct {
-	Version string         `json:"version"`
-	Shapes  []DrawingShape `json:"shapes"`
+	Version string         `json:"version" bson:"version"`
+	Shapes  []DrawingShape `json:"shapes" bson:"shapes"` // Urutan array menentukan urutan layer (z-order)
 }
 
 // Drawing merepresentasikan struktur dokumen gambar di database.
@@ -38,10 +38,10 @@ type Drawing struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	ChannelID   primitive.ObjectID `bson:"channelId,omitempty" json:"channelId"`
 	AuthorID    primitive.ObjectID `bson:"authorId,omitempty" json:"authorId"`
-	Title       string             `json:"title"`
-	DrawingData DrawingData        `json:"drawingData"`
-	CreatedAt   time.Time          `json:"createdAt"`
-	UpdatedAt   time.Time          `json:"updatedAt"`
+	Title       string             `json:"title" bson:"title"`
+	DrawingData DrawingData        `json:"drawingData" bson:"drawingData"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
 }
 
 /*
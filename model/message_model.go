@@ -20,6 +20,13 @@ type MessageReaction struct {
 	UserIDs []primitive.ObjectID `json:"userIds" bson:"userIds"`
 }
 
+// MessageMentions merepresentasikan mention yang ditemukan pada isi pesan, sudah diubah menjadi ID.
+type MessageMentions struct {
+	UserIDs []primitive.ObjectID `json:"userIds" bson:"userIds,omitempty"`
+	RoleIDs []primitive.ObjectID `json:"roleIds" bson:"roleIds,omitempty"`
+	Channel bool                 `json:"channel" bson:"channel,omitempty"` // true jika pesan memuat @channel
+}
+
 // Message merepresentasikan struktur dokumen pesan di database.
 type Message struct {
 	ID            primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
@@ -33,4 +40,11 @@ type Message struct {
 	UpdatedAt     *time.Time            `json:"updatedAt" bson:"updatedAt,omitempty"`
 	IsPinned      bool                  `json:"isPinned" bson:"isPinned"`
 	Reactions     []MessageReaction     `json:"reactions" bson:"reactions"`
+	// ParentID berisi ID pesan utama jika pesan ini adalah balasan di dalam thread.
+	ParentID *primitive.ObjectID `json:"parentId" bson:"parentId,omitempty"`
+	// ReplyCount dan LastReplyAt hanya dipelihara pada pesan utama sebuah thread.
+	ReplyCount  int64      `json:"replyCount" bson:"replyCount"`
+	LastReplyAt *time.Time `json:"lastReplyAt" bson:"lastReplyAt,omitempty"`
+	// Mentions diisi saat pesan dibuat dari @user, @role dan @channel di Content.
+	Mentions *MessageMentions `json:"mentions" bson:"mentions,omitempty"`
 }
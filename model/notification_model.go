@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tipe notifikasi.
+const (
+	NotificationTypeMention = "mention"
+)
+
+// Jenis mention yang menyebabkan notifikasi dibuat.
+const (
+	MentionKindUser    = "user"
+	MentionKindRole    = "role"
+	MentionKindChannel = "channel"
+)
+
+// Notification merepresentasikan notifikasi in-app untuk satu penerima.
+type Notification struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID  `bson:"userId" json:"userId"`   // Penerima notifikasi
+	ActorID     primitive.ObjectID  `bson:"actorId" json:"actorId"` // User yang menyebabkan notifikasi, misalnya pengirim pesan
+	Type        string              `json:"type" bson:"type"`
+	MentionKind string              `json:"mentionKind,omitempty" bson:"mentionKind,omitempty"` // user, role atau channel
+	BusinessID  primitive.ObjectID  `bson:"businessId" json:"businessId"`
+	ChannelID   primitive.ObjectID  `bson:"channelId" json:"channelId"`
+	MessageID   primitive.ObjectID  `bson:"messageId" json:"messageId"`
+	ParentID    *primitive.ObjectID `bson:"parentId,omitempty" json:"parentId"` // Pesan utama jika mention berada di balasan thread
+	Preview     string              `json:"preview" bson:"preview"`
+	IsRead      bool                `json:"isRead" bson:"isRead"`
+	ReadAt      *time.Time          `json:"readAt" bson:"readAt,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+}
@@ -32,12 +32,16 @@ type ReportData struct {
 	Details    []ReportDetailCategory `json:"details"`
 	Conclusion string                 `json:"conclusion"`
 }
+
+// ReportVisualizationConfig menjelaskan bagaimana data dari sebuah model.Database diolah menjadi laporan.
+// Skema ChartConfig, CardConfig, TableConfig dan FilterConfig dijelaskan di service/report_service.go.
 type ReportVisualizationConfig struct {
-	ReportType       string                 `json:"reportType"`             // e.g., "card", "bar_chart", "table"
-	SourceDatabaseID primitive.ObjectID     `json:"sourceDatabaseId"`       // ID dari model Database yang akan di-query
-	ChartConfig      map[string]interface{} `json:"chartConfig,omitempty"`  // Konfigurasi spesifik untuk chart (sumbu X, Y, dll)
-	CardConfig       map[string]interface{} `json:"cardConfig,omitempty"`   // Konfigurasi spesifik untuk card (field yang ditampilkan)
-	FilterConfig     map[string]interface{} `json:"filterConfig,omitempty"` // Konfigurasi filter data
+	ReportType       string                 `json:"reportType" bson:"reportType"`                         // e.g., "card", "bar_chart", "table"
+	SourceDatabaseID primitive.ObjectID     `json:"sourceDatabaseId" bson:"sourceDatabaseId"`             // ID dari model Database yang akan di-query
+	ChartConfig      map[string]interface{} `json:"chartConfig,omitempty" bson:"chartConfig,omitempty"`   // Konfigurasi spesifik untuk chart (sumbu X, Y, dll)
+	CardConfig       map[string]interface{} `json:"cardConfig,omitempty" bson:"cardConfig,omitempty"`     // Konfigurasi spesifik untuk card (field yang ditampilkan)
+	TableConfig      map[string]interface{} `json:"tableConfig,omitempty" bson:"tableConfig,omitempty"`   // Konfigurasi spesifik untuk tabel (kolom, pengelompokan, limit)
+	FilterConfig     map[string]interface{} `json:"filterConfig,omitempty" bson:"filterConfig,omitempty"` // Konfigurasi filter data
 	// ... bisa tambahkan field lain sesuai kebutuhan visualisasi
 }
 
@@ -46,11 +50,11 @@ type Report struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	ChannelID primitive.ObjectID `bson:"channelId,omitempty" json:"channelId"`
 	AuthorID  primitive.ObjectID `bson:"authorId,omitempty" json:"authorId"`
-	Title     string             `json:"title"`
+	Title     string             `json:"title" bson:"title"`
 	// Ganti ReportData dengan konfigurasi visualisasi
-	Configuration ReportVisualizationConfig `json:"configuration"`
-	CreatedAt     time.Time                 `json:"createdAt"`
-	UpdatedAt     time.Time                 `json:"updatedAt"`
+	Configuration ReportVisualizationConfig `json:"configuration" bson:"configuration"`
+	CreatedAt     time.Time                 `json:"createdAt" bson:"createdAt"`
+	UpdatedAt     time.Time                 `json:"updatedAt" bson:"updatedAt"`
 }
 
 /*
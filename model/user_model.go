@@ -6,6 +6,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Status presence user (lihat User.Status), diperbarui dari koneksi WebSocket pesan.
+const (
+	UserStatusOnline  = "online"
+	UserStatusIdle    = "idle"
+	UserStatusOffline = "offline"
+)
+
 // User merepresentasikan struktur dokumen pengguna di database.
 type User struct {
 	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"` // Mengubah tipe ID
@@ -21,6 +21,7 @@ type BusinessRepository interface {
 	GetAllBusinesses(ctx context.Context) ([]model.Business, error)
 	UpdateBusiness(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Business, error)
 	DeleteBusiness(ctx context.Context, id primitive.ObjectID) error
+	GetBusinessesByOwnerID(ctx context.Context, ownerID string) ([]model.Business, error)
 }
 
 // businessRepositoryImpl adalah implementasi dari BusinessRepository.
@@ -95,6 +96,23 @@ func (r *businessRepositoryImpl) GetAllBusinesses(ctx context.Context) ([]model.
 	return businesses, nil
 }
 
+// GetBusinessesByOwnerID mengambil semua bisnis milik ownerID.
+func (r *businessRepositoryImpl) GetBusinessesByOwnerID(ctx context.Context, ownerID string) ([]model.Business, error) {
+	businesses := []model.Business{}
+	cursor, err := r.collection.Find(ctx, bson.M{"ownerId": ownerID})
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil bisnis milik owner: %s", ownerID)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &businesses); err != nil {
+		utils.LogError(err, "Gagal mendekode dokumen bisnis milik owner: %s", ownerID)
+		return nil, err
+	}
+	return businesses, nil
+}
+
 // UpdateBusiness memperbarui objek Business berdasarkan ID.
 func (r *businessRepositoryImpl) UpdateBusiness(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Business, error) {
 	// Set UpdatedAt saat memperbarui
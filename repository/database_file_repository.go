@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DatabaseFileRepository adalah interface untuk operasi database metadata file kolom database.
+type DatabaseFileRepository interface {
+	CreateFile(ctx context.Context, file *model.DatabaseFile) error
+	GetFileByID(ctx context.Context, id primitive.ObjectID) (*model.DatabaseFile, error)
+	GetFilesByIDs(ctx context.Context, databaseID primitive.ObjectID, ids []primitive.ObjectID) ([]model.DatabaseFile, error)
+	DeleteFile(ctx context.Context, id primitive.ObjectID) error
+}
+
+// databaseFileRepositoryImpl adalah implementasi dari DatabaseFileRepository.
+type databaseFileRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewDatabaseFileRepository membuat instance baru dari DatabaseFileRepository.
+func NewDatabaseFileRepository(dbClient *mongo.Client) DatabaseFileRepository {
+	collection := config.GetCollection(dbClient, "DatabaseFiles")
+	return &databaseFileRepositoryImpl{collection: collection}
+}
+
+// CreateFile menyimpan metadata file baru. ID yang sudah diisi (misalnya dipakai di StorageKey) dipertahankan.
+func (r *databaseFileRepositoryImpl) CreateFile(ctx context.Context, file *model.DatabaseFile) error {
+	if file.ID.IsZero() {
+		file.ID = primitive.NewObjectID()
+	}
+	file.CreatedAt = time.Now()
+	if _, err := r.collection.InsertOne(ctx, file); err != nil {
+		utils.LogError(err, "Gagal menyimpan metadata file database")
+		return err
+	}
+	utils.LogInfo("Berhasil menyimpan file %s untuk database: %s", file.ID.Hex(), file.DatabaseID.Hex())
+	return nil
+}
+
+// GetFileByID mengambil metadata file berdasarkan ID. Mengembalikan nil, nil jika tidak ditemukan.
+func (r *databaseFileRepositoryImpl) GetFileByID(ctx context.Context, id primitive.ObjectID) (*model.DatabaseFile, error) {
+	var file model.DatabaseFile
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&file)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("File database dengan ID %s tidak ditemukan", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil file database berdasarkan ID: %s", id.Hex())
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetFilesByIDs mengambil metadata file milik database tertentu berdasarkan daftar ID.
+// File yang tidak ditemukan atau milik database lain tidak disertakan.
+func (r *databaseFileRepositoryImpl) GetFilesByIDs(ctx context.Context, databaseID primitive.ObjectID, ids []primitive.ObjectID) ([]model.DatabaseFile, error) {
+	files := make([]model.DatabaseFile, 0, len(ids))
+	if len(ids) == 0 {
+		return files, nil
+	}
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "databaseId": databaseID})
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil file database: %s", databaseID.Hex())
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	if err := cursor.All(ctx, &files); err != nil {
+		utils.LogError(err, "Gagal mendekode file database: %s", databaseID.Hex())
+		return nil, err
+	}
+	return files, nil
+}
+
+// DeleteFile menghapus metadata file. Mengembalikan mongo.ErrNoDocuments jika tidak ditemukan.
+func (r *databaseFileRepositoryImpl) DeleteFile(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus file database dengan ID: %s", id.Hex())
+		return err
+	}
+	if result.DeletedCount == 0 {
+		utils.LogWarning("File database dengan ID %s tidak ditemukan untuk dihapus", id.Hex())
+		return mongo.ErrNoDocuments
+	}
+	utils.LogInfo("Berhasil menghapus file database dengan ID: %s", id.Hex())
+	return nil
+}
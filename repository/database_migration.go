@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureDatabaseIndexes membuat index untuk koleksi Databases, DatabaseRows, DatabaseRowRevisions, DatabaseTemplates
+// dan DatabaseFiles.
+// Aman dipanggil berulang kali karena MongoDB mengabaikan index yang sudah ada dengan spesifikasi sama.
+func EnsureDatabaseIndexes(ctx context.Context, dbClient *mongo.Client) error {
+	databaseCollection := config.GetCollection(dbClient, "Databases")
+	rowCollection := config.GetCollection(dbClient, "DatabaseRows")
+
+	if _, err := databaseCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "channelId", Value: 1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index channelId pada koleksi databases")
+		return err
+	}
+
+	// Dipakai untuk mencari kolom relation yang merujuk sebuah database saat baris target dihapus
+	if _, err := databaseCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "databaseData.columns.relation.databaseId", Value: 1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index relation pada koleksi databases")
+		return err
+	}
+
+	// Index utama baris: semua query baris difilter berdasarkan databaseId dan diurutkan berdasarkan _id
+	if _, err := rowCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "databaseId", Value: 1}, {Key: "_id", Value: 1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index databaseId pada koleksi database_rows")
+		return err
+	}
+
+	// Riwayat baris dibaca per baris (terbaru lebih dulu) dan per database untuk daftar baris terhapus
+	revisionCollection := config.GetCollection(dbClient, "DatabaseRowRevisions")
+	if _, err := revisionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "databaseId", Value: 1}, {Key: "rowId", Value: 1}, {Key: "_id", Value: -1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index pada koleksi database_row_revisions")
+		return err
+	}
+
+	templateCollection := config.GetCollection(dbClient, "DatabaseTemplates")
+	if _, err := templateCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "businessId", Value: 1}, {Key: "name", Value: 1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index pada koleksi database_templates")
+		return err
+	}
+
+	fileCollection := config.GetCollection(dbClient, "DatabaseFiles")
+	if _, err := fileCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "databaseId", Value: 1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index pada koleksi database_files")
+		return err
+	}
+
+	utils.LogInfo("Index koleksi databases, database_rows, database_row_revisions, database_templates dan database_files siap")
+	return nil
+}
+
+// MigrateEmbeddedDatabaseRows memindahkan baris yang masih tertanam di dokumen database
+// (databaseData.rows, atau databasedata.rows dari versi tanpa bson tag) ke koleksi DatabaseRows,
+// lalu menormalkan nama field lama (databasedata, createdat, updatedat) menjadi camelCase.
+//
+// Migrasi bersifat idempoten: baris disalin dengan upsert $setOnInsert sehingga tidak menimpa baris
+// yang sudah dipindahkan, baris tanpa _id mendapat ID tetap dari legacyRowID sehingga tidak tersalin
+// dua kali jika migrasi diulang, dan field lama baru dihapus setelah semua barisnya tersalin.
+// Mengembalikan jumlah dokumen database yang dimigrasi.
+func MigrateEmbeddedDatabaseRows(ctx context.Context, dbClient *mongo.Client) (int, error) {
+	databaseCollection := config.GetCollection(dbClient, "Databases")
+	rowCollection := config.GetCollection(dbClient, "DatabaseRows")
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"databasedata": bson.M{"$exists": true}},
+		bson.M{"databaseData.rows": bson.M{"$exists": true}},
+		bson.M{"createdat": bson.M{"$exists": true}},
+		bson.M{"updatedat": bson.M{"$exists": true}},
+	}}
+	cursor, err := databaseCollection.Find(ctx, filter)
+	if err != nil {
+		utils.LogError(err, "Gagal mencari database yang perlu dimigrasi")
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			utils.LogError(err, "Gagal mendekode dokumen database saat migrasi")
+			return migrated, err
+		}
+		databaseID, ok := doc["_id"].(primitive.ObjectID)
+		if !ok {
+			utils.LogWarning("Melewati dokumen database dengan _id bukan ObjectID saat migrasi: %v", doc["_id"])
+			continue
+		}
+
+		legacyData := toBsonM(doc["databasedata"])
+		currentData := toBsonM(doc["databaseData"])
+
+		// Kumpulkan baris dari kedua lokasi; dokumen lama bisa memiliki keduanya
+		// karena $push ke "databaseData.rows" dilakukan pada dokumen yang disimpan sebagai "databasedata".
+		createdAt := firstNonNil(doc["createdAt"], doc["createdat"], time.Now())
+		updatedAt := firstNonNil(doc["updatedAt"], doc["updatedat"], createdAt)
+		var writes []mongo.WriteModel
+		index := 0
+		for _, rawRows := range []interface{}{legacyData["rows"], currentData["rows"]} {
+			for _, rawRow := range toBsonA(rawRows) {
+				row := toBsonM(rawRow)
+				rowID, ok := row["_id"].(primitive.ObjectID)
+				if !ok {
+					rowID = legacyRowID(databaseID, index)
+				}
+				index++
+				values := toBsonM(row["values"])
+				if values == nil {
+					values = bson.M{}
+				}
+				writes = append(writes, mongo.NewUpdateOneModel().
+					SetFilter(bson.M{"_id": rowID}).
+					SetUpdate(bson.M{"$setOnInsert": bson.M{
+						"databaseId": databaseID,
+						"values":     values,
+						"version":    1,
+						"createdAt":  createdAt,
+						"updatedAt":  updatedAt,
+					}}).
+					SetUpsert(true))
+			}
+		}
+		if len(writes) > 0 {
+			if _, err := rowCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+				utils.LogError(err, "Gagal menyalin baris database %s ke koleksi database_rows", databaseID.Hex())
+				return migrated, err
+			}
+		}
+
+		columns := currentData["columns"]
+		if len(toBsonA(columns)) == 0 {
+			columns = legacyData["columns"]
+		}
+		if columns == nil {
+			columns = bson.A{}
+		}
+		update := bson.M{
+			"$set": bson.M{
+				"databaseData.columns": columns,
+				"createdAt":            createdAt,
+				"updatedAt":            updatedAt,
+			},
+			"$unset": bson.M{
+				"databasedata":      "",
+				"databaseData.rows": "",
+				"createdat":         "",
+				"updatedat":         "",
+			},
+		}
+		if _, err := databaseCollection.UpdateOne(ctx, bson.M{"_id": databaseID}, update); err != nil {
+			utils.LogError(err, "Gagal menormalkan dokumen database %s saat migrasi", databaseID.Hex())
+			return migrated, err
+		}
+		utils.LogInfo("Migrasi database %s: %d baris dipindahkan ke koleksi database_rows", databaseID.Hex(), len(writes))
+		migrated++
+	}
+	if err := cursor.Err(); err != nil {
+		utils.LogError(err, "Gagal membaca cursor saat migrasi database")
+		return migrated, err
+	}
+	return migrated, nil
+}
+
+// MigrateDatabaseVersions mengisi version = 1 pada dokumen database dan baris yang dibuat sebelum
+// field version ada, sehingga update bersyarat (If-Match) dapat dipakai untuk semua data.
+// Idempoten karena hanya menyentuh dokumen tanpa field version.
+func MigrateDatabaseVersions(ctx context.Context, dbClient *mongo.Client) error {
+	for _, name := range []string{"Databases", "DatabaseRows"} {
+		collection := config.GetCollection(dbClient, name)
+		result, err := collection.UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"version": 1}})
+		if err != nil {
+			utils.LogError(err, "Gagal mengisi version pada koleksi %s", collection.Name())
+			return err
+		}
+		if result.ModifiedCount > 0 {
+			utils.LogInfo("Mengisi version pada %d dokumen koleksi %s", result.ModifiedCount, collection.Name())
+		}
+	}
+	return nil
+}
+
+// legacyRowID membuat ID tetap untuk baris tertanam tanpa _id dari ID database dan posisi baris, sehingga
+// migrasi yang diulang menghasilkan ID yang sama. Timestamp ID database dipertahankan dan posisi baris
+// menjadi 3 byte terakhir, sehingga urutan _id baris mengikuti urutan aslinya.
+func legacyRowID(databaseID primitive.ObjectID, index int) primitive.ObjectID {
+	sum := sha256.Sum256(databaseID[:])
+	var id primitive.ObjectID
+	copy(id[0:4], databaseID[0:4])
+	copy(id[4:9], sum[:5])
+	id[9] = byte(index >> 16)
+	id[10] = byte(index >> 8)
+	id[11] = byte(index)
+	return id
+}
+
+// toBsonM mengonversi nilai dokumen hasil decode (bson.M atau bson.D) menjadi bson.M.
+func toBsonM(value interface{}) bson.M {
+	switch v := value.(type) {
+	case bson.M:
+		return v
+	case bson.D:
+		m := make(bson.M, len(v))
+		for _, elem := range v {
+			m[elem.Key] = elem.Value
+		}
+		return m
+	}
+	return nil
+}
+
+// toBsonA mengonversi nilai array hasil decode menjadi bson.A.
+func toBsonA(value interface{}) bson.A {
+	switch v := value.(type) {
+	case bson.A:
+		return v
+	case []interface{}:
+		return v
+	}
+	return nil
+}
+
+// firstNonNil mengembalikan nilai pertama yang tidak nil.
+func firstNonNil(values ...interface{}) interface{} {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
@@ -21,17 +21,28 @@ import (
 type DatabaseRepository interface {
 	CreateDatabase(ctx context.Context, database *model.Database) error
 	GetDatabaseByID(ctx context.Context, id primitive.ObjectID) (*model.Database, error)
+	// GetDatabaseSchemaByID mengambil dokumen database (judul, kolom, view) tanpa memuat barisnya.
+	GetDatabaseSchemaByID(ctx context.Context, id primitive.ObjectID) (*model.Database, error)
 	GetDatabasesByChannelID(ctx context.Context, channelID primitive.ObjectID) ([]model.Database, error)
-	UpdateDatabase(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Database, error)
-	DeleteDatabase(ctx context.Context, id primitive.ObjectID) error
+	// expectedVersion > 0 membuat update hanya diterapkan jika versi database masih sama (lihat ErrVersionMismatch)
+	UpdateDatabase(ctx context.Context, id primitive.ObjectID, updateData bson.M, expectedVersion int64) (*model.Database, error)
+	// DeleteDatabase menghapus database beserta baris, revisi dan metadata file-nya. File yang dihapus
+	// dikembalikan agar pemanggil dapat menghapus isinya dari FileStorage.
+	DeleteDatabase(ctx context.Context, id primitive.ObjectID) ([]model.DatabaseFile, error)
 
 	// Operasi untuk baris data (rows)
-	AddRowToDatabase(ctx context.Context, databaseID primitive.ObjectID, row *model.DatabaseRow) (*model.Database, error)
-	UpdateRowInDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID, updatedValues model.DatabaseRowValue) (*model.Database, error)
-	DeleteRowFromDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID) (*model.Database, error)
+	// authorID adalah user yang melakukan perubahan dan dicatat pada revisi baris. Database yang dikembalikan
+	// hanya memuat baris yang ditambah atau diubah (kosong untuk DeleteRowFromDatabase), bukan seluruh baris.
+	AddRowToDatabase(ctx context.Context, databaseID primitive.ObjectID, row *model.DatabaseRow, authorID primitive.ObjectID) (*model.Database, error)
+	AddRowsToDatabase(ctx context.Context, databaseID primitive.ObjectID, rows []model.DatabaseRow, authorID primitive.ObjectID) (*model.Database, error)
+	UpdateRowInDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID, updatedValues model.DatabaseRowValue, authorID primitive.ObjectID, expectedVersion int64) (*model.Database, error)
+	DeleteRowFromDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID, authorID primitive.ObjectID) (*model.Database, error)
+	ApplyRowBatch(ctx context.Context, databaseID primitive.ObjectID, ops []RowBatchOperation, authorID primitive.ObjectID) (*model.Database, error)
 
 	// Operasi untuk kolom
-	UpdateColumnInDatabase(ctx context.Context, databaseID, columnID primitive.ObjectID, updateData bson.M) (*model.Database, error)
+	AddColumnToDatabase(ctx context.Context, databaseID primitive.ObjectID, column *model.DatabaseColumn, defaultValue interface{}) (*model.Database, error)
+	ReorderColumnsInDatabase(ctx context.Context, databaseID primitive.ObjectID, columnIDs []primitive.ObjectID) (*model.Database, error)
+	UpdateColumnInDatabase(ctx context.Context, databaseID, columnID primitive.ObjectID, updateData bson.M, expectedVersion int64) (*model.Database, error)
 	DeleteColumnFromDatabase(ctx context.Context, databaseID, columnID primitive.ObjectID) (*model.Database, error)
 	GetColumnInDatabase(ctx context.Context, databaseID, columnID primitive.ObjectID) (*model.DatabaseColumn, error)
 
@@ -42,33 +53,88 @@ type DatabaseRepository interface {
 	GetSelectOptionInColumn(ctx context.Context, databaseID, columnID, optionID primitive.ObjectID) (*model.SelectOption, error)
 	GetRowInDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID) (*model.DatabaseRow, error)
 	GetRowsByDatabaseID(ctx context.Context, databaseID primitive.ObjectID) ([]model.DatabaseRow, error)
+	// QueryRowPage menjalankan filter, sort dan pagination baris di MongoDB (lihat database_row_query.go)
+	QueryRowPage(ctx context.Context, databaseID primitive.ObjectID, query RowPageQuery) ([]model.DatabaseRow, int64, error)
+	GetRowsByIDs(ctx context.Context, databaseID primitive.ObjectID, rowIDs []primitive.ObjectID) ([]model.DatabaseRow, error)
+	// FindRowIDByValue mengembalikan ID satu baris yang sel columnID-nya sama dengan value, selain baris
+	// excludeIDs. Mengembalikan primitive.NilObjectID jika tidak ada; dipakai pemeriksaan batasan unique.
+	FindRowIDByValue(ctx context.Context, databaseID primitive.ObjectID, columnID string, value interface{}, excludeIDs []primitive.ObjectID) (primitive.ObjectID, error)
+	ReplaceRowsInDatabase(ctx context.Context, databaseID primitive.ObjectID, rows []model.DatabaseRow, authorID primitive.ObjectID) (*model.Database, error)
+	RemoveFileReferences(ctx context.Context, databaseID, columnID primitive.ObjectID, fileID string) error
+
+	// Operasi untuk riwayat perubahan baris (lihat database_row_revision.go)
+	GetRowRevisions(ctx context.Context, databaseID, rowID primitive.ObjectID, limit int) ([]model.DatabaseRowRevision, error)
+	GetRowRevision(ctx context.Context, databaseID, revisionID primitive.ObjectID) (*model.DatabaseRowRevision, error)
+	GetDeletedRows(ctx context.Context, databaseID primitive.ObjectID) ([]model.DatabaseRowRevision, error)
+	RestoreRow(ctx context.Context, databaseID, rowID primitive.ObjectID, values model.DatabaseRowValue, revisionID, authorID primitive.ObjectID) (*model.Database, error)
+
+	// Operasi untuk view tersimpan
+	AddViewToDatabase(ctx context.Context, databaseID primitive.ObjectID, view *model.DatabaseView) (*model.Database, error)
+	UpdateViewInDatabase(ctx context.Context, databaseID, viewID primitive.ObjectID, view *model.DatabaseView) (*model.Database, error)
+	DeleteViewFromDatabase(ctx context.Context, databaseID, viewID primitive.ObjectID) (*model.Database, error)
 }
 
+// ErrVersionMismatch dikembalikan ketika update bersyarat (expectedVersion > 0) ditolak karena database
+// atau baris sudah diubah oleh request lain sejak versi tersebut dibaca.
+var ErrVersionMismatch = errors.New("versi data sudah berubah")
+
+// ErrDatabaseColumnsChanged dikembalikan ketika kolom database berubah di antara pembacaan dan penulisan reorder.
+var ErrDatabaseColumnsChanged = errors.New("kolom database berubah saat reorder, silakan coba lagi")
+
 // databaseRepositoryImpl adalah implementasi dari DatabaseRepository.
+// Dokumen database (judul dan kolom) disimpan di koleksi "Databases", sedangkan setiap baris
+// disimpan sebagai dokumen terpisah di koleksi "DatabaseRows" dengan field databaseId.
+// Riwayat perubahan baris disimpan di koleksi "DatabaseRowRevisions".
+//
+// Dokumen database dan setiap baris memiliki field version yang dinaikkan ($inc) pada setiap perubahan:
+// version database untuk judul, kolom, opsi select dan view; version baris untuk nilai baris tersebut
+// (termasuk perubahan tidak langsung seperti pengisian default kolom baru). Perubahan baris tidak
+// menaikkan version database.
 type databaseRepositoryImpl struct {
-	collection *mongo.Collection
+	client             *mongo.Client // Untuk sesi transaksi (lihat database_row_batch.go)
+	collection         *mongo.Collection
+	rowCollection      *mongo.Collection
+	revisionCollection *mongo.Collection
+	fileCollection     *mongo.Collection // Metadata file kolom, dihapus bersama database
 }
 
 // NewDatabaseRepository membuat instance baru dari DatabaseRepository.
 func NewDatabaseRepository(dbClient *mongo.Client) DatabaseRepository {
 	collection := config.GetCollection(dbClient, "Databases")
-	return &databaseRepositoryImpl{collection: collection}
+	rowCollection := config.GetCollection(dbClient, "DatabaseRows")
+	revisionCollection := config.GetCollection(dbClient, "DatabaseRowRevisions")
+	fileCollection := config.GetCollection(dbClient, "DatabaseFiles")
+	return &databaseRepositoryImpl{client: dbClient, collection: collection, rowCollection: rowCollection, revisionCollection: revisionCollection, fileCollection: fileCollection}
 }
 
-// CreateDatabase menyimpan objek Database baru ke database.
+// CreateDatabase menyimpan objek Database baru ke database beserta baris awalnya (jika ada).
 func (r *databaseRepositoryImpl) CreateDatabase(ctx context.Context, database *model.Database) error {
 	database.CreatedAt = time.Now()
 	database.UpdatedAt = time.Now()
+	database.Version = 1
 	_, err := r.collection.InsertOne(ctx, database)
 	if err != nil {
 		utils.LogError(err, "Gagal membuat database baru di database")
 		return err
 	}
+
+	if len(database.DatabaseData.Rows) > 0 {
+		if err := r.insertRows(ctx, database.ID, database.DatabaseData.Rows); err != nil {
+			// Kompensasi: hapus kembali dokumen database agar tidak tersisa database tanpa baris awalnya
+			if _, delErr := r.collection.DeleteOne(ctx, bson.M{"_id": database.ID}); delErr != nil {
+				utils.LogError(delErr, "Gagal membatalkan pembuatan database: %s", database.ID.Hex())
+			}
+			return err
+		}
+		if err := r.recordRevisions(ctx, createRevisions(database.ID, database.AuthorID, database.DatabaseData.Rows)); err != nil {
+			utils.LogError(err, "Database %s dibuat tanpa revisi baris awal", database.ID.Hex())
+		}
+	}
 	utils.LogInfo("Berhasil membuat database baru: %s", database.ID.Hex())
 	return nil
 }
 
-// GetDatabaseByID mengambil objek Database berdasarkan ID.
+// GetDatabaseByID mengambil objek Database berdasarkan ID beserta seluruh barisnya.
 func (r *databaseRepositoryImpl) GetDatabaseByID(ctx context.Context, id primitive.ObjectID) (*model.Database, error) {
 	var database model.Database
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&database)
@@ -80,11 +146,29 @@ func (r *databaseRepositoryImpl) GetDatabaseByID(ctx context.Context, id primiti
 		utils.LogError(err, "Gagal mengambil database berdasarkan ID: %s", id.Hex())
 		return nil, err
 	}
+	if err := r.populateRows(ctx, &database); err != nil {
+		return nil, err
+	}
 	utils.LogInfo("Berhasil mengambil database dengan ID: %s", id.Hex())
 	return &database, nil
 }
 
-// GetDatabasesByChannelID mengambil semua database berdasarkan ChannelID.
+// GetDatabaseSchemaByID sama dengan GetDatabaseByID tetapi DatabaseData.Rows dibiarkan kosong.
+func (r *databaseRepositoryImpl) GetDatabaseSchemaByID(ctx context.Context, id primitive.ObjectID) (*model.Database, error) {
+	var database model.Database
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&database)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Database dengan ID %s tidak ditemukan", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil database berdasarkan ID: %s", id.Hex())
+		return nil, err
+	}
+	return &database, nil
+}
+
+// GetDatabasesByChannelID mengambil semua database berdasarkan ChannelID beserta barisnya.
 func (r *databaseRepositoryImpl) GetDatabasesByChannelID(ctx context.Context, channelID primitive.ObjectID) ([]model.Database, error) {
 	var databases []model.Database
 	filter := bson.M{"channelId": channelID}
@@ -100,74 +184,314 @@ func (r *databaseRepositoryImpl) GetDatabasesByChannelID(ctx context.Context, ch
 		utils.LogError(err, "Gagal mendekode dokumen database by channelId")
 		return nil, err
 	}
+
+	if len(databases) > 0 {
+		// Ambil baris semua database sekaligus dengan satu query, lalu kelompokkan per database
+		databaseIDs := make([]primitive.ObjectID, len(databases))
+		for i := range databases {
+			databaseIDs[i] = databases[i].ID
+		}
+		rows, err := r.findRows(ctx, bson.M{"databaseId": bson.M{"$in": databaseIDs}})
+		if err != nil {
+			return nil, err
+		}
+		rowsByDatabase := make(map[primitive.ObjectID][]model.DatabaseRow, len(databases))
+		for _, row := range rows {
+			rowsByDatabase[row.DatabaseID] = append(rowsByDatabase[row.DatabaseID], row)
+		}
+		for i := range databases {
+			databases[i].DatabaseData.Rows = rowsByDatabase[databases[i].ID]
+			if databases[i].DatabaseData.Rows == nil {
+				databases[i].DatabaseData.Rows = []model.DatabaseRow{}
+			}
+		}
+	}
 	utils.LogInfo("Berhasil mengambil database by channelId. Total: %d", len(databases))
 	return databases, nil
 }
 
 // UpdateDatabase memperbarui objek Database berdasarkan ID.
-func (r *databaseRepositoryImpl) UpdateDatabase(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Database, error) {
+func (r *databaseRepositoryImpl) UpdateDatabase(ctx context.Context, id primitive.ObjectID, updateData bson.M, expectedVersion int64) (*model.Database, error) {
 	// Tambahkan updatedAt ke updateData
 	updateData["$set"].(bson.M)["updatedAt"] = time.Now()
+	updateData["$inc"] = bson.M{"version": 1}
 
-	filter := bson.M{"_id": id}
+	filter := versionFilter(bson.M{"_id": id}, expectedVersion)
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var updatedDatabase model.Database
 	err := r.collection.FindOneAndUpdate(ctx, filter, updateData, opts).Decode(&updatedDatabase)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
+			if expectedVersion > 0 && r.documentExists(ctx, r.collection, bson.M{"_id": id}) {
+				utils.LogWarning("Database dengan ID %s sudah berubah dari versi %d", id.Hex(), expectedVersion)
+				return nil, ErrVersionMismatch
+			}
 			utils.LogWarning("Database dengan ID %s tidak ditemukan untuk diperbarui", id.Hex())
 			return nil, nil
 		}
 		utils.LogError(err, "Gagal memperbarui database di database: %s", id.Hex())
 		return nil, err
 	}
+	if err := r.populateRows(ctx, &updatedDatabase); err != nil {
+		return nil, err
+	}
 	utils.LogInfo("Berhasil memperbarui database dengan ID: %s", id.Hex())
 	return &updatedDatabase, nil
 }
 
-// DeleteDatabase menghapus objek Database berdasarkan ID.
-func (r *databaseRepositoryImpl) DeleteDatabase(ctx context.Context, id primitive.ObjectID) error {
+// DeleteDatabase menghapus objek Database berdasarkan ID beserta seluruh baris, revisi dan metadata file-nya.
+func (r *databaseRepositoryImpl) DeleteDatabase(ctx context.Context, id primitive.ObjectID) ([]model.DatabaseFile, error) {
 	filter := bson.M{"_id": id}
 	result, err := r.collection.DeleteOne(ctx, filter)
 	if err != nil {
 		utils.LogError(err, "Gagal menghapus database dengan ID: %s", id.Hex())
-		return err
+		return nil, err
 	}
 	if result.DeletedCount == 0 {
 		utils.LogWarning("Database dengan ID %s tidak ditemukan untuk dihapus", id.Hex())
-		return mongo.ErrNoDocuments
+		return nil, mongo.ErrNoDocuments
 	}
-	utils.LogInfo("Berhasil menghapus database dengan ID: %s", id.Hex())
-	return nil
+
+	rowResult, err := r.rowCollection.DeleteMany(ctx, bson.M{"databaseId": id})
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus baris milik database dengan ID: %s", id.Hex())
+		return nil, err
+	}
+	if err := r.removeRelationReferences(ctx, id, nil); err != nil {
+		return nil, err
+	}
+	if _, err := r.revisionCollection.DeleteMany(ctx, bson.M{"databaseId": id}); err != nil {
+		utils.LogError(err, "Gagal menghapus revisi baris milik database dengan ID: %s", id.Hex())
+		return nil, err
+	}
+
+	files := make([]model.DatabaseFile, 0)
+	cursor, err := r.fileCollection.Find(ctx, bson.M{"databaseId": id})
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil file milik database dengan ID: %s", id.Hex())
+		return nil, err
+	}
+	if err := cursor.All(ctx, &files); err != nil {
+		utils.LogError(err, "Gagal mendekode file milik database dengan ID: %s", id.Hex())
+		return nil, err
+	}
+	if len(files) > 0 {
+		if _, err := r.fileCollection.DeleteMany(ctx, bson.M{"databaseId": id}); err != nil {
+			utils.LogError(err, "Gagal menghapus file milik database dengan ID: %s", id.Hex())
+			return nil, err
+		}
+	}
+	utils.LogInfo("Berhasil menghapus database dengan ID: %s beserta %d baris dan %d file", id.Hex(), rowResult.DeletedCount, len(files))
+	return files, nil
 }
 
-// AddRowToDatabase menambahkan baris baru ke array Rows dalam dokumen database.
-func (r *databaseRepositoryImpl) AddRowToDatabase(ctx context.Context, databaseID primitive.ObjectID, row *model.DatabaseRow) (*model.Database, error) {
+// AddRowToDatabase menambahkan baris baru ke koleksi baris milik database.
+func (r *databaseRepositoryImpl) AddRowToDatabase(ctx context.Context, databaseID primitive.ObjectID, row *model.DatabaseRow, authorID primitive.ObjectID) (*model.Database, error) {
+	updatedDatabase, err := r.touchDatabase(ctx, databaseID)
+	if err != nil {
+		utils.LogError(err, "Gagal menambahkan baris ke database: %s", databaseID.Hex())
+		return nil, err
+	}
+	if updatedDatabase == nil {
+		utils.LogWarning("Database dengan ID %s tidak ditemukan untuk menambahkan baris", databaseID.Hex())
+		return nil, nil
+	}
+
 	row.ID = primitive.NewObjectID()
+	if err := r.insertRows(ctx, databaseID, []model.DatabaseRow{*row}); err != nil {
+		return nil, err
+	}
+	if err := r.recordRevisions(ctx, createRevisions(databaseID, authorID, []model.DatabaseRow{*row})); err != nil {
+		utils.LogError(err, "Baris %s ditambahkan ke database %s tanpa revisi", row.ID.Hex(), databaseID.Hex())
+	}
+	if err := r.populateRowsByIDs(ctx, updatedDatabase, []primitive.ObjectID{row.ID}); err != nil {
+		return nil, err
+	}
+	utils.LogInfo("Berhasil menambahkan baris ke database ID: %s. Row ID: %s", databaseID.Hex(), row.ID.Hex())
+	return updatedDatabase, nil
+}
+
+// AddRowsToDatabase menambahkan banyak baris sekaligus ke koleksi baris milik database (dipakai oleh import).
+func (r *databaseRepositoryImpl) AddRowsToDatabase(ctx context.Context, databaseID primitive.ObjectID, rows []model.DatabaseRow, authorID primitive.ObjectID) (*model.Database, error) {
+	updatedDatabase, err := r.touchDatabase(ctx, databaseID)
+	if err != nil {
+		utils.LogError(err, "Gagal menambahkan baris ke database: %s", databaseID.Hex())
+		return nil, err
+	}
+	if updatedDatabase == nil {
+		utils.LogWarning("Database dengan ID %s tidak ditemukan untuk menambahkan baris", databaseID.Hex())
+		return nil, nil
+	}
+
+	if err := r.insertRows(ctx, databaseID, rows); err != nil {
+		return nil, err
+	}
+	if err := r.recordRevisions(ctx, createRevisions(databaseID, authorID, rows)); err != nil {
+		utils.LogError(err, "%d baris ditambahkan ke database %s tanpa revisi", len(rows), databaseID.Hex())
+	}
+	rowIDs := make([]primitive.ObjectID, len(rows))
+	for i, row := range rows {
+		rowIDs[i] = row.ID
+	}
+	if err := r.populateRowsByIDs(ctx, updatedDatabase, rowIDs); err != nil {
+		return nil, err
+	}
+	utils.LogInfo("Berhasil menambahkan %d baris ke database ID: %s", len(rows), databaseID.Hex())
+	return updatedDatabase, nil
+}
+
+// AddColumnToDatabase menambahkan kolom baru ke dokumen database lalu mengisi nilai default
+// kolom tersebut ke seluruh baris yang sudah ada. Jika column.Order bernilai 0, kolom ditempatkan
+// di urutan terakhir; jika tidak, kolom lain dengan order >= column.Order digeser satu posisi.
+func (r *databaseRepositoryImpl) AddColumnToDatabase(ctx context.Context, databaseID primitive.ObjectID, column *model.DatabaseColumn, defaultValue interface{}) (*model.Database, error) {
+	// ID dan posisi kolom ditentukan sebelum transaksi agar tetap sama jika driver mengulang transaksi
+	if column.ID.IsZero() {
+		column.ID = primitive.NewObjectID()
+	}
+	appendLast := column.Order <= 0
+
+	session, err := r.client.StartSession()
+	if err != nil {
+		utils.LogError(err, "Gagal memulai sesi untuk menambahkan kolom ke database: %s", databaseID.Hex())
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	var backfilled int64
+	result, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		updatedDatabase, modified, err := r.addColumn(sc, databaseID, column, appendLast, defaultValue)
+		backfilled = modified
+		return updatedDatabase, err
+	})
+	if err != nil {
+		utils.LogError(err, "Gagal menambahkan kolom ke database: %s", databaseID.Hex())
+		return nil, err
+	}
+	updatedDatabase, _ := result.(*model.Database)
+	if updatedDatabase == nil {
+		utils.LogWarning("Database dengan ID %s tidak ditemukan untuk menambahkan kolom", databaseID.Hex())
+		return nil, nil
+	}
+
+	if err := r.populateRows(ctx, updatedDatabase); err != nil {
+		return nil, err
+	}
+	utils.LogInfo("Berhasil menambahkan kolom ID: %s ke database ID: %s (%d baris diisi nilai default)", column.ID.Hex(), databaseID.Hex(), backfilled)
+	return updatedDatabase, nil
+}
+
+// addColumn adalah isi transaksi AddColumnToDatabase. ctx harus berupa mongo.SessionContext.
+// Version database hanya dinaikkan sekali oleh $push kolom. Mengembalikan jumlah baris yang diisi nilai default.
+func (r *databaseRepositoryImpl) addColumn(ctx context.Context, databaseID primitive.ObjectID, column *model.DatabaseColumn, appendLast bool, defaultValue interface{}) (*model.Database, int64, error) {
+	var existing model.Database
+	err := r.collection.FindOne(ctx, bson.M{"_id": databaseID}).Decode(&existing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	if appendLast {
+		maxOrder := 0
+		for _, col := range existing.DatabaseData.Columns {
+			if col.Order > maxOrder {
+				maxOrder = col.Order
+			}
+		}
+		column.Order = maxOrder + 1
+	} else {
+		// Geser kolom lain agar order tetap unik
+		shiftOpts := options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"col.order": bson.M{"$gte": column.Order}}},
+		})
+		_, err := r.collection.UpdateOne(ctx, bson.M{"_id": databaseID}, bson.M{
+			"$inc": bson.M{"databaseData.columns.$[col].order": 1},
+		}, shiftOpts)
+		if err != nil {
+			utils.LogError(err, "Gagal menggeser urutan kolom database: %s", databaseID.Hex())
+			return nil, 0, err
+		}
+	}
 
-	filter := bson.M{"_id": databaseID}
 	update := bson.M{
-		"$push": bson.M{"databaseData.rows": row},
+		"$push": bson.M{"databaseData.columns": column},
 		"$set":  bson.M{"updatedAt": time.Now()},
+		"$inc":  bson.M{"version": 1},
 	}
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var updatedDatabase model.Database
-	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDatabase)
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": databaseID}, update, opts).Decode(&updatedDatabase)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			utils.LogWarning("Database dengan ID %s tidak ditemukan untuk menambahkan baris", databaseID.Hex())
-			return nil, nil
+			return nil, 0, nil
 		}
-		utils.LogError(err, "Gagal menambahkan baris ke database: %s", databaseID.Hex())
+		return nil, 0, err
+	}
+
+	// Isi nilai default kolom baru pada baris yang sudah ada. Kolom formula tidak menyimpan nilai
+	// karena dihitung saat dibaca.
+	var backfilled int64
+	if column.Type != "formula" {
+		valueKey := "values." + column.ID.Hex()
+		result, err := r.rowCollection.UpdateMany(ctx,
+			bson.M{"databaseId": databaseID, valueKey: bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{valueKey: defaultValue}, "$inc": bson.M{"version": 1}},
+		)
+		if err != nil {
+			utils.LogError(err, "Gagal mengisi nilai default kolom %s pada baris database: %s", column.ID.Hex(), databaseID.Hex())
+			return nil, 0, err
+		}
+		backfilled = result.ModifiedCount
+	}
+	return &updatedDatabase, backfilled, nil
+}
+
+// ReorderColumnsInDatabase menulis ulang Order setiap kolom sesuai urutan columnIDs (dimulai dari 1)
+// dalam satu operasi update. columnIDs harus berisi tepat seluruh kolom yang ada; jika kolom berubah
+// secara bersamaan (ditambah atau dihapus), update tidak diterapkan dan ErrDatabaseColumnsChanged dikembalikan.
+func (r *databaseRepositoryImpl) ReorderColumnsInDatabase(ctx context.Context, databaseID primitive.ObjectID, columnIDs []primitive.ObjectID) (*model.Database, error) {
+	setMap := bson.M{"updatedAt": time.Now()}
+	arrayFilters := make([]interface{}, 0, len(columnIDs))
+	for i, columnID := range columnIDs {
+		identifier := fmt.Sprintf("c%d", i)
+		setMap["databaseData.columns.$["+identifier+"].order"] = i + 1
+		arrayFilters = append(arrayFilters, bson.M{identifier + "._id": columnID})
+	}
+
+	filter := bson.M{
+		"_id":                      databaseID,
+		"databaseData.columns._id": bson.M{"$all": columnIDs},
+		"databaseData.columns":     bson.M{"$size": len(columnIDs)},
+	}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+	var updatedDatabase model.Database
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": setMap, "$inc": bson.M{"version": 1}}, opts).Decode(&updatedDatabase)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			count, countErr := r.collection.CountDocuments(ctx, bson.M{"_id": databaseID})
+			if countErr == nil && count == 0 {
+				utils.LogWarning("Database dengan ID %s tidak ditemukan untuk reorder kolom", databaseID.Hex())
+				return nil, nil
+			}
+			utils.LogWarning("Kolom pada database %s berubah saat reorder", databaseID.Hex())
+			return nil, ErrDatabaseColumnsChanged
+		}
+		utils.LogError(err, "Gagal menyusun ulang kolom database: %s", databaseID.Hex())
 		return nil, err
 	}
-	utils.LogInfo("Berhasil menambahkan baris ke database ID: %s. Row ID: %s", databaseID.Hex(), row.ID.Hex())
+	if err := r.populateRows(ctx, &updatedDatabase); err != nil {
+		return nil, err
+	}
+	utils.LogInfo("Berhasil menyusun ulang %d kolom pada database ID: %s", len(columnIDs), databaseID.Hex())
 	return &updatedDatabase, nil
 }
 
 // UpdateColumnInDatabase memperbarui sebuah kolom tertentu dalam dokumen database.
-func (r *databaseRepositoryImpl) UpdateColumnInDatabase(ctx context.Context, databaseID, columnID primitive.ObjectID, updateData bson.M) (*model.Database, error) {
-	filter := bson.M{"_id": databaseID, "databaseData.columns._id": columnID}
+func (r *databaseRepositoryImpl) UpdateColumnInDatabase(ctx context.Context, databaseID, columnID primitive.ObjectID, updateData bson.M, expectedVersion int64) (*model.Database, error) {
+	filter := versionFilter(bson.M{"_id": databaseID, "databaseData.columns._id": columnID}, expectedVersion)
 
 	// Pastikan updateData memiliki $set
 	setMap, ok := updateData["$set"].(bson.M)
@@ -177,6 +501,7 @@ func (r *databaseRepositoryImpl) UpdateColumnInDatabase(ctx context.Context, dat
 
 	// Tambahkan updatedAt ke map yang ada
 	setMap["updatedAt"] = time.Now()
+	updateData["$inc"] = bson.M{"version": 1}
 
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var updatedDatabase model.Database
@@ -184,6 +509,10 @@ func (r *databaseRepositoryImpl) UpdateColumnInDatabase(ctx context.Context, dat
 	err := r.collection.FindOneAndUpdate(ctx, filter, updateData, opts).Decode(&updatedDatabase)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
+			if expectedVersion > 0 && r.documentExists(ctx, r.collection, bson.M{"_id": databaseID, "databaseData.columns._id": columnID}) {
+				utils.LogWarning("Database dengan ID %s sudah berubah dari versi %d", databaseID.Hex(), expectedVersion)
+				return nil, ErrVersionMismatch
+			}
 			utils.LogWarning("Database dengan ID %s atau Kolom ID %s tidak ditemukan untuk diperbarui", databaseID.Hex(), columnID.Hex())
 			return nil, nil
 		}
@@ -191,10 +520,14 @@ func (r *databaseRepositoryImpl) UpdateColumnInDatabase(ctx context.Context, dat
 		return nil, err
 	}
 	utils.LogInfo("Berhasil memperbarui kolom ID: %s di database ID: %s", columnID.Hex(), databaseID.Hex())
+	if err := r.populateRows(ctx, &updatedDatabase); err != nil {
+		return nil, err
+	}
 	return &updatedDatabase, nil
 }
 
-// DeleteColumnFromDatabase menghapus kolom tertentu dari array Columns dalam dokumen database.
+// DeleteColumnFromDatabase menghapus kolom tertentu dari array Columns dalam dokumen database,
+// sekaligus menghapus nilai kolom tersebut dari seluruh baris.
 func (r *databaseRepositoryImpl) DeleteColumnFromDatabase(ctx context.Context, databaseID, columnID primitive.ObjectID) (*model.Database, error) {
 	filter := bson.M{"_id": databaseID}
 	update := bson.M{
@@ -202,6 +535,7 @@ func (r *databaseRepositoryImpl) DeleteColumnFromDatabase(ctx context.Context, d
 			"databaseData.columns": bson.M{"_id": columnID},
 		},
 		"$set": bson.M{"updatedAt": time.Now()},
+		"$inc": bson.M{"version": 1},
 	}
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var updatedDatabase model.Database
@@ -214,6 +548,21 @@ func (r *databaseRepositoryImpl) DeleteColumnFromDatabase(ctx context.Context, d
 		utils.LogError(err, "Gagal menghapus kolom dari database: %s, Kolom ID: %s", databaseID.Hex(), columnID.Hex())
 		return nil, err
 	}
+
+	_, err = r.rowCollection.UpdateMany(ctx,
+		bson.M{"databaseId": databaseID, "values." + columnID.Hex(): bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"values." + columnID.Hex(): ""}, "$inc": bson.M{"version": 1}},
+	)
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus nilai kolom %s dari baris database: %s", columnID.Hex(), databaseID.Hex())
+		return nil, err
+	}
+	if err := r.removeViewColumnReferences(ctx, databaseID, columnID); err != nil {
+		return nil, err
+	}
+	if err := r.populateRows(ctx, &updatedDatabase); err != nil {
+		return nil, err
+	}
 	utils.LogInfo("Berhasil menghapus kolom ID: %s dari database ID: %s", columnID.Hex(), databaseID.Hex())
 	return &updatedDatabase, nil
 }
@@ -227,6 +576,7 @@ func (r *databaseRepositoryImpl) AddSelectOptionToColumn(ctx context.Context, da
 	update := bson.M{
 		"$push": bson.M{"databaseData.columns.$.options": option}, // Menambahkan opsi ke kolom yang cocok
 		"$set":  bson.M{"updatedAt": time.Now()},
+		"$inc":  bson.M{"version": 1},
 	}
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var updatedDatabase model.Database
@@ -240,6 +590,9 @@ func (r *databaseRepositoryImpl) AddSelectOptionToColumn(ctx context.Context, da
 		return nil, err
 	}
 	utils.LogInfo("Berhasil menambahkan opsi select ke kolom ID: %s di database ID: %s", columnID.Hex(), databaseID.Hex())
+	if err := r.populateRows(ctx, &updatedDatabase); err != nil {
+		return nil, err
+	}
 	return &updatedDatabase, nil
 }
 
@@ -263,6 +616,7 @@ func (r *databaseRepositoryImpl) UpdateSelectOptionInColumn(ctx context.Context,
 			"databaseData.columns.$[col].options.$[opt].order": updateData["$set"].(bson.M)["order"],
 			"updatedAt": time.Now(),
 		},
+		"$inc": bson.M{"version": 1},
 	}
 	// ArrayFilters untuk mengidentifikasi kolom dan opsi yang akan diperbarui
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetArrayFilters(options.ArrayFilters{
@@ -283,6 +637,9 @@ func (r *databaseRepositoryImpl) UpdateSelectOptionInColumn(ctx context.Context,
 		return nil, err
 	}
 	utils.LogInfo("Berhasil memperbarui opsi select ID: %s di kolom ID: %s dalam database ID: %s", optionID.Hex(), columnID.Hex(), databaseID.Hex())
+	if err := r.populateRows(ctx, &updatedDatabase); err != nil {
+		return nil, err
+	}
 	return &updatedDatabase, nil
 }
 
@@ -301,6 +658,22 @@ func (r *databaseRepositoryImpl) DeleteSelectOptionFromColumn(ctx context.Contex
 		"$pull": bson.M{
 			"databaseData.columns.$.options": bson.M{"_id": optionID},
 		},
+		"$set": bson.M{"updatedAt": time.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+
+	// Default value kolom yang merujuk opsi ini ikut dihapus agar baris baru tetap valid
+	defaultFilter := bson.M{
+		"_id": databaseID,
+		"databaseData.columns": bson.M{
+			"$elemMatch": bson.M{
+				"_id":                      columnID,
+				"constraints.defaultValue": optionID.Hex(),
+			},
+		},
+	}
+	if _, err := r.collection.UpdateOne(ctx, defaultFilter, bson.M{"$unset": bson.M{"databaseData.columns.$.constraints.defaultValue": ""}}); err != nil {
+		return nil, fmt.Errorf("gagal menghapus default value kolom: %w", err)
 	}
 
 	var updatedDatabase model.Database
@@ -308,6 +681,9 @@ func (r *databaseRepositoryImpl) DeleteSelectOptionFromColumn(ctx context.Contex
 	if err != nil {
 		return nil, fmt.Errorf("gagal menghapus select option dari kolom: %w", err)
 	}
+	if err := r.populateRows(ctx, &updatedDatabase); err != nil {
+		return nil, err
+	}
 
 	return &updatedDatabase, nil
 }
@@ -383,16 +759,12 @@ func (repository *databaseRepositoryImpl) GetSelectOptionInColumn(ctx context.Co
 
 func (repository *databaseRepositoryImpl) GetRowInDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID) (*model.DatabaseRow, error) {
 	filter := bson.M{
-		"_id":                   databaseID,
-		"databaseData.rows._id": rowID,
+		"_id":        rowID,
+		"databaseId": databaseID,
 	}
 
-	projection := bson.M{
-		"databaseData.rows.$": 1,
-	}
-
-	var database model.Database
-	err := repository.collection.FindOne(ctx, filter, options.FindOne().SetProjection(projection)).Decode(&database)
+	var row model.DatabaseRow
+	err := repository.rowCollection.FindOne(ctx, filter).Decode(&row)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, fmt.Errorf("baris tidak ditemukan dengan ID %s di database ID %s atau database tidak ditemukan", rowID.Hex(), databaseID.Hex())
@@ -400,79 +772,479 @@ func (repository *databaseRepositoryImpl) GetRowInDatabase(ctx context.Context,
 		return nil, fmt.Errorf("gagal mengambil baris dari database: %w", err)
 	}
 
-	if len(database.DatabaseData.Rows) == 0 {
-		return nil, fmt.Errorf("baris tidak ditemukan dengan ID %s di database ID %s", rowID.Hex(), databaseID.Hex())
+	return &row, nil
+}
+
+func (repository *databaseRepositoryImpl) GetRowsByDatabaseID(ctx context.Context, databaseID primitive.ObjectID) ([]model.DatabaseRow, error) {
+	count, err := repository.collection.CountDocuments(ctx, bson.M{"_id": databaseID})
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil baris berdasarkan ID database: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("database tidak ditemukan dengan ID %s", databaseID.Hex())
 	}
 
-	return &database.DatabaseData.Rows[0], nil
+	rows, err := repository.findRows(ctx, bson.M{"databaseId": databaseID})
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil baris berdasarkan ID database: %w", err)
+	}
+	return rows, nil
 }
 
-func (repository *databaseRepositoryImpl) GetRowsByDatabaseID(ctx context.Context, databaseID primitive.ObjectID) ([]model.DatabaseRow, error) {
-	filter := bson.M{
-		"_id": databaseID,
+// UpdateRowInDatabase memperbarui nilai-nilai dari baris tertentu milik database.
+func (r *databaseRepositoryImpl) UpdateRowInDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID, updatedValues model.DatabaseRowValue, authorID primitive.ObjectID, expectedVersion int64) (*model.Database, error) {
+	filter := versionFilter(bson.M{"_id": rowID, "databaseId": databaseID}, expectedVersion)
+	update := bson.M{
+		"$set": bson.M{
+			"values":    updatedValues,
+			"updatedAt": time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	// Dokumen sebelum diperbarui dipakai untuk mencatat diff revisi
+	var previous model.DatabaseRow
+	err := r.rowCollection.FindOneAndUpdate(ctx, filter, update).Decode(&previous)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if expectedVersion > 0 && r.documentExists(ctx, r.rowCollection, bson.M{"_id": rowID, "databaseId": databaseID}) {
+				utils.LogWarning("Baris ID %s di database %s sudah berubah dari versi %d", rowID.Hex(), databaseID.Hex(), expectedVersion)
+				return nil, ErrVersionMismatch
+			}
+			utils.LogWarning("Database dengan ID %s atau Baris ID %s tidak ditemukan untuk diperbarui", databaseID.Hex(), rowID.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal memperbarui baris dalam database: %s, Row ID: %s", databaseID.Hex(), rowID.Hex())
+		return nil, err
+	}
+	if revision := newRowRevision(databaseID, rowID, model.RowRevisionUpdate, authorID, previous.Values, updatedValues); len(revision.Changes) > 0 {
+		if err := r.recordRevisions(ctx, []model.DatabaseRowRevision{revision}); err != nil {
+			utils.LogError(err, "Baris %s di database %s diperbarui tanpa revisi", rowID.Hex(), databaseID.Hex())
+		}
 	}
 
-	projection := bson.M{
-		"databaseData.rows": 1,
+	updatedDatabase, err := r.touchDatabase(ctx, databaseID)
+	if err != nil {
+		utils.LogError(err, "Gagal memperbarui baris dalam database: %s, Row ID: %s", databaseID.Hex(), rowID.Hex())
+		return nil, err
+	}
+	if updatedDatabase == nil {
+		utils.LogWarning("Database dengan ID %s tidak ditemukan untuk diperbarui", databaseID.Hex())
+		return nil, nil
 	}
+	if err := r.populateRowsByIDs(ctx, updatedDatabase, []primitive.ObjectID{rowID}); err != nil {
+		return nil, err
+	}
+	utils.LogInfo("Berhasil memperbarui baris ID: %s di database ID: %s", rowID.Hex(), databaseID.Hex())
+	return updatedDatabase, nil
+}
 
-	var database model.Database
-	err := repository.collection.FindOne(ctx, filter, options.FindOne().SetProjection(projection)).Decode(&database)
+// DeleteRowFromDatabase menghapus baris tertentu milik database. Mengembalikan mongo.ErrNoDocuments jika
+// baris tidak ditemukan, atau nil, nil jika database tidak ditemukan.
+func (r *databaseRepositoryImpl) DeleteRowFromDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID, authorID primitive.ObjectID) (*model.Database, error) {
+	var deletedRow model.DatabaseRow
+	err := r.rowCollection.FindOneAndDelete(ctx, bson.M{"_id": rowID, "databaseId": databaseID}).Decode(&deletedRow)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, fmt.Errorf("database tidak ditemukan dengan ID %s", databaseID.Hex())
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Baris ID %s tidak ditemukan di database %s untuk dihapus", rowID.Hex(), databaseID.Hex())
+			return nil, mongo.ErrNoDocuments
 		}
-		return nil, fmt.Errorf("gagal mengambil baris berdasarkan ID database: %w", err)
+		utils.LogError(err, "Gagal menghapus baris dari database: %s, Row ID: %s", databaseID.Hex(), rowID.Hex())
+		return nil, err
+	}
+	revision := newRowRevision(databaseID, rowID, model.RowRevisionDelete, authorID, deletedRow.Values, nil)
+	if err := r.recordRevisions(ctx, []model.DatabaseRowRevision{revision}); err != nil {
+		utils.LogError(err, "Baris %s dihapus dari database %s tanpa revisi", rowID.Hex(), databaseID.Hex())
+	}
+	if err := r.removeRelationReferences(ctx, databaseID, []string{rowID.Hex()}); err != nil {
+		return nil, err
 	}
 
-	return database.DatabaseData.Rows, nil
+	// Database hanya ditandai berubah setelah baris benar-benar terhapus
+	updatedDatabase, err := r.touchDatabase(ctx, databaseID)
+	if err != nil {
+		utils.LogError(err, "Gagal memperbarui database setelah menghapus baris: %s, Row ID: %s", databaseID.Hex(), rowID.Hex())
+		return nil, err
+	}
+	if updatedDatabase == nil {
+		utils.LogWarning("Database dengan ID %s tidak ditemukan setelah menghapus baris %s", databaseID.Hex(), rowID.Hex())
+		return nil, nil
+	}
+	updatedDatabase.DatabaseData.Rows = []model.DatabaseRow{}
+	utils.LogInfo("Berhasil menghapus baris ID: %s dari database ID: %s", rowID.Hex(), databaseID.Hex())
+	return updatedDatabase, nil
+}
+
+// ReplaceRowsInDatabase mengganti seluruh baris milik database dengan baris yang diberikan.
+// Baris dengan ID yang sudah ada dipertahankan ID-nya, baris tanpa ID mendapat ID baru.
+func (r *databaseRepositoryImpl) ReplaceRowsInDatabase(ctx context.Context, databaseID primitive.ObjectID, rows []model.DatabaseRow, authorID primitive.ObjectID) (*model.Database, error) {
+	// ID baris baru ditentukan sebelum transaksi agar tetap sama jika driver mengulang transaksi
+	for i := range rows {
+		if rows[i].ID.IsZero() {
+			rows[i].ID = primitive.NewObjectID()
+		}
+	}
+
+	session, err := r.client.StartSession()
+	if err != nil {
+		utils.LogError(err, "Gagal memulai sesi untuk mengganti baris database: %s", databaseID.Hex())
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return r.replaceRows(sc, databaseID, rows, authorID)
+	})
+	if err != nil {
+		utils.LogError(err, "Gagal mengganti baris database: %s", databaseID.Hex())
+		return nil, err
+	}
+	updatedDatabase, _ := result.(*model.Database)
+	if updatedDatabase == nil {
+		utils.LogWarning("Database dengan ID %s tidak ditemukan untuk mengganti baris", databaseID.Hex())
+		return nil, nil
+	}
+
+	if err := r.populateRows(ctx, updatedDatabase); err != nil {
+		return nil, err
+	}
+	utils.LogInfo("Berhasil mengganti baris database ID: %s. Total: %d", databaseID.Hex(), len(rows))
+	return updatedDatabase, nil
 }
 
-// UpdateRowInDatabase memperbarui nilai-nilai dari baris tertentu dalam dokumen database.
-func (r *databaseRepositoryImpl) UpdateRowInDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID, updatedValues model.DatabaseRowValue) (*model.Database, error) {
-	filter := bson.M{"_id": databaseID, "databaseData.rows._id": rowID}
-	// Gunakan $set dengan posisi array yang diidentifikasi oleh operator posisi $.
+// replaceRows adalah isi transaksi ReplaceRowsInDatabase. ctx harus berupa mongo.SessionContext.
+func (r *databaseRepositoryImpl) replaceRows(ctx context.Context, databaseID primitive.ObjectID, rows []model.DatabaseRow, authorID primitive.ObjectID) (*model.Database, error) {
+	updatedDatabase, err := r.touchDatabase(ctx, databaseID)
+	if err != nil || updatedDatabase == nil {
+		return nil, err
+	}
+
+	// Catat baris lama yang tidak ada lagi di daftar baru agar referensi relation ke baris tersebut dibersihkan
+	oldRows, err := r.findRows(ctx, bson.M{"databaseId": databaseID})
+	if err != nil {
+		return nil, err
+	}
+	keptIDs := make(map[primitive.ObjectID]bool, len(rows))
+	for _, row := range rows {
+		keptIDs[row.ID] = true
+	}
+	var removedIDs []string
+	var revisions []model.DatabaseRowRevision
+	oldValues := make(map[primitive.ObjectID]model.DatabaseRowValue, len(oldRows))
+	oldVersions := make(map[primitive.ObjectID]int64, len(oldRows))
+	for _, row := range oldRows {
+		oldValues[row.ID] = row.Values
+		oldVersions[row.ID] = row.Version
+		if !keptIDs[row.ID] {
+			removedIDs = append(removedIDs, row.ID.Hex())
+			revisions = append(revisions, newRowRevision(databaseID, row.ID, model.RowRevisionDelete, authorID, row.Values, nil))
+		}
+	}
+
+	// Baris yang dipertahankan melanjutkan version lamanya agar If-Match dari client tetap bermakna
+	for i := range rows {
+		if version, existed := oldVersions[rows[i].ID]; existed {
+			rows[i].Version = version + 1
+		}
+	}
+
+	if _, err := r.rowCollection.DeleteMany(ctx, bson.M{"databaseId": databaseID}); err != nil {
+		utils.LogError(err, "Gagal menghapus baris lama database: %s", databaseID.Hex())
+		return nil, err
+	}
+	if err := r.insertRows(ctx, databaseID, rows); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		previous, existed := oldValues[row.ID]
+		if !existed {
+			revisions = append(revisions, newRowRevision(databaseID, row.ID, model.RowRevisionCreate, authorID, nil, row.Values))
+			continue
+		}
+		if revision := newRowRevision(databaseID, row.ID, model.RowRevisionUpdate, authorID, previous, row.Values); len(revision.Changes) > 0 {
+			revisions = append(revisions, revision)
+		}
+	}
+	if err := r.recordRevisions(ctx, revisions); err != nil {
+		return nil, err
+	}
+	if len(removedIDs) > 0 {
+		if err := r.removeRelationReferences(ctx, databaseID, removedIDs); err != nil {
+			return nil, err
+		}
+	}
+	return updatedDatabase, nil
+}
+
+// GetRowsByIDs mengambil baris-baris milik database berdasarkan daftar ID. ID yang tidak ditemukan diabaikan.
+func (r *databaseRepositoryImpl) GetRowsByIDs(ctx context.Context, databaseID primitive.ObjectID, rowIDs []primitive.ObjectID) ([]model.DatabaseRow, error) {
+	if len(rowIDs) == 0 {
+		return []model.DatabaseRow{}, nil
+	}
+	return r.findRows(ctx, bson.M{"databaseId": databaseID, "_id": bson.M{"$in": rowIDs}})
+}
+
+// FindRowIDByValue mencari satu baris lain yang memakai nilai kolom yang sama.
+func (r *databaseRepositoryImpl) FindRowIDByValue(ctx context.Context, databaseID primitive.ObjectID, columnID string, value interface{}, excludeIDs []primitive.ObjectID) (primitive.ObjectID, error) {
+	filter := bson.M{"databaseId": databaseID, "values." + columnID: value}
+	if len(excludeIDs) > 0 {
+		filter["_id"] = bson.M{"$nin": excludeIDs}
+	}
+	var row struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	err := r.rowCollection.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&row)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return primitive.NilObjectID, nil
+		}
+		utils.LogError(err, "Gagal mencari nilai kolom %s di database: %s", columnID, databaseID.Hex())
+		return primitive.NilObjectID, err
+	}
+	return row.ID, nil
+}
+
+// removeRelationReferences menghapus ID baris target dari sel-sel kolom relation (di database mana pun)
+// yang merujuk targetDatabaseID. Jika rowIDs nil, semua referensi ke database target dikosongkan.
+func (r *databaseRepositoryImpl) removeRelationReferences(ctx context.Context, targetDatabaseID primitive.ObjectID, rowIDs []string) error {
+	cursor, err := r.collection.Find(ctx, bson.M{"databaseData.columns.relation.databaseId": targetDatabaseID})
+	if err != nil {
+		utils.LogError(err, "Gagal mencari kolom relation yang merujuk database: %s", targetDatabaseID.Hex())
+		return err
+	}
+	var sources []model.Database
+	if err := cursor.All(ctx, &sources); err != nil {
+		utils.LogError(err, "Gagal mendekode database yang merujuk database: %s", targetDatabaseID.Hex())
+		return err
+	}
+
+	for _, source := range sources {
+		for _, col := range source.DatabaseData.Columns {
+			if col.Type != "relation" || col.Relation == nil || col.Relation.DatabaseID != targetDatabaseID {
+				continue
+			}
+			valueKey := "values." + col.ID.Hex()
+			filter := bson.M{"databaseId": source.ID, valueKey: bson.M{"$exists": true}}
+			update := bson.M{"$set": bson.M{valueKey: bson.A{}}, "$inc": bson.M{"version": 1}}
+			if rowIDs != nil {
+				filter[valueKey] = bson.M{"$in": rowIDs}
+				update = bson.M{"$pull": bson.M{valueKey: bson.M{"$in": rowIDs}}, "$inc": bson.M{"version": 1}}
+			}
+			result, err := r.rowCollection.UpdateMany(ctx, filter, update)
+			if err != nil {
+				utils.LogError(err, "Gagal membersihkan referensi relation kolom %s di database: %s", col.ID.Hex(), source.ID.Hex())
+				return err
+			}
+			if result.ModifiedCount > 0 {
+				utils.LogInfo("Membersihkan referensi relation pada %d baris kolom %s di database: %s", result.ModifiedCount, col.ID.Hex(), source.ID.Hex())
+			}
+		}
+	}
+	return nil
+}
+
+// RemoveFileReferences menghapus ID file dari sel kolom file pada semua baris database, dipakai saat
+// file dihapus. Seperti pembersihan referensi relation, perubahan ini tidak dicatat sebagai revisi baris.
+func (r *databaseRepositoryImpl) RemoveFileReferences(ctx context.Context, databaseID, columnID primitive.ObjectID, fileID string) error {
+	valueKey := "values." + columnID.Hex()
+	result, err := r.rowCollection.UpdateMany(ctx,
+		bson.M{"databaseId": databaseID, valueKey: fileID},
+		bson.M{"$pull": bson.M{valueKey: fileID}, "$inc": bson.M{"version": 1}},
+	)
+	if err != nil {
+		utils.LogError(err, "Gagal membersihkan referensi file %s di database: %s", fileID, databaseID.Hex())
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		utils.LogInfo("Membersihkan referensi file %s pada %d baris database: %s", fileID, result.ModifiedCount, databaseID.Hex())
+	}
+	return nil
+}
+
+// AddViewToDatabase menambahkan view baru ke dokumen database. Mengembalikan database tanpa baris,
+// atau nil, nil jika database tidak ditemukan.
+func (r *databaseRepositoryImpl) AddViewToDatabase(ctx context.Context, databaseID primitive.ObjectID, view *model.DatabaseView) (*model.Database, error) {
+	now := time.Now()
+	view.ID = primitive.NewObjectID()
+	view.CreatedAt = now
+	view.UpdatedAt = now
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	update := bson.M{
-		"$set": bson.M{
-			"databaseData.rows.$.values": updatedValues, // Perbarui field values di baris yang cocok
-			"updatedAt":                  time.Now(),
-		},
+		"$push": bson.M{"views": view},
+		"$set":  bson.M{"updatedAt": now},
+		"$inc":  bson.M{"version": 1},
+	}
+	var updatedDatabase model.Database
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": databaseID}, update, opts).Decode(&updatedDatabase)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Database dengan ID %s tidak ditemukan untuk menambahkan view", databaseID.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal menambahkan view ke database: %s", databaseID.Hex())
+		return nil, err
 	}
+	utils.LogInfo("Berhasil menambahkan view ID: %s ke database ID: %s", view.ID.Hex(), databaseID.Hex())
+	return &updatedDatabase, nil
+}
+
+// UpdateViewInDatabase mengganti konfigurasi view dengan view baru (ID dan CreatedAt dipertahankan).
+// Mengembalikan database tanpa baris, atau nil, nil jika database atau view tidak ditemukan.
+func (r *databaseRepositoryImpl) UpdateViewInDatabase(ctx context.Context, databaseID, viewID primitive.ObjectID, view *model.DatabaseView) (*model.Database, error) {
+	now := time.Now()
+	view.ID = viewID
+	view.UpdatedAt = now
+
+	filter := bson.M{"_id": databaseID, "views._id": viewID}
+	update := bson.M{"$set": bson.M{"views.$": view, "updatedAt": now}, "$inc": bson.M{"version": 1}}
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var updatedDatabase model.Database
 	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDatabase)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			utils.LogWarning("Database dengan ID %s atau Baris ID %s tidak ditemukan untuk diperbarui", databaseID.Hex(), rowID.Hex())
+			utils.LogWarning("Database dengan ID %s atau View ID %s tidak ditemukan untuk diperbarui", databaseID.Hex(), viewID.Hex())
 			return nil, nil
 		}
-		utils.LogError(err, "Gagal memperbarui baris dalam database: %s, Row ID: %s", databaseID.Hex(), rowID.Hex())
+		utils.LogError(err, "Gagal memperbarui view dalam database: %s, View ID: %s", databaseID.Hex(), viewID.Hex())
 		return nil, err
 	}
-	utils.LogInfo("Berhasil memperbarui baris ID: %s di database ID: %s", rowID.Hex(), databaseID.Hex())
+	utils.LogInfo("Berhasil memperbarui view ID: %s di database ID: %s", viewID.Hex(), databaseID.Hex())
 	return &updatedDatabase, nil
 }
 
-// DeleteRowFromDatabase menghapus baris tertentu dari array Rows dalam dokumen database.
-func (r *databaseRepositoryImpl) DeleteRowFromDatabase(ctx context.Context, databaseID, rowID primitive.ObjectID) (*model.Database, error) {
-	filter := bson.M{"_id": databaseID}
+// DeleteViewFromDatabase menghapus view dari dokumen database. Mengembalikan database tanpa baris,
+// atau nil, nil jika database atau view tidak ditemukan.
+func (r *databaseRepositoryImpl) DeleteViewFromDatabase(ctx context.Context, databaseID, viewID primitive.ObjectID) (*model.Database, error) {
+	filter := bson.M{"_id": databaseID, "views._id": viewID}
 	update := bson.M{
-		"$pull": bson.M{
-			"databaseData.rows": bson.M{"_id": rowID},
-		},
-		"$set": bson.M{"updatedAt": time.Now()},
+		"$pull": bson.M{"views": bson.M{"_id": viewID}},
+		"$set":  bson.M{"updatedAt": time.Now()},
+		"$inc":  bson.M{"version": 1},
 	}
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var updatedDatabase model.Database
 	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDatabase)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			utils.LogWarning("Database dengan ID %s atau Baris ID %s tidak ditemukan untuk dihapus", databaseID.Hex(), rowID.Hex())
+			utils.LogWarning("Database dengan ID %s atau View ID %s tidak ditemukan untuk dihapus", databaseID.Hex(), viewID.Hex())
 			return nil, nil
 		}
-		utils.LogError(err, "Gagal menghapus baris dari database: %s, Row ID: %s", databaseID.Hex(), rowID.Hex())
+		utils.LogError(err, "Gagal menghapus view dari database: %s, View ID: %s", databaseID.Hex(), viewID.Hex())
 		return nil, err
 	}
-	utils.LogInfo("Berhasil menghapus baris ID: %s dari database ID: %s", rowID.Hex(), databaseID.Hex())
+	utils.LogInfo("Berhasil menghapus view ID: %s dari database ID: %s", viewID.Hex(), databaseID.Hex())
 	return &updatedDatabase, nil
 }
+
+// removeViewColumnReferences menghapus referensi kolom yang dihapus dari kolom terlihat, sort dan filter semua view.
+func (r *databaseRepositoryImpl) removeViewColumnReferences(ctx context.Context, databaseID, columnID primitive.ObjectID) error {
+	// Operator $[] membutuhkan field views, sehingga database tanpa view dilewati
+	filter := bson.M{"_id": databaseID, "views.0": bson.M{"$exists": true}}
+	update := bson.M{"$pull": bson.M{
+		"views.$[].visibleColumns":    columnID.Hex(),
+		"views.$[].sort":              bson.M{"columnId": columnID.Hex()},
+		"views.$[].filter.conditions": bson.M{"columnId": columnID.Hex()},
+	}}
+	if _, err := r.collection.UpdateOne(ctx, filter, update); err != nil {
+		utils.LogError(err, "Gagal menghapus referensi kolom %s dari view database: %s", columnID.Hex(), databaseID.Hex())
+		return err
+	}
+	return nil
+}
+
+// insertRows menyimpan baris-baris baru ke koleksi baris dengan databaseId dan timestamp.
+func (r *databaseRepositoryImpl) insertRows(ctx context.Context, databaseID primitive.ObjectID, rows []model.DatabaseRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	now := time.Now()
+	docs := make([]interface{}, len(rows))
+	for i := range rows {
+		if rows[i].ID.IsZero() {
+			rows[i].ID = primitive.NewObjectID()
+		}
+		if rows[i].Values == nil {
+			rows[i].Values = model.DatabaseRowValue{}
+		}
+		if rows[i].Version <= 0 {
+			rows[i].Version = 1
+		}
+		rows[i].DatabaseID = databaseID
+		rows[i].CreatedAt = now
+		rows[i].UpdatedAt = now
+		docs[i] = rows[i]
+	}
+	if _, err := r.rowCollection.InsertMany(ctx, docs); err != nil {
+		utils.LogError(err, "Gagal menyimpan baris untuk database: %s", databaseID.Hex())
+		return err
+	}
+	return nil
+}
+
+// findRows mengambil baris dari koleksi baris, diurutkan sesuai urutan pembuatan (_id).
+func (r *databaseRepositoryImpl) findRows(ctx context.Context, filter bson.M) ([]model.DatabaseRow, error) {
+	cursor, err := r.rowCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil baris database")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rows := make([]model.DatabaseRow, 0)
+	if err := cursor.All(ctx, &rows); err != nil {
+		utils.LogError(err, "Gagal mendekode baris database")
+		return nil, err
+	}
+	return rows, nil
+}
+
+// populateRows mengisi DatabaseData.Rows dari koleksi baris agar kontrak model.Database tetap sama.
+func (r *databaseRepositoryImpl) populateRows(ctx context.Context, database *model.Database) error {
+	rows, err := r.findRows(ctx, bson.M{"databaseId": database.ID})
+	if err != nil {
+		return err
+	}
+	database.DatabaseData.Rows = rows
+	return nil
+}
+
+// populateRowsByIDs mengisi DatabaseData.Rows hanya dengan baris rowIDs. Dipakai mutasi baris agar tidak
+// memuat seluruh baris database setelah setiap perubahan.
+func (r *databaseRepositoryImpl) populateRowsByIDs(ctx context.Context, database *model.Database, rowIDs []primitive.ObjectID) error {
+	rows, err := r.GetRowsByIDs(ctx, database.ID, rowIDs)
+	if err != nil {
+		return err
+	}
+	database.DatabaseData.Rows = rows
+	return nil
+}
+
+// touchDatabase memperbarui updatedAt database dan mengembalikan dokumen terbarunya (tanpa baris).
+// Mengembalikan nil, nil jika database tidak ditemukan.
+func (r *databaseRepositoryImpl) touchDatabase(ctx context.Context, databaseID primitive.ObjectID) (*model.Database, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var database model.Database
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": databaseID}, bson.M{"$set": bson.M{"updatedAt": time.Now()}}, opts).Decode(&database)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &database, nil
+}
+
+// versionFilter menambahkan syarat version ke filter jika expectedVersion > 0.
+func versionFilter(filter bson.M, expectedVersion int64) bson.M {
+	if expectedVersion > 0 {
+		filter["version"] = expectedVersion
+	}
+	return filter
+}
+
+// documentExists memeriksa apakah dokumen yang cocok dengan filter ada, dipakai untuk membedakan
+// dokumen yang tidak ditemukan dari update bersyarat yang gagal karena version berubah.
+func (r *databaseRepositoryImpl) documentExists(ctx context.Context, collection *mongo.Collection, filter bson.M) bool {
+	count, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	return err == nil && count > 0
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Operasi batch baris diterapkan dalam satu transaksi MongoDB: semua operasi berhasil atau tidak ada
+// yang tersimpan. Berbeda dengan operasi baris tunggal, revisi baris ditulis di dalam transaksi yang
+// sama sehingga kegagalan menulis revisi ikut membatalkan batch.
+//
+// Transaksi membutuhkan MongoDB replica set atau sharded cluster; pada server standalone
+// ApplyRowBatch mengembalikan error dari driver.
+
+// Jenis operasi pada RowBatchOperation.
+const (
+	RowBatchInsert = "insert"
+	RowBatchUpdate = "update"
+	RowBatchDelete = "delete"
+)
+
+// ErrRowNotFound dikembalikan (dibungkus RowBatchError) jika baris yang diubah atau dihapus tidak ada.
+var ErrRowNotFound = errors.New("baris tidak ditemukan")
+
+// RowBatchOperation adalah satu operasi dalam batch baris. Values harus sudah divalidasi terhadap
+// kolom database. Untuk insert, RowID diisi oleh ApplyRowBatch.
+type RowBatchOperation struct {
+	Op     string
+	RowID  primitive.ObjectID
+	Values model.DatabaseRowValue
+}
+
+// RowBatchError menunjukkan operasi batch yang gagal diterapkan; seluruh batch dibatalkan.
+type RowBatchError struct {
+	Index int
+	Err   error
+}
+
+// Error mengimplementasikan interface error.
+func (e *RowBatchError) Error() string {
+	return fmt.Sprintf("operasi %d: %v", e.Index, e.Err)
+}
+
+// Unwrap mengembalikan error penyebab.
+func (e *RowBatchError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyRowBatch menerapkan operasi insert, update dan delete secara berurutan dalam satu transaksi.
+// Mengembalikan database beserta baris yang ditambah atau diubah batch, nil, nil jika database tidak
+// ditemukan, atau *RowBatchError jika salah satu operasi gagal.
+func (r *databaseRepositoryImpl) ApplyRowBatch(ctx context.Context, databaseID primitive.ObjectID, ops []RowBatchOperation, authorID primitive.ObjectID) (*model.Database, error) {
+	// ID baris baru ditentukan sebelum transaksi agar tetap sama jika driver mengulang transaksi
+	for i := range ops {
+		if ops[i].Op == RowBatchInsert {
+			ops[i].RowID = primitive.NewObjectID()
+		}
+	}
+
+	session, err := r.client.StartSession()
+	if err != nil {
+		utils.LogError(err, "Gagal memulai sesi untuk batch baris database: %s", databaseID.Hex())
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return r.applyRowBatch(sc, databaseID, ops, authorID)
+	})
+	if err != nil {
+		var batchErr *RowBatchError
+		if errors.As(err, &batchErr) {
+			utils.LogWarning("Batch baris database %s dibatalkan: %v", databaseID.Hex(), batchErr)
+		} else {
+			utils.LogError(err, "Gagal menerapkan batch baris database: %s", databaseID.Hex())
+		}
+		return nil, err
+	}
+	updatedDatabase, _ := result.(*model.Database)
+	if updatedDatabase == nil {
+		utils.LogWarning("Database dengan ID %s tidak ditemukan untuk batch baris", databaseID.Hex())
+		return nil, nil
+	}
+
+	var changedRowIDs []primitive.ObjectID
+	for _, op := range ops {
+		if op.Op != RowBatchDelete {
+			changedRowIDs = append(changedRowIDs, op.RowID)
+		}
+	}
+	if err := r.populateRowsByIDs(ctx, updatedDatabase, changedRowIDs); err != nil {
+		return nil, err
+	}
+	utils.LogInfo("Berhasil menerapkan %d operasi batch baris di database ID: %s", len(ops), databaseID.Hex())
+	return updatedDatabase, nil
+}
+
+// applyRowBatch adalah isi transaksi ApplyRowBatch. ctx harus berupa mongo.SessionContext.
+func (r *databaseRepositoryImpl) applyRowBatch(ctx context.Context, databaseID primitive.ObjectID, ops []RowBatchOperation, authorID primitive.ObjectID) (*model.Database, error) {
+	updatedDatabase, err := r.touchDatabase(ctx, databaseID)
+	if err != nil || updatedDatabase == nil {
+		return nil, err
+	}
+
+	filter := func(rowID primitive.ObjectID) bson.M {
+		return bson.M{"_id": rowID, "databaseId": databaseID}
+	}
+	var newRows []model.DatabaseRow
+	var removedIDs []string
+	var revisions []model.DatabaseRowRevision
+	for i, op := range ops {
+		switch op.Op {
+		case RowBatchInsert:
+			newRows = append(newRows, model.DatabaseRow{ID: op.RowID, Values: op.Values})
+			revisions = append(revisions, newRowRevision(databaseID, op.RowID, model.RowRevisionCreate, authorID, nil, op.Values))
+
+		case RowBatchUpdate:
+			var previous model.DatabaseRow
+			update := bson.M{"$set": bson.M{"values": op.Values, "updatedAt": time.Now()}, "$inc": bson.M{"version": 1}}
+			if err := r.rowCollection.FindOneAndUpdate(ctx, filter(op.RowID), update).Decode(&previous); err != nil {
+				if err == mongo.ErrNoDocuments {
+					return nil, &RowBatchError{Index: i, Err: ErrRowNotFound}
+				}
+				return nil, err
+			}
+			if revision := newRowRevision(databaseID, op.RowID, model.RowRevisionUpdate, authorID, previous.Values, op.Values); len(revision.Changes) > 0 {
+				revisions = append(revisions, revision)
+			}
+
+		case RowBatchDelete:
+			var deleted model.DatabaseRow
+			if err := r.rowCollection.FindOneAndDelete(ctx, filter(op.RowID)).Decode(&deleted); err != nil {
+				if err == mongo.ErrNoDocuments {
+					return nil, &RowBatchError{Index: i, Err: ErrRowNotFound}
+				}
+				return nil, err
+			}
+			removedIDs = append(removedIDs, op.RowID.Hex())
+			revisions = append(revisions, newRowRevision(databaseID, op.RowID, model.RowRevisionDelete, authorID, deleted.Values, nil))
+
+		default:
+			return nil, &RowBatchError{Index: i, Err: fmt.Errorf("operasi '%s' tidak dikenal", op.Op)}
+		}
+	}
+
+	if err := r.insertRows(ctx, databaseID, newRows); err != nil {
+		return nil, err
+	}
+	if err := r.recordRevisions(ctx, revisions); err != nil {
+		return nil, err
+	}
+	if len(removedIDs) > 0 {
+		if err := r.removeRelationReferences(ctx, databaseID, removedIDs); err != nil {
+			return nil, err
+		}
+	}
+	return updatedDatabase, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RowPageQuery adalah query satu halaman baris yang sudah diterjemahkan ke MongoDB oleh
+// service.BuildRowPageQuery. Match dan AddFields diterapkan sebelum total dihitung, sedangkan
+// After (posisi cursor), Sort, Skip dan Limit hanya membentuk halaman.
+type RowPageQuery struct {
+	Match     bson.M
+	AddFields bson.M
+	After     bson.M
+	Sort      bson.D
+	Skip      int64
+	Limit     int64
+}
+
+// rowQueryCollation membuat perbandingan dan pengurutan teks tidak peka huruf besar/kecil,
+// sama seperti filter dan sort baris di memori.
+var rowQueryCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// QueryRowPage mengambil satu halaman baris beserta jumlah semua baris yang cocok dengan Match
+// dalam satu aggregation ($facet).
+func (r *databaseRepositoryImpl) QueryRowPage(ctx context.Context, databaseID primitive.ObjectID, query RowPageQuery) ([]model.DatabaseRow, int64, error) {
+	match := bson.M{"databaseId": databaseID}
+	if len(query.Match) > 0 {
+		match = bson.M{"$and": bson.A{match, query.Match}}
+	}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+	if len(query.AddFields) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: query.AddFields}})
+	}
+
+	page := bson.A{}
+	if len(query.After) > 0 {
+		page = append(page, bson.M{"$match": query.After})
+	}
+	page = append(page, bson.M{"$sort": query.Sort})
+	if query.Skip > 0 {
+		page = append(page, bson.M{"$skip": query.Skip})
+	}
+	if query.Limit > 0 {
+		page = append(page, bson.M{"$limit": query.Limit})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: bson.M{
+		"rows":  page,
+		"total": bson.A{bson.M{"$count": "count"}},
+	}}})
+
+	opts := options.Aggregate().SetCollation(rowQueryCollation).SetAllowDiskUse(true)
+	cursor, err := r.rowCollection.Aggregate(ctx, pipeline, opts)
+	if err != nil {
+		utils.LogError(err, "Gagal menjalankan query baris database %s", databaseID.Hex())
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Rows  []model.DatabaseRow `bson:"rows"`
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		utils.LogError(err, "Gagal mendekode hasil query baris database %s", databaseID.Hex())
+		return nil, 0, err
+	}
+
+	rows := make([]model.DatabaseRow, 0)
+	var total int64
+	if len(results) > 0 {
+		if results[0].Rows != nil {
+			rows = results[0].Rows
+		}
+		if len(results[0].Total) > 0 {
+			total = results[0].Total[0].Count
+		}
+	}
+	return rows, total, nil
+}
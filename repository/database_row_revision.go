@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Setiap perubahan baris (tambah, ubah, hapus, ganti massal, pulihkan) dicatat sebagai DatabaseRowRevision
+// di koleksi "DatabaseRowRevisions". Perubahan skema (nilai default kolom baru, penghapusan kolom) dan
+// pembersihan referensi relation otomatis tidak dicatat karena bukan perubahan yang dilakukan pada baris.
+//
+// Revisi ditulis setelah perubahan baris berhasil. Pada operasi yang berjalan dalam transaksi (ganti massal,
+// batch) kegagalan menulis revisi membatalkan seluruh transaksi. Pada operasi lain kegagalan hanya dicatat
+// di log agar perubahan yang sudah tersimpan tidak dilaporkan sebagai gagal.
+
+// GetRowRevisions mengambil revisi sebuah baris (termasuk baris yang sudah dihapus), terbaru lebih dulu.
+// limit <= 0 berarti tanpa batas.
+func (r *databaseRepositoryImpl) GetRowRevisions(ctx context.Context, databaseID, rowID primitive.ObjectID, limit int) ([]model.DatabaseRowRevision, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := r.revisionCollection.Find(ctx, bson.M{"databaseId": databaseID, "rowId": rowID}, opts)
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil revisi baris %s pada database: %s", rowID.Hex(), databaseID.Hex())
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := make([]model.DatabaseRowRevision, 0)
+	if err := cursor.All(ctx, &revisions); err != nil {
+		utils.LogError(err, "Gagal mendekode revisi baris %s pada database: %s", rowID.Hex(), databaseID.Hex())
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetRowRevision mengambil satu revisi milik database. Mengembalikan nil, nil jika tidak ditemukan.
+func (r *databaseRepositoryImpl) GetRowRevision(ctx context.Context, databaseID, revisionID primitive.ObjectID) (*model.DatabaseRowRevision, error) {
+	var revision model.DatabaseRowRevision
+	err := r.revisionCollection.FindOne(ctx, bson.M{"_id": revisionID, "databaseId": databaseID}).Decode(&revision)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil revisi %s pada database: %s", revisionID.Hex(), databaseID.Hex())
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetDeletedRows mengambil revisi "delete" terakhir dari setiap baris database yang saat ini sudah tidak ada,
+// terbaru lebih dulu. Values setiap revisi berisi isi baris sesaat sebelum dihapus.
+func (r *databaseRepositoryImpl) GetDeletedRows(ctx context.Context, databaseID primitive.ObjectID) ([]model.DatabaseRowRevision, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"databaseId": databaseID, "action": model.RowRevisionDelete}}},
+		{{Key: "$sort", Value: bson.M{"_id": -1}}},
+		{{Key: "$group", Value: bson.M{"_id": "$rowId", "revision": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$revision"}}},
+		{{Key: "$sort", Value: bson.M{"_id": -1}}},
+	}
+	cursor, err := r.revisionCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil baris terhapus pada database: %s", databaseID.Hex())
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var revisions []model.DatabaseRowRevision
+	if err := cursor.All(ctx, &revisions); err != nil {
+		utils.LogError(err, "Gagal mendekode baris terhapus pada database: %s", databaseID.Hex())
+		return nil, err
+	}
+
+	// Baris yang sudah dipulihkan atau dibuat ulang dengan ID yang sama tidak termasuk baris terhapus
+	rowIDs := make([]primitive.ObjectID, len(revisions))
+	for i, revision := range revisions {
+		rowIDs[i] = revision.RowID
+	}
+	existingRows, err := r.GetRowsByIDs(ctx, databaseID, rowIDs)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[primitive.ObjectID]bool, len(existingRows))
+	for _, row := range existingRows {
+		existing[row.ID] = true
+	}
+	deleted := make([]model.DatabaseRowRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		if !existing[revision.RowID] {
+			deleted = append(deleted, revision)
+		}
+	}
+	return deleted, nil
+}
+
+// RestoreRow mengembalikan isi baris ke values (nilai revisi yang sudah divalidasi terhadap kolom saat ini).
+// Baris yang sudah dihapus dibuat kembali dengan ID yang sama. Perubahan dicatat sebagai revisi "restore".
+// Database yang dikembalikan hanya memuat baris tersebut. Mengembalikan nil, nil jika database tidak ditemukan.
+func (r *databaseRepositoryImpl) RestoreRow(ctx context.Context, databaseID, rowID primitive.ObjectID, values model.DatabaseRowValue, revisionID, authorID primitive.ObjectID) (*model.Database, error) {
+	updatedDatabase, err := r.touchDatabase(ctx, databaseID)
+	if err != nil {
+		utils.LogError(err, "Gagal memulihkan baris %s pada database: %s", rowID.Hex(), databaseID.Hex())
+		return nil, err
+	}
+	if updatedDatabase == nil {
+		utils.LogWarning("Database dengan ID %s tidak ditemukan untuk memulihkan baris", databaseID.Hex())
+		return nil, nil
+	}
+
+	var oldValues model.DatabaseRowValue
+	var current model.DatabaseRow
+	err = r.rowCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": rowID, "databaseId": databaseID},
+		bson.M{"$set": bson.M{"values": values, "updatedAt": time.Now()}, "$inc": bson.M{"version": 1}},
+	).Decode(&current)
+	switch {
+	case err == nil:
+		oldValues = current.Values
+	case err == mongo.ErrNoDocuments:
+		if err := r.insertRows(ctx, databaseID, []model.DatabaseRow{{ID: rowID, Values: values}}); err != nil {
+			return nil, err
+		}
+	default:
+		utils.LogError(err, "Gagal memulihkan baris %s pada database: %s", rowID.Hex(), databaseID.Hex())
+		return nil, err
+	}
+
+	revision := newRowRevision(databaseID, rowID, model.RowRevisionRestore, authorID, oldValues, values)
+	revision.RestoredFrom = revisionID
+	if err := r.recordRevisions(ctx, []model.DatabaseRowRevision{revision}); err != nil {
+		utils.LogError(err, "Baris %s di database %s dipulihkan tanpa revisi", rowID.Hex(), databaseID.Hex())
+	}
+
+	if err := r.populateRowsByIDs(ctx, updatedDatabase, []primitive.ObjectID{rowID}); err != nil {
+		return nil, err
+	}
+	utils.LogInfo("Berhasil memulihkan baris ID: %s di database ID: %s ke revisi %s", rowID.Hex(), databaseID.Hex(), revisionID.Hex())
+	return updatedDatabase, nil
+}
+
+// recordRevisions menyimpan revisi baris. Di dalam transaksi error-nya harus dikembalikan agar transaksi
+// dibatalkan; di luar transaksi pemanggil cukup mencatatnya di log (lihat komentar di awal file).
+func (r *databaseRepositoryImpl) recordRevisions(ctx context.Context, revisions []model.DatabaseRowRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(revisions))
+	for i := range revisions {
+		docs[i] = revisions[i]
+	}
+	if _, err := r.revisionCollection.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("gagal mencatat %d revisi baris untuk database %s: %w", len(revisions), revisions[0].DatabaseID.Hex(), err)
+	}
+	return nil
+}
+
+// newRowRevision membuat revisi dengan diff antara oldValues dan newValues. Untuk aksi "delete",
+// Values revisi adalah oldValues (isi baris sebelum dihapus).
+func newRowRevision(databaseID, rowID primitive.ObjectID, action string, authorID primitive.ObjectID, oldValues, newValues model.DatabaseRowValue) model.DatabaseRowRevision {
+	values := newValues
+	if action == model.RowRevisionDelete {
+		values = oldValues
+		newValues = nil
+	}
+	if values == nil {
+		values = model.DatabaseRowValue{}
+	}
+	return model.DatabaseRowRevision{
+		ID:         primitive.NewObjectID(),
+		DatabaseID: databaseID,
+		RowID:      rowID,
+		Action:     action,
+		AuthorID:   authorID,
+		Values:     values,
+		Changes:    diffRowValues(oldValues, newValues),
+		CreatedAt:  time.Now(),
+	}
+}
+
+// createRevisions membuat revisi "create" untuk baris-baris yang baru disimpan.
+func createRevisions(databaseID, authorID primitive.ObjectID, rows []model.DatabaseRow) []model.DatabaseRowRevision {
+	revisions := make([]model.DatabaseRowRevision, len(rows))
+	for i, row := range rows {
+		revisions[i] = newRowRevision(databaseID, row.ID, model.RowRevisionCreate, authorID, nil, row.Values)
+	}
+	return revisions
+}
+
+// diffRowValues membandingkan nilai baris per kolom, terurut berdasarkan ID kolom. Kolom yang kosong (null)
+// dan kolom yang tidak ada dianggap sama.
+func diffRowValues(oldValues, newValues model.DatabaseRowValue) []model.DatabaseRowFieldChange {
+	columnIDs := make(map[string]bool, len(oldValues)+len(newValues))
+	for columnID := range oldValues {
+		columnIDs[columnID] = true
+	}
+	for columnID := range newValues {
+		columnIDs[columnID] = true
+	}
+	keys := make([]string, 0, len(columnIDs))
+	for columnID := range columnIDs {
+		keys = append(keys, columnID)
+	}
+	sort.Strings(keys)
+
+	changes := make([]model.DatabaseRowFieldChange, 0)
+	for _, columnID := range keys {
+		oldValue, newValue := oldValues[columnID], newValues[columnID]
+		if rowValuesEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, model.DatabaseRowFieldChange{ColumnID: columnID, OldValue: oldValue, NewValue: newValue})
+	}
+	return changes
+}
+
+// rowValuesEqual membandingkan dua nilai sel berdasarkan representasi BSON-nya, sehingga nilai hasil
+// decode (mis. primitive.DateTime, primitive.A) sama dengan nilai hasil validasi (time.Time, []string).
+func rowValuesEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	x, errX := bson.Marshal(bson.M{"v": a})
+	y, errY := bson.Marshal(bson.M{"v": b})
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
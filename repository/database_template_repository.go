@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DatabaseTemplateRepository adalah interface untuk operasi database entitas DatabaseTemplate.
+type DatabaseTemplateRepository interface {
+	CreateTemplate(ctx context.Context, template *model.DatabaseTemplate) error
+	GetTemplateByID(ctx context.Context, id primitive.ObjectID) (*model.DatabaseTemplate, error)
+	GetTemplatesByBusinessID(ctx context.Context, businessID primitive.ObjectID) ([]model.DatabaseTemplate, error)
+	DeleteTemplate(ctx context.Context, id primitive.ObjectID) error
+}
+
+// databaseTemplateRepositoryImpl adalah implementasi dari DatabaseTemplateRepository.
+type databaseTemplateRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewDatabaseTemplateRepository membuat instance baru dari DatabaseTemplateRepository.
+func NewDatabaseTemplateRepository(dbClient *mongo.Client) DatabaseTemplateRepository {
+	collection := config.GetCollection(dbClient, "DatabaseTemplates")
+	return &databaseTemplateRepositoryImpl{collection: collection}
+}
+
+// CreateTemplate menyimpan template database baru.
+func (r *databaseTemplateRepositoryImpl) CreateTemplate(ctx context.Context, template *model.DatabaseTemplate) error {
+	if template.ID.IsZero() {
+		template.ID = primitive.NewObjectID()
+	}
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+	if _, err := r.collection.InsertOne(ctx, template); err != nil {
+		utils.LogError(err, "Gagal membuat template database baru")
+		return err
+	}
+	utils.LogInfo("Berhasil membuat template database: %s", template.ID.Hex())
+	return nil
+}
+
+// GetTemplateByID mengambil template database berdasarkan ID. Mengembalikan nil, nil jika tidak ditemukan.
+func (r *databaseTemplateRepositoryImpl) GetTemplateByID(ctx context.Context, id primitive.ObjectID) (*model.DatabaseTemplate, error) {
+	var template model.DatabaseTemplate
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Template database dengan ID %s tidak ditemukan", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil template database berdasarkan ID: %s", id.Hex())
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetTemplatesByBusinessID mengambil semua template database milik bisnis, diurutkan berdasarkan nama.
+func (r *databaseTemplateRepositoryImpl) GetTemplatesByBusinessID(ctx context.Context, businessID primitive.ObjectID) ([]model.DatabaseTemplate, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"businessId": businessID}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil template database untuk bisnis: %s", businessID.Hex())
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	templates := make([]model.DatabaseTemplate, 0)
+	if err := cursor.All(ctx, &templates); err != nil {
+		utils.LogError(err, "Gagal mendekode template database untuk bisnis: %s", businessID.Hex())
+		return nil, err
+	}
+	return templates, nil
+}
+
+// DeleteTemplate menghapus template database. Mengembalikan mongo.ErrNoDocuments jika tidak ditemukan.
+func (r *databaseTemplateRepositoryImpl) DeleteTemplate(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus template database dengan ID: %s", id.Hex())
+		return err
+	}
+	if result.DeletedCount == 0 {
+		utils.LogWarning("Template database dengan ID %s tidak ditemukan untuk dihapus", id.Hex())
+		return mongo.ErrNoDocuments
+	}
+	utils.LogInfo("Berhasil menghapus template database dengan ID: %s", id.Hex())
+	return nil
+}
@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeletionRepository adalah interface untuk operasi penghapusan berantai lintas koleksi dan
+// penyimpanan DeletionJob. Langkah penghapusan dijelaskan oleh model.DeletionStep.
+type DeletionRepository interface {
+	// FindIDs mengembalikan _id dokumen di koleksi collection yang field-nya berisi salah satu ids.
+	FindIDs(ctx context.Context, collection, field string, ids []primitive.ObjectID) ([]primitive.ObjectID, error)
+	// CountStep menghitung dokumen yang akan terkena langkah step.
+	CountStep(ctx context.Context, step model.DeletionStep) (int64, error)
+	// ExecuteStep menjalankan step sekaligus. limit > 0 membatasi jumlah dokumen per panggilan (untuk batch);
+	// 0 berarti semua dokumen. Mengembalikan jumlah dokumen yang dihapus atau diubah.
+	ExecuteStep(ctx context.Context, step model.DeletionStep, limit int) (int64, error)
+	// FindFilesByDatabaseIDs mengambil metadata file milik database-database tersebut. limit 0 berarti semua.
+	FindFilesByDatabaseIDs(ctx context.Context, databaseIDs []primitive.ObjectID, limit int) ([]model.DatabaseFile, error)
+	// DeleteFilesByIDs menghapus metadata file berdasarkan ID.
+	DeleteFilesByIDs(ctx context.Context, ids []primitive.ObjectID) (int64, error)
+	// RunInTransaction menjalankan fn di dalam transaksi MongoDB (butuh replica set atau mongos).
+	RunInTransaction(ctx context.Context, fn func(txCtx context.Context) error) error
+
+	CreateJob(ctx context.Context, job *model.DeletionJob) error
+	GetJobByID(ctx context.Context, id primitive.ObjectID) (*model.DeletionJob, error)
+	// GetActiveJobByTarget mengambil job pending/running untuk target tertentu. Mengembalikan nil, nil jika tidak ada.
+	GetActiveJobByTarget(ctx context.Context, targetType string, targetID primitive.ObjectID) (*model.DeletionJob, error)
+	// GetUnfinishedJobs mengambil semua job yang masih pending atau running.
+	GetUnfinishedJobs(ctx context.Context) ([]model.DeletionJob, error)
+	// SaveJob menyimpan seluruh isi job (status dan progres langkah).
+	SaveJob(ctx context.Context, job *model.DeletionJob) error
+}
+
+// deletionRepositoryImpl adalah implementasi dari DeletionRepository.
+type deletionRepositoryImpl struct {
+	client        *mongo.Client
+	database      *mongo.Database
+	jobCollection *mongo.Collection
+}
+
+// NewDeletionRepository membuat instance baru dari DeletionRepository.
+func NewDeletionRepository(dbClient *mongo.Client) DeletionRepository {
+	return &deletionRepositoryImpl{
+		client:        dbClient,
+		database:      dbClient.Database(config.DBConfig.DatabaseName),
+		jobCollection: config.GetCollection(dbClient, "DeletionJobs"),
+	}
+}
+
+// collection mengambil koleksi berdasarkan key konfigurasi. Berbeda dengan config.GetCollection,
+// key yang tidak dikenal dikembalikan sebagai error karena key berasal dari job yang tersimpan.
+func (r *deletionRepositoryImpl) collection(key string) (*mongo.Collection, error) {
+	name, ok := config.DBConfig.Collections[key]
+	if !ok {
+		return nil, fmt.Errorf("koleksi dengan kunci %s tidak ditemukan di konfigurasi database", key)
+	}
+	return r.database.Collection(name), nil
+}
+
+// FindIDs mengambil _id dokumen yang field-nya berisi salah satu ids.
+func (r *deletionRepositoryImpl) FindIDs(ctx context.Context, collection, field string, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	coll, err := r.collection(collection)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := coll.Find(ctx, bson.M{field: bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil ID dokumen %s untuk penghapusan berantai", collection)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		found = append(found, doc.ID)
+	}
+	return found, cursor.Err()
+}
+
+// stepFilter membuat filter dokumen untuk step. Untuk detach_users, businessIds user disimpan sebagai string hex.
+func stepFilter(step model.DeletionStep) bson.M {
+	if step.Action == model.DeletionActionDetachUsers {
+		hexIDs := make([]string, len(step.IDs))
+		for i, id := range step.IDs {
+			hexIDs[i] = id.Hex()
+		}
+		return bson.M{step.Field: bson.M{"$in": hexIDs}}
+	}
+	return bson.M{step.Field: bson.M{"$in": step.IDs}}
+}
+
+// relationReference adalah sel kolom relation di database lain yang merujuk salah satu database yang dihapus.
+type relationReference struct {
+	filter   bson.M // Baris yang sel kolomnya masih berisi referensi
+	valueKey string // "values.<columnID>"
+}
+
+// findRelationReferences mencari kolom relation di database lain yang merujuk databaseIDs. Database yang
+// ikut dihapus dilewati karena barisnya dihapus oleh langkah berikutnya.
+func (r *deletionRepositoryImpl) findRelationReferences(ctx context.Context, databaseIDs []primitive.ObjectID) ([]relationReference, error) {
+	coll, err := r.collection("Databases")
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := coll.Find(ctx, bson.M{
+		"_id": bson.M{"$nin": databaseIDs},
+		"databaseData.columns.relation.databaseId": bson.M{"$in": databaseIDs},
+	}, options.Find().SetProjection(bson.M{"databaseData.columns": 1}))
+	if err != nil {
+		utils.LogError(err, "Gagal mencari kolom relation yang merujuk database yang dihapus")
+		return nil, err
+	}
+	var sources []model.Database
+	if err := cursor.All(ctx, &sources); err != nil {
+		return nil, err
+	}
+
+	deleted := make(map[primitive.ObjectID]bool, len(databaseIDs))
+	for _, id := range databaseIDs {
+		deleted[id] = true
+	}
+	var refs []relationReference
+	for _, source := range sources {
+		for _, col := range source.DatabaseData.Columns {
+			if col.Type != "relation" || col.Relation == nil || !deleted[col.Relation.DatabaseID] {
+				continue
+			}
+			valueKey := "values." + col.ID.Hex()
+			refs = append(refs, relationReference{
+				// Hanya sel yang belum kosong, sehingga langkah ini aman diulang
+				filter:   bson.M{"databaseId": source.ID, valueKey + ".0": bson.M{"$exists": true}},
+				valueKey: valueKey,
+			})
+		}
+	}
+	return refs, nil
+}
+
+// CountStep menghitung dokumen yang cocok dengan filter step.
+func (r *deletionRepositoryImpl) CountStep(ctx context.Context, step model.DeletionStep) (int64, error) {
+	if len(step.IDs) == 0 {
+		return 0, nil
+	}
+	coll, err := r.collection(step.Collection)
+	if err != nil {
+		return 0, err
+	}
+	filter := stepFilter(step)
+	if step.Action == model.DeletionActionClearRelations {
+		refs, err := r.findRelationReferences(ctx, step.IDs)
+		if err != nil {
+			return 0, err
+		}
+		if len(refs) == 0 {
+			return 0, nil
+		}
+		or := make(bson.A, len(refs))
+		for i, ref := range refs {
+			or[i] = ref.filter
+		}
+		filter = bson.M{"$or": or}
+	}
+	count, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		utils.LogError(err, "Gagal menghitung dokumen %s untuk penghapusan berantai", step.Collection)
+		return 0, err
+	}
+	return count, nil
+}
+
+// ExecuteStep menjalankan step. Untuk delete dengan limit, _id dokumen diambil terlebih dahulu agar
+// setiap batch dapat dicatat sebagai progres job.
+func (r *deletionRepositoryImpl) ExecuteStep(ctx context.Context, step model.DeletionStep, limit int) (int64, error) {
+	if len(step.IDs) == 0 {
+		return 0, nil
+	}
+	coll, err := r.collection(step.Collection)
+	if err != nil {
+		return 0, err
+	}
+	filter := stepFilter(step)
+
+	if step.Action == model.DeletionActionClearRelations {
+		return r.clearRelationReferences(ctx, coll, step.IDs)
+	}
+
+	if step.Action == model.DeletionActionDetachUsers {
+		unset := bson.M{}
+		hexIDs := make([]string, len(step.IDs))
+		for i, id := range step.IDs {
+			hexIDs[i] = id.Hex()
+			unset["roles."+id.Hex()] = ""
+		}
+		result, err := coll.UpdateMany(ctx, filter, bson.M{
+			"$pull":  bson.M{"businessIds": bson.M{"$in": hexIDs}},
+			"$unset": unset,
+		})
+		if err != nil {
+			utils.LogError(err, "Gagal melepas bisnis dari user")
+			return 0, err
+		}
+		return result.ModifiedCount, nil
+	}
+
+	if limit > 0 {
+		cursor, err := coll.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(int64(limit)))
+		if err != nil {
+			utils.LogError(err, "Gagal mengambil batch dokumen %s untuk dihapus", step.Collection)
+			return 0, err
+		}
+		var docs []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &docs); err != nil {
+			return 0, err
+		}
+		if len(docs) == 0 {
+			return 0, nil
+		}
+		batchIDs := make([]primitive.ObjectID, len(docs))
+		for i, doc := range docs {
+			batchIDs[i] = doc.ID
+		}
+		filter = bson.M{"_id": bson.M{"$in": batchIDs}}
+	}
+
+	result, err := coll.DeleteMany(ctx, filter)
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus dokumen %s pada penghapusan berantai", step.Collection)
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// clearRelationReferences mengosongkan sel kolom relation yang merujuk databaseIDs, seperti
+// DatabaseRepository.DeleteDatabase. Semua sel dikosongkan dalam satu panggilan tanpa batch.
+func (r *deletionRepositoryImpl) clearRelationReferences(ctx context.Context, rows *mongo.Collection, databaseIDs []primitive.ObjectID) (int64, error) {
+	refs, err := r.findRelationReferences(ctx, databaseIDs)
+	if err != nil {
+		return 0, err
+	}
+	var modified int64
+	for _, ref := range refs {
+		result, err := rows.UpdateMany(ctx, ref.filter, bson.M{"$set": bson.M{ref.valueKey: bson.A{}}, "$inc": bson.M{"version": 1}})
+		if err != nil {
+			utils.LogError(err, "Gagal membersihkan referensi relation %s pada penghapusan berantai", ref.valueKey)
+			return 0, err
+		}
+		modified += result.ModifiedCount
+	}
+	return modified, nil
+}
+
+// FindFilesByDatabaseIDs mengambil metadata file milik database-database tersebut.
+func (r *deletionRepositoryImpl) FindFilesByDatabaseIDs(ctx context.Context, databaseIDs []primitive.ObjectID, limit int) ([]model.DatabaseFile, error) {
+	files := make([]model.DatabaseFile, 0)
+	if len(databaseIDs) == 0 {
+		return files, nil
+	}
+	coll, err := r.collection("DatabaseFiles")
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find()
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := coll.Find(ctx, bson.M{"databaseId": bson.M{"$in": databaseIDs}}, opts)
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil metadata file untuk penghapusan berantai")
+		return nil, err
+	}
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// DeleteFilesByIDs menghapus metadata file berdasarkan ID.
+func (r *deletionRepositoryImpl) DeleteFilesByIDs(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	coll, err := r.collection("DatabaseFiles")
+	if err != nil {
+		return 0, err
+	}
+	result, err := coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus metadata file pada penghapusan berantai")
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// RunInTransaction menjalankan fn di dalam sesi transaksi. fn dapat dipanggil ulang oleh driver
+// jika terjadi error transien, sehingga fn tidak boleh menyimpan efek samping di luar transaksi.
+func (r *deletionRepositoryImpl) RunInTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+	session, err := r.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
+
+// CreateJob menyimpan DeletionJob baru.
+func (r *deletionRepositoryImpl) CreateJob(ctx context.Context, job *model.DeletionJob) error {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	if _, err := r.jobCollection.InsertOne(ctx, job); err != nil {
+		utils.LogError(err, "Gagal membuat job penghapusan untuk %s %s", job.TargetType, job.TargetID.Hex())
+		return err
+	}
+	utils.LogInfo("Berhasil membuat job penghapusan %s untuk %s %s", job.ID.Hex(), job.TargetType, job.TargetID.Hex())
+	return nil
+}
+
+// GetJobByID mengambil DeletionJob berdasarkan ID. Mengembalikan nil, nil jika tidak ditemukan.
+func (r *deletionRepositoryImpl) GetJobByID(ctx context.Context, id primitive.ObjectID) (*model.DeletionJob, error) {
+	var job model.DeletionJob
+	err := r.jobCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Job penghapusan dengan ID %s tidak ditemukan", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil job penghapusan berdasarkan ID: %s", id.Hex())
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetActiveJobByTarget mengambil job pending/running untuk target tertentu.
+func (r *deletionRepositoryImpl) GetActiveJobByTarget(ctx context.Context, targetType string, targetID primitive.ObjectID) (*model.DeletionJob, error) {
+	var job model.DeletionJob
+	err := r.jobCollection.FindOne(ctx, bson.M{
+		"targetType": targetType,
+		"targetId":   targetID,
+		"status":     bson.M{"$in": []string{model.DeletionJobPending, model.DeletionJobRunning}},
+	}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil job penghapusan aktif untuk %s %s", targetType, targetID.Hex())
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetUnfinishedJobs mengambil semua job pending atau running, urut dari yang terlama.
+func (r *deletionRepositoryImpl) GetUnfinishedJobs(ctx context.Context) ([]model.DeletionJob, error) {
+	cursor, err := r.jobCollection.Find(ctx,
+		bson.M{"status": bson.M{"$in": []string{model.DeletionJobPending, model.DeletionJobRunning}}},
+		options.Find().SetSort(bson.M{"createdAt": 1}),
+	)
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil job penghapusan yang belum selesai")
+		return nil, err
+	}
+	jobs := make([]model.DeletionJob, 0)
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// SaveJob mengganti dokumen job dengan isi terbaru.
+func (r *deletionRepositoryImpl) SaveJob(ctx context.Context, job *model.DeletionJob) error {
+	job.UpdatedAt = time.Now()
+	if _, err := r.jobCollection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job); err != nil {
+		utils.LogError(err, "Gagal menyimpan job penghapusan %s", job.ID.Hex())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DocumentRepository adalah interface untuk operasi database entitas Document.
+type DocumentRepository interface {
+	CreateDocument(ctx context.Context, document *model.Document) error
+	GetDocumentByID(ctx context.Context, id primitive.ObjectID) (*model.Document, error)
+	GetDocumentsByChannelID(ctx context.Context, channelID primitive.ObjectID) ([]model.Document, error)
+	UpdateDocument(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Document, error)
+	DeleteDocument(ctx context.Context, id primitive.ObjectID) error
+}
+
+// documentRepositoryImpl adalah implementasi dari DocumentRepository.
+type documentRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewDocumentRepository membuat instance baru dari DocumentRepository.
+func NewDocumentRepository(dbClient *mongo.Client) DocumentRepository {
+	collection := config.GetCollection(dbClient, "Documents")
+	return &documentRepositoryImpl{collection: collection}
+}
+
+// CreateDocument menyimpan objek Document baru ke database.
+func (r *documentRepositoryImpl) CreateDocument(ctx context.Context, document *model.Document) error {
+	document.CreatedAt = time.Now()
+	document.UpdatedAt = time.Now()
+	_, err := r.collection.InsertOne(ctx, document)
+	if err != nil {
+		utils.LogError(err, "Gagal membuat dokumen baru di database")
+		return err
+	}
+	utils.LogInfo("Berhasil membuat dokumen baru: %s", document.ID.Hex())
+	return nil
+}
+
+// GetDocumentByID mengambil objek Document berdasarkan ID.
+func (r *documentRepositoryImpl) GetDocumentByID(ctx context.Context, id primitive.ObjectID) (*model.Document, error) {
+	var document model.Document
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&document)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Dokumen dengan ID %s tidak ditemukan", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil dokumen berdasarkan ID: %s", id.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil mengambil dokumen dengan ID: %s", id.Hex())
+	return &document, nil
+}
+
+// GetDocumentsByChannelID mengambil semua dokumen berdasarkan ChannelID, diurutkan dari yang terbaru diperbarui.
+func (r *documentRepositoryImpl) GetDocumentsByChannelID(ctx context.Context, channelID primitive.ObjectID) ([]model.Document, error) {
+	var documents []model.Document
+	filter := bson.M{"channelId": channelID}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}))
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil dokumen berdasarkan channelId")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &documents); err != nil {
+		utils.LogError(err, "Gagal mendekode dokumen by channelId")
+		return nil, err
+	}
+	utils.LogInfo("Berhasil mengambil dokumen by channelId. Total: %d", len(documents))
+	return documents, nil
+}
+
+// UpdateDocument memperbarui objek Document berdasarkan ID.
+func (r *documentRepositoryImpl) UpdateDocument(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Document, error) {
+	if setMap, ok := updateData["$set"].(bson.M); ok {
+		setMap["updatedAt"] = time.Now()
+	} else {
+		updateData["$set"] = bson.M{"updatedAt": time.Now()}
+	}
+
+	filter := bson.M{"_id": id}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updatedDocument model.Document
+	err := r.collection.FindOneAndUpdate(ctx, filter, updateData, opts).Decode(&updatedDocument)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Dokumen dengan ID %s tidak ditemukan untuk diperbarui", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal memperbarui dokumen di database: %s", id.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil memperbarui dokumen dengan ID: %s", id.Hex())
+	return &updatedDocument, nil
+}
+
+// DeleteDocument menghapus objek Document berdasarkan ID.
+func (r *documentRepositoryImpl) DeleteDocument(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus dokumen dengan ID: %s", id.Hex())
+		return err
+	}
+	if result.DeletedCount == 0 {
+		utils.LogWarning("Dokumen dengan ID %s tidak ditemukan untuk dihapus", id.Hex())
+		return mongo.ErrNoDocuments
+	}
+	utils.LogInfo("Berhasil menghapus dokumen dengan ID: %s", id.Hex())
+	return nil
+}
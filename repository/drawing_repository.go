@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DrawingRepository adalah interface untuk operasi database entitas Drawing.
+type DrawingRepository interface {
+	CreateDrawing(ctx context.Context, drawing *model.Drawing) error
+	GetDrawingByID(ctx context.Context, id primitive.ObjectID) (*model.Drawing, error)
+	GetDrawingsByChannelID(ctx context.Context, channelID primitive.ObjectID) ([]model.Drawing, error)
+	UpdateDrawing(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Drawing, error)
+	DeleteDrawing(ctx context.Context, id primitive.ObjectID) error
+
+	// Operasi untuk shape di dalam gambar
+	AddShapeToDrawing(ctx context.Context, drawingID primitive.ObjectID, shape *model.DrawingShape) (*model.Drawing, error)
+	UpdateShapeInDrawing(ctx context.Context, drawingID, shapeID primitive.ObjectID, updateData bson.M) (*model.Drawing, error)
+	DeleteShapeFromDrawing(ctx context.Context, drawingID, shapeID primitive.ObjectID) (*model.Drawing, error)
+	ReorderShapesInDrawing(ctx context.Context, drawingID primitive.ObjectID, shapeIDs []primitive.ObjectID) (*model.Drawing, error)
+}
+
+// ErrDrawingShapesChanged dikembalikan ketika isi shape berubah di antara pembacaan dan penulisan reorder.
+var ErrDrawingShapesChanged = errors.New("shape pada gambar berubah saat reorder, silakan coba lagi")
+
+// drawingRepositoryImpl adalah implementasi dari DrawingRepository.
+type drawingRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewDrawingRepository membuat instance baru dari DrawingRepository.
+func NewDrawingRepository(dbClient *mongo.Client) DrawingRepository {
+	collection := config.GetCollection(dbClient, "Drawings")
+	return &drawingRepositoryImpl{collection: collection}
+}
+
+// CreateDrawing menyimpan objek Drawing baru ke database.
+func (r *drawingRepositoryImpl) CreateDrawing(ctx context.Context, drawing *model.Drawing) error {
+	drawing.CreatedAt = time.Now()
+	drawing.UpdatedAt = time.Now()
+	_, err := r.collection.InsertOne(ctx, drawing)
+	if err != nil {
+		utils.LogError(err, "Gagal membuat gambar baru di database")
+		return err
+	}
+	utils.LogInfo("Berhasil membuat gambar baru: %s", drawing.ID.Hex())
+	return nil
+}
+
+// GetDrawingByID mengambil objek Drawing berdasarkan ID.
+func (r *drawingRepositoryImpl) GetDrawingByID(ctx context.Context, id primitive.ObjectID) (*model.Drawing, error) {
+	var drawing model.Drawing
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&drawing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Gambar dengan ID %s tidak ditemukan", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil gambar berdasarkan ID: %s", id.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil mengambil gambar dengan ID: %s", id.Hex())
+	return &drawing, nil
+}
+
+// GetDrawingsByChannelID mengambil semua gambar berdasarkan ChannelID.
+func (r *drawingRepositoryImpl) GetDrawingsByChannelID(ctx context.Context, channelID primitive.ObjectID) ([]model.Drawing, error) {
+	var drawings []model.Drawing
+	filter := bson.M{"channelId": channelID}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}))
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil gambar berdasarkan channelId")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &drawings); err != nil {
+		utils.LogError(err, "Gagal mendekode dokumen gambar by channelId")
+		return nil, err
+	}
+	utils.LogInfo("Berhasil mengambil gambar by channelId. Total: %d", len(drawings))
+	return drawings, nil
+}
+
+// UpdateDrawing memperbarui objek Drawing berdasarkan ID.
+func (r *drawingRepositoryImpl) UpdateDrawing(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Drawing, error) {
+	if setMap, ok := updateData["$set"].(bson.M); ok {
+		setMap["updatedAt"] = time.Now()
+	} else {
+		updateData["$set"] = bson.M{"updatedAt": time.Now()}
+	}
+
+	filter := bson.M{"_id": id}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updatedDrawing model.Drawing
+	err := r.collection.FindOneAndUpdate(ctx, filter, updateData, opts).Decode(&updatedDrawing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Gambar dengan ID %s tidak ditemukan untuk diperbarui", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal memperbarui gambar di database: %s", id.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil memperbarui gambar dengan ID: %s", id.Hex())
+	return &updatedDrawing, nil
+}
+
+// DeleteDrawing menghapus objek Drawing berdasarkan ID.
+func (r *drawingRepositoryImpl) DeleteDrawing(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus gambar dengan ID: %s", id.Hex())
+		return err
+	}
+	if result.DeletedCount == 0 {
+		utils.LogWarning("Gambar dengan ID %s tidak ditemukan untuk dihapus", id.Hex())
+		return mongo.ErrNoDocuments
+	}
+	utils.LogInfo("Berhasil menghapus gambar dengan ID: %s", id.Hex())
+	return nil
+}
+
+// AddShapeToDrawing menambahkan shape baru ke akhir array Shapes (layer paling atas).
+func (r *drawingRepositoryImpl) AddShapeToDrawing(ctx context.Context, drawingID primitive.ObjectID, shape *model.DrawingShape) (*model.Drawing, error) {
+	shape.ID = primitive.NewObjectID()
+
+	filter := bson.M{"_id": drawingID}
+	update := bson.M{
+		"$push": bson.M{"drawingData.shapes": shape},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updatedDrawing model.Drawing
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDrawing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Gambar dengan ID %s tidak ditemukan untuk menambahkan shape", drawingID.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal menambahkan shape ke gambar: %s", drawingID.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil menambahkan shape ke gambar ID: %s. Shape ID: %s", drawingID.Hex(), shape.ID.Hex())
+	return &updatedDrawing, nil
+}
+
+// UpdateShapeInDrawing memperbarui field-field shape tertentu dalam dokumen gambar.
+// updateData harus berisi operator $set dengan key relatif terhadap shape (misal: "x", "fill").
+func (r *drawingRepositoryImpl) UpdateShapeInDrawing(ctx context.Context, drawingID, shapeID primitive.ObjectID, updateData bson.M) (*model.Drawing, error) {
+	fields, ok := updateData["$set"].(bson.M)
+	if !ok {
+		return nil, fmt.Errorf("updateData harus mengandung operator $set")
+	}
+
+	setMap := bson.M{"updatedAt": time.Now()}
+	for key, value := range fields {
+		setMap["drawingData.shapes.$."+key] = value
+	}
+
+	filter := bson.M{"_id": drawingID, "drawingData.shapes._id": shapeID}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updatedDrawing model.Drawing
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": setMap}, opts).Decode(&updatedDrawing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Gambar dengan ID %s atau Shape ID %s tidak ditemukan untuk diperbarui", drawingID.Hex(), shapeID.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal memperbarui shape dalam gambar: %s, Shape ID: %s", drawingID.Hex(), shapeID.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil memperbarui shape ID: %s di gambar ID: %s", shapeID.Hex(), drawingID.Hex())
+	return &updatedDrawing, nil
+}
+
+// DeleteShapeFromDrawing menghapus shape tertentu dari array Shapes.
+func (r *drawingRepositoryImpl) DeleteShapeFromDrawing(ctx context.Context, drawingID, shapeID primitive.ObjectID) (*model.Drawing, error) {
+	filter := bson.M{"_id": drawingID, "drawingData.shapes._id": shapeID}
+	update := bson.M{
+		"$pull": bson.M{"drawingData.shapes": bson.M{"_id": shapeID}},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updatedDrawing model.Drawing
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDrawing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Gambar dengan ID %s atau Shape ID %s tidak ditemukan untuk dihapus", drawingID.Hex(), shapeID.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal menghapus shape dari gambar: %s, Shape ID: %s", drawingID.Hex(), shapeID.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil menghapus shape ID: %s dari gambar ID: %s", shapeID.Hex(), drawingID.Hex())
+	return &updatedDrawing, nil
+}
+
+// ReorderShapesInDrawing menyusun ulang array Shapes sesuai urutan shapeIDs.
+// shapeIDs harus berisi tepat seluruh shape yang ada. Penulisan dilakukan dengan filter
+// terhadap isi array saat dibaca, sehingga perubahan shape yang terjadi bersamaan
+// akan menyebabkan reorder gagal alih-alih menghapus shape secara diam-diam.
+func (r *drawingRepositoryImpl) ReorderShapesInDrawing(ctx context.Context, drawingID primitive.ObjectID, shapeIDs []primitive.ObjectID) (*model.Drawing, error) {
+	drawing, err := r.GetDrawingByID(ctx, drawingID)
+	if err != nil {
+		return nil, err
+	}
+	if drawing == nil {
+		return nil, nil
+	}
+
+	if len(shapeIDs) != len(drawing.DrawingData.Shapes) {
+		return nil, fmt.Errorf("jumlah shape tidak sesuai: diharapkan %d, diterima %d", len(drawing.DrawingData.Shapes), len(shapeIDs))
+	}
+
+	shapesByID := make(map[primitive.ObjectID]model.DrawingShape, len(drawing.DrawingData.Shapes))
+	for _, shape := range drawing.DrawingData.Shapes {
+		shapesByID[shape.ID] = shape
+	}
+
+	reordered := make([]model.DrawingShape, 0, len(shapeIDs))
+	for _, id := range shapeIDs {
+		shape, ok := shapesByID[id]
+		if !ok {
+			return nil, fmt.Errorf("shape dengan ID %s tidak ditemukan atau duplikat", id.Hex())
+		}
+		reordered = append(reordered, shape)
+		delete(shapesByID, id)
+	}
+
+	filter := bson.M{
+		"_id":                    drawingID,
+		"drawingData.shapes._id": bson.M{"$all": shapeIDs},
+		"drawingData.shapes":     bson.M{"$size": len(shapeIDs)},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"drawingData.shapes": reordered,
+			"updatedAt":          time.Now(),
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updatedDrawing model.Drawing
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedDrawing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Shape pada gambar %s berubah saat reorder", drawingID.Hex())
+			return nil, ErrDrawingShapesChanged
+		}
+		utils.LogError(err, "Gagal menyusun ulang shape pada gambar: %s", drawingID.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil menyusun ulang %d shape pada gambar ID: %s", len(shapeIDs), drawingID.Hex())
+	return &updatedDrawing, nil
+}
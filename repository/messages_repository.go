@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -24,8 +25,14 @@ type MessageRepository interface {
 	DeleteMessage(ctx context.Context, id primitive.ObjectID) error
 	AddMessageReaction(ctx context.Context, messageID, userID primitive.ObjectID, emoji string) (*model.Message, error)
 	RemoveMessageReaction(ctx context.Context, messageID, userID primitive.ObjectID, emoji string) (*model.Message, error)
+	CreateReply(ctx context.Context, reply *model.Message) (*model.Message, error)
+	GetThreadReplies(ctx context.Context, parentID primitive.ObjectID, limit, skip int64) ([]model.Message, error)
+	RefreshThreadSummary(ctx context.Context, parentID primitive.ObjectID) (*model.Message, error)
 }
 
+// ErrParentMessageNotFound dikembalikan jika pesan utama thread tidak ada (atau terhapus saat balasan dibuat).
+var ErrParentMessageNotFound = errors.New("pesan utama thread tidak ditemukan")
+
 // messageRepositoryImpl adalah implementasi dari MessageRepository.
 type messageRepositoryImpl struct {
 	collection *mongo.Collection
@@ -77,10 +84,11 @@ func (r *messageRepositoryImpl) GetMessageByID(ctx context.Context, id primitive
 	return &message, nil
 }
 
-// GetMessagesByChannelID mengambil semua pesan berdasarkan ChannelID.
+// GetMessagesByChannelID mengambil pesan utama berdasarkan ChannelID. Balasan thread tidak ikut,
+// balasan diambil melalui GetThreadReplies.
 func (r *messageRepositoryImpl) GetMessagesByChannelID(ctx context.Context, channelID primitive.ObjectID, limit, skip int64) ([]model.Message, error) {
 	var messages []model.Message
-	filter := bson.M{"channelId": channelID}
+	filter := bson.M{"channelId": channelID, "parentId": nil}
 
 	findOptions := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}) // Pesan terbaru di atas
 	if limit > 0 {
@@ -131,7 +139,8 @@ func (r *messageRepositoryImpl) UpdateMessage(ctx context.Context, id primitive.
 	return &updatedMessage, nil
 }
 
-// DeleteMessage menghapus objek Message berdasarkan ID.
+// DeleteMessage menghapus objek Message berdasarkan ID. Jika pesan adalah pesan utama thread,
+// seluruh balasannya ikut dihapus.
 func (r *messageRepositoryImpl) DeleteMessage(ctx context.Context, id primitive.ObjectID) error {
 	filter := bson.M{"_id": id}
 	result, err := r.collection.DeleteOne(ctx, filter)
@@ -144,7 +153,13 @@ func (r *messageRepositoryImpl) DeleteMessage(ctx context.Context, id primitive.
 		utils.LogWarning("Pesan dengan ID %s tidak ditemukan untuk dihapus", id.Hex())
 		return mongo.ErrNoDocuments
 	}
-	utils.LogInfo("Berhasil menghapus pesan dengan ID: %s", id.Hex())
+
+	replies, err := r.collection.DeleteMany(ctx, bson.M{"parentId": id})
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus balasan thread untuk pesan ID: %s", id.Hex())
+		return err
+	}
+	utils.LogInfo("Berhasil menghapus pesan dengan ID: %s (balasan terhapus: %d)", id.Hex(), replies.DeletedCount)
 	return nil
 }
 
@@ -276,3 +291,130 @@ func (r *messageRepositoryImpl) RemoveMessageReaction(ctx context.Context, messa
 	utils.LogInfo("Berhasil menghapus reaksi '%s' dari pesan ID: %s oleh user ID: %s", emoji, messageID.Hex(), userID.Hex())
 	return &updatedMessage, nil
 }
+
+// CreateReply menyimpan balasan thread lalu menaikkan replyCount dan lastReplyAt pada pesan utama.
+// reply.ParentID harus sudah diisi dan merujuk ke pesan utama (bukan balasan). Mengembalikan pesan
+// utama yang sudah diperbarui.
+func (r *messageRepositoryImpl) CreateReply(ctx context.Context, reply *model.Message) (*model.Message, error) {
+	if reply.ParentID == nil {
+		return nil, fmt.Errorf("%w: parentId kosong", ErrParentMessageNotFound)
+	}
+	parentID := *reply.ParentID
+
+	if err := r.CreateMessage(ctx, reply); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"_id": parentID, "parentId": nil}
+	update := bson.M{
+		"$inc": bson.M{"replyCount": 1},
+		"$max": bson.M{"lastReplyAt": reply.CreatedAt},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var parent model.Message
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&parent)
+	if err != nil {
+		// Pesan utama terhapus di antara validasi dan insert; hapus balasan agar tidak menjadi yatim.
+		if _, delErr := r.collection.DeleteOne(ctx, bson.M{"_id": reply.ID}); delErr != nil {
+			utils.LogError(delErr, "Gagal menghapus balasan yatim dengan ID: %s", reply.ID.Hex())
+		}
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Pesan utama %s tidak ditemukan saat membuat balasan", parentID.Hex())
+			return nil, fmt.Errorf("%w: %s", ErrParentMessageNotFound, parentID.Hex())
+		}
+		utils.LogError(err, "Gagal memperbarui ringkasan thread untuk pesan ID: %s", parentID.Hex())
+		return nil, err
+	}
+
+	utils.LogInfo("Berhasil membuat balasan %s pada thread %s", reply.ID.Hex(), parentID.Hex())
+	return &parent, nil
+}
+
+// GetThreadReplies mengambil balasan sebuah thread, diurutkan dari yang paling lama.
+func (r *messageRepositoryImpl) GetThreadReplies(ctx context.Context, parentID primitive.ObjectID, limit, skip int64) ([]model.Message, error) {
+	var replies []model.Message
+	filter := bson.M{"parentId": parentID}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	}
+	if skip > 0 {
+		findOptions.SetSkip(skip)
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil balasan thread untuk pesan ID: %s", parentID.Hex())
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &replies); err != nil {
+		utils.LogError(err, "Gagal mendekode balasan thread untuk pesan ID: %s", parentID.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil mengambil balasan thread %s. Total: %d", parentID.Hex(), len(replies))
+	return replies, nil
+}
+
+// RefreshThreadSummary menghitung ulang replyCount dan lastReplyAt pesan utama dari balasan yang
+// tersisa. Dipakai setelah balasan dihapus. Mengembalikan nil, nil jika pesan utama tidak ada.
+func (r *messageRepositoryImpl) RefreshThreadSummary(ctx context.Context, parentID primitive.ObjectID) (*model.Message, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"parentId": parentID})
+	if err != nil {
+		utils.LogError(err, "Gagal menghitung balasan thread untuk pesan ID: %s", parentID.Hex())
+		return nil, err
+	}
+
+	update := bson.M{"$set": bson.M{"replyCount": count}}
+	var latest model.Message
+	err = r.collection.FindOne(ctx, bson.M{"parentId": parentID},
+		options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetProjection(bson.M{"createdAt": 1}),
+	).Decode(&latest)
+	switch {
+	case err == mongo.ErrNoDocuments:
+		update["$unset"] = bson.M{"lastReplyAt": ""}
+	case err != nil:
+		utils.LogError(err, "Gagal mengambil balasan terakhir thread untuk pesan ID: %s", parentID.Hex())
+		return nil, err
+	default:
+		update["$set"].(bson.M)["lastReplyAt"] = latest.CreatedAt
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var parent model.Message
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": parentID}, update, opts).Decode(&parent)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Pesan utama %s tidak ditemukan saat memperbarui ringkasan thread", parentID.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal memperbarui ringkasan thread untuk pesan ID: %s", parentID.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil memperbarui ringkasan thread %s. Total balasan: %d", parentID.Hex(), count)
+	return &parent, nil
+}
+
+// EnsureMessageIndexes membuat index yang dipakai riwayat channel dan pengambilan thread.
+func EnsureMessageIndexes(ctx context.Context, dbClient *mongo.Client) error {
+	collection := config.GetCollection(dbClient, "Messages")
+
+	// Riwayat channel hanya menampilkan pesan utama (parentId null), terbaru lebih dulu
+	if _, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "channelId", Value: 1}, {Key: "parentId", Value: 1}, {Key: "createdAt", Value: -1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index channelId pada koleksi messages")
+		return err
+	}
+
+	// Balasan thread dibaca per pesan utama, terlama lebih dulu
+	if _, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "parentId", Value: 1}, {Key: "createdAt", Value: 1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index parentId pada koleksi messages")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotificationRepository adalah interface untuk operasi database Notification.
+type NotificationRepository interface {
+	// CreateNotifications menyimpan beberapa notifikasi sekaligus dan mengisi ID serta CreatedAt masing-masing.
+	CreateNotifications(ctx context.Context, notifications []model.Notification) error
+	// GetNotificationsByUserID mengambil notifikasi milik user, terbaru lebih dulu.
+	GetNotificationsByUserID(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit, skip int64) ([]model.Notification, error)
+	// CountUnread menghitung notifikasi user yang belum dibaca.
+	CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	// MarkAsRead menandai satu notifikasi milik user sebagai dibaca. Mengembalikan nil, nil jika tidak ditemukan.
+	MarkAsRead(ctx context.Context, userID, notificationID primitive.ObjectID) (*model.Notification, error)
+	// MarkAllAsRead menandai semua notifikasi user yang belum dibaca sebagai dibaca dan mengembalikan jumlahnya.
+	MarkAllAsRead(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+// notificationRepositoryImpl adalah implementasi dari NotificationRepository.
+type notificationRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewNotificationRepository membuat instance baru dari NotificationRepository.
+func NewNotificationRepository(dbClient *mongo.Client) NotificationRepository {
+	collection := config.GetCollection(dbClient, "Notifications")
+	return &notificationRepositoryImpl{collection: collection}
+}
+
+// CreateNotifications menyimpan notifikasi dengan InsertMany.
+func (r *notificationRepositoryImpl) CreateNotifications(ctx context.Context, notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	now := time.Now()
+	docs := make([]interface{}, len(notifications))
+	for i := range notifications {
+		notifications[i].ID = primitive.NewObjectID()
+		notifications[i].CreatedAt = now
+		notifications[i].IsRead = false
+		docs[i] = notifications[i]
+	}
+
+	if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+		utils.LogError(err, "Gagal menyimpan notifikasi ke database")
+		return err
+	}
+	utils.LogInfo("Berhasil membuat notifikasi. Total: %d", len(notifications))
+	return nil
+}
+
+// GetNotificationsByUserID mengambil notifikasi milik user dengan pagination limit/skip.
+func (r *notificationRepositoryImpl) GetNotificationsByUserID(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit, skip int64) ([]model.Notification, error) {
+	notifications := []model.Notification{}
+	filter := bson.M{"userId": userID}
+	if unreadOnly {
+		filter["isRead"] = false
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}) // Notifikasi terbaru di atas
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	}
+	if skip > 0 {
+		findOptions.SetSkip(skip)
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil notifikasi untuk user: %s", userID.Hex())
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &notifications); err != nil {
+		utils.LogError(err, "Gagal mendekode dokumen notifikasi untuk user: %s", userID.Hex())
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// CountUnread menghitung notifikasi yang belum dibaca milik user.
+func (r *notificationRepositoryImpl) CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"userId": userID, "isRead": false})
+	if err != nil {
+		utils.LogError(err, "Gagal menghitung notifikasi belum dibaca untuk user: %s", userID.Hex())
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkAsRead menandai notifikasi sebagai dibaca. Filter userId memastikan user hanya dapat mengubah
+// notifikasinya sendiri; notifikasi milik user lain diperlakukan sebagai tidak ditemukan.
+func (r *notificationRepositoryImpl) MarkAsRead(ctx context.Context, userID, notificationID primitive.ObjectID) (*model.Notification, error) {
+	var notification model.Notification
+	err := r.collection.FindOne(ctx, bson.M{"_id": notificationID, "userId": userID}).Decode(&notification)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Notifikasi dengan ID %s tidak ditemukan untuk user %s", notificationID.Hex(), userID.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil notifikasi dengan ID: %s", notificationID.Hex())
+		return nil, err
+	}
+	if notification.IsRead {
+		return &notification, nil
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": notificationID, "userId": userID}
+	update := bson.M{"$set": bson.M{"isRead": true, "readAt": now}}
+	if _, err := r.collection.UpdateOne(ctx, filter, update); err != nil {
+		utils.LogError(err, "Gagal menandai notifikasi %s sebagai dibaca", notificationID.Hex())
+		return nil, err
+	}
+	notification.IsRead = true
+	notification.ReadAt = &now
+	return &notification, nil
+}
+
+// MarkAllAsRead menandai semua notifikasi user yang belum dibaca sebagai dibaca.
+func (r *notificationRepositoryImpl) MarkAllAsRead(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	filter := bson.M{"userId": userID, "isRead": false}
+	update := bson.M{"$set": bson.M{"isRead": true, "readAt": time.Now()}}
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		utils.LogError(err, "Gagal menandai semua notifikasi sebagai dibaca untuk user: %s", userID.Hex())
+		return 0, err
+	}
+	utils.LogInfo("Berhasil menandai %d notifikasi sebagai dibaca untuk user: %s", result.ModifiedCount, userID.Hex())
+	return result.ModifiedCount, nil
+}
+
+// EnsureNotificationIndexes membuat index untuk daftar notifikasi per user dan hitungan belum dibaca.
+func EnsureNotificationIndexes(ctx context.Context, dbClient *mongo.Client) error {
+	collection := config.GetCollection(dbClient, "Notifications")
+
+	// Daftar notifikasi user diurutkan dari yang terbaru, dan versi yang difilter isRead = false
+	if _, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "isRead", Value: 1}, {Key: "_id", Value: -1}}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index userId pada koleksi notifications")
+		return err
+	}
+
+	// Dipakai penghapusan berantai channel
+	if _, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "channelId", Value: 1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index channelId pada koleksi notifications")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReadStateRepository adalah interface untuk posisi baca user per channel dan hitungan belum dibaca.
+type ReadStateRepository interface {
+	// MarkRead memajukan posisi baca user pada channel ke messageID. Posisi baca tidak pernah mundur;
+	// jika posisi sekarang sudah di depan messageID, posisi tersebut dikembalikan apa adanya.
+	MarkRead(ctx context.Context, userID, channelID, businessID, messageID primitive.ObjectID) (*model.ChannelReadState, error)
+	// GetReadStatesByBusiness mengambil semua posisi baca user pada channel-channel sebuah bisnis.
+	GetReadStatesByBusiness(ctx context.Context, userID, businessID primitive.ObjectID) ([]model.ChannelReadState, error)
+	// GetLatestMessageID mengambil ID pesan utama terbaru di channel. Mengembalikan nil, nil jika channel kosong.
+	GetLatestMessageID(ctx context.Context, channelID primitive.ObjectID) (*primitive.ObjectID, error)
+	// CountUnreadMessages menghitung pesan utama setelah posisi baca untuk setiap channel dalam cursors
+	// (channel ID -> ID pesan terakhir dibaca; ObjectID kosong berarti belum pernah membaca).
+	CountUnreadMessages(ctx context.Context, cursors map[primitive.ObjectID]primitive.ObjectID) (map[primitive.ObjectID]int64, error)
+	// CountUnreadMentions menghitung notifikasi mention yang belum dibaca per channel pada sebuah bisnis.
+	CountUnreadMentions(ctx context.Context, userID, businessID primitive.ObjectID) (map[primitive.ObjectID]int64, error)
+	// MarkMentionsRead menandai notifikasi mention pada channel sampai messageID sebagai dibaca.
+	MarkMentionsRead(ctx context.Context, userID, channelID, messageID primitive.ObjectID) (int64, error)
+}
+
+// readStateRepositoryImpl adalah implementasi dari ReadStateRepository.
+type readStateRepositoryImpl struct {
+	collection             *mongo.Collection
+	messageCollection      *mongo.Collection
+	notificationCollection *mongo.Collection
+}
+
+// NewReadStateRepository membuat instance baru dari ReadStateRepository.
+func NewReadStateRepository(dbClient *mongo.Client) ReadStateRepository {
+	return &readStateRepositoryImpl{
+		collection:             config.GetCollection(dbClient, "ChannelReadStates"),
+		messageCollection:      config.GetCollection(dbClient, "Messages"),
+		notificationCollection: config.GetCollection(dbClient, "Notifications"),
+	}
+}
+
+// MarkRead memperbarui posisi baca hanya jika messageID lebih baru. Jika dokumen sudah ada dengan posisi
+// yang sama atau lebih baru, filter tidak cocok dan upsert gagal karena index unik; kondisi itu bukan error.
+func (r *readStateRepositoryImpl) MarkRead(ctx context.Context, userID, channelID, businessID, messageID primitive.ObjectID) (*model.ChannelReadState, error) {
+	filter := bson.M{
+		"userId":            userID,
+		"channelId":         channelID,
+		"lastReadMessageId": bson.M{"$lt": messageID},
+	}
+	update := bson.M{"$set": bson.M{
+		"businessId":        businessID,
+		"lastReadMessageId": messageID,
+		"lastReadAt":        time.Now(),
+	}}
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		utils.LogError(err, "Gagal memperbarui posisi baca user %s pada channel %s", userID.Hex(), channelID.Hex())
+		return nil, err
+	}
+
+	var state model.ChannelReadState
+	if err := r.collection.FindOne(ctx, bson.M{"userId": userID, "channelId": channelID}).Decode(&state); err != nil {
+		utils.LogError(err, "Gagal mengambil posisi baca user %s pada channel %s", userID.Hex(), channelID.Hex())
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetReadStatesByBusiness mengambil posisi baca user untuk semua channel bisnis.
+func (r *readStateRepositoryImpl) GetReadStatesByBusiness(ctx context.Context, userID, businessID primitive.ObjectID) ([]model.ChannelReadState, error) {
+	states := []model.ChannelReadState{}
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID, "businessId": businessID})
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil posisi baca user %s pada bisnis %s", userID.Hex(), businessID.Hex())
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &states); err != nil {
+		utils.LogError(err, "Gagal mendekode posisi baca user %s", userID.Hex())
+		return nil, err
+	}
+	return states, nil
+}
+
+// GetLatestMessageID mengambil _id pesan utama terbaru di channel.
+func (r *readStateRepositoryImpl) GetLatestMessageID(ctx context.Context, channelID primitive.ObjectID) (*primitive.ObjectID, error) {
+	var latest struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}}).SetProjection(bson.M{"_id": 1})
+	err := r.messageCollection.FindOne(ctx, bson.M{"channelId": channelID, "parentId": nil}, opts).Decode(&latest)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil pesan terbaru channel %s", channelID.Hex())
+		return nil, err
+	}
+	return &latest.ID, nil
+}
+
+// CountUnreadMessages menghitung semua channel dalam satu aggregation. Setiap cabang $or berupa
+// {channelId, parentId: null, _id > cursor} sehingga dilayani index {channelId, parentId, _id}.
+func (r *readStateRepositoryImpl) CountUnreadMessages(ctx context.Context, cursors map[primitive.ObjectID]primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+	counts := make(map[primitive.ObjectID]int64, len(cursors))
+	if len(cursors) == 0 {
+		return counts, nil
+	}
+
+	branches := make([]bson.M, 0, len(cursors))
+	for channelID, lastRead := range cursors {
+		branch := bson.M{"channelId": channelID, "parentId": nil}
+		if !lastRead.IsZero() {
+			branch["_id"] = bson.M{"$gt": lastRead}
+		}
+		branches = append(branches, branch)
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": branches}}},
+		{{Key: "$group", Value: bson.M{"_id": "$channelId", "count": bson.M{"$sum": 1}}}},
+	}
+	if err := r.aggregateCounts(ctx, r.messageCollection, pipeline, counts); err != nil {
+		utils.LogError(err, "Gagal menghitung pesan belum dibaca")
+		return nil, err
+	}
+	return counts, nil
+}
+
+// CountUnreadMentions mengelompokkan notifikasi mention yang belum dibaca berdasarkan channel.
+func (r *readStateRepositoryImpl) CountUnreadMentions(ctx context.Context, userID, businessID primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+	counts := make(map[primitive.ObjectID]int64)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"userId":     userID,
+			"businessId": businessID,
+			"isRead":     false,
+			"type":       model.NotificationTypeMention,
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$channelId", "count": bson.M{"$sum": 1}}}},
+	}
+	if err := r.aggregateCounts(ctx, r.notificationCollection, pipeline, counts); err != nil {
+		utils.LogError(err, "Gagal menghitung mention belum dibaca untuk user %s", userID.Hex())
+		return nil, err
+	}
+	return counts, nil
+}
+
+// MarkMentionsRead menandai notifikasi mention milik user pada channel sampai messageID sebagai dibaca.
+func (r *readStateRepositoryImpl) MarkMentionsRead(ctx context.Context, userID, channelID, messageID primitive.ObjectID) (int64, error) {
+	filter := bson.M{
+		"userId":    userID,
+		"channelId": channelID,
+		"isRead":    false,
+		"type":      model.NotificationTypeMention,
+		"messageId": bson.M{"$lte": messageID},
+	}
+	result, err := r.notificationCollection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"isRead": true, "readAt": time.Now()}})
+	if err != nil {
+		utils.LogError(err, "Gagal menandai mention channel %s sebagai dibaca", channelID.Hex())
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// aggregateCounts menjalankan pipeline yang menghasilkan {_id: channelId, count} dan mengisi counts.
+func (r *readStateRepositoryImpl) aggregateCounts(ctx context.Context, collection *mongo.Collection, pipeline mongo.Pipeline, counts map[primitive.ObjectID]int64) error {
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var row struct {
+			ChannelID primitive.ObjectID `bson:"_id"`
+			Count     int64              `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return err
+		}
+		counts[row.ChannelID] = row.Count
+	}
+	return cursor.Err()
+}
+
+// EnsureReadStateIndexes membuat index untuk posisi baca dan hitungan belum dibaca.
+func EnsureReadStateIndexes(ctx context.Context, dbClient *mongo.Client) error {
+	readStateCollection := config.GetCollection(dbClient, "ChannelReadStates")
+	if _, err := readStateCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// Satu posisi baca per user per channel; juga dipakai upsert MarkRead
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "channelId", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "businessId", Value: 1}}},
+		// Dipakai penghapusan berantai channel
+		{Keys: bson.D{{Key: "channelId", Value: 1}}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index pada koleksi channel_read_states")
+		return err
+	}
+
+	// Hitungan pesan belum dibaca: pesan utama per channel setelah _id posisi baca
+	messageCollection := config.GetCollection(dbClient, "Messages")
+	if _, err := messageCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "channelId", Value: 1}, {Key: "parentId", Value: 1}, {Key: "_id", Value: 1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index unread pada koleksi messages")
+		return err
+	}
+
+	// Hitungan mention belum dibaca per channel dalam satu bisnis
+	notificationCollection := config.GetCollection(dbClient, "Notifications")
+	if _, err := notificationCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "businessId", Value: 1}, {Key: "isRead", Value: 1}, {Key: "channelId", Value: 1}},
+	}); err != nil {
+		utils.LogError(err, "Gagal membuat index mention pada koleksi notifications")
+		return err
+	}
+	return nil
+}
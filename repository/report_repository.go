@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend_my_manajer/config"
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReportRepository adalah interface untuk operasi database entitas Report.
+type ReportRepository interface {
+	CreateReport(ctx context.Context, report *model.Report) error
+	GetReportByID(ctx context.Context, id primitive.ObjectID) (*model.Report, error)
+	GetReportsByChannelID(ctx context.Context, channelID primitive.ObjectID) ([]model.Report, error)
+	UpdateReport(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Report, error)
+	DeleteReport(ctx context.Context, id primitive.ObjectID) error
+}
+
+// reportRepositoryImpl adalah implementasi dari ReportRepository.
+type reportRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewReportRepository membuat instance baru dari ReportRepository.
+func NewReportRepository(dbClient *mongo.Client) ReportRepository {
+	collection := config.GetCollection(dbClient, "Reports")
+	return &reportRepositoryImpl{collection: collection}
+}
+
+// CreateReport menyimpan objek Report baru ke database.
+func (r *reportRepositoryImpl) CreateReport(ctx context.Context, report *model.Report) error {
+	report.CreatedAt = time.Now()
+	report.UpdatedAt = time.Now()
+	_, err := r.collection.InsertOne(ctx, report)
+	if err != nil {
+		utils.LogError(err, "Gagal membuat laporan baru di database")
+		return err
+	}
+	utils.LogInfo("Berhasil membuat laporan baru: %s", report.ID.Hex())
+	return nil
+}
+
+// GetReportByID mengambil objek Report berdasarkan ID.
+func (r *reportRepositoryImpl) GetReportByID(ctx context.Context, id primitive.ObjectID) (*model.Report, error) {
+	var report model.Report
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&report)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Laporan dengan ID %s tidak ditemukan", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal mengambil laporan berdasarkan ID: %s", id.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil mengambil laporan dengan ID: %s", id.Hex())
+	return &report, nil
+}
+
+// GetReportsByChannelID mengambil semua laporan berdasarkan ChannelID, diurutkan dari yang terbaru diperbarui.
+func (r *reportRepositoryImpl) GetReportsByChannelID(ctx context.Context, channelID primitive.ObjectID) ([]model.Report, error) {
+	var reports []model.Report
+	filter := bson.M{"channelId": channelID}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}))
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil laporan berdasarkan channelId")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &reports); err != nil {
+		utils.LogError(err, "Gagal mendekode laporan by channelId")
+		return nil, err
+	}
+	utils.LogInfo("Berhasil mengambil laporan by channelId. Total: %d", len(reports))
+	return reports, nil
+}
+
+// UpdateReport memperbarui objek Report berdasarkan ID.
+func (r *reportRepositoryImpl) UpdateReport(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.Report, error) {
+	if setMap, ok := updateData["$set"].(bson.M); ok {
+		setMap["updatedAt"] = time.Now()
+	} else {
+		updateData["$set"] = bson.M{"updatedAt": time.Now()}
+	}
+
+	filter := bson.M{"_id": id}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updatedReport model.Report
+	err := r.collection.FindOneAndUpdate(ctx, filter, updateData, opts).Decode(&updatedReport)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.LogWarning("Laporan dengan ID %s tidak ditemukan untuk diperbarui", id.Hex())
+			return nil, nil
+		}
+		utils.LogError(err, "Gagal memperbarui laporan di database: %s", id.Hex())
+		return nil, err
+	}
+	utils.LogInfo("Berhasil memperbarui laporan dengan ID: %s", id.Hex())
+	return &updatedReport, nil
+}
+
+// DeleteReport menghapus objek Report berdasarkan ID.
+func (r *reportRepositoryImpl) DeleteReport(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		utils.LogError(err, "Gagal menghapus laporan dengan ID: %s", id.Hex())
+		return err
+	}
+	if result.DeletedCount == 0 {
+		utils.LogWarning("Laporan dengan ID %s tidak ditemukan untuk dihapus", id.Hex())
+		return mongo.ErrNoDocuments
+	}
+	utils.LogInfo("Berhasil menghapus laporan dengan ID: %s", id.Hex())
+	return nil
+}
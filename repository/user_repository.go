@@ -23,6 +23,9 @@ type UserRepository interface {
 	UpdateUser(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*model.User, error)
 	DeleteUser(ctx context.Context, id primitive.ObjectID) error
 	IsSuperAdminExists(ctx context.Context) (bool, error)
+	FindUsersByBusinessID(ctx context.Context, businessID string) ([]model.User, error)
+	FindUsersByBusinessRoles(ctx context.Context, businessID string, roleIDs []string) ([]model.User, error)
+	ResetPresenceStatuses(ctx context.Context) (int64, error)
 }
 
 // userRepositoryImpl adalah implementasi dari UserRepository.
@@ -119,6 +122,49 @@ func (r *userRepositoryImpl) UpdateUser(ctx context.Context, id primitive.Object
 	return &updatedUser, nil
 }
 
+// FindUsersByBusinessID mengambil semua user yang memiliki businessID di BusinessIDs.
+func (r *userRepositoryImpl) FindUsersByBusinessID(ctx context.Context, businessID string) ([]model.User, error) {
+	return r.findUsers(ctx, bson.M{"businessIds": businessID}, "Gagal mengambil anggota bisnis: %s", businessID)
+}
+
+// FindUsersByBusinessRoles mengambil user yang memiliki salah satu roleIDs pada bisnis tersebut (roles.<businessId>).
+func (r *userRepositoryImpl) FindUsersByBusinessRoles(ctx context.Context, businessID string, roleIDs []string) ([]model.User, error) {
+	if len(roleIDs) == 0 {
+		return []model.User{}, nil
+	}
+	filter := bson.M{"roles." + businessID: bson.M{"$in": roleIDs}}
+	return r.findUsers(ctx, filter, "Gagal mengambil user berdasarkan role pada bisnis: %s", businessID)
+}
+
+// ResetPresenceStatuses mengubah status semua user yang masih online/idle menjadi offline. Dipanggil saat
+// server mulai, karena status tersebut berasal dari koneksi WebSocket proses sebelumnya.
+func (r *userRepositoryImpl) ResetPresenceStatuses(ctx context.Context) (int64, error) {
+	filter := bson.M{"status": bson.M{"$in": []string{model.UserStatusOnline, model.UserStatusIdle}}}
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": model.UserStatusOffline}})
+	if err != nil {
+		utils.LogError(err, "Gagal mereset status presence user")
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// findUsers menjalankan query user dengan filter dan mencatat errorMsg jika gagal.
+func (r *userRepositoryImpl) findUsers(ctx context.Context, filter bson.M, errorMsg string, args ...interface{}) ([]model.User, error) {
+	users := []model.User{}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		utils.LogError(err, errorMsg, args...)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &users); err != nil {
+		utils.LogError(err, errorMsg, args...)
+		return nil, err
+	}
+	return users, nil
+}
+
 // DeleteUser menghapus pengguna berdasarkan ID.
 func (r *userRepositoryImpl) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
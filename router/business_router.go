@@ -12,12 +12,14 @@ import (
 
 // SetupBusinessRoutes mendaftarkan semua rute API untuk entitas Business.
 // router adalah instance Fiber.Router (bisa *fiber.App atau grup rute), dan dbClient adalah koneksi MongoDB.
-func SetupBusinessRoutes(router fiber.Router, dbClient *mongo.Client) {
+func SetupBusinessRoutes(router fiber.Router, dbClient *mongo.Client, presenceHub *handler.PresenceHub, deletionService service.DeletionService) {
 	// Inisialisasi repository dan handler untuk Business
 	businessRepo := repository.NewBusinessRepository(dbClient)
 	activityLogRepo := repository.NewActivityLogRepository(dbClient)
 	activityLogService := service.NewActivityLogService(activityLogRepo)
-	businessHandler := handler.NewBusinessHandler(businessRepo, activityLogService)
+	businessHandler := handler.NewBusinessHandler(businessRepo, activityLogService, deletionService)
+	readStateHandler := handler.NewReadStateHandler(newReadStateService(dbClient))
+	presenceHandler := handler.NewPresenceHandler(presenceHub)
 
 	// Menerapkan middleware autentikasi ke semua rute bisnis
 	businessRoutes := router.Group("/businesses", middleware.AuthMiddleware())
@@ -38,6 +40,12 @@ func SetupBusinessRoutes(router fiber.Router, dbClient *mongo.Client) {
 	// DELETE /api/v1/businesses/:id
 	businessRoutes.Delete("/:id", businessHandler.DeleteBusiness)
 
+	// GET /api/v1/businesses/:id/unread
+	businessRoutes.Get("/:id/unread", readStateHandler.GetBusinessUnreadCounts)
+
+	// GET /api/v1/businesses/:id/presence
+	businessRoutes.Get("/:id/presence", presenceHandler.GetBusinessPresence)
+
 	/*
 		Cara Penggunaan Middleware:
 
@@ -12,12 +12,12 @@ import (
 
 // SetupChannelRoutes mendaftarkan semua rute API untuk entitas Channel.
 // router adalah instance Fiber.Router (bisa *fiber.App atau grup rute), dan dbClient adalah koneksi MongoDB.
-func SetupChannelRoutes(router fiber.Router, dbClient *mongo.Client) {
+func SetupChannelRoutes(router fiber.Router, dbClient *mongo.Client, deletionService service.DeletionService) {
 	// Inisialisasi repository, service, dan handler untuk Channel
 	channelRepo := repository.NewChannelRepository(dbClient)
 	activityLogRepo := repository.NewActivityLogRepository(dbClient)
 	activityLogService := service.NewActivityLogService(activityLogRepo)
-	channelHandler := handler.NewChannelHandler(channelRepo, activityLogService)
+	channelHandler := handler.NewChannelHandler(channelRepo, repository.NewBusinessRepository(dbClient), activityLogService, deletionService)
 
 	// Menerapkan middleware autentikasi ke semua rute channel
 	channelRoutes := router.Group("/channels", middleware.AuthMiddleware())
@@ -1,42 +1,81 @@
 package router
 
 import (
+	"context"
+	"time"
+
 	"backend_my_manajer/handler"
 	"backend_my_manajer/middleware"
 	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // SetupDatabaseRoutes mendaftarkan rute untuk entitas Database.
 func SetupDatabaseRoutes(router fiber.Router, dbClient *mongo.Client) {
+	// Pindahkan baris yang masih tertanam di dokumen database ke koleksi database_rows dan siapkan index.
+	// Migrasi idempoten, sehingga jika gagal akan dicoba lagi pada startup berikutnya.
+	migrationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if migrated, err := repository.MigrateEmbeddedDatabaseRows(migrationCtx, dbClient); err != nil {
+		utils.LogError(err, "Migrasi baris database gagal")
+	} else if migrated > 0 {
+		utils.LogInfo("Migrasi baris database selesai. Total database dimigrasi: %d", migrated)
+	}
+	if err := repository.MigrateDatabaseVersions(migrationCtx, dbClient); err != nil {
+		utils.LogError(err, "Migrasi version database gagal")
+	}
+	if err := repository.EnsureDatabaseIndexes(migrationCtx, dbClient); err != nil {
+		utils.LogError(err, "Gagal menyiapkan index database")
+	}
+
 	dbRepo := repository.NewDatabaseRepository(dbClient)
 	userRepo := repository.NewUserRepository(dbClient) // Digunakan untuk otorisasi di handler
-	dbHandler := handler.NewDatabaseHandler(dbRepo, userRepo)
+	fileRepo := repository.NewDatabaseFileRepository(dbClient)
+	channelRepo := repository.NewChannelRepository(dbClient)
+	businessRepo := repository.NewBusinessRepository(dbClient)
+	// Memvalidasi channel tujuan saat database dibuat, diduplikasi atau dibuat dari template
+	channelAccess := service.NewChannelAccessService(channelRepo, businessRepo, userRepo)
+	realtimeHub := handler.NewDatabaseRealtimeHub() // Dipakai bersama oleh handler REST dan WebSocket database
 
-	dbRoutes := router.Group("/databases")
+	// Storage isi file kolom bertipe file; jika konfigurasinya salah, endpoint file mengembalikan 503
+	fileStorage, err := service.NewFileStorageFromEnv()
+	if err != nil {
+		utils.LogError(err, "Gagal menyiapkan file storage database")
+		fileStorage = nil
+	}
+	dbHandler := handler.NewDatabaseHandler(dbRepo, userRepo, fileRepo, realtimeHub, channelAccess, fileStorage)
+	fileHandler := handler.NewDatabaseFileHandler(fileRepo, dbRepo, fileStorage, realtimeHub)
 
 	// Middleware autentikasi untuk semua rute database
-	// dbRoutes.Use(middleware.AuthMiddleware())
+	dbRoutes := router.Group("/databases", middleware.AuthMiddleware())
 
 	// Rute CRUD untuk Database
 	dbRoutes.Post("/", dbHandler.CreateDatabase)
 	dbRoutes.Get("/:id", dbHandler.GetDatabaseByID)
 	dbRoutes.Put("/:id", dbHandler.UpdateDatabase)
 	dbRoutes.Delete("/:id", dbHandler.DeleteDatabase)
+	dbRoutes.Post("/:id/duplicate", dbHandler.DuplicateDatabase)
 
 	// Rute untuk mendapatkan database berdasarkan ChannelID
 	dbRoutes.Get("/channel/:channelId", dbHandler.GetDatabasesByChannelID)
 
 	// Rute CRUD untuk baris data dalam database
 	dbRoutes.Post("/:id/rows", dbHandler.AddRowToDatabase)
+	dbRoutes.Post("/:id/rows/batch", dbHandler.BatchRowOperations)
+	dbRoutes.Get("/:id/rows/deleted", dbHandler.GetDeletedRows) // Harus sebelum /:id/rows/:rowId
 	dbRoutes.Put("/:id/rows/:rowId", dbHandler.UpdateRowInDatabase)
 	dbRoutes.Delete("/:id/rows/:rowId", dbHandler.DeleteRowFromDatabase)
 	dbRoutes.Get("/:id/rows/:rowId", dbHandler.GetRowInDatabase) // Menambahkan rute GET untuk baris tunggal
 	dbRoutes.Get("/:id/rows", dbHandler.GetRowsByDatabaseID)     // Menambahkan rute GET untuk semua baris dalam database
 
 	// Rute CRUD untuk kolom dalam database
+	dbRoutes.Post("/:id/columns", dbHandler.AddColumnToDatabase)
+	dbRoutes.Patch("/:id/columns/reorder", dbHandler.ReorderColumnsInDatabase)
 	dbRoutes.Put("/:id/columns/:columnId", dbHandler.UpdateColumnInDatabase)
 	dbRoutes.Delete("/:id/columns/:columnId", dbHandler.DeleteColumnFromDatabase)
 	dbRoutes.Get("/:id/columns/:columnId", dbHandler.GetColumnInDatabase) // Menambahkan rute GET untuk kolom
@@ -47,5 +86,43 @@ func SetupDatabaseRoutes(router fiber.Router, dbClient *mongo.Client) {
 	dbRoutes.Delete("/:id/columns/:columnId/options/:optionId", dbHandler.DeleteSelectOptionFromColumn)
 	dbRoutes.Get("/:id/columns/:columnId/options/:optionId", dbHandler.GetSelectOptionInColumn) // Menambahkan rute GET untuk select option
 
+	// Rute import dan export CSV/XLSX
+	dbRoutes.Post("/import", dbHandler.ImportDatabase)
+	dbRoutes.Post("/:id/import", dbHandler.ImportRowsToDatabase)
+	dbRoutes.Get("/:id/export", dbHandler.ExportDatabase)
+
+	// Rute untuk view tersimpan (table, kanban, calendar)
+	dbRoutes.Get("/:id/views", dbHandler.GetDatabaseViews)
+	dbRoutes.Post("/:id/views", dbHandler.CreateDatabaseView)
+	dbRoutes.Put("/:id/views/:viewId", dbHandler.UpdateDatabaseView)
+	dbRoutes.Delete("/:id/views/:viewId", dbHandler.DeleteDatabaseView)
+	dbRoutes.Get("/:id/views/:viewId/rows", dbHandler.GetDatabaseViewRows)
+
+	// Rute untuk file kolom bertipe file
+	dbRoutes.Post("/:id/files", fileHandler.UploadFile)
+	dbRoutes.Get("/:id/files/:fileId", fileHandler.GetFile)
+	dbRoutes.Get("/:id/files/:fileId/download", fileHandler.DownloadFile)
+	dbRoutes.Delete("/:id/files/:fileId", fileHandler.DeleteFile)
+
+	// Rute untuk riwayat perubahan baris
+	dbRoutes.Get("/:id/rows/:rowId/revisions", dbHandler.GetRowRevisions)
+	dbRoutes.Post("/:id/rows/:rowId/revisions/:revisionId/restore", dbHandler.RestoreRowRevision)
+
+	// Rute untuk template database (skema tersimpan per bisnis)
+	templateRepo := repository.NewDatabaseTemplateRepository(dbClient)
+	templateHandler := handler.NewDatabaseTemplateHandler(templateRepo, dbRepo, channelRepo, channelAccess)
+
+	templateRoutes := router.Group("/database-templates", middleware.AuthMiddleware())
+	templateRoutes.Post("/", templateHandler.CreateTemplate)
+	templateRoutes.Get("/business/:businessId", templateHandler.GetTemplatesByBusinessID)
+	templateRoutes.Get("/:id", templateHandler.GetTemplateByID)
+	templateRoutes.Delete("/:id", templateHandler.DeleteTemplate)
+	templateRoutes.Post("/:id/instantiate", templateHandler.InstantiateTemplate)
+
+	// WebSocket untuk kolaborasi realtime pada database, di samping /ws/messages/:channelId.
+	// Autentikasi lewat query token seperti WebSocket pesan.
+	realtimeHandler := handler.NewDatabaseRealtimeHandler(dbRepo, realtimeHub)
+	router.Get("/ws/databases/:id", middleware.WebSocketAuthMiddleware(), websocket.New(realtimeHandler.HandleDatabaseWebSocket))
+
 	// Rute CRUD untuk rows dalam database
 }
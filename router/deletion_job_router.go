@@ -0,0 +1,42 @@
+package router
+
+import (
+	"context"
+
+	"backend_my_manajer/handler"
+	"backend_my_manajer/middleware"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// newDeletionService membuat DeletionService yang dibagikan SetupRoutes ke handler channel, bisnis dan job
+// penghapusan. Job disimpan di database sehingga beberapa instance service tetap berbagi progres yang sama.
+func newDeletionService(dbClient *mongo.Client) service.DeletionService {
+	fileStorage, err := service.NewFileStorageFromEnv()
+	if err != nil {
+		utils.LogError(err, "Gagal menyiapkan file storage untuk penghapusan berantai; isi file tidak ikut dihapus")
+		fileStorage = nil
+	}
+	return service.NewDeletionService(
+		repository.NewDeletionRepository(dbClient),
+		repository.NewChannelRepository(dbClient),
+		repository.NewBusinessRepository(dbClient),
+		fileStorage,
+	)
+}
+
+// SetupDeletionJobRoutes mendaftarkan rute status job penghapusan berantai dan melanjutkan job yang
+// tertinggal saat server sebelumnya berhenti.
+func SetupDeletionJobRoutes(router fiber.Router, deletionService service.DeletionService) {
+	go deletionService.ResumeUnfinishedJobs(context.Background())
+
+	deletionJobHandler := handler.NewDeletionJobHandler(deletionService)
+
+	// Middleware autentikasi untuk semua rute job penghapusan
+	deletionJobRoutes := router.Group("/deletion-jobs", middleware.AuthMiddleware())
+	deletionJobRoutes.Get("/:id", deletionJobHandler.GetDeletionJob)
+}
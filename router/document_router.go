@@ -0,0 +1,30 @@
+package router
+
+import (
+	"backend_my_manajer/handler"
+	"backend_my_manajer/middleware"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SetupDocumentRoutes mendaftarkan rute untuk entitas Document.
+func SetupDocumentRoutes(router fiber.Router, dbClient *mongo.Client) {
+	docRepo := repository.NewDocumentRepository(dbClient)
+	channelAccess := service.NewChannelAccessService(repository.NewChannelRepository(dbClient), repository.NewBusinessRepository(dbClient), repository.NewUserRepository(dbClient))
+	docHandler := handler.NewDocumentHandler(docRepo, channelAccess)
+
+	// Middleware autentikasi untuk semua rute dokumen
+	docRoutes := router.Group("/documents", middleware.AuthMiddleware())
+
+	// Rute CRUD untuk Document
+	docRoutes.Post("/", docHandler.CreateDocument)
+	docRoutes.Get("/:id", docHandler.GetDocumentByID)
+	docRoutes.Put("/:id", docHandler.UpdateDocument)
+	docRoutes.Delete("/:id", docHandler.DeleteDocument)
+
+	// Rute untuk mendapatkan dokumen berdasarkan ChannelID
+	docRoutes.Get("/channel/:channelId", docHandler.GetDocumentsByChannelID)
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"backend_my_manajer/handler"
+	"backend_my_manajer/middleware"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SetupDrawingRoutes mendaftarkan rute untuk entitas Drawing.
+func SetupDrawingRoutes(router fiber.Router, dbClient *mongo.Client) {
+	drawingRepo := repository.NewDrawingRepository(dbClient)
+	channelAccess := service.NewChannelAccessService(repository.NewChannelRepository(dbClient), repository.NewBusinessRepository(dbClient), repository.NewUserRepository(dbClient))
+	drawingHandler := handler.NewDrawingHandler(drawingRepo, channelAccess)
+
+	// Middleware autentikasi untuk semua rute gambar
+	drawingRoutes := router.Group("/drawings", middleware.AuthMiddleware())
+
+	// Rute CRUD untuk Drawing
+	drawingRoutes.Post("/", drawingHandler.CreateDrawing)
+	drawingRoutes.Get("/:id", drawingHandler.GetDrawingByID)
+	drawingRoutes.Put("/:id", drawingHandler.UpdateDrawing)
+	drawingRoutes.Delete("/:id", drawingHandler.DeleteDrawing)
+
+	// Rute untuk mendapatkan gambar berdasarkan ChannelID
+	drawingRoutes.Get("/channel/:channelId", drawingHandler.GetDrawingsByChannelID)
+
+	// Rute untuk shape dalam gambar
+	drawingRoutes.Post("/:id/shapes", drawingHandler.AddShapeToDrawing)
+	drawingRoutes.Patch("/:id/shapes/reorder", drawingHandler.ReorderShapesInDrawing)
+	drawingRoutes.Put("/:id/shapes/:shapeId", drawingHandler.UpdateShapeInDrawing)
+	drawingRoutes.Delete("/:id/shapes/:shapeId", drawingHandler.DeleteShapeFromDrawing)
+}
@@ -1,24 +1,62 @@
 package router
 
 import (
+	"context"
+	"time"
+
 	"backend_my_manajer/handler"
 	"backend_my_manajer/middleware" // Pastikan middleware diimpor
 	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
 
 	"github.com/gofiber/contrib/websocket" // Import Fiber WebSocket
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// newReadStateService membuat ReadStateService untuk mark_read di WebSocket pesan dan endpoint unread bisnis.
+func newReadStateService(dbClient *mongo.Client) service.ReadStateService {
+	channelRepo := repository.NewChannelRepository(dbClient)
+	return service.NewReadStateService(
+		repository.NewReadStateRepository(dbClient),
+		repository.NewMessageRepository(dbClient),
+		channelRepo,
+		service.NewChannelAccessService(channelRepo, repository.NewBusinessRepository(dbClient), repository.NewUserRepository(dbClient)),
+	)
+}
+
 // SetupMessageRoutes mendaftarkan rute WebSocket untuk entitas Message.
-func SetupMessageRoutes(api fiber.Router, dbClient *mongo.Client) {
+// notificationHub dipakai bersama dengan rute notifikasi agar notifikasi mention terkirim secara live.
+// presenceHub mencatat presence online/idle/offline dari siklus koneksi WebSocket pesan.
+func SetupMessageRoutes(api fiber.Router, dbClient *mongo.Client, notificationHub *handler.NotificationHub, presenceHub *handler.PresenceHub) {
+	// Siapkan index riwayat channel dan thread balasan
+	indexCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := repository.EnsureMessageIndexes(indexCtx, dbClient); err != nil {
+		utils.LogError(err, "Gagal menyiapkan index pesan")
+	}
+	if err := repository.EnsureReadStateIndexes(indexCtx, dbClient); err != nil {
+		utils.LogError(err, "Gagal menyiapkan index posisi baca channel")
+	}
+
 	// Inisialisasi repository dan handler untuk Message
 	messageRepo := repository.NewMessageRepository(dbClient)
-	messageHandler := handler.NewMessageHandler(messageRepo)
+	notificationService := service.NewNotificationService(
+		repository.NewNotificationRepository(dbClient),
+		repository.NewChannelRepository(dbClient),
+		repository.NewBusinessRepository(dbClient),
+		repository.NewRoleRepository(dbClient),
+		repository.NewUserRepository(dbClient),
+	)
+	channelAccess := service.NewChannelAccessService(repository.NewChannelRepository(dbClient), repository.NewBusinessRepository(dbClient), repository.NewUserRepository(dbClient))
+	messageHandler := handler.NewMessageHandler(messageRepo, channelAccess, notificationService, notificationHub, newReadStateService(dbClient), presenceHub)
 
 	// Grup untuk WebSocket dengan middleware autentikasi
 	wsGroup := api.Group("/ws", middleware.WebSocketAuthMiddleware())
 
-	// Terapkan middleware ke rute spesifik
+	// Satu koneksi untuk semua channel: client mengirim subscribe/unsubscribe per channel
+	wsGroup.Get("/messages", websocket.New(messageHandler.HandleWebSocketMessage))
+	// Kompatibilitas client lama yang membuka satu koneksi per channel
 	wsGroup.Get("/messages/:channelId", websocket.New(messageHandler.HandleWebSocketMessage))
 }
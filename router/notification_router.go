@@ -0,0 +1,45 @@
+package router
+
+import (
+	"context"
+	"time"
+
+	"backend_my_manajer/handler"
+	"backend_my_manajer/middleware"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+	"backend_my_manajer/utils"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// newPresenceHub membuat PresenceHub. Status presence dari proses sebelumnya direset oleh hub saat dibuat.
+func newPresenceHub(dbClient *mongo.Client, notificationHub *handler.NotificationHub) *handler.PresenceHub {
+	userRepo := repository.NewUserRepository(dbClient)
+	businessRepo := repository.NewBusinessRepository(dbClient)
+	channelAccess := service.NewChannelAccessService(repository.NewChannelRepository(dbClient), businessRepo, userRepo)
+	return handler.NewPresenceHub(userRepo, businessRepo, channelAccess, notificationHub)
+}
+
+// SetupNotificationRoutes mendaftarkan rute REST dan WebSocket untuk notifikasi in-app.
+func SetupNotificationRoutes(router fiber.Router, dbClient *mongo.Client, notificationHub *handler.NotificationHub, presenceHub *handler.PresenceHub) {
+	indexCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := repository.EnsureNotificationIndexes(indexCtx, dbClient); err != nil {
+		utils.LogError(err, "Gagal menyiapkan index notifikasi")
+	}
+
+	notificationRepo := repository.NewNotificationRepository(dbClient)
+	notificationHandler := handler.NewNotificationHandler(notificationRepo, notificationHub, presenceHub)
+
+	// Middleware autentikasi untuk semua rute notifikasi
+	notificationRoutes := router.Group("/notifications", middleware.AuthMiddleware())
+	notificationRoutes.Get("/", notificationHandler.GetNotifications)
+	notificationRoutes.Patch("/read-all", notificationHandler.MarkAllNotificationsRead)
+	notificationRoutes.Patch("/:id/read", notificationHandler.MarkNotificationRead)
+
+	// WebSocket per user untuk notifikasi live dan langganan presence, di samping /ws/messages/:channelId
+	router.Get("/ws/notifications", middleware.WebSocketAuthMiddleware(), websocket.New(notificationHandler.HandleNotificationWebSocket))
+}
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"backend_my_manajer/handler"
+	"backend_my_manajer/middleware"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/service"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SetupReportRoutes mendaftarkan rute untuk entitas Report.
+func SetupReportRoutes(router fiber.Router, dbClient *mongo.Client) {
+	reportRepo := repository.NewReportRepository(dbClient)
+	dbRepo := repository.NewDatabaseRepository(dbClient)
+	reportService := service.NewReportService(dbRepo)
+	channelAccess := service.NewChannelAccessService(repository.NewChannelRepository(dbClient), repository.NewBusinessRepository(dbClient), repository.NewUserRepository(dbClient))
+	reportHandler := handler.NewReportHandler(reportRepo, reportService, channelAccess)
+
+	// Middleware autentikasi untuk semua rute laporan
+	reportRoutes := router.Group("/reports", middleware.AuthMiddleware())
+
+	// Rute CRUD untuk Report
+	reportRoutes.Post("/", reportHandler.CreateReport)
+	reportRoutes.Get("/:id", reportHandler.GetReportByID)
+	reportRoutes.Put("/:id", reportHandler.UpdateReport)
+	reportRoutes.Delete("/:id", reportHandler.DeleteReport)
+
+	// Rute untuk mengevaluasi laporan terhadap database sumbernya
+	reportRoutes.Get("/:id/data", reportHandler.GetReportData)
+
+	// Rute untuk mendapatkan laporan berdasarkan ChannelID
+	reportRoutes.Get("/channel/:channelId", reportHandler.GetReportsByChannelID)
+}
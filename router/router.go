@@ -1,6 +1,8 @@
 package router
 
 import (
+	"backend_my_manajer/handler"
+
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -10,16 +12,28 @@ func SetupRoutes(app *fiber.App, dbClient *mongo.Client) {
 	// Membuat grup route utama, misalnya /api/v1
 	api := app.Group("/api/v1")
 
+	// Hub notifikasi dipakai bersama oleh rute pesan (pembuat notifikasi) dan rute notifikasi (penerima)
+	notificationHub := handler.NewNotificationHub()
+	// Hub presence diisi oleh WebSocket pesan, di-query lewat rute bisnis dan di-subscribe lewat WebSocket notifikasi
+	presenceHub := newPresenceHub(dbClient, notificationHub)
+	// Satu DeletionService dipakai rute bisnis, channel dan job penghapusan agar job yang berjalan tercatat di satu tempat
+	deletionService := newDeletionService(dbClient)
+
 	// Mendaftarkan rute untuk setiap entitas
 	SetupAuthRoutes(api, dbClient)
 	SetupUserRoutes(api, dbClient)
 	SetupSuperAdminRoutes(api, dbClient)
-	SetupBusinessRoutes(api, dbClient)
-	SetupChannelRoutes(api, dbClient)
+	SetupBusinessRoutes(api, dbClient, presenceHub, deletionService)
+	SetupChannelRoutes(api, dbClient, deletionService)
 	SetupChannelCategoryRoutes(api, dbClient)
-	SetupMessageRoutes(api, dbClient)
+	SetupMessageRoutes(api, dbClient, notificationHub, presenceHub)
+	SetupNotificationRoutes(api, dbClient, notificationHub, presenceHub)
 	SetupRoleRoutes(api, dbClient)
 	SetupDatabaseRoutes(api, dbClient)    // Menambahkan SetupDatabaseRoutes
 	SetupActivityLogRoutes(api, dbClient) // Menambahkan rute untuk log aktivitas
+	SetupDocumentRoutes(api, dbClient)
+	SetupDrawingRoutes(api, dbClient)
+	SetupReportRoutes(api, dbClient)
+	SetupDeletionJobRoutes(api, deletionService)
 	// Tambahkan setup route lain di sini jika ada
 }
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tipe channel (lihat model.Channel.Type). Selain messages, tipe ini menampung konten.
+const (
+	ChannelTypeMessages  = "messages"
+	ChannelTypeDatabases = "databases"
+	ChannelTypeDocuments = "documents"
+	ChannelTypeDrawings  = "drawings"
+	ChannelTypeReports   = "reports"
+)
+
+var (
+	// ErrChannelNotFound dikembalikan jika channel tidak ada atau bisnisnya sudah tidak ada.
+	ErrChannelNotFound = errors.New("channel tidak ditemukan")
+	// ErrNotBusinessMember dikembalikan jika user bukan pemilik maupun anggota bisnis channel.
+	ErrNotBusinessMember = errors.New("user bukan anggota bisnis channel")
+	// ErrChannelTypeMismatch dikembalikan jika tipe channel tidak sesuai dengan jenis konten.
+	ErrChannelTypeMismatch = errors.New("tipe channel tidak sesuai")
+)
+
+// ChannelAccessService adalah antarmuka untuk memeriksa channel tujuan konten (database, dokumen,
+// drawing, laporan) dan keanggotaan bisnis user.
+type ChannelAccessService interface {
+	// ValidateContentChannel memastikan channel ada, user adalah pemilik atau anggota bisnis channel,
+	// dan tipe channel sama dengan channelType. Mengembalikan channel tersebut.
+	ValidateContentChannel(ctx context.Context, channelID, userID primitive.ObjectID, channelType string) (*model.Channel, error)
+	// IsBusinessMember memeriksa apakah user adalah pemilik bisnis atau memiliki bisnis tersebut di BusinessIDs.
+	IsBusinessMember(ctx context.Context, businessID, userID primitive.ObjectID) (bool, error)
+}
+
+type channelAccessServiceImpl struct {
+	channelRepo  repository.ChannelRepository
+	businessRepo repository.BusinessRepository
+	userRepo     repository.UserRepository
+}
+
+// NewChannelAccessService membuat instance baru dari ChannelAccessService.
+func NewChannelAccessService(channelRepo repository.ChannelRepository, businessRepo repository.BusinessRepository, userRepo repository.UserRepository) ChannelAccessService {
+	return &channelAccessServiceImpl{channelRepo: channelRepo, businessRepo: businessRepo, userRepo: userRepo}
+}
+
+// ValidateContentChannel memeriksa channel tujuan secara berurutan: keberadaan, keanggotaan bisnis, lalu tipe.
+// Tipe diperiksa terakhir agar user di luar bisnis tidak dapat mengetahui tipe channel milik bisnis lain.
+func (s *channelAccessServiceImpl) ValidateContentChannel(ctx context.Context, channelID, userID primitive.ObjectID, channelType string) (*model.Channel, error) {
+	channel, err := s.channelRepo.GetChannelByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotFound, channelID.Hex())
+	}
+
+	business, err := s.businessRepo.GetBusinessByID(ctx, channel.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, fmt.Errorf("%w: bisnis channel %s tidak ditemukan", ErrChannelNotFound, channelID.Hex())
+	}
+	if business.OwnerID != userID.Hex() {
+		member, err := s.isListedMember(ctx, business.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, fmt.Errorf("%w: %s", ErrNotBusinessMember, business.ID.Hex())
+		}
+	}
+
+	if channel.Type != channelType {
+		return nil, fmt.Errorf("%w: channel '%s' bertipe '%s', dibutuhkan '%s'", ErrChannelTypeMismatch, channel.Name, channel.Type, channelType)
+	}
+	return channel, nil
+}
+
+// IsBusinessMember memeriksa pemilik bisnis terlebih dahulu, lalu daftar BusinessIDs milik user.
+func (s *channelAccessServiceImpl) IsBusinessMember(ctx context.Context, businessID, userID primitive.ObjectID) (bool, error) {
+	business, err := s.businessRepo.GetBusinessByID(ctx, businessID)
+	if err != nil {
+		return false, err
+	}
+	if business != nil && business.OwnerID == userID.Hex() {
+		return true, nil
+	}
+	return s.isListedMember(ctx, businessID, userID)
+}
+
+// isListedMember memeriksa apakah businessID tercantum di BusinessIDs milik user.
+func (s *channelAccessServiceImpl) isListedMember(ctx context.Context, businessID, userID primitive.ObjectID) (bool, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, nil
+	}
+	for _, id := range user.BusinessIDs {
+		if id == businessID.Hex() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
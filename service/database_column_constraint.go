@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Batasan kolom (DatabaseColumn.Constraints) diperiksa setelah ValidateRowValues setiap kali baris
+// ditambah atau diubah:
+//   - required     : nilai tidak boleh kosong (null, teks kosong, atau relation tanpa baris)
+//   - unique       : nilai yang tidak kosong tidak boleh sama dengan baris lain di database yang sama
+//                    (text dibandingkan persis; hanya untuk text, number, date dan select)
+//   - defaultValue : diisikan ke baris baru yang tidak mengirim kolom tersebut (bukan untuk relation dan file)
+//   - min, max     : batas inklusif untuk number dan date
+//   - pattern      : regex (sintaks RE2) yang harus cocok dengan seluruh teks, hanya untuk text
+//
+// Batasan berlaku untuk penulisan berikutnya; baris lama yang melanggar required, min, max atau pattern
+// tidak diubah. Khusus unique, batasan ditolak jika kolom sudah berisi nilai duplikat (FindUniqueConflicts).
+
+// ErrInvalidColumnConstraint dikembalikan jika konfigurasi batasan kolom tidak valid.
+var ErrInvalidColumnConstraint = errors.New("batasan kolom tidak valid")
+
+// ErrUniqueLookupFailed dikembalikan RowConstraintChecker jika pencarian nilai unique di database gagal.
+var ErrUniqueLookupFailed = errors.New("gagal memeriksa nilai unique")
+
+// UniqueConflict menjelaskan satu nilai yang dipakai lebih dari satu baris.
+type UniqueConflict struct {
+	Value  string   `json:"value"`
+	RowIDs []string `json:"rowIds"`
+}
+
+// NormalizeColumnConstraints memeriksa batasan kolom terhadap tipenya dan mengonversi DefaultValue,
+// Min dan Max ke tipe kanonik kolom (in-place). Batasan yang seluruhnya kosong dihapus (nil).
+func NormalizeColumnConstraints(col *model.DatabaseColumn) error {
+	c := col.Constraints
+	if c == nil {
+		return nil
+	}
+	if !c.Required && !c.Unique && c.DefaultValue == nil && c.Min == nil && c.Max == nil && c.Pattern == "" {
+		col.Constraints = nil
+		return nil
+	}
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: kolom '%s': %s", ErrInvalidColumnConstraint, col.Name, fmt.Sprintf(format, args...))
+	}
+
+	switch col.Type {
+	case "formula", "rollup":
+		return invalid("kolom %s dihitung oleh server dan tidak mendukung batasan", col.Type)
+	}
+	if c.Unique {
+		switch col.Type {
+		case "text", "number", "date", "select":
+		default:
+			return invalid("unique hanya didukung untuk kolom text, number, date dan select")
+		}
+	}
+	if c.Pattern != "" {
+		if col.Type != "text" {
+			return invalid("pattern hanya didukung untuk kolom text")
+		}
+		if _, err := compileConstraintPattern(c.Pattern); err != nil {
+			return invalid("pattern tidak valid: %v", err)
+		}
+	}
+	if c.Min != nil || c.Max != nil {
+		if col.Type != "number" && col.Type != "date" {
+			return invalid("min dan max hanya didukung untuk kolom number dan date")
+		}
+		for _, bound := range []*interface{}{&c.Min, &c.Max} {
+			if *bound == nil {
+				continue
+			}
+			value, err := coerceCellValue(col, *bound)
+			if err != nil {
+				return invalid("min/max: %v", err)
+			}
+			*bound = value
+		}
+		if c.Min != nil && c.Max != nil && compareBound(col, c.Min, c.Max) > 0 {
+			return invalid("min tidak boleh lebih besar dari max")
+		}
+	}
+	if c.DefaultValue != nil {
+		if col.Type == "relation" || col.Type == "file" {
+			return invalid("defaultValue tidak didukung untuk kolom %s", col.Type)
+		}
+		value, err := coerceCellValue(col, c.DefaultValue)
+		if err != nil {
+			return invalid("defaultValue: %v", err)
+		}
+		if message := checkValueConstraints(col, value, nil); message != "" {
+			return invalid("defaultValue: %s", message)
+		}
+		c.DefaultValue = value
+	}
+	return nil
+}
+
+// FindUniqueConflicts mengembalikan nilai kolom yang dipakai lebih dari satu baris, sesuai urutan baris.
+func FindUniqueConflicts(col *model.DatabaseColumn, rows []model.DatabaseRow) []UniqueConflict {
+	columnID := col.ID.Hex()
+	byKey := make(map[string]*UniqueConflict)
+	var keys []string
+	for _, row := range rows {
+		value := row.Values[columnID]
+		key, ok := uniqueKey(col, value)
+		if !ok {
+			continue
+		}
+		conflict, exists := byKey[key]
+		if !exists {
+			conflict = &UniqueConflict{Value: CellDisplayValue(col, value)}
+			byKey[key] = conflict
+			keys = append(keys, key)
+		}
+		conflict.RowIDs = append(conflict.RowIDs, row.ID.Hex())
+	}
+
+	conflicts := make([]UniqueConflict, 0)
+	for _, key := range keys {
+		if conflict := byKey[key]; len(conflict.RowIDs) > 1 {
+			conflicts = append(conflicts, *conflict)
+		}
+	}
+	return conflicts
+}
+
+// RowConstraintChecker memeriksa batasan kolom untuk baris-baris yang ditulis dalam satu request.
+// Nilai unik baris yang sudah lolos pemeriksaan disimpan, sehingga duplikat di antara baris dalam request
+// yang sama (import, batch) juga terdeteksi. Baris tersimpan diberikan langsung (NewRowConstraintChecker)
+// atau dicari di database per nilai (NewStoredRowConstraintChecker).
+type RowConstraintChecker struct {
+	columns  []model.DatabaseColumn
+	patterns map[string]*regexp.Regexp
+	owners   map[string]map[string]primitive.ObjectID // ID kolom -> kunci nilai -> ID baris pemilik
+	rowKeys  map[primitive.ObjectID]map[string]string // ID baris -> ID kolom -> kunci nilai
+	pending  map[primitive.ObjectID]bool              // ID sementara baris baru yang belum tersimpan
+
+	// lookup mencari baris tersimpan lain yang memakai nilai kolom; nil jika baris tersimpan sudah dicatat
+	lookup func(columnID string, value interface{}, excludeIDs []primitive.ObjectID) (primitive.ObjectID, error)
+	// touched berisi baris tersimpan yang nilainya sudah ditentukan request ini (Check atau Forget),
+	// sehingga nilai lamanya di database diabaikan oleh lookup
+	touched map[primitive.ObjectID]bool
+}
+
+// NewRowConstraintChecker membuat checker untuk kolom database dan baris yang sudah tersimpan.
+func NewRowConstraintChecker(columns []model.DatabaseColumn, rows []model.DatabaseRow) *RowConstraintChecker {
+	checker := &RowConstraintChecker{
+		columns:  columns,
+		patterns: make(map[string]*regexp.Regexp),
+		owners:   make(map[string]map[string]primitive.ObjectID),
+		rowKeys:  make(map[primitive.ObjectID]map[string]string),
+		pending:  make(map[primitive.ObjectID]bool),
+	}
+	for i := range columns {
+		col := &columns[i]
+		if col.Constraints == nil {
+			continue
+		}
+		if col.Constraints.Pattern != "" {
+			if re, err := compileConstraintPattern(col.Constraints.Pattern); err == nil {
+				checker.patterns[col.ID.Hex()] = re
+			}
+		}
+		if col.Constraints.Unique {
+			checker.owners[col.ID.Hex()] = make(map[string]primitive.ObjectID)
+		}
+	}
+	for _, row := range rows {
+		checker.record(row.ID, row.Values)
+	}
+	return checker
+}
+
+// NewStoredRowConstraintChecker membuat checker yang mencari nilai unique baris tersimpan langsung di
+// database databaseID, sehingga seluruh baris database tidak perlu dimuat. ctx dipakai oleh setiap Check.
+func NewStoredRowConstraintChecker(ctx context.Context, dbRepo repository.DatabaseRepository, databaseID primitive.ObjectID, columns []model.DatabaseColumn) *RowConstraintChecker {
+	checker := NewRowConstraintChecker(columns, nil)
+	checker.lookup = func(columnID string, value interface{}, excludeIDs []primitive.ObjectID) (primitive.ObjectID, error) {
+		return dbRepo.FindRowIDByValue(ctx, databaseID, columnID, value, excludeIDs)
+	}
+	return checker
+}
+
+// Check memeriksa values (hasil ValidateRowValues) milik baris rowID. rowID kosong berarti baris baru:
+// DefaultValue diisikan untuk kolom yang tidak dikirim. Jika valid, nilai unik baris dicatat dan
+// values (beserta default) dikembalikan; jika tidak, error bertipe *RowValidationError.
+func (checker *RowConstraintChecker) Check(rowID primitive.ObjectID, values model.DatabaseRowValue) (model.DatabaseRowValue, error) {
+	if values == nil {
+		values = model.DatabaseRowValue{}
+	}
+	isNew := rowID.IsZero()
+	if isNew {
+		// ID sementara agar baris baru tidak dianggap pemilik nilai unik baris baru lainnya
+		rowID = primitive.NewObjectID()
+	}
+
+	var fieldErrors []RowFieldError
+	for i := range checker.columns {
+		col := &checker.columns[i]
+		c := col.Constraints
+		if c == nil {
+			continue
+		}
+		columnID := col.ID.Hex()
+		if _, sent := values[columnID]; isNew && !sent && c.DefaultValue != nil {
+			value, err := coerceCellValue(col, c.DefaultValue)
+			if err != nil {
+				fieldErrors = append(fieldErrors, RowFieldError{ColumnID: columnID, ColumnName: col.Name, Message: "defaultValue kolom tidak valid lagi: " + err.Error()})
+				continue
+			}
+			values[columnID] = value
+		}
+
+		value := values[columnID]
+		if isEmptyValue(value) {
+			if c.Required {
+				fieldErrors = append(fieldErrors, RowFieldError{ColumnID: columnID, ColumnName: col.Name, Message: "kolom wajib diisi"})
+			}
+			continue
+		}
+		if message := checkValueConstraints(col, value, checker.patterns[columnID]); message != "" {
+			fieldErrors = append(fieldErrors, RowFieldError{ColumnID: columnID, ColumnName: col.Name, Message: message})
+			continue
+		}
+		if owners, ok := checker.owners[columnID]; ok {
+			key, _ := uniqueKey(col, value)
+			owner, taken := owners[key]
+			if !taken && checker.lookup != nil {
+				stored, err := checker.lookup(columnID, value, checker.excludedRows(rowID, isNew))
+				if err != nil {
+					return nil, fmt.Errorf("%w: %v", ErrUniqueLookupFailed, err)
+				}
+				owner, taken = stored, !stored.IsZero()
+			}
+			if taken && owner != rowID {
+				message := fmt.Sprintf("nilai '%s' sudah dipakai baris lain", CellDisplayValue(col, value))
+				if !checker.pending[owner] {
+					message = fmt.Sprintf("nilai '%s' sudah dipakai baris %s", CellDisplayValue(col, value), owner.Hex())
+				}
+				fieldErrors = append(fieldErrors, RowFieldError{ColumnID: columnID, ColumnName: col.Name, Message: message})
+			}
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &RowValidationError{Errors: fieldErrors}
+	}
+
+	checker.release(rowID)
+	checker.record(rowID, values)
+	if isNew {
+		checker.pending[rowID] = true
+	} else {
+		checker.markTouched(rowID)
+	}
+	return values, nil
+}
+
+// Forget melepas nilai unik milik baris (misalnya baris yang dihapus dalam batch yang sama).
+func (checker *RowConstraintChecker) Forget(rowID primitive.ObjectID) {
+	checker.release(rowID)
+	checker.markTouched(rowID)
+}
+
+// excludedRows mengembalikan baris tersimpan yang diabaikan lookup: baris yang sedang diperiksa dan
+// baris yang sudah disentuh request ini.
+func (checker *RowConstraintChecker) excludedRows(rowID primitive.ObjectID, isNew bool) []primitive.ObjectID {
+	excluded := make([]primitive.ObjectID, 0, len(checker.touched)+1)
+	if !isNew {
+		excluded = append(excluded, rowID)
+	}
+	for id := range checker.touched {
+		if id != rowID {
+			excluded = append(excluded, id)
+		}
+	}
+	return excluded
+}
+
+// markTouched mencatat baris tersimpan yang nilainya sudah ditentukan request ini.
+func (checker *RowConstraintChecker) markTouched(rowID primitive.ObjectID) {
+	if checker.lookup == nil {
+		return
+	}
+	if checker.touched == nil {
+		checker.touched = make(map[primitive.ObjectID]bool)
+	}
+	checker.touched[rowID] = true
+}
+
+// release melepas nilai unik yang tercatat untuk baris.
+func (checker *RowConstraintChecker) release(rowID primitive.ObjectID) {
+	for columnID, key := range checker.rowKeys[rowID] {
+		if owners, ok := checker.owners[columnID]; ok && owners[key] == rowID {
+			delete(owners, key)
+		}
+	}
+	delete(checker.rowKeys, rowID)
+}
+
+// record mencatat nilai unik milik baris.
+func (checker *RowConstraintChecker) record(rowID primitive.ObjectID, values model.DatabaseRowValue) {
+	keys := make(map[string]string)
+	for i := range checker.columns {
+		col := &checker.columns[i]
+		columnID := col.ID.Hex()
+		owners, ok := checker.owners[columnID]
+		if !ok {
+			continue
+		}
+		key, ok := uniqueKey(col, values[columnID])
+		if !ok {
+			continue
+		}
+		if _, taken := owners[key]; !taken {
+			owners[key] = rowID
+		}
+		keys[columnID] = key
+	}
+	checker.rowKeys[rowID] = keys
+}
+
+// checkValueConstraints memeriksa min, max dan pattern untuk nilai yang tidak kosong.
+// Mengembalikan pesan kesalahan, atau string kosong jika valid.
+func checkValueConstraints(col *model.DatabaseColumn, value interface{}, pattern *regexp.Regexp) string {
+	c := col.Constraints
+	if c == nil || isEmptyValue(value) {
+		return ""
+	}
+	if c.Min != nil && compareBound(col, value, c.Min) < 0 {
+		return fmt.Sprintf("nilai tidak boleh kurang dari %s", CellDisplayValue(col, c.Min))
+	}
+	if c.Max != nil && compareBound(col, value, c.Max) > 0 {
+		return fmt.Sprintf("nilai tidak boleh lebih dari %s", CellDisplayValue(col, c.Max))
+	}
+	if c.Pattern != "" {
+		if pattern == nil {
+			var err error
+			if pattern, err = compileConstraintPattern(c.Pattern); err != nil {
+				return ""
+			}
+		}
+		if text, ok := value.(string); ok && !pattern.MatchString(text) {
+			return fmt.Sprintf("nilai tidak sesuai pola %s", c.Pattern)
+		}
+	}
+	return ""
+}
+
+// compareBound membandingkan dua nilai number atau date dan mengembalikan -1, 0 atau 1.
+func compareBound(col *model.DatabaseColumn, a, b interface{}) int {
+	if col.Type == "date" {
+		x, okX := toTime(a)
+		y, okY := toTime(b)
+		if !okX || !okY {
+			return 0
+		}
+		return x.Compare(y)
+	}
+	x, okX := toFloat(a)
+	y, okY := toFloat(b)
+	if !okX || !okY {
+		return 0
+	}
+	return compareFloat(x, y)
+}
+
+// uniqueKey membuat kunci perbandingan unique untuk nilai yang tidak kosong.
+func uniqueKey(col *model.DatabaseColumn, value interface{}) (string, bool) {
+	if isEmptyValue(value) {
+		return "", false
+	}
+	switch col.Type {
+	case "number":
+		if f, ok := toFloat(value); ok {
+			return strconv.FormatFloat(f, 'g', -1, 64), true
+		}
+	case "date":
+		if t, ok := toTime(value); ok {
+			return t.UTC().Format(time.RFC3339Nano), true
+		}
+	}
+	return fmt.Sprint(value), true
+}
+
+// compileConstraintPattern mengompilasi pattern agar harus cocok dengan seluruh teks.
+func compileConstraintPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend_my_manajer/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeColumnConstraints(t *testing.T) {
+	tests := []struct {
+		name        string
+		col         model.DatabaseColumn
+		wantErr     bool
+		wantMin     interface{}
+		wantDefault interface{}
+		wantNil     bool
+	}{
+		{name: "batasan kosong dihapus", col: model.DatabaseColumn{Type: "text", Constraints: &model.ColumnConstraints{}}, wantNil: true},
+		{name: "min dan max number dikonversi", col: model.DatabaseColumn{Type: "number", Constraints: &model.ColumnConstraints{Min: "1", Max: 10}}, wantMin: float64(1)},
+		{name: "min date dikonversi", col: model.DatabaseColumn{Type: "date", Constraints: &model.ColumnConstraints{Min: "2024-01-01"}}, wantMin: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "default dikonversi", col: model.DatabaseColumn{Type: "number", Constraints: &model.ColumnConstraints{DefaultValue: "5"}}, wantDefault: float64(5)},
+		{name: "min lebih besar dari max", col: model.DatabaseColumn{Type: "number", Constraints: &model.ColumnConstraints{Min: 10, Max: 1}}, wantErr: true},
+		{name: "min pada text", col: model.DatabaseColumn{Type: "text", Constraints: &model.ColumnConstraints{Min: 1}}, wantErr: true},
+		{name: "pattern pada number", col: model.DatabaseColumn{Type: "number", Constraints: &model.ColumnConstraints{Pattern: "[0-9]+"}}, wantErr: true},
+		{name: "pattern tidak valid", col: model.DatabaseColumn{Type: "text", Constraints: &model.ColumnConstraints{Pattern: "(abc"}}, wantErr: true},
+		{name: "unique pada boolean", col: model.DatabaseColumn{Type: "boolean", Constraints: &model.ColumnConstraints{Unique: true}}, wantErr: true},
+		{name: "batasan pada formula", col: model.DatabaseColumn{Type: "formula", Constraints: &model.ColumnConstraints{Required: true}}, wantErr: true},
+		{name: "default pada relation", col: model.DatabaseColumn{Type: "relation", Constraints: &model.ColumnConstraints{DefaultValue: []interface{}{}}}, wantErr: true},
+		{name: "default melanggar max", col: model.DatabaseColumn{Type: "number", Constraints: &model.ColumnConstraints{Max: 3, DefaultValue: 4}}, wantErr: true},
+		{name: "default melanggar pattern", col: model.DatabaseColumn{Type: "text", Constraints: &model.ColumnConstraints{Pattern: "[A-Z]{3}", DefaultValue: "ab"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col := tt.col
+			err := NormalizeColumnConstraints(&col)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidColumnConstraint) {
+					t.Fatalf("NormalizeColumnConstraints() error = %v, want ErrInvalidColumnConstraint", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeColumnConstraints() error = %v", err)
+			}
+			if tt.wantNil {
+				if col.Constraints != nil {
+					t.Errorf("Constraints = %#v, want nil", col.Constraints)
+				}
+				return
+			}
+			if tt.wantMin != nil && !reflect.DeepEqual(col.Constraints.Min, tt.wantMin) {
+				t.Errorf("Min = %#v, want %#v", col.Constraints.Min, tt.wantMin)
+			}
+			if tt.wantDefault != nil && !reflect.DeepEqual(col.Constraints.DefaultValue, tt.wantDefault) {
+				t.Errorf("DefaultValue = %#v, want %#v", col.Constraints.DefaultValue, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestRowConstraintCheckerCheck(t *testing.T) {
+	code := model.DatabaseColumn{ID: primitive.NewObjectID(), Name: "Kode", Type: "text", Constraints: &model.ColumnConstraints{Required: true, Unique: true, Pattern: "[A-Z]{3}"}}
+	qty := model.DatabaseColumn{ID: primitive.NewObjectID(), Name: "Qty", Type: "number", Constraints: &model.ColumnConstraints{Min: float64(0), Max: float64(100), DefaultValue: float64(1)}}
+	columns := []model.DatabaseColumn{code, qty}
+	codeID, qtyID := code.ID.Hex(), qty.ID.Hex()
+
+	existing := model.DatabaseRow{ID: primitive.NewObjectID(), Values: model.DatabaseRowValue{codeID: "ABC", qtyID: float64(5)}}
+
+	tests := []struct {
+		name       string
+		rowID      primitive.ObjectID
+		values     model.DatabaseRowValue
+		want       model.DatabaseRowValue
+		wantErrIDs []string
+	}{
+		{
+			name:   "baris baru mendapat default",
+			values: model.DatabaseRowValue{codeID: "XYZ"},
+			want:   model.DatabaseRowValue{codeID: "XYZ", qtyID: float64(1)},
+		},
+		{
+			name:   "null eksplisit tidak diganti default",
+			values: model.DatabaseRowValue{codeID: "XYZ", qtyID: nil},
+			want:   model.DatabaseRowValue{codeID: "XYZ", qtyID: nil},
+		},
+		{
+			name:   "baris lama tidak mendapat default",
+			rowID:  existing.ID,
+			values: model.DatabaseRowValue{codeID: "ABC"},
+			want:   model.DatabaseRowValue{codeID: "ABC"},
+		},
+		{name: "required kosong", values: model.DatabaseRowValue{codeID: "  "}, wantErrIDs: []string{codeID}},
+		{name: "required tidak dikirim", values: model.DatabaseRowValue{qtyID: float64(2)}, wantErrIDs: []string{codeID}},
+		{name: "pattern harus cocok seluruh teks", values: model.DatabaseRowValue{codeID: "ABCD"}, wantErrIDs: []string{codeID}},
+		{name: "unique dengan baris lain", values: model.DatabaseRowValue{codeID: "ABC"}, wantErrIDs: []string{codeID}},
+		{name: "di bawah min", values: model.DatabaseRowValue{codeID: "XYZ", qtyID: float64(-1)}, wantErrIDs: []string{qtyID}},
+		{name: "batas max inklusif", values: model.DatabaseRowValue{codeID: "XYZ", qtyID: float64(100)}, want: model.DatabaseRowValue{codeID: "XYZ", qtyID: float64(100)}},
+		{name: "semua pelanggaran dilaporkan", values: model.DatabaseRowValue{codeID: "abc", qtyID: float64(101)}, wantErrIDs: []string{codeID, qtyID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewRowConstraintChecker(columns, []model.DatabaseRow{existing})
+			got, err := checker.Check(tt.rowID, tt.values)
+			if tt.wantErrIDs != nil {
+				var validationErr *RowValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("Check() error = %v, want *RowValidationError", err)
+				}
+				var gotIDs []string
+				for _, fieldErr := range validationErr.Errors {
+					gotIDs = append(gotIDs, fieldErr.ColumnID)
+				}
+				if !reflect.DeepEqual(gotIDs, tt.wantErrIDs) {
+					t.Errorf("Check() error columns = %v, want %v", gotIDs, tt.wantErrIDs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowConstraintCheckerWithinRequest(t *testing.T) {
+	code := model.DatabaseColumn{ID: primitive.NewObjectID(), Name: "Kode", Type: "text", Constraints: &model.ColumnConstraints{Unique: true}}
+	codeID := code.ID.Hex()
+	existing := model.DatabaseRow{ID: primitive.NewObjectID(), Values: model.DatabaseRowValue{codeID: "A"}}
+	checker := NewRowConstraintChecker([]model.DatabaseColumn{code}, []model.DatabaseRow{existing})
+
+	steps := []struct {
+		name    string
+		rowID   primitive.ObjectID
+		value   string
+		forget  bool // Forget(existing.ID) sebelum Check, seperti baris yang dihapus dalam batch yang sama
+		wantErr bool
+	}{
+		{name: "baris baru pertama", value: "B"},
+		{name: "duplikat baris baru dalam request yang sama", value: "B", wantErr: true},
+		{name: "baris lama memakai nilainya sendiri", rowID: existing.ID, value: "A"},
+		{name: "baris baru memakai nilai baris lama", value: "A", wantErr: true},
+		{name: "nilai baris yang dihapus boleh dipakai", value: "A", forget: true},
+	}
+
+	for _, step := range steps {
+		if step.forget {
+			checker.Forget(existing.ID)
+		}
+		_, err := checker.Check(step.rowID, model.DatabaseRowValue{codeID: step.value})
+		if (err != nil) != step.wantErr {
+			t.Errorf("%s: Check(%q) error = %v, wantErr %v", step.name, step.value, err, step.wantErr)
+		}
+	}
+}
+
+func TestFindUniqueConflicts(t *testing.T) {
+	col := model.DatabaseColumn{ID: primitive.NewObjectID(), Type: "number"}
+	columnID := col.ID.Hex()
+	rows := []model.DatabaseRow{
+		{ID: primitive.NewObjectID(), Values: model.DatabaseRowValue{columnID: float64(1)}},
+		{ID: primitive.NewObjectID(), Values: model.DatabaseRowValue{columnID: "1.0"}},
+		{ID: primitive.NewObjectID(), Values: model.DatabaseRowValue{columnID: float64(2)}},
+		{ID: primitive.NewObjectID(), Values: model.DatabaseRowValue{columnID: nil}},
+		{ID: primitive.NewObjectID(), Values: model.DatabaseRowValue{}},
+	}
+
+	got := FindUniqueConflicts(&col, rows)
+	want := []UniqueConflict{{Value: "1", RowIDs: []string{rows[0].ID.Hex(), rows[1].ID.Hex()}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindUniqueConflicts() = %#v, want %#v", got, want)
+	}
+}
+
+func TestRowConstraintCheckerStoredLookup(t *testing.T) {
+	code := model.DatabaseColumn{ID: primitive.NewObjectID(), Name: "Kode", Type: "text", Constraints: &model.ColumnConstraints{Unique: true}}
+	codeID := code.ID.Hex()
+	rowA, rowB := primitive.NewObjectID(), primitive.NewObjectID()
+	stored := map[primitive.ObjectID]string{rowA: "A", rowB: "B"}
+
+	checker := NewRowConstraintChecker([]model.DatabaseColumn{code}, nil)
+	checker.lookup = func(columnID string, value interface{}, excludeIDs []primitive.ObjectID) (primitive.ObjectID, error) {
+		excluded := make(map[primitive.ObjectID]bool)
+		for _, id := range excludeIDs {
+			excluded[id] = true
+		}
+		for id, v := range stored {
+			if columnID == codeID && v == value && !excluded[id] {
+				return id, nil
+			}
+		}
+		return primitive.NilObjectID, nil
+	}
+
+	steps := []struct {
+		name    string
+		rowID   primitive.ObjectID
+		value   string
+		forget  primitive.ObjectID // Forget sebelum Check, seperti baris yang dihapus dalam batch yang sama
+		wantErr bool
+	}{
+		{name: "baris baru memakai nilai tersimpan", value: "A", wantErr: true},
+		{name: "baris tersimpan memakai nilainya sendiri", rowID: rowA, value: "A"},
+		{name: "baris tersimpan memakai nilai baris tersimpan lain", rowID: rowA, value: "B", wantErr: true},
+		{name: "baris tersimpan diubah ke nilai baru", rowID: rowB, value: "C"},
+		{name: "nilai lama baris yang sudah diubah boleh dipakai", value: "B"},
+		{name: "nilai baris yang dihapus boleh dipakai", forget: rowA, value: "A"},
+	}
+
+	for _, step := range steps {
+		if !step.forget.IsZero() {
+			checker.Forget(step.forget)
+		}
+		_, err := checker.Check(step.rowID, model.DatabaseRowValue{codeID: step.value})
+		if (err != nil) != step.wantErr {
+			t.Errorf("%s: Check(%q) error = %v, wantErr %v", step.name, step.value, err, step.wantErr)
+		}
+	}
+}
+
+func TestRowConstraintCheckerLookupError(t *testing.T) {
+	code := model.DatabaseColumn{ID: primitive.NewObjectID(), Name: "Kode", Type: "text", Constraints: &model.ColumnConstraints{Unique: true}}
+	checker := NewRowConstraintChecker([]model.DatabaseColumn{code}, nil)
+	checker.lookup = func(string, interface{}, []primitive.ObjectID) (primitive.ObjectID, error) {
+		return primitive.NilObjectID, errors.New("koneksi terputus")
+	}
+	if _, err := checker.Check(primitive.NilObjectID, model.DatabaseRowValue{code.ID.Hex(): "A"}); !errors.Is(err, ErrUniqueLookupFailed) {
+		t.Errorf("Check() error = %v, want ErrUniqueLookupFailed", err)
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kolom "file" menyimpan daftar ID DatabaseFile. File diunggah lebih dulu ke kolom tersebut
+// (POST /databases/{id}/files), lalu ID-nya ditulis ke sel seperti nilai kolom lain. Sebuah file hanya
+// boleh dipakai di database dan kolom tempat file diunggah.
+
+// DatabaseFileStorageKey mengembalikan key FileStorage untuk file milik database.
+func DatabaseFileStorageKey(databaseID, fileID primitive.ObjectID) string {
+	return "databases/" + databaseID.Hex() + "/" + fileID.Hex()
+}
+
+// ValidateFileValues memastikan setiap ID pada kolom file merujuk file yang diunggah ke kolom yang
+// sama di database databaseID. Kesalahan dikembalikan sebagai *RowValidationError; error lain
+// (misalnya kegagalan query) dikembalikan apa adanya.
+func ValidateFileValues(ctx context.Context, fileRepo repository.DatabaseFileRepository, databaseID primitive.ObjectID, columns []model.DatabaseColumn, values model.DatabaseRowValue) error {
+	var fieldErrors []RowFieldError
+	for _, col := range columns {
+		if col.Type != "file" {
+			continue
+		}
+		fileIDs := relationRowIDs(values[col.ID.Hex()])
+		if len(fileIDs) == 0 {
+			continue
+		}
+		objectIDs := make([]primitive.ObjectID, 0, len(fileIDs))
+		for _, fileID := range fileIDs {
+			if oid, err := primitive.ObjectIDFromHex(fileID); err == nil {
+				objectIDs = append(objectIDs, oid)
+			}
+		}
+		found, err := fileRepo.GetFilesByIDs(ctx, databaseID, objectIDs)
+		if err != nil {
+			return err
+		}
+		existing := make(map[string]bool, len(found))
+		for _, file := range found {
+			if file.ColumnID == col.ID {
+				existing[file.ID.Hex()] = true
+			}
+		}
+		var missing []string
+		for _, fileID := range fileIDs {
+			if !existing[fileID] {
+				missing = append(missing, fileID)
+			}
+		}
+		if len(missing) > 0 {
+			fieldErrors = append(fieldErrors, RowFieldError{
+				ColumnID:   col.ID.Hex(),
+				ColumnName: col.Name,
+				Message:    fmt.Sprintf("file %s tidak ditemukan di kolom ini", strings.Join(missing, ", ")),
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &RowValidationError{Errors: fieldErrors}
+	}
+	return nil
+}
@@ -0,0 +1,1198 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/utils"
+)
+
+// Kolom bertipe "formula" menyimpan ekspresi di DatabaseColumn.Formula dan nilainya dihitung server
+// setiap kali baris dibaca. Sintaks ekspresi:
+//   - Referensi kolom : {Nama Kolom} atau {<ID kolom>}; referensi lewat ID tetap berlaku saat kolom diganti nama
+//   - Literal         : 12, 3.5, "teks" atau 'teks', TRUE, FALSE
+//   - Aritmetika      : + - * / % (number), date + number dan date - number (menggeser hari)
+//   - Gabung teks     : a & b (nilai apa pun dikonversi ke teks, null menjadi "")
+//   - Perbandingan    : = != <> < <= > >= (kedua sisi bertipe sama), hasilnya boolean
+//   - Fungsi          : IF(kondisi, a[, b]), AND(...), OR(...), NOT(x), CONCAT(...),
+//                       DATEDIFF(akhir, awal[, "days"|"hours"|"minutes"|"weeks"|"months"|"years"]),
+//                       ROUND(x[, digit]), ABS(x), LEN(teks), UPPER(teks), LOWER(teks), TODAY(), NOW()
+//
+// Tipe hasil diperiksa saat kolom dibuat atau diubah. Saat evaluasi, operasi dengan nilai null
+// menghasilkan null (kecuali & dan CONCAT), dan error runtime (mis. pembagian dengan nol) menghasilkan null.
+
+// MaxFormulaLength adalah panjang maksimum ekspresi formula.
+const MaxFormulaLength = 2000
+
+// ErrInvalidFormula dikembalikan jika formula tidak dapat diurai, merujuk kolom yang tidak ada,
+// memiliki referensi melingkar, atau tipe operand-nya tidak cocok.
+var ErrInvalidFormula = errors.New("formula tidak valid")
+
+// Tipe nilai dalam ekspresi formula.
+const (
+	formulaTypeNumber  = "number"
+	formulaTypeText    = "text"
+	formulaTypeBoolean = "boolean"
+	formulaTypeDate    = "date"
+)
+
+// ValidateFormulaColumns memeriksa semua kolom formula: sintaks, referensi kolom, referensi
+// melingkar dan kecocokan tipe. Error yang dikembalikan membungkus ErrInvalidFormula.
+func ValidateFormulaColumns(columns []model.DatabaseColumn) error {
+	_, err := compileFormulaColumns(columns)
+	return err
+}
+
+// FormulaReferencesColumn mengembalikan nama kolom formula pertama yang merujuk columnID,
+// atau string kosong jika tidak ada. Dipakai untuk mencegah penghapusan kolom yang masih dirujuk.
+func FormulaReferencesColumn(columns []model.DatabaseColumn, columnID string) string {
+	resolve := newFormulaResolver(columns)
+	for _, col := range columns {
+		if col.Type != "formula" || col.ID.Hex() == columnID {
+			continue
+		}
+		parser := &formulaParser{resolve: resolve}
+		if _, err := parser.parse(col.Formula); err != nil {
+			continue
+		}
+		for _, dep := range parser.deps {
+			if dep == columnID {
+				return col.Name
+			}
+		}
+	}
+	return ""
+}
+
+// EvaluateFormulaColumns menghitung nilai setiap kolom formula dan menuliskannya ke Values
+// masing-masing baris (in-place). Nilai formula yang tersimpan di database diabaikan.
+func EvaluateFormulaColumns(columns []model.DatabaseColumn, rows []model.DatabaseRow) {
+	hasFormula := false
+	for _, col := range columns {
+		if col.Type == "formula" {
+			hasFormula = true
+			break
+		}
+	}
+	if !hasFormula {
+		return
+	}
+
+	compiled, err := compileFormulaColumns(columns)
+	if err != nil {
+		// Seharusnya tidak terjadi karena formula divalidasi saat disimpan
+		utils.LogWarning("Gagal menyiapkan kolom formula, nilai formula dikosongkan: %v", err)
+		for i := range rows {
+			if rows[i].Values == nil {
+				rows[i].Values = model.DatabaseRowValue{}
+			}
+			for _, col := range columns {
+				if col.Type == "formula" {
+					rows[i].Values[col.ID.Hex()] = nil
+				}
+			}
+		}
+		return
+	}
+
+	columnsByID := indexColumns(columns)
+	for i := range rows {
+		if rows[i].Values == nil {
+			rows[i].Values = model.DatabaseRowValue{}
+		}
+		values := rows[i].Values
+		env := &formulaEnv{now: time.Now().UTC(), lookup: func(columnID string) interface{} {
+			col := columnsByID[columnID]
+			if col == nil {
+				return nil
+			}
+			if col.Type == "formula" {
+				return values[columnID]
+			}
+			return formulaInputValue(col, values[columnID])
+		}}
+		for _, formula := range compiled {
+			result, err := formula.root.eval(env)
+			if err != nil {
+				result = nil
+			}
+			values[formula.columnID] = result
+		}
+	}
+}
+
+// computedResultColumns mengembalikan salinan kolom di mana setiap kolom formula bertipe sesuai tipe
+// hasil ekspresinya dan kolom rollup bertipe number. Formula yang tidak valid diperlakukan sebagai text.
+func computedResultColumns(columns []model.DatabaseColumn) []model.DatabaseColumn {
+	resultTypes := make(map[string]string)
+	if compiled, err := compileFormulaColumns(columns); err == nil {
+		for _, formula := range compiled {
+			resultTypes[formula.columnID] = formula.resultType
+		}
+	}
+	result := make([]model.DatabaseColumn, len(columns))
+	copy(result, columns)
+	for i := range result {
+		if result[i].Type == "rollup" {
+			result[i].Type = formulaTypeNumber
+		}
+		if result[i].Type != "formula" {
+			continue
+		}
+		result[i].Type = formulaTypeText
+		if t, ok := resultTypes[result[i].ID.Hex()]; ok {
+			result[i].Type = t
+		}
+	}
+	return result
+}
+
+// compiledFormula adalah formula yang sudah diurai dan diperiksa tipenya.
+type compiledFormula struct {
+	columnID   string
+	root       formulaNode
+	deps       []string
+	resultType string
+}
+
+// compileFormulaColumns mengurai semua kolom formula dan mengembalikannya dalam urutan evaluasi
+// (dependensi lebih dulu).
+func compileFormulaColumns(columns []model.DatabaseColumn) ([]*compiledFormula, error) {
+	resolve := newFormulaResolver(columns)
+	columnsByID := indexColumns(columns)
+
+	formulas := make(map[string]*compiledFormula)
+	var formulaIDs []string
+	for _, col := range columns {
+		if col.Type != "formula" {
+			continue
+		}
+		if strings.TrimSpace(col.Formula) == "" {
+			return nil, fmt.Errorf("%w: kolom '%s' bertipe formula tetapi ekspresinya kosong", ErrInvalidFormula, col.Name)
+		}
+		if len(col.Formula) > MaxFormulaLength {
+			return nil, fmt.Errorf("%w: formula kolom '%s' melebihi %d karakter", ErrInvalidFormula, col.Name, MaxFormulaLength)
+		}
+		parser := &formulaParser{resolve: resolve}
+		root, err := parser.parse(col.Formula)
+		if err != nil {
+			return nil, fmt.Errorf("%w: kolom '%s': %v", ErrInvalidFormula, col.Name, err)
+		}
+		formulas[col.ID.Hex()] = &compiledFormula{columnID: col.ID.Hex(), root: root, deps: parser.deps}
+		formulaIDs = append(formulaIDs, col.ID.Hex())
+	}
+
+	// Urutkan topologis agar formula yang dirujuk dihitung lebih dulu, sekaligus mendeteksi siklus
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := make(map[string]int, len(formulas))
+	ordered := make([]*compiledFormula, 0, len(formulas))
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case done:
+			return nil
+		case visiting:
+			// Bentuk jalur siklus mulai dari kolom yang pertama kali terulang
+			start := 0
+			for i, p := range path {
+				if p == id {
+					start = i
+					break
+				}
+			}
+			names := make([]string, 0, len(path)-start+1)
+			for _, p := range append(path[start:], id) {
+				names = append(names, columnsByID[p].Name)
+			}
+			return fmt.Errorf("%w: referensi melingkar %s", ErrInvalidFormula, strings.Join(names, " -> "))
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range formulas[id].deps {
+			if _, isFormula := formulas[dep]; isFormula {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		ordered = append(ordered, formulas[id])
+		return nil
+	}
+	for _, id := range formulaIDs {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Periksa tipe dalam urutan evaluasi agar tipe hasil formula yang dirujuk sudah diketahui
+	resultTypes := make(map[string]string, len(ordered))
+	refType := func(columnID string) (string, error) {
+		col := columnsByID[columnID]
+		if col.Type == "formula" {
+			return resultTypes[columnID], nil
+		}
+		return formulaColumnType(col)
+	}
+	for _, formula := range ordered {
+		resultType, err := formula.root.check(refType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: kolom '%s': %v", ErrInvalidFormula, columnsByID[formula.columnID].Name, err)
+		}
+		formula.resultType = resultType
+		resultTypes[formula.columnID] = resultType
+	}
+	return ordered, nil
+}
+
+// newFormulaResolver membuat fungsi yang menerjemahkan isi {…} menjadi ID kolom, mencocokkan ID lebih dulu lalu nama.
+func newFormulaResolver(columns []model.DatabaseColumn) func(ref string) (string, bool) {
+	return func(ref string) (string, bool) {
+		ref = strings.TrimSpace(ref)
+		for _, col := range columns {
+			if col.ID.Hex() == ref {
+				return ref, true
+			}
+		}
+		for _, col := range columns {
+			if strings.EqualFold(strings.TrimSpace(col.Name), ref) {
+				return col.ID.Hex(), true
+			}
+		}
+		return "", false
+	}
+}
+
+// formulaColumnType memetakan tipe kolom biasa ke tipe nilai formula.
+func formulaColumnType(col *model.DatabaseColumn) (string, error) {
+	switch col.Type {
+	case "number", "rollup":
+		return formulaTypeNumber, nil
+	case "text", "select":
+		return formulaTypeText, nil
+	case "boolean":
+		return formulaTypeBoolean, nil
+	case "date":
+		return formulaTypeDate, nil
+	}
+	return "", fmt.Errorf("kolom '%s' bertipe %s tidak dapat dipakai dalam formula", col.Name, col.Type)
+}
+
+// formulaInputValue mengonversi nilai sel tersimpan ke nilai formula (float64, string, bool, time.Time atau nil).
+func formulaInputValue(col *model.DatabaseColumn, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	switch col.Type {
+	case "number", "rollup":
+		if f, ok := toFloat(value); ok {
+			return f
+		}
+	case "date":
+		if t, ok := toTime(value); ok {
+			return t.UTC()
+		}
+	case "boolean":
+		if b, ok := toBool(value); ok {
+			return b
+		}
+	case "text", "select":
+		return CellDisplayValue(col, value)
+	}
+	return nil
+}
+
+// formulaValueType menebak tipe formula dari nilai hasil evaluasi.
+func formulaValueType(value interface{}) string {
+	switch value.(type) {
+	case float64:
+		return formulaTypeNumber
+	case bool:
+		return formulaTypeBoolean
+	case time.Time:
+		return formulaTypeDate
+	}
+	return formulaTypeText
+}
+
+// formulaText mengonversi nilai formula ke teks untuk operator & dan CONCAT.
+func formulaText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+// ---------------------------------------------------------------------------
+// Tokenizer dan parser
+// ---------------------------------------------------------------------------
+
+type formulaTokenKind int
+
+const (
+	formulaTokenEOF formulaTokenKind = iota
+	formulaTokenNumber
+	formulaTokenString
+	formulaTokenIdent
+	formulaTokenRef
+	formulaTokenOp
+)
+
+type formulaToken struct {
+	kind formulaTokenKind
+	text string
+	pos  int
+}
+
+// tokenizeFormula memecah ekspresi menjadi token.
+func tokenizeFormula(expr string) ([]formulaToken, error) {
+	var tokens []formulaToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenNumber, text: string(runes[start:i]), pos: start})
+		case r == '"' || r == '\'':
+			start := i
+			quote := r
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == quote {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("teks pada posisi %d tidak ditutup", start+1)
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenString, text: sb.String(), pos: start})
+		case r == '{':
+			start := i
+			end := -1
+			for j := i + 1; j < len(runes); j++ {
+				if runes[j] == '}' {
+					end = j
+					break
+				}
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("referensi kolom pada posisi %d tidak ditutup dengan '}'", start+1)
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenRef, text: string(runes[i+1 : end]), pos: start})
+			i = end + 1
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			start := i
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				if two == "<=" || two == ">=" || two == "!=" || two == "<>" {
+					tokens = append(tokens, formulaToken{kind: formulaTokenOp, text: two, pos: start})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("+-*/%&=<>(),", r) {
+				return nil, fmt.Errorf("karakter '%c' pada posisi %d tidak dikenal", r, start+1)
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenOp, text: string(r), pos: start})
+			i++
+		}
+	}
+	return append(tokens, formulaToken{kind: formulaTokenEOF, pos: len(runes)}), nil
+}
+
+// formulaParser adalah parser recursive-descent untuk ekspresi formula.
+// Prioritas operator dari rendah ke tinggi: perbandingan, &, + -, * / %, unary -.
+type formulaParser struct {
+	tokens  []formulaToken
+	pos     int
+	resolve func(ref string) (string, bool)
+	deps    []string
+}
+
+func (p *formulaParser) parse(expr string) (formulaNode, error) {
+	tokens, err := tokenizeFormula(expr)
+	if err != nil {
+		return nil, err
+	}
+	p.tokens = tokens
+	node, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != formulaTokenEOF {
+		return nil, fmt.Errorf("token '%s' tidak diharapkan pada posisi %d", tok.text, tok.pos+1)
+	}
+	return node, nil
+}
+
+func (p *formulaParser) peek() formulaToken {
+	return p.tokens[p.pos]
+}
+
+func (p *formulaParser) next() formulaToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != formulaTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *formulaParser) isOp(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != formulaTokenOp {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *formulaParser) expectOp(op string) error {
+	if !p.isOp(op) {
+		tok := p.peek()
+		if tok.kind == formulaTokenEOF {
+			return fmt.Errorf("diharapkan '%s' tetapi ekspresi berakhir", op)
+		}
+		return fmt.Errorf("diharapkan '%s' pada posisi %d", op, tok.pos+1)
+	}
+	p.next()
+	return nil
+}
+
+func (p *formulaParser) parseComparison() (formulaNode, error) {
+	left, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("=", "!=", "<>", "<", "<=", ">", ">=") {
+		op := p.next().text
+		if op == "<>" {
+			op = "!="
+		}
+		right, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		left = &formulaBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseConcat() (formulaNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&") {
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = &formulaBinary{op: "&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseAdditive() (formulaNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+", "-") {
+		op := p.next().text
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &formulaBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseTerm() (formulaNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*", "/", "%") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &formulaBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseUnary() (formulaNode, error) {
+	if p.isOp("-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &formulaNegate{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *formulaParser) parsePrimary() (formulaNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case formulaTokenNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("angka '%s' pada posisi %d tidak valid", tok.text, tok.pos+1)
+		}
+		return &formulaLiteral{value: f, typ: formulaTypeNumber}, nil
+	case formulaTokenString:
+		return &formulaLiteral{value: tok.text, typ: formulaTypeText}, nil
+	case formulaTokenRef:
+		columnID, ok := p.resolve(tok.text)
+		if !ok {
+			return nil, fmt.Errorf("kolom '{%s}' tidak ditemukan", tok.text)
+		}
+		p.deps = append(p.deps, columnID)
+		return &formulaRef{columnID: columnID}, nil
+	case formulaTokenIdent:
+		name := strings.ToUpper(tok.text)
+		switch name {
+		case "TRUE":
+			return &formulaLiteral{value: true, typ: formulaTypeBoolean}, nil
+		case "FALSE":
+			return &formulaLiteral{value: false, typ: formulaTypeBoolean}, nil
+		}
+		if _, ok := formulaFunctions[name]; !ok {
+			return nil, fmt.Errorf("fungsi '%s' tidak dikenal", tok.text)
+		}
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		call := &formulaCall{name: name}
+		if !p.isOp(")") {
+			for {
+				arg, err := p.parseComparison()
+				if err != nil {
+					return nil, err
+				}
+				call.args = append(call.args, arg)
+				if !p.isOp(",") {
+					break
+				}
+				p.next()
+			}
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return call, nil
+	case formulaTokenOp:
+		if tok.text == "(" {
+			node, err := p.parseComparison()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return node, nil
+		}
+		return nil, fmt.Errorf("token '%s' tidak diharapkan pada posisi %d", tok.text, tok.pos+1)
+	}
+	return nil, errors.New("ekspresi tidak lengkap")
+}
+
+// ---------------------------------------------------------------------------
+// AST: pemeriksaan tipe dan evaluasi
+// ---------------------------------------------------------------------------
+
+// formulaEnv menyediakan nilai kolom dan waktu evaluasi untuk satu baris.
+type formulaEnv struct {
+	now    time.Time
+	lookup func(columnID string) interface{}
+}
+
+// formulaNode adalah simpul pohon ekspresi formula.
+type formulaNode interface {
+	check(refType func(columnID string) (string, error)) (string, error)
+	eval(env *formulaEnv) (interface{}, error)
+}
+
+type formulaLiteral struct {
+	value interface{}
+	typ   string
+}
+
+func (n *formulaLiteral) check(func(string) (string, error)) (string, error) { return n.typ, nil }
+func (n *formulaLiteral) eval(*formulaEnv) (interface{}, error)              { return n.value, nil }
+
+type formulaRef struct {
+	columnID string
+}
+
+func (n *formulaRef) check(refType func(string) (string, error)) (string, error) {
+	return refType(n.columnID)
+}
+
+func (n *formulaRef) eval(env *formulaEnv) (interface{}, error) {
+	return env.lookup(n.columnID), nil
+}
+
+type formulaNegate struct {
+	operand formulaNode
+}
+
+func (n *formulaNegate) check(refType func(string) (string, error)) (string, error) {
+	t, err := n.operand.check(refType)
+	if err != nil {
+		return "", err
+	}
+	if t != formulaTypeNumber {
+		return "", fmt.Errorf("operator '-' membutuhkan number, bukan %s", t)
+	}
+	return formulaTypeNumber, nil
+}
+
+func (n *formulaNegate) eval(env *formulaEnv) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return -v.(float64), nil
+}
+
+type formulaBinary struct {
+	op          string
+	left, right formulaNode
+}
+
+func (n *formulaBinary) check(refType func(string) (string, error)) (string, error) {
+	lt, err := n.left.check(refType)
+	if err != nil {
+		return "", err
+	}
+	rt, err := n.right.check(refType)
+	if err != nil {
+		return "", err
+	}
+	switch n.op {
+	case "&":
+		return formulaTypeText, nil
+	case "+", "-":
+		switch {
+		case lt == formulaTypeNumber && rt == formulaTypeNumber:
+			return formulaTypeNumber, nil
+		case lt == formulaTypeDate && rt == formulaTypeNumber:
+			return formulaTypeDate, nil
+		case n.op == "+" && lt == formulaTypeNumber && rt == formulaTypeDate:
+			return formulaTypeDate, nil
+		case lt == formulaTypeDate && rt == formulaTypeDate:
+			return "", fmt.Errorf("gunakan DATEDIFF untuk menghitung selisih dua tanggal")
+		}
+		return "", fmt.Errorf("operator '%s' tidak dapat dipakai untuk %s dan %s", n.op, lt, rt)
+	case "*", "/", "%":
+		if lt != formulaTypeNumber || rt != formulaTypeNumber {
+			return "", fmt.Errorf("operator '%s' membutuhkan number, bukan %s dan %s", n.op, lt, rt)
+		}
+		return formulaTypeNumber, nil
+	default: // perbandingan
+		if lt != rt {
+			return "", fmt.Errorf("tidak dapat membandingkan %s dengan %s", lt, rt)
+		}
+		if lt == formulaTypeBoolean && n.op != "=" && n.op != "!=" {
+			return "", fmt.Errorf("operator '%s' tidak dapat dipakai untuk boolean", n.op)
+		}
+		return formulaTypeBoolean, nil
+	}
+}
+
+func (n *formulaBinary) eval(env *formulaEnv) (interface{}, error) {
+	l, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&":
+		return formulaText(l) + formulaText(r), nil
+	case "=", "!=":
+		equal := l == nil && r == nil
+		if l != nil && r != nil {
+			equal = compareFormulaValues(l, r) == 0
+		}
+		if n.op == "=" {
+			return equal, nil
+		}
+		return !equal, nil
+	case "<", "<=", ">", ">=":
+		if l == nil || r == nil {
+			return false, nil
+		}
+		cmp := compareFormulaValues(l, r)
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		}
+		return cmp >= 0, nil
+	}
+
+	if l == nil || r == nil {
+		return nil, nil
+	}
+	// Aritmetika tanggal: geser sejumlah hari
+	if t, ok := l.(time.Time); ok {
+		days := r.(float64)
+		if n.op == "-" {
+			days = -days
+		}
+		return t.Add(time.Duration(days * float64(24*time.Hour))), nil
+	}
+	if t, ok := r.(time.Time); ok {
+		return t.Add(time.Duration(l.(float64) * float64(24*time.Hour))), nil
+	}
+
+	x, y := l.(float64), r.(float64)
+	switch n.op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/":
+		if y == 0 {
+			return nil, errors.New("pembagian dengan nol")
+		}
+		return x / y, nil
+	}
+	if y == 0 {
+		return nil, errors.New("modulo dengan nol")
+	}
+	return math.Mod(x, y), nil
+}
+
+// compareFormulaValues membandingkan dua nilai formula yang bertipe sama.
+func compareFormulaValues(a, b interface{}) int {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			return compareFloat(x, y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(formulaText(a), formulaText(b))
+}
+
+// formulaFunction mendefinisikan pemeriksaan tipe dan evaluasi sebuah fungsi formula.
+type formulaFunction struct {
+	check func(args []formulaNode, types []string) (string, error)
+	eval  func(args []formulaNode, env *formulaEnv) (interface{}, error)
+}
+
+type formulaCall struct {
+	name string
+	args []formulaNode
+}
+
+func (n *formulaCall) check(refType func(string) (string, error)) (string, error) {
+	types := make([]string, len(n.args))
+	for i, arg := range n.args {
+		t, err := arg.check(refType)
+		if err != nil {
+			return "", err
+		}
+		types[i] = t
+	}
+	t, err := formulaFunctions[n.name].check(n.args, types)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", n.name, err)
+	}
+	return t, nil
+}
+
+func (n *formulaCall) eval(env *formulaEnv) (interface{}, error) {
+	return formulaFunctions[n.name].eval(n.args, env)
+}
+
+// formulaArgCount memastikan jumlah argumen berada di antara min dan max (max < 0 berarti tak terbatas).
+func formulaArgCount(types []string, min, max int) error {
+	if len(types) < min || (max >= 0 && len(types) > max) {
+		switch {
+		case max < 0:
+			return fmt.Errorf("membutuhkan minimal %d argumen", min)
+		case min == max:
+			return fmt.Errorf("membutuhkan %d argumen", min)
+		}
+		return fmt.Errorf("membutuhkan %d sampai %d argumen", min, max)
+	}
+	return nil
+}
+
+// formulaArgTypes memastikan setiap argumen bertipe want.
+func formulaArgTypes(types []string, want string) error {
+	for i, t := range types {
+		if t != want {
+			return fmt.Errorf("argumen ke-%d harus %s, bukan %s", i+1, want, t)
+		}
+	}
+	return nil
+}
+
+// evalFormulaArgs mengevaluasi semua argumen fungsi.
+func evalFormulaArgs(args []formulaNode, env *formulaEnv) ([]interface{}, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// dateDiffUnits adalah satuan yang diterima DATEDIFF.
+var dateDiffUnits = []string{"days", "hours", "minutes", "weeks", "months", "years"}
+
+var formulaFunctions map[string]formulaFunction
+
+func init() {
+	formulaFunctions = map[string]formulaFunction{
+		"IF": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 2, 3); err != nil {
+					return "", err
+				}
+				if types[0] != formulaTypeBoolean {
+					return "", fmt.Errorf("kondisi harus boolean, bukan %s", types[0])
+				}
+				if len(types) == 3 && types[1] != types[2] {
+					return "", fmt.Errorf("kedua cabang harus bertipe sama, bukan %s dan %s", types[1], types[2])
+				}
+				return types[1], nil
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				cond, err := args[0].eval(env)
+				if err != nil {
+					return nil, err
+				}
+				if b, _ := cond.(bool); b {
+					return args[1].eval(env)
+				}
+				if len(args) == 3 {
+					return args[2].eval(env)
+				}
+				return nil, nil
+			},
+		},
+		"AND": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 1, -1); err != nil {
+					return "", err
+				}
+				return formulaTypeBoolean, formulaArgTypes(types, formulaTypeBoolean)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				for _, arg := range args {
+					v, err := arg.eval(env)
+					if err != nil {
+						return nil, err
+					}
+					if b, _ := v.(bool); !b {
+						return false, nil
+					}
+				}
+				return true, nil
+			},
+		},
+		"OR": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 1, -1); err != nil {
+					return "", err
+				}
+				return formulaTypeBoolean, formulaArgTypes(types, formulaTypeBoolean)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				for _, arg := range args {
+					v, err := arg.eval(env)
+					if err != nil {
+						return nil, err
+					}
+					if b, _ := v.(bool); b {
+						return true, nil
+					}
+				}
+				return false, nil
+			},
+		},
+		"NOT": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 1, 1); err != nil {
+					return "", err
+				}
+				return formulaTypeBoolean, formulaArgTypes(types, formulaTypeBoolean)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				v, err := args[0].eval(env)
+				if err != nil || v == nil {
+					return nil, err
+				}
+				return !v.(bool), nil
+			},
+		},
+		"CONCAT": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				return formulaTypeText, formulaArgCount(types, 1, -1)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				values, err := evalFormulaArgs(args, env)
+				if err != nil {
+					return nil, err
+				}
+				var sb strings.Builder
+				for _, v := range values {
+					sb.WriteString(formulaText(v))
+				}
+				return sb.String(), nil
+			},
+		},
+		"DATEDIFF": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 2, 3); err != nil {
+					return "", err
+				}
+				if err := formulaArgTypes(types[:2], formulaTypeDate); err != nil {
+					return "", err
+				}
+				if len(args) == 3 {
+					unit, ok := args[2].(*formulaLiteral)
+					if !ok || unit.typ != formulaTypeText {
+						return "", errors.New("satuan harus berupa teks literal")
+					}
+					valid := false
+					for _, u := range dateDiffUnits {
+						if strings.EqualFold(unit.value.(string), u) {
+							valid = true
+						}
+					}
+					if !valid {
+						return "", fmt.Errorf("satuan '%s' tidak dikenal (gunakan %s)", unit.value, strings.Join(dateDiffUnits, ", "))
+					}
+				}
+				return formulaTypeNumber, nil
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				values, err := evalFormulaArgs(args, env)
+				if err != nil || values[0] == nil || values[1] == nil {
+					return nil, err
+				}
+				end, start := values[0].(time.Time), values[1].(time.Time)
+				unit := "days"
+				if len(values) == 3 {
+					unit = strings.ToLower(values[2].(string))
+				}
+				return dateDiff(end, start, unit), nil
+			},
+		},
+		"ROUND": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 1, 2); err != nil {
+					return "", err
+				}
+				return formulaTypeNumber, formulaArgTypes(types, formulaTypeNumber)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				values, err := evalFormulaArgs(args, env)
+				if err != nil || values[0] == nil {
+					return nil, err
+				}
+				digits := 0.0
+				if len(values) == 2 && values[1] != nil {
+					digits = math.Trunc(values[1].(float64))
+				}
+				scale := math.Pow(10, digits)
+				return math.Round(values[0].(float64)*scale) / scale, nil
+			},
+		},
+		"ABS": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 1, 1); err != nil {
+					return "", err
+				}
+				return formulaTypeNumber, formulaArgTypes(types, formulaTypeNumber)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				v, err := args[0].eval(env)
+				if err != nil || v == nil {
+					return nil, err
+				}
+				return math.Abs(v.(float64)), nil
+			},
+		},
+		"LEN": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 1, 1); err != nil {
+					return "", err
+				}
+				return formulaTypeNumber, formulaArgTypes(types, formulaTypeText)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				v, err := args[0].eval(env)
+				if err != nil || v == nil {
+					return nil, err
+				}
+				return float64(len([]rune(v.(string)))), nil
+			},
+		},
+		"UPPER": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 1, 1); err != nil {
+					return "", err
+				}
+				return formulaTypeText, formulaArgTypes(types, formulaTypeText)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				v, err := args[0].eval(env)
+				if err != nil || v == nil {
+					return nil, err
+				}
+				return strings.ToUpper(v.(string)), nil
+			},
+		},
+		"LOWER": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				if err := formulaArgCount(types, 1, 1); err != nil {
+					return "", err
+				}
+				return formulaTypeText, formulaArgTypes(types, formulaTypeText)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				v, err := args[0].eval(env)
+				if err != nil || v == nil {
+					return nil, err
+				}
+				return strings.ToLower(v.(string)), nil
+			},
+		},
+		"TODAY": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				return formulaTypeDate, formulaArgCount(types, 0, 0)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				return env.now.Truncate(24 * time.Hour), nil
+			},
+		},
+		"NOW": {
+			check: func(args []formulaNode, types []string) (string, error) {
+				return formulaTypeDate, formulaArgCount(types, 0, 0)
+			},
+			eval: func(args []formulaNode, env *formulaEnv) (interface{}, error) {
+				return env.now, nil
+			},
+		},
+	}
+}
+
+// dateDiff menghitung selisih end - start dalam satuan yang diminta, dibulatkan ke arah nol.
+// Satuan months dan years dihitung berdasarkan kalender, bukan jumlah hari.
+func dateDiff(end, start time.Time, unit string) float64 {
+	switch unit {
+	case "hours":
+		return math.Trunc(end.Sub(start).Hours())
+	case "minutes":
+		return math.Trunc(end.Sub(start).Minutes())
+	case "weeks":
+		return math.Trunc(end.Sub(start).Hours() / (24 * 7))
+	case "months", "years":
+		sign := 1.0
+		if end.Before(start) {
+			end, start = start, end
+			sign = -1
+		}
+		months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month())
+		// Bulan terakhir belum genap jika tanggal/jam akhir masih sebelum tanggal/jam awal
+		if start.AddDate(0, months, 0).After(end) {
+			months--
+		}
+		if unit == "years" {
+			return sign * float64(months/12)
+		}
+		return sign * float64(months)
+	}
+	return math.Trunc(end.Sub(start).Hours() / 24)
+}
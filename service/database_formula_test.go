@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend_my_manajer/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// formulaTestColumns membuat kolom input tetap ditambah satu kolom formula "Hasil" dengan ekspresi formula.
+func formulaTestColumns(formula string) []model.DatabaseColumn {
+	return []model.DatabaseColumn{
+		{ID: primitive.NewObjectID(), Name: "Harga", Type: "number"},
+		{ID: primitive.NewObjectID(), Name: "Qty", Type: "number"},
+		{ID: primitive.NewObjectID(), Name: "Nama", Type: "text"},
+		{ID: primitive.NewObjectID(), Name: "Mulai", Type: "date"},
+		{ID: primitive.NewObjectID(), Name: "Aktif", Type: "boolean"},
+		{ID: primitive.NewObjectID(), Name: "Hasil", Type: "formula", Formula: formula},
+	}
+}
+
+func TestEvaluateFormulaColumns(t *testing.T) {
+	start := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		formula string
+		values  map[string]interface{} // Nilai per nama kolom input
+		want    interface{}
+	}{
+		{name: "perkalian sebelum penjumlahan", formula: "1 + 2 * 3", want: float64(7)},
+		{name: "kurung mengubah urutan", formula: "(1 + 2) * 3", want: float64(9)},
+		{name: "pengurangan asosiatif kiri", formula: "10 - 4 - 3", want: float64(3)},
+		{name: "pembagian asosiatif kiri", formula: "8 / 4 / 2", want: float64(1)},
+		{name: "minus unary lebih kuat dari perkalian", formula: "-2 * 3", want: float64(-6)},
+		{name: "modulo setingkat perkalian", formula: "1 + 7 % 4", want: float64(4)},
+		{name: "gabung teks setelah aritmetika", formula: `1 + 2 & "x"`, want: "3x"},
+		{name: "perbandingan setelah gabung teks", formula: `"a" & "b" = "ab"`, want: true},
+		{name: "perbandingan setelah aritmetika", formula: "1 + 1 < 3", want: true},
+		{name: "referensi kolom", formula: "{Harga} * {Qty}", values: map[string]interface{}{"Harga": 2.5, "Qty": float64(4)}, want: float64(10)},
+		{name: "null pada aritmetika menghasilkan null", formula: "{Harga} * 2", want: nil},
+		{name: "null pada gabung teks menjadi string kosong", formula: `{Nama} & "!"`, want: "!"},
+		{name: "pembagian dengan nol menghasilkan null", formula: "{Harga} / 0", values: map[string]interface{}{"Harga": float64(1)}, want: nil},
+		{name: "tanggal ditambah hari", formula: "{Mulai} + 5", values: map[string]interface{}{"Mulai": start}, want: start.AddDate(0, 0, 5)},
+		{name: "IF dengan kondisi boolean", formula: `IF({Aktif}, "ya", "tidak")`, values: map[string]interface{}{"Aktif": false}, want: "tidak"},
+		{name: "IF bersarang dengan perbandingan", formula: `IF({Qty} >= 10, "grosir", IF({Qty} > 0, "eceran", "kosong"))`, values: map[string]interface{}{"Qty": float64(3)}, want: "eceran"},
+		{name: "ROUND dengan digit", formula: "ROUND(10 / 3, 2)", want: 3.33},
+		{name: "fungsi teks", formula: `UPPER({Nama}) & LEN({Nama})`, values: map[string]interface{}{"Nama": "abc"}, want: "ABC3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns := formulaTestColumns(tt.formula)
+			if err := ValidateFormulaColumns(columns); err != nil {
+				t.Fatalf("ValidateFormulaColumns(%q) error = %v", tt.formula, err)
+			}
+			values := model.DatabaseRowValue{}
+			for _, col := range columns {
+				if value, ok := tt.values[col.Name]; ok {
+					values[col.ID.Hex()] = value
+				}
+			}
+			rows := []model.DatabaseRow{{ID: primitive.NewObjectID(), Values: values}}
+
+			EvaluateFormulaColumns(columns, rows)
+
+			got := rows[0].Values[columns[len(columns)-1].ID.Hex()]
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q = %#v, want %#v", tt.formula, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFormulaColumnsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula string
+	}{
+		{name: "number ditambah text", formula: `{Harga} + {Nama}`},
+		{name: "perkalian boolean", formula: "{Aktif} * 2"},
+		{name: "selisih dua tanggal", formula: "{Mulai} - {Mulai}"},
+		{name: "number dikurangi tanggal", formula: "1 - {Mulai}"},
+		{name: "membandingkan number dengan text", formula: `{Harga} = "10"`},
+		{name: "urutan boolean", formula: "{Aktif} < TRUE"},
+		{name: "minus pada text", formula: "-{Nama}"},
+		{name: "kondisi IF bukan boolean", formula: `IF({Harga}, 1, 2)`},
+		{name: "cabang IF berbeda tipe", formula: `IF({Aktif}, 1, "satu")`},
+		{name: "kolom tidak ada", formula: "{Diskon} * 2"},
+		{name: "fungsi tidak dikenal", formula: "SUM(1, 2)"},
+		{name: "jumlah argumen salah", formula: "ABS(1, 2)"},
+		{name: "kurung tidak ditutup", formula: "(1 + 2"},
+		{name: "operator tanpa operand", formula: "1 +"},
+		{name: "referensi ke diri sendiri", formula: "{Hasil} + 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFormulaColumns(formulaTestColumns(tt.formula))
+			if !errors.Is(err, ErrInvalidFormula) {
+				t.Errorf("ValidateFormulaColumns(%q) error = %v, want ErrInvalidFormula", tt.formula, err)
+			}
+		})
+	}
+}
+
+func TestValidateFormulaColumnsCycle(t *testing.T) {
+	a := model.DatabaseColumn{ID: primitive.NewObjectID(), Name: "A", Type: "formula", Formula: "{B} + 1"}
+	b := model.DatabaseColumn{ID: primitive.NewObjectID(), Name: "B", Type: "formula", Formula: "{A} + 1"}
+	if err := ValidateFormulaColumns([]model.DatabaseColumn{a, b}); !errors.Is(err, ErrInvalidFormula) {
+		t.Errorf("ValidateFormulaColumns() error = %v, want ErrInvalidFormula untuk referensi melingkar", err)
+	}
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Export menulis kolom sesuai DatabaseColumn.Order dengan header berupa nama kolom. Nilai select
+// ditulis sebagai value opsinya, relation sebagai daftar ID baris dipisah koma, dan date sebagai
+// YYYY-MM-DD (atau RFC3339 jika memiliki jam) sehingga file hasil export dapat di-import kembali.
+//
+// Import membaca baris pertama sebagai header, lalu:
+//   - membuat database baru dengan tipe kolom yang ditebak dari isinya (InferImportColumns), atau
+//   - menambahkan baris ke database yang sudah ada dengan pemetaan header -> kolom (ResolveImportMapping).
+//
+// Baris yang tidak valid tidak menggagalkan seluruh file; baris tersebut dilewati dan dilaporkan
+// sebagai ImportRowError dengan nomor baris sesuai spreadsheet (header = baris 1).
+
+// MaxImportRows adalah jumlah maksimum baris data dalam satu file import.
+const MaxImportRows = 5000
+
+// maxInferredSelectOptions adalah jumlah nilai unik maksimum agar kolom teks ditebak sebagai select.
+const maxInferredSelectOptions = 20
+
+// ErrInvalidImportMapping dikembalikan jika pemetaan header ke kolom tidak valid.
+var ErrInvalidImportMapping = errors.New("pemetaan kolom import tidak valid")
+
+// ImportRowError menjelaskan satu baris spreadsheet yang gagal di-import.
+type ImportRowError struct {
+	Row    int             `json:"row"`
+	Errors []RowFieldError `json:"errors"`
+}
+
+// BuildExportTable menyusun header dan sel-sel export dari kolom dan baris database.
+// Nilai kolom turunan harus sudah dihitung dengan ComputeDerivedColumns.
+func BuildExportTable(columns []model.DatabaseColumn, rows []model.DatabaseRow) ([]string, [][]SpreadsheetCell) {
+	ordered := make([]model.DatabaseColumn, len(columns))
+	copy(ordered, columns)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Order < ordered[j].Order })
+
+	header := make([]string, len(ordered))
+	for i, col := range ordered {
+		header[i] = col.Name
+	}
+	records := make([][]SpreadsheetCell, len(rows))
+	for i, row := range rows {
+		record := make([]SpreadsheetCell, len(ordered))
+		for j := range ordered {
+			record[j] = exportCell(&ordered[j], row.Values[ordered[j].ID.Hex()])
+		}
+		records[i] = record
+	}
+	return header, records
+}
+
+// exportCell mengonversi satu nilai sel menjadi sel spreadsheet.
+func exportCell(col *model.DatabaseColumn, value interface{}) SpreadsheetCell {
+	if isEmptyValue(value) {
+		return SpreadsheetCell{}
+	}
+	colType := col.Type
+	if colType == "formula" {
+		colType = formulaValueType(value)
+	}
+	switch colType {
+	case "date":
+		if t, ok := toTime(value); ok {
+			t = t.UTC()
+			if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+				return SpreadsheetCell{Text: t.Format("2006-01-02")}
+			}
+			return SpreadsheetCell{Text: t.Format(time.RFC3339)}
+		}
+	case "number", "rollup":
+		if f, ok := toFloat(value); ok {
+			return SpreadsheetCell{Text: strconv.FormatFloat(f, 'f', -1, 64), IsNumber: true}
+		}
+	case "relation", "file":
+		return SpreadsheetCell{Text: strings.Join(relationRowIDs(value), ", ")}
+	}
+	return SpreadsheetCell{Text: CellDisplayValue(&model.DatabaseColumn{Type: colType, Options: col.Options}, value)}
+}
+
+// InferImportColumns membuat definisi kolom untuk database baru dari header dan baris data.
+// Tipe ditebak dari nilai yang tidak kosong: boolean (true/false), number, date, select (sedikit
+// nilai unik yang berulang) atau text. Nama header kosong atau duplikat dibuat unik.
+func InferImportColumns(header []string, records [][]string) []model.DatabaseColumn {
+	columns := make([]model.DatabaseColumn, len(header))
+	usedNames := make(map[string]int, len(header))
+	now := time.Now()
+	for i, rawName := range header {
+		name := strings.TrimSpace(rawName)
+		if name == "" {
+			name = fmt.Sprintf("Kolom %d", i+1)
+		}
+		key := strings.ToLower(name)
+		usedNames[key]++
+		if n := usedNames[key]; n > 1 {
+			name = fmt.Sprintf("%s (%d)", name, n)
+		}
+
+		var values []string
+		for _, record := range records {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				values = append(values, strings.TrimSpace(record[i]))
+			}
+		}
+		colType, options := inferColumnType(values)
+		for j := range options {
+			options[j].CreatedAt = now
+		}
+		columns[i] = model.DatabaseColumn{
+			ID:      primitive.NewObjectID(),
+			Name:    name,
+			Type:    colType,
+			Options: options,
+			Order:   i + 1,
+		}
+	}
+	return columns
+}
+
+// inferColumnType menebak tipe kolom dari nilai-nilai yang tidak kosong. Untuk select, opsi dibuat
+// sesuai urutan kemunculan nilai.
+func inferColumnType(values []string) (string, []model.SelectOption) {
+	if len(values) == 0 {
+		return "text", nil
+	}
+	allBool, allNumber, allDate := true, true, true
+	for _, v := range values {
+		if !strings.EqualFold(v, "true") && !strings.EqualFold(v, "false") {
+			allBool = false
+		}
+		if f, err := strconv.ParseFloat(v, 64); err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			allNumber = false
+		}
+		if _, ok := toTime(v); !ok {
+			allDate = false
+		}
+	}
+	switch {
+	case allBool:
+		return "boolean", nil
+	case allNumber:
+		return "number", nil
+	case allDate:
+		return "date", nil
+	}
+
+	var distinct []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			distinct = append(distinct, v)
+		}
+	}
+	if len(distinct) > maxInferredSelectOptions || len(distinct)*2 > len(values) {
+		return "text", nil
+	}
+	options := make([]model.SelectOption, len(distinct))
+	for i, v := range distinct {
+		options[i] = model.SelectOption{ID: primitive.NewObjectID(), Value: v, Order: i + 1}
+	}
+	return "select", options
+}
+
+// ResolveImportMapping menentukan kolom tujuan untuk setiap header. mapping berisi nama header ->
+// ID atau nama kolom; nilai kosong berarti header diabaikan. Jika mapping nil, header dicocokkan
+// dengan nama kolom tanpa membedakan huruf besar/kecil. Mengembalikan kolom per indeks header
+// (nil jika diabaikan) dan daftar header yang diabaikan.
+func ResolveImportMapping(columns []model.DatabaseColumn, header []string, mapping map[string]string) ([]*model.DatabaseColumn, []string, error) {
+	headerIndex := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, exists := headerIndex[strings.TrimSpace(name)]; !exists {
+			headerIndex[strings.TrimSpace(name)] = i
+		}
+	}
+	for key := range mapping {
+		if _, ok := headerIndex[strings.TrimSpace(key)]; !ok {
+			return nil, nil, fmt.Errorf("%w: header '%s' tidak ada di file", ErrInvalidImportMapping, key)
+		}
+	}
+
+	targets := make([]*model.DatabaseColumn, len(header))
+	var ignored []string
+	mappedBy := make(map[string]string)
+	for i, rawName := range header {
+		name := strings.TrimSpace(rawName)
+		ref := name
+		if mapping != nil {
+			ref = ""
+			if headerIndex[name] == i {
+				ref = mapping[name]
+			}
+		}
+		col := findColumnByRef(columns, ref)
+		if col == nil {
+			if mapping != nil && strings.TrimSpace(ref) != "" {
+				return nil, nil, fmt.Errorf("%w: kolom '%s' untuk header '%s' tidak ditemukan", ErrInvalidImportMapping, ref, name)
+			}
+			ignored = append(ignored, rawName)
+			continue
+		}
+		if col.Type == "formula" || col.Type == "rollup" || col.Type == "file" {
+			if mapping != nil {
+				reason := "dihitung oleh server"
+				if col.Type == "file" {
+					reason = "diisi lewat upload file"
+				}
+				return nil, nil, fmt.Errorf("%w: kolom '%s' bertipe %s %s dan tidak dapat di-import", ErrInvalidImportMapping, col.Name, col.Type, reason)
+			}
+			ignored = append(ignored, rawName)
+			continue
+		}
+		if previous, ok := mappedBy[col.ID.Hex()]; ok {
+			return nil, nil, fmt.Errorf("%w: header '%s' dan '%s' dipetakan ke kolom yang sama '%s'", ErrInvalidImportMapping, previous, name, col.Name)
+		}
+		mappedBy[col.ID.Hex()] = name
+		targets[i] = col
+	}
+	return targets, ignored, nil
+}
+
+// BuildImportRows memvalidasi baris-baris data spreadsheet (tanpa header) terhadap kolom tujuannya.
+// targets adalah kolom per indeks header (nil untuk header yang diabaikan). Batasan kolom diperiksa
+// terhadap baris tersimpan databaseID (kosong untuk database baru) dan baris import sebelumnya. Baris
+// yang seluruhnya kosong dilewati.
+// Mengembalikan baris valid dan daftar error per baris; error non-validasi (misalnya kegagalan
+// membaca database target relation) dikembalikan sebagai error.
+func BuildImportRows(ctx context.Context, dbRepo repository.DatabaseRepository, columns []model.DatabaseColumn, targets []*model.DatabaseColumn, databaseID primitive.ObjectID, records [][]string) ([]model.DatabaseRow, []ImportRowError, error) {
+	rows := make([]model.DatabaseRow, 0, len(records))
+	checker := NewRowConstraintChecker(columns, nil)
+	if !databaseID.IsZero() {
+		checker = NewStoredRowConstraintChecker(ctx, dbRepo, databaseID, columns)
+	}
+	var rowErrors []ImportRowError
+	for i, record := range records {
+		rowNumber := i + 2
+		values := make(map[string]interface{})
+		for j, col := range targets {
+			if col == nil || j >= len(record) {
+				continue
+			}
+			if value := importCellValue(col, record[j]); value != nil {
+				values[col.ID.Hex()] = value
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		coerced, err := ValidateRowValues(columns, values)
+		if err == nil {
+			err = ValidateRelationValues(ctx, dbRepo, columns, coerced)
+		}
+		if err == nil {
+			coerced, err = checker.Check(primitive.NilObjectID, coerced)
+		}
+		if err != nil {
+			var validationErr *RowValidationError
+			if !errors.As(err, &validationErr) {
+				return nil, nil, err
+			}
+			rowErrors = append(rowErrors, ImportRowError{Row: rowNumber, Errors: validationErr.Errors})
+			continue
+		}
+		rows = append(rows, model.DatabaseRow{Values: coerced})
+	}
+	return rows, rowErrors, nil
+}
+
+// importCellValue mengubah teks sel menjadi nilai mentah untuk ValidateRowValues. Sel kosong menjadi nil.
+func importCellValue(col *model.DatabaseColumn, raw string) interface{} {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return nil
+	}
+	switch col.Type {
+	case "text":
+		return raw
+	case "relation":
+		parts := strings.FieldsFunc(trimmed, func(r rune) bool { return r == ',' || r == ';' || r == ' ' })
+		items := make([]interface{}, len(parts))
+		for i, part := range parts {
+			items[i] = part
+		}
+		return items
+	}
+	return trimmed
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kolom "relation" menyimpan daftar ID baris (hex) dari database target di channel yang sama.
+// Kolom "rollup" tidak menyimpan nilai; nilainya dihitung saat dibaca dengan mengagregasi
+// kolom target pada baris-baris yang dirujuk kolom relation:
+//   - count : jumlah baris terkait (atau jumlah yang kolom targetnya terisi jika targetColumnId diisi)
+//   - sum, avg, min, max : agregasi kolom number di database target
+
+// ErrInvalidColumnLink dikembalikan jika konfigurasi kolom relation atau rollup tidak valid.
+var ErrInvalidColumnLink = errors.New("konfigurasi kolom relation/rollup tidak valid")
+
+// ResolveLinkedColumns memvalidasi konfigurasi kolom relation dan rollup milik database, lalu
+// menerjemahkan referensi kolom rollup yang ditulis dengan nama menjadi ID kolom (in-place).
+// Konfigurasi yang tidak sesuai tipe kolom dihapus. database dipakai untuk ID dan ChannelID-nya;
+// ID boleh kosong untuk database yang belum dibuat.
+func ResolveLinkedColumns(ctx context.Context, dbRepo repository.DatabaseRepository, database *model.Database, columns []model.DatabaseColumn) error {
+	targets := make(map[primitive.ObjectID]*model.Database)
+	loadTarget := func(targetID primitive.ObjectID) (*model.Database, error) {
+		if !database.ID.IsZero() && targetID == database.ID {
+			return &model.Database{ID: database.ID, ChannelID: database.ChannelID, DatabaseData: model.DatabaseData{Columns: columns}}, nil
+		}
+		if target, ok := targets[targetID]; ok {
+			return target, nil
+		}
+		target, err := dbRepo.GetDatabaseByID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		targets[targetID] = target
+		return target, nil
+	}
+
+	for i := range columns {
+		col := &columns[i]
+		switch col.Type {
+		case "relation":
+			col.Rollup = nil
+			if col.Relation == nil || col.Relation.DatabaseID.IsZero() {
+				return fmt.Errorf("%w: kolom '%s' membutuhkan database target", ErrInvalidColumnLink, col.Name)
+			}
+			target, err := loadTarget(col.Relation.DatabaseID)
+			if err != nil {
+				return err
+			}
+			if target == nil {
+				return fmt.Errorf("%w: database target kolom '%s' tidak ditemukan", ErrInvalidColumnLink, col.Name)
+			}
+			if target.ChannelID != database.ChannelID {
+				return fmt.Errorf("%w: database target kolom '%s' harus berada di channel yang sama", ErrInvalidColumnLink, col.Name)
+			}
+		case "rollup":
+			col.Relation = nil
+			if col.Rollup == nil {
+				return fmt.Errorf("%w: kolom '%s' membutuhkan konfigurasi rollup", ErrInvalidColumnLink, col.Name)
+			}
+		default:
+			col.Relation, col.Rollup = nil, nil
+		}
+	}
+
+	// Rollup diperiksa setelah semua kolom relation valid
+	for i := range columns {
+		col := &columns[i]
+		if col.Type != "rollup" {
+			continue
+		}
+		rollup := col.Rollup
+		switch rollup.Aggregation {
+		case AggregationCount, AggregationSum, AggregationAvg, AggregationMin, AggregationMax:
+		default:
+			return fmt.Errorf("%w: aggregation '%s' pada kolom '%s' tidak didukung (gunakan count, sum, avg, min atau max)", ErrInvalidColumnLink, rollup.Aggregation, col.Name)
+		}
+
+		relationCol := findColumnByRef(columns, rollup.RelationColumnID)
+		if relationCol == nil || relationCol.Type != "relation" {
+			return fmt.Errorf("%w: kolom '%s' harus merujuk kolom relation di database ini", ErrInvalidColumnLink, col.Name)
+		}
+		rollup.RelationColumnID = relationCol.ID.Hex()
+
+		if rollup.TargetColumnID == "" {
+			if rollup.Aggregation != AggregationCount {
+				return fmt.Errorf("%w: aggregation '%s' pada kolom '%s' membutuhkan targetColumnId", ErrInvalidColumnLink, rollup.Aggregation, col.Name)
+			}
+			continue
+		}
+		target, err := loadTarget(relationCol.Relation.DatabaseID)
+		if err != nil {
+			return err
+		}
+		targetCol := findColumnByRef(target.DatabaseData.Columns, rollup.TargetColumnID)
+		if targetCol == nil {
+			return fmt.Errorf("%w: kolom target '%s' untuk rollup '%s' tidak ditemukan di database target", ErrInvalidColumnLink, rollup.TargetColumnID, col.Name)
+		}
+		if rollup.Aggregation != AggregationCount && targetCol.Type != "number" {
+			return fmt.Errorf("%w: aggregation '%s' pada kolom '%s' membutuhkan kolom target number, kolom '%s' bertipe %s", ErrInvalidColumnLink, rollup.Aggregation, col.Name, targetCol.Name, targetCol.Type)
+		}
+		rollup.TargetColumnID = targetCol.ID.Hex()
+	}
+	return nil
+}
+
+// LinkedColumnDependent mengembalikan nama kolom rollup pertama yang bergantung pada columnID
+// (sebagai kolom relation-nya), atau string kosong jika tidak ada.
+func LinkedColumnDependent(columns []model.DatabaseColumn, columnID string) string {
+	for _, col := range columns {
+		if col.Type == "rollup" && col.Rollup != nil && col.Rollup.RelationColumnID == columnID {
+			return col.Name
+		}
+	}
+	return ""
+}
+
+// ValidateRelationValues memastikan setiap ID baris pada kolom relation benar-benar ada di database target.
+// values harus sudah melewati ValidateRowValues. Error bertipe *RowValidationError.
+func ValidateRelationValues(ctx context.Context, dbRepo repository.DatabaseRepository, columns []model.DatabaseColumn, values model.DatabaseRowValue) error {
+	var fieldErrors []RowFieldError
+	for _, col := range columns {
+		if col.Type != "relation" || col.Relation == nil {
+			continue
+		}
+		rowIDs := relationRowIDs(values[col.ID.Hex()])
+		if len(rowIDs) == 0 {
+			continue
+		}
+		objectIDs := make([]primitive.ObjectID, 0, len(rowIDs))
+		for _, rowID := range rowIDs {
+			if oid, err := primitive.ObjectIDFromHex(rowID); err == nil {
+				objectIDs = append(objectIDs, oid)
+			}
+		}
+		found, err := dbRepo.GetRowsByIDs(ctx, col.Relation.DatabaseID, objectIDs)
+		if err != nil {
+			return err
+		}
+		existing := make(map[string]bool, len(found))
+		for _, row := range found {
+			existing[row.ID.Hex()] = true
+		}
+		var missing []string
+		for _, rowID := range rowIDs {
+			if !existing[rowID] {
+				missing = append(missing, rowID)
+			}
+		}
+		if len(missing) > 0 {
+			fieldErrors = append(fieldErrors, RowFieldError{
+				ColumnID:   col.ID.Hex(),
+				ColumnName: col.Name,
+				Message:    fmt.Sprintf("baris %s tidak ditemukan di database target", strings.Join(missing, ", ")),
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &RowValidationError{Errors: fieldErrors}
+	}
+	return nil
+}
+
+// ComputeDerivedColumns menghitung nilai kolom turunan pada semua baris database (in-place):
+// rollup lebih dulu, lalu formula (yang boleh merujuk rollup). Kegagalan memuat database target
+// hanya dicatat di log dan nilai rollup terkait menjadi null.
+func ComputeDerivedColumns(ctx context.Context, dbRepo repository.DatabaseRepository, database *model.Database) {
+	columns := database.DatabaseData.Columns
+	rows := database.DatabaseData.Rows
+	columnsByID := indexColumns(columns)
+
+	targets := make(map[primitive.ObjectID]map[string]model.DatabaseRow)
+	loadTargetRows := func(targetID primitive.ObjectID) map[string]model.DatabaseRow {
+		if targetRows, ok := targets[targetID]; ok {
+			return targetRows
+		}
+		targetRows := make(map[string]model.DatabaseRow)
+		source := rows
+		if targetID != database.ID {
+			target, err := dbRepo.GetDatabaseByID(ctx, targetID)
+			if err != nil {
+				utils.LogWarning("Gagal memuat database target %s untuk rollup: %v", targetID.Hex(), err)
+			}
+			source = nil
+			if target != nil {
+				source = target.DatabaseData.Rows
+			}
+		}
+		for _, row := range source {
+			targetRows[row.ID.Hex()] = row
+		}
+		targets[targetID] = targetRows
+		return targetRows
+	}
+
+	for _, col := range columns {
+		if col.Type != "rollup" {
+			continue
+		}
+		var relationCol *model.DatabaseColumn
+		if col.Rollup != nil {
+			relationCol = columnsByID[col.Rollup.RelationColumnID]
+		}
+		for i := range rows {
+			if rows[i].Values == nil {
+				rows[i].Values = model.DatabaseRowValue{}
+			}
+			if relationCol == nil || relationCol.Relation == nil {
+				rows[i].Values[col.ID.Hex()] = nil
+				continue
+			}
+			targetRows := loadTargetRows(relationCol.Relation.DatabaseID)
+			rows[i].Values[col.ID.Hex()] = computeRollup(col.Rollup, relationRowIDs(rows[i].Values[relationCol.ID.Hex()]), targetRows)
+		}
+	}
+
+	EvaluateFormulaColumns(columns, rows)
+}
+
+// computeRollup mengagregasi kolom target pada baris-baris terkait dengan akumulator yang sama
+// seperti metric laporan. Baris yang sudah tidak ada diabaikan.
+func computeRollup(rollup *model.RollupConfig, rowIDs []string, targetRows map[string]model.DatabaseRow) interface{} {
+	metric := ReportMetric{ColumnID: rollup.TargetColumnID, Aggregation: rollup.Aggregation}
+	acc := &reportAccumulator{}
+	for _, rowID := range rowIDs {
+		if row, ok := targetRows[rowID]; ok {
+			acc.add(metric, row)
+		}
+	}
+	if result := acc.result(rollup.Aggregation); result != nil {
+		return *result
+	}
+	return nil
+}
+
+// findColumnByRef mencari kolom berdasarkan ID hex, lalu berdasarkan nama (tanpa membedakan huruf besar/kecil).
+func findColumnByRef(columns []model.DatabaseColumn, ref string) *model.DatabaseColumn {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil
+	}
+	for i := range columns {
+		if columns[i].ID.Hex() == ref {
+			return &columns[i]
+		}
+	}
+	for i := range columns {
+		if strings.EqualFold(strings.TrimSpace(columns[i].Name), ref) {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+// relationRowIDs membaca nilai sel relation (hasil validasi atau hasil decode BSON) sebagai daftar ID baris hex.
+func relationRowIDs(value interface{}) []string {
+	var items []interface{}
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []string:
+		return v
+	case []interface{}:
+		items = v
+	case primitive.A:
+		items = v
+	case string:
+		items = []interface{}{v}
+	default:
+		return nil
+	}
+	rowIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		switch id := item.(type) {
+		case string:
+			rowIDs = append(rowIDs, id)
+		case primitive.ObjectID:
+			rowIDs = append(rowIDs, id.Hex())
+		}
+	}
+	return rowIDs
+}
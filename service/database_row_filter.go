@@ -0,0 +1,379 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend_my_manajer/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Operator filter yang didukung untuk baris database.
+const (
+	FilterOpEquals     = "equals"
+	FilterOpNotEquals  = "not_equals"
+	FilterOpContains   = "contains"
+	FilterOpGreater    = "gt"
+	FilterOpGreaterEq  = "gte"
+	FilterOpLess       = "lt"
+	FilterOpLessEq     = "lte"
+	FilterOpBetween    = "between"
+	FilterOpIsEmpty    = "is_empty"
+	FilterOpIsNotEmpty = "is_not_empty"
+	FilterOpIn         = "in" // Untuk kolom select: cocok jika nilai adalah salah satu opsi yang diberikan
+)
+
+// RowFilterCondition adalah satu kondisi filter terhadap satu kolom.
+// Untuk operator "between", Value berisi array [min, max]; untuk "in", Value berisi array nilai/ID opsi.
+type RowFilterCondition struct {
+	ColumnID string      `json:"columnId"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value,omitempty"`
+}
+
+// RowFilter adalah kumpulan kondisi filter. Match bernilai "all" (default, AND) atau "any" (OR).
+type RowFilter struct {
+	Match      string               `json:"match,omitempty"`
+	Conditions []RowFilterCondition `json:"conditions"`
+}
+
+// ParseRowFilter mengonversi konfigurasi filter bebas (mis. ReportVisualizationConfig.FilterConfig)
+// menjadi RowFilter. Konfigurasi kosong menghasilkan filter tanpa kondisi.
+func ParseRowFilter(raw map[string]interface{}) (*RowFilter, error) {
+	filter := &RowFilter{}
+	if len(raw) == 0 {
+		return filter, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("filter tidak valid: %w", err)
+	}
+	if err := json.Unmarshal(data, filter); err != nil {
+		return nil, fmt.Errorf("filter tidak valid: %w", err)
+	}
+	return filter, nil
+}
+
+// Validate memastikan setiap kondisi merujuk ke kolom yang ada dan memakai operator yang sesuai dengan tipe kolom.
+func (f *RowFilter) Validate(columns []model.DatabaseColumn) error {
+	if f.Match != "" && f.Match != "all" && f.Match != "any" {
+		return fmt.Errorf("match harus 'all' atau 'any', bukan '%s'", f.Match)
+	}
+	columnsByID := indexColumns(columns)
+	for _, cond := range f.Conditions {
+		col, ok := columnsByID[cond.ColumnID]
+		if !ok {
+			return fmt.Errorf("kolom '%s' pada filter tidak ditemukan", cond.ColumnID)
+		}
+		switch cond.Operator {
+		case FilterOpEquals, FilterOpNotEquals, FilterOpIsEmpty, FilterOpIsNotEmpty:
+		case FilterOpContains:
+			if col.Type != "text" && col.Type != "select" && col.Type != "formula" && col.Type != "relation" {
+				return fmt.Errorf("operator 'contains' tidak didukung untuk kolom '%s' bertipe %s", col.Name, col.Type)
+			}
+		case FilterOpGreater, FilterOpGreaterEq, FilterOpLess, FilterOpLessEq, FilterOpBetween:
+			if col.Type != "number" && col.Type != "date" && col.Type != "formula" && col.Type != "rollup" {
+				return fmt.Errorf("operator '%s' hanya didukung untuk kolom number atau date, kolom '%s' bertipe %s", cond.Operator, col.Name, col.Type)
+			}
+			if cond.Operator == FilterOpBetween {
+				bounds, ok := cond.Value.([]interface{})
+				if !ok || len(bounds) != 2 {
+					return fmt.Errorf("operator 'between' pada kolom '%s' membutuhkan value berupa array [min, max]", col.Name)
+				}
+			}
+		case FilterOpIn:
+			if col.Type != "select" {
+				return fmt.Errorf("operator 'in' hanya didukung untuk kolom select, kolom '%s' bertipe %s", col.Name, col.Type)
+			}
+			if _, ok := cond.Value.([]interface{}); !ok {
+				return fmt.Errorf("operator 'in' pada kolom '%s' membutuhkan value berupa array", col.Name)
+			}
+		default:
+			return fmt.Errorf("operator filter '%s' tidak dikenal", cond.Operator)
+		}
+	}
+	return nil
+}
+
+// FilterRows mengembalikan baris-baris yang memenuhi filter. Perbandingan mengikuti DatabaseColumn.Type,
+// sehingga kolom number dan date dibandingkan secara numerik/kronologis, bukan sebagai string.
+func FilterRows(columns []model.DatabaseColumn, rows []model.DatabaseRow, filter *RowFilter) []model.DatabaseRow {
+	if filter == nil || len(filter.Conditions) == 0 {
+		return rows
+	}
+	columnsByID := indexColumns(columns)
+	matchAny := filter.Match == "any"
+
+	result := make([]model.DatabaseRow, 0, len(rows))
+	for _, row := range rows {
+		matched := !matchAny
+		for _, cond := range filter.Conditions {
+			ok := matchCondition(columnsByID[cond.ColumnID], row.Values[cond.ColumnID], cond)
+			if matchAny && ok {
+				matched = true
+				break
+			}
+			if !matchAny && !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			result = append(result, row)
+		}
+	}
+	return result
+}
+
+// matchCondition mengevaluasi satu kondisi terhadap satu nilai sel.
+func matchCondition(col *model.DatabaseColumn, value interface{}, cond RowFilterCondition) bool {
+	if col == nil {
+		return false
+	}
+	switch cond.Operator {
+	case FilterOpIsEmpty:
+		return isEmptyValue(value)
+	case FilterOpIsNotEmpty:
+		return !isEmptyValue(value)
+	case FilterOpEquals:
+		cmp, ok := CompareCellValues(col, value, cond.Value)
+		return ok && cmp == 0
+	case FilterOpNotEquals:
+		cmp, ok := CompareCellValues(col, value, cond.Value)
+		return !ok || cmp != 0
+	case FilterOpContains:
+		needle := strings.ToLower(fmt.Sprint(cond.Value))
+		return strings.Contains(strings.ToLower(CellDisplayValue(col, value)), needle)
+	case FilterOpGreater, FilterOpGreaterEq, FilterOpLess, FilterOpLessEq:
+		if isEmptyValue(value) {
+			return false
+		}
+		cmp, ok := CompareCellValues(col, value, cond.Value)
+		if !ok {
+			return false
+		}
+		switch cond.Operator {
+		case FilterOpGreater:
+			return cmp > 0
+		case FilterOpGreaterEq:
+			return cmp >= 0
+		case FilterOpLess:
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	case FilterOpBetween:
+		bounds, ok := cond.Value.([]interface{})
+		if !ok || len(bounds) != 2 || isEmptyValue(value) {
+			return false
+		}
+		// Batas bernilai null dianggap terbuka, sehingga [min, null] berarti ">= min"
+		if bounds[0] != nil {
+			if cmp, ok := CompareCellValues(col, value, bounds[0]); !ok || cmp < 0 {
+				return false
+			}
+		}
+		if bounds[1] != nil {
+			if cmp, ok := CompareCellValues(col, value, bounds[1]); !ok || cmp > 0 {
+				return false
+			}
+		}
+		return true
+	case FilterOpIn:
+		candidates, ok := cond.Value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, candidate := range candidates {
+			if cmp, ok := CompareCellValues(col, value, candidate); ok && cmp == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// CompareCellValues membandingkan dua nilai sesuai tipe kolom. Mengembalikan -1, 0, 1 dan false
+// jika salah satu nilai tidak dapat dikonversi ke tipe kolom.
+func CompareCellValues(col *model.DatabaseColumn, a, b interface{}) (int, bool) {
+	switch col.Type {
+	case "number", "rollup":
+		x, okA := toFloat(a)
+		y, okB := toFloat(b)
+		if !okA || !okB {
+			return 0, false
+		}
+		return compareFloat(x, y), true
+	case "date":
+		x, okA := toTime(a)
+		y, okB := toTime(b)
+		if !okA || !okB {
+			return 0, false
+		}
+		return x.Compare(y), true
+	case "boolean":
+		x, okA := toBool(a)
+		y, okB := toBool(b)
+		if !okA || !okB {
+			return 0, false
+		}
+		if x == y {
+			return 0, true
+		}
+		if !x {
+			return -1, true
+		}
+		return 1, true
+	case "select":
+		// Opsi dapat dirujuk lewat ID maupun value-nya
+		x := strings.ToLower(CellDisplayValue(col, a))
+		y := strings.ToLower(CellDisplayValue(col, b))
+		return strings.Compare(x, y), true
+	case "formula":
+		// Nilai formula sudah dievaluasi; bandingkan sesuai tipe nilai hasilnya
+		sample := a
+		if sample == nil {
+			sample = b
+		}
+		return CompareCellValues(&model.DatabaseColumn{Type: formulaValueType(sample)}, a, b)
+	default:
+		if a == nil || b == nil {
+			if a == nil && b == nil {
+				return 0, true
+			}
+			return 0, false
+		}
+		return strings.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b))), true
+	}
+}
+
+// CellDisplayValue mengembalikan representasi string dari nilai sel. Untuk kolom select,
+// ID opsi diterjemahkan menjadi value opsi; untuk kolom date, tanggal diformat sebagai RFC3339.
+func CellDisplayValue(col *model.DatabaseColumn, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	switch col.Type {
+	case "select":
+		raw := fmt.Sprint(value)
+		if oid, ok := value.(primitive.ObjectID); ok {
+			raw = oid.Hex()
+		}
+		for _, opt := range col.Options {
+			if opt.ID.Hex() == raw || strings.EqualFold(opt.Value, raw) {
+				return opt.Value
+			}
+		}
+		return raw
+	case "formula":
+		return CellDisplayValue(&model.DatabaseColumn{Type: formulaValueType(value)}, value)
+	case "date":
+		if t, ok := toTime(value); ok {
+			return t.UTC().Format(time.RFC3339)
+		}
+	case "number", "rollup":
+		if f, ok := toFloat(value); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// indexColumns membuat peta kolom berdasarkan ID hex-nya.
+func indexColumns(columns []model.DatabaseColumn) map[string]*model.DatabaseColumn {
+	columnsByID := make(map[string]*model.DatabaseColumn, len(columns))
+	for i := range columns {
+		columnsByID[columns[i].ID.Hex()] = &columns[i]
+	}
+	return columnsByID
+}
+
+// isEmptyValue mengecek apakah nilai sel dianggap kosong.
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	case primitive.A:
+		return len(v) == 0
+	}
+	return false
+}
+
+// toFloat mengonversi nilai numerik (termasuk hasil decode BSON dan string angka) ke float64.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, !math.IsNaN(v)
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case primitive.Decimal128:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		return f, err == nil
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toTime mengonversi nilai tanggal (time.Time, primitive.DateTime, atau string RFC3339/YYYY-MM-DD) ke time.Time.
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case primitive.DateTime:
+		return v.Time(), true
+	case string:
+		s := strings.TrimSpace(v)
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// toBool mengonversi nilai boolean (termasuk string "true"/"false") ke bool.
+func toBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return b, err == nil
+	}
+	return false, false
+}
+
+// compareFloat membandingkan dua float64 dan mengembalikan -1, 0 atau 1.
+func compareFloat(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
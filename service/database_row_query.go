@@ -0,0 +1,239 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"backend_my_manajer/model"
+)
+
+// Batas default dan maksimum jumlah baris per halaman.
+const (
+	DefaultRowQueryLimit = 100
+	MaxRowQueryLimit     = 1000
+)
+
+// ErrInvalidRowQuery dikembalikan jika parameter query baris (filter, sort, pagination) tidak valid.
+var ErrInvalidRowQuery = errors.New("query baris tidak valid")
+
+// RowSort adalah satu kriteria pengurutan baris.
+type RowSort struct {
+	ColumnID   string
+	Descending bool
+}
+
+// RowQuery adalah parameter query baris database: filter, multi-sort dan pagination.
+// Pagination memakai Page (offset) atau Cursor (keyset), tidak keduanya.
+type RowQuery struct {
+	Filter *RowFilter
+	Sort   []RowSort
+	Page   int
+	Limit  int
+	Cursor string
+}
+
+// RowQueryResult adalah hasil query baris beserta metadata pagination.
+type RowQueryResult struct {
+	Rows       []model.DatabaseRow
+	Total      int // Jumlah baris yang cocok dengan filter (sebelum pagination)
+	Page       int // 0 jika memakai cursor
+	Limit      int
+	HasMore    bool
+	NextCursor string
+}
+
+// rowCursor adalah isi cursor: nilai kolom sort dan ID dari baris terakhir pada halaman sebelumnya.
+type rowCursor struct {
+	Values []interface{} `json:"v"`
+	RowID  string        `json:"id"`
+}
+
+// ParseRowSort mengurai parameter sort berformat "columnId:asc,columnId2:desc".
+// Arah default adalah asc.
+func ParseRowSort(raw string, columns []model.DatabaseColumn) ([]RowSort, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	columnsByID := indexColumns(columns)
+	var sorts []RowSort
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		columnID, direction, _ := strings.Cut(part, ":")
+		if _, ok := columnsByID[columnID]; !ok {
+			return nil, fmt.Errorf("%w: kolom sort '%s' tidak ditemukan", ErrInvalidRowQuery, columnID)
+		}
+		switch strings.ToLower(direction) {
+		case "", "asc":
+			sorts = append(sorts, RowSort{ColumnID: columnID})
+		case "desc":
+			sorts = append(sorts, RowSort{ColumnID: columnID, Descending: true})
+		default:
+			return nil, fmt.Errorf("%w: arah sort '%s' tidak dikenal (gunakan asc atau desc)", ErrInvalidRowQuery, direction)
+		}
+	}
+	return sorts, nil
+}
+
+// ParseRowFilterParam mengurai parameter filter berformat JSON. Menerima objek RowFilter
+// ({"match": "all", "conditions": [...]}) atau langsung array kondisi.
+func ParseRowFilterParam(raw string, columns []model.DatabaseColumn) (*RowFilter, error) {
+	filter := &RowFilter{}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return filter, nil
+	}
+	var err error
+	if strings.HasPrefix(raw, "[") {
+		err = json.Unmarshal([]byte(raw), &filter.Conditions)
+	} else {
+		err = json.Unmarshal([]byte(raw), filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: format filter tidak valid: %v", ErrInvalidRowQuery, err)
+	}
+	if err := filter.Validate(columns); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRowQuery, err)
+	}
+	return filter, nil
+}
+
+// SortRows mengurutkan baris (in-place) sesuai kriteria sort dengan perbandingan bertipe.
+// Nilai kosong selalu ditempatkan di akhir, dan ID baris dipakai sebagai pemutus seri agar urutan stabil.
+func SortRows(columns []model.DatabaseColumn, rows []model.DatabaseRow, sorts []RowSort) {
+	columnsByID := indexColumns(columns)
+	sort.SliceStable(rows, func(i, j int) bool {
+		return compareRows(columnsByID, sorts, rows[i].Values, rows[i].ID.Hex(), rows[j].Values, rows[j].ID.Hex()) < 0
+	})
+}
+
+// QueryRows menerapkan filter, sort dan pagination terhadap baris database.
+func QueryRows(columns []model.DatabaseColumn, rows []model.DatabaseRow, query RowQuery) (*RowQueryResult, error) {
+	limit, err := rowQueryLimit(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Salin slice agar urutan baris pada model asli tidak berubah
+	matched := append([]model.DatabaseRow(nil), FilterRows(columns, rows, query.Filter)...)
+	SortRows(columns, matched, query.Sort)
+
+	result := &RowQueryResult{Total: len(matched), Limit: limit}
+	start := 0
+	if query.Cursor != "" {
+		cursor, err := decodeRowCursor(query.Cursor, len(query.Sort))
+		if err != nil {
+			return nil, err
+		}
+		columnsByID := indexColumns(columns)
+		cursorValues := make(model.DatabaseRowValue, len(query.Sort))
+		for i, s := range query.Sort {
+			cursorValues[s.ColumnID] = cursor.Values[i]
+		}
+		// Keyset pagination: lanjutkan dari baris pertama yang berada setelah posisi cursor
+		start = sort.Search(len(matched), func(i int) bool {
+			return compareRows(columnsByID, query.Sort, matched[i].Values, matched[i].ID.Hex(), cursorValues, cursor.RowID) > 0
+		})
+	} else {
+		page := query.Page
+		if page <= 0 {
+			page = 1
+		}
+		result.Page = page
+		start = (page - 1) * limit
+	}
+
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	result.Rows = matched[start:end]
+	result.HasMore = end < len(matched)
+	if result.HasMore && len(result.Rows) > 0 {
+		result.NextCursor = encodeRowCursor(result.Rows[len(result.Rows)-1], query.Sort)
+	}
+	return result, nil
+}
+
+// rowQueryLimit mengembalikan limit halaman (default DefaultRowQueryLimit) dan menolak kombinasi
+// parameter pagination yang tidak valid.
+func rowQueryLimit(query RowQuery) (int, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultRowQueryLimit
+	}
+	if limit > MaxRowQueryLimit {
+		return 0, fmt.Errorf("%w: limit maksimum adalah %d", ErrInvalidRowQuery, MaxRowQueryLimit)
+	}
+	if query.Cursor != "" && query.Page > 0 {
+		return 0, fmt.Errorf("%w: gunakan page atau cursor, tidak keduanya", ErrInvalidRowQuery)
+	}
+	return limit, nil
+}
+
+// compareRows membandingkan dua baris berdasarkan kriteria sort, lalu ID baris sebagai pemutus seri.
+func compareRows(columnsByID map[string]*model.DatabaseColumn, sorts []RowSort, a model.DatabaseRowValue, aID string, b model.DatabaseRowValue, bID string) int {
+	for _, s := range sorts {
+		col := columnsByID[s.ColumnID]
+		if col == nil {
+			continue
+		}
+		x, y := a[s.ColumnID], b[s.ColumnID]
+		emptyX, emptyY := isEmptyValue(x), isEmptyValue(y)
+		switch {
+		case emptyX && emptyY:
+			continue
+		case emptyX:
+			return 1
+		case emptyY:
+			return -1
+		}
+		cmp, ok := CompareCellValues(col, x, y)
+		if !ok {
+			// Nilai yang tidak sesuai tipe kolom dibandingkan sebagai teks
+			cmp = strings.Compare(CellDisplayValue(col, x), CellDisplayValue(col, y))
+		}
+		if cmp != 0 {
+			if s.Descending {
+				return -cmp
+			}
+			return cmp
+		}
+	}
+	return strings.Compare(aID, bID)
+}
+
+// encodeRowCursor membuat cursor opaque dari baris terakhir sebuah halaman.
+func encodeRowCursor(row model.DatabaseRow, sorts []RowSort) string {
+	cursor := rowCursor{RowID: row.ID.Hex(), Values: make([]interface{}, len(sorts))}
+	for i, s := range sorts {
+		cursor.Values[i] = row.Values[s.ColumnID]
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeRowCursor membaca cursor opaque dan memastikan cursor dibuat dengan jumlah kolom sort yang sama.
+func decodeRowCursor(raw string, sortCount int) (*rowCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor tidak valid", ErrInvalidRowQuery)
+	}
+	var cursor rowCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.RowID == "" {
+		return nil, fmt.Errorf("%w: cursor tidak valid", ErrInvalidRowQuery)
+	}
+	if len(cursor.Values) != sortCount {
+		return nil, fmt.Errorf("%w: cursor tidak cocok dengan parameter sort", ErrInvalidRowQuery)
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Query baris diterjemahkan ke MongoDB jika semua kolom yang difilter dan diurutkan menyimpan nilainya
+// langsung di dokumen baris (text, number, date, boolean, dan select untuk filter). Kolom turunan
+// (formula, rollup) baru ada setelah dihitung, serta relation dan file yang dibandingkan lewat nilai
+// tampilannya, tetap diproses di memori oleh QueryRows.
+//
+// Terjemahan mengandalkan nilai yang sudah dikonversi oleh ValidateRowValues: angka tersimpan sebagai
+// number, tanggal sebagai date, dan select sebagai ID opsi.
+
+// ExecuteRowQuery menjalankan query baris database. database cukup berisi skema (lihat
+// DatabaseRepository.GetDatabaseSchemaByID); DatabaseData.Rows diisi dengan baris halaman hasil query
+// beserta nilai kolom turunannya.
+func ExecuteRowQuery(ctx context.Context, dbRepo repository.DatabaseRepository, database *model.Database, query RowQuery) (*RowQueryResult, error) {
+	limit, err := rowQueryLimit(query)
+	if err != nil {
+		return nil, err
+	}
+	columns := database.DatabaseData.Columns
+
+	pageQuery, ok, err := BuildRowPageQuery(columns, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	// Rollup ke database yang sama membutuhkan semua baris, bukan hanya baris halaman ini
+	if !ok || hasSelfRollup(database) {
+		rows, err := dbRepo.GetRowsByDatabaseID(ctx, database.ID)
+		if err != nil {
+			return nil, err
+		}
+		database.DatabaseData.Rows = rows
+		// Kolom rollup dan formula dihitung lebih dulu agar dapat difilter dan diurutkan
+		ComputeDerivedColumns(ctx, dbRepo, database)
+		result, err := QueryRows(columns, database.DatabaseData.Rows, query)
+		if err != nil {
+			return nil, err
+		}
+		database.DatabaseData.Rows = result.Rows
+		return result, nil
+	}
+
+	rows, total, err := dbRepo.QueryRowPage(ctx, database.ID, *pageQuery)
+	if err != nil {
+		return nil, err
+	}
+	result := &RowQueryResult{Total: int(total), Limit: limit}
+	if query.Cursor == "" {
+		result.Page = query.Page
+		if result.Page <= 0 {
+			result.Page = 1
+		}
+	}
+	// Satu baris tambahan diambil hanya untuk mengetahui apakah masih ada halaman berikutnya
+	if len(rows) > limit {
+		rows = rows[:limit]
+		result.HasMore = true
+		result.NextCursor = encodeRowCursor(rows[len(rows)-1], query.Sort)
+	}
+
+	database.DatabaseData.Rows = rows
+	ComputeDerivedColumns(ctx, dbRepo, database)
+	result.Rows = database.DatabaseData.Rows
+	return result, nil
+}
+
+// BuildRowPageQuery menerjemahkan filter, sort dan pagination ke RowPageQuery. Mengembalikan false
+// jika query memakai kolom atau operator yang harus diproses di memori.
+func BuildRowPageQuery(columns []model.DatabaseColumn, query RowQuery, limit int) (*repository.RowPageQuery, bool, error) {
+	columnsByID := indexColumns(columns)
+	pageQuery := &repository.RowPageQuery{Limit: int64(limit) + 1}
+
+	if query.Filter != nil && len(query.Filter.Conditions) > 0 {
+		conditions := make(bson.A, 0, len(query.Filter.Conditions))
+		for _, cond := range query.Filter.Conditions {
+			col := columnsByID[cond.ColumnID]
+			if col == nil {
+				return nil, false, nil
+			}
+			condition, ok := mongoRowCondition(col, cond)
+			if !ok {
+				return nil, false, nil
+			}
+			conditions = append(conditions, condition)
+		}
+		if query.Filter.Match == "any" {
+			pageQuery.Match = bson.M{"$or": conditions}
+		} else {
+			pageQuery.Match = bson.M{"$and": conditions}
+		}
+	}
+
+	// Nilai kosong selalu di akhir: setiap kolom sort mendapat flag kosong (0/1) yang diurutkan lebih dulu
+	pageQuery.AddFields = bson.M{}
+	pageQuery.Sort = bson.D{}
+	for i, s := range query.Sort {
+		col := columnsByID[s.ColumnID]
+		if col == nil || !mongoSortableColumn(col) {
+			return nil, false, nil
+		}
+		field := "values." + s.ColumnID
+		pageQuery.AddFields[sortEmptyField(i)] = emptyValueExpr(field)
+		direction := 1
+		if s.Descending {
+			direction = -1
+		}
+		pageQuery.Sort = append(pageQuery.Sort, bson.E{Key: sortEmptyField(i), Value: 1}, bson.E{Key: field, Value: direction})
+	}
+	// ID baris sebagai pemutus seri, sama seperti compareRows
+	pageQuery.Sort = append(pageQuery.Sort, bson.E{Key: "_id", Value: 1})
+
+	if query.Cursor != "" {
+		after, err := mongoRowCursor(columnsByID, query.Sort, query.Cursor)
+		if err != nil {
+			return nil, false, err
+		}
+		pageQuery.After = after
+	} else if query.Page > 1 {
+		pageQuery.Skip = int64(query.Page-1) * int64(limit)
+	}
+	return pageQuery, true, nil
+}
+
+// mongoRowCondition menerjemahkan satu kondisi filter dengan semantik yang sama seperti matchCondition.
+func mongoRowCondition(col *model.DatabaseColumn, cond RowFilterCondition) (bson.M, bool) {
+	field := "values." + cond.ColumnID
+	switch col.Type {
+	case "text", "number", "date", "boolean", "select":
+	default:
+		return nil, false
+	}
+
+	switch cond.Operator {
+	case FilterOpIsEmpty:
+		return emptyValueFilter(field), true
+	case FilterOpIsNotEmpty:
+		return bson.M{"$nor": bson.A{emptyValueFilter(field)}}, true
+	case FilterOpEquals, FilterOpNotEquals:
+		value, ok := mongoCellValue(col, cond.Value)
+		if !ok {
+			return nil, false
+		}
+		if cond.Operator == FilterOpNotEquals {
+			return bson.M{field: bson.M{"$ne": value}}, true
+		}
+		return bson.M{field: value}, true
+	case FilterOpContains:
+		if col.Type == "select" {
+			// Select dicari lewat value opsi, sedangkan yang tersimpan adalah ID opsi
+			optionIDs := bson.A{}
+			for _, opt := range col.Options {
+				if strings.Contains(strings.ToLower(opt.Value), strings.ToLower(fmt.Sprint(cond.Value))) {
+					optionIDs = append(optionIDs, opt.ID.Hex())
+				}
+			}
+			return bson.M{field: bson.M{"$in": optionIDs}}, true
+		}
+		return bson.M{field: bson.M{"$regex": regexp.QuoteMeta(fmt.Sprint(cond.Value)), "$options": "i"}}, true
+	case FilterOpGreater, FilterOpGreaterEq, FilterOpLess, FilterOpLessEq:
+		value, ok := mongoCellValue(col, cond.Value)
+		if !ok {
+			return nil, false
+		}
+		return bson.M{field: bson.M{"$" + cond.Operator: value}}, true
+	case FilterOpBetween:
+		bounds, ok := cond.Value.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return nil, false
+		}
+		// Batas bernilai null dianggap terbuka; tanpa batas sama sekali berarti cukup tidak kosong
+		rangeFilter := bson.M{}
+		for i, op := range []string{"$gte", "$lte"} {
+			if bounds[i] == nil {
+				continue
+			}
+			value, ok := mongoCellValue(col, bounds[i])
+			if !ok {
+				return nil, false
+			}
+			rangeFilter[op] = value
+		}
+		if len(rangeFilter) == 0 {
+			return bson.M{"$nor": bson.A{emptyValueFilter(field)}}, true
+		}
+		return bson.M{field: rangeFilter}, true
+	case FilterOpIn:
+		candidates, ok := cond.Value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		optionIDs := bson.A{}
+		for _, candidate := range candidates {
+			if value, ok := mongoCellValue(col, candidate); ok {
+				optionIDs = append(optionIDs, value)
+			}
+		}
+		return bson.M{field: bson.M{"$in": optionIDs}}, true
+	}
+	return nil, false
+}
+
+// mongoCellValue mengonversi nilai filter atau cursor ke bentuk yang tersimpan untuk tipe kolom.
+// Mengembalikan false jika nilai tidak dapat dikonversi; kondisi tersebut diproses di memori.
+func mongoCellValue(col *model.DatabaseColumn, value interface{}) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	switch col.Type {
+	case "number":
+		return toFloat(value)
+	case "date":
+		t, ok := toTime(value)
+		return t.UTC(), ok
+	case "boolean":
+		return toBool(value)
+	case "select":
+		raw := fmt.Sprint(value)
+		if oid, ok := value.(primitive.ObjectID); ok {
+			raw = oid.Hex()
+		}
+		for _, opt := range col.Options {
+			if opt.ID.Hex() == raw || strings.EqualFold(opt.Value, raw) {
+				return opt.ID.Hex(), true
+			}
+		}
+		return nil, false
+	case "text":
+		return fmt.Sprint(value), true
+	}
+	return nil, false
+}
+
+// mongoSortableColumn memeriksa apakah urutan nilai tersimpan kolom sama dengan urutan compareRows.
+// Select diurutkan berdasarkan value opsi, bukan ID yang tersimpan, sehingga tetap diproses di memori.
+func mongoSortableColumn(col *model.DatabaseColumn) bool {
+	switch col.Type {
+	case "text", "number", "date", "boolean":
+		return true
+	}
+	return false
+}
+
+// mongoRowCursor menerjemahkan cursor menjadi kondisi "setelah baris cursor" menurut urutan
+// (flag kosong, nilai) per kolom sort lalu ID baris.
+func mongoRowCursor(columnsByID map[string]*model.DatabaseColumn, sorts []RowSort, raw string) (bson.M, error) {
+	cursor, err := decodeRowCursor(raw, len(sorts))
+	if err != nil {
+		return nil, err
+	}
+	rowID, err := primitive.ObjectIDFromHex(cursor.RowID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor tidak valid", ErrInvalidRowQuery)
+	}
+
+	var branches bson.A
+	var equalPrefix bson.A // Kondisi "sama dengan cursor" untuk kolom sort sebelumnya
+	withPrefix := func(condition bson.M) bson.M {
+		return bson.M{"$and": append(append(bson.A{}, equalPrefix...), condition)}
+	}
+	for i, s := range sorts {
+		emptyField, valueField := sortEmptyField(i), "values."+s.ColumnID
+		if isEmptyValue(cursor.Values[i]) {
+			// Nilai kosong berada di akhir, jadi setelah cursor kosong hanya ada baris kosong lain pada kolom ini
+			equalPrefix = append(equalPrefix, bson.M{emptyField: 1})
+			continue
+		}
+		value, ok := mongoCellValue(columnsByID[s.ColumnID], cursor.Values[i])
+		if !ok {
+			return nil, fmt.Errorf("%w: cursor tidak cocok dengan parameter sort", ErrInvalidRowQuery)
+		}
+		op := "$gt"
+		if s.Descending {
+			op = "$lt"
+		}
+		branches = append(branches, withPrefix(bson.M{"$or": bson.A{
+			bson.M{emptyField: 1},
+			bson.M{emptyField: 0, valueField: bson.M{op: value}},
+		}}))
+		equalPrefix = append(equalPrefix, bson.M{emptyField: 0, valueField: value})
+	}
+	branches = append(branches, withPrefix(bson.M{"_id": bson.M{"$gt": rowID}}))
+	return bson.M{"$or": branches}, nil
+}
+
+// emptyValueFilter cocok dengan nilai yang dianggap kosong oleh isEmptyValue: tidak ada, null,
+// string kosong atau hanya spasi, dan array kosong.
+func emptyValueFilter(field string) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{field: nil},
+		bson.M{field: bson.M{"$regex": `^\s*$`}},
+		bson.M{field: bson.A{}},
+	}}
+}
+
+// emptyValueExpr adalah versi ekspresi aggregation dari emptyValueFilter yang menghasilkan 1 atau 0.
+func emptyValueExpr(field string) bson.M {
+	path := "$" + field
+	return bson.M{"$cond": bson.A{
+		bson.M{"$or": bson.A{
+			bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{path, nil}}, nil}},
+			bson.M{"$eq": bson.A{path, bson.A{}}},
+			// $and berhenti pada kondisi false pertama, sehingga $regexMatch hanya menerima string
+			bson.M{"$and": bson.A{
+				bson.M{"$eq": bson.A{bson.M{"$type": path}, "string"}},
+				bson.M{"$regexMatch": bson.M{"input": path, "regex": `^\s*$`}},
+			}},
+		}},
+		1,
+		0,
+	}}
+}
+
+// hasSelfRollup memeriksa apakah ada kolom rollup yang relasinya menunjuk ke database itu sendiri.
+func hasSelfRollup(database *model.Database) bool {
+	columnsByID := indexColumns(database.DatabaseData.Columns)
+	for _, col := range database.DatabaseData.Columns {
+		if col.Type != "rollup" || col.Rollup == nil {
+			continue
+		}
+		relationCol := columnsByID[col.Rollup.RelationColumnID]
+		if relationCol != nil && relationCol.Relation != nil && relationCol.Relation.DatabaseID == database.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func sortEmptyField(i int) string {
+	return fmt.Sprintf("_sortEmpty%d", i)
+}
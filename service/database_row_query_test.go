@@ -0,0 +1,274 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend_my_manajer/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rowQueryFixture berisi kolom dan baris contoh untuk test filter dan sort. Baris diberi nama
+// lewat kolom "Nama" agar hasil dapat dibandingkan tanpa bergantung pada ID.
+type rowQueryFixture struct {
+	columns                                  []model.DatabaseColumn
+	name, amount, due, done, status, formula string
+	optTodo, optDone                         model.SelectOption
+	rows                                     []model.DatabaseRow
+}
+
+func newRowQueryFixture() *rowQueryFixture {
+	f := &rowQueryFixture{
+		optTodo: model.SelectOption{ID: primitive.NewObjectID(), Value: "Todo"},
+		optDone: model.SelectOption{ID: primitive.NewObjectID(), Value: "Done"},
+	}
+	f.columns = []model.DatabaseColumn{
+		{ID: primitive.NewObjectID(), Name: "Nama", Type: "text"},
+		{ID: primitive.NewObjectID(), Name: "Jumlah", Type: "number"},
+		{ID: primitive.NewObjectID(), Name: "Tenggat", Type: "date"},
+		{ID: primitive.NewObjectID(), Name: "Selesai", Type: "boolean"},
+		{ID: primitive.NewObjectID(), Name: "Status", Type: "select", Options: []model.SelectOption{f.optTodo, f.optDone}},
+		{ID: primitive.NewObjectID(), Name: "Label", Type: "formula", Formula: `{Nama} & "!"`},
+	}
+	f.name, f.amount, f.due = f.columns[0].ID.Hex(), f.columns[1].ID.Hex(), f.columns[2].ID.Hex()
+	f.done, f.status, f.formula = f.columns[3].ID.Hex(), f.columns[4].ID.Hex(), f.columns[5].ID.Hex()
+
+	add := func(values model.DatabaseRowValue) {
+		f.rows = append(f.rows, model.DatabaseRow{ID: primitive.NewObjectID(), Values: values})
+	}
+	add(model.DatabaseRowValue{f.name: "Apel", f.amount: float64(10), f.due: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), f.done: true, f.status: f.optDone.ID.Hex()})
+	add(model.DatabaseRowValue{f.name: "belimbing", f.amount: float64(2), f.due: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), f.done: false, f.status: f.optTodo.ID.Hex()})
+	add(model.DatabaseRowValue{f.name: "Ceri", f.amount: float64(100), f.done: false, f.status: f.optTodo.ID.Hex()})
+	add(model.DatabaseRowValue{f.name: "  ", f.amount: nil, f.due: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)})
+	add(model.DatabaseRowValue{f.name: "Durian", f.amount: float64(10)})
+	return f
+}
+
+// rowNames mengembalikan nilai kolom Nama dari setiap baris sesuai urutan.
+func (f *rowQueryFixture) rowNames(rows []model.DatabaseRow) []string {
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Values[f.name].(string))
+	}
+	return names
+}
+
+func TestFilterRows(t *testing.T) {
+	f := newRowQueryFixture()
+
+	tests := []struct {
+		name   string
+		filter *RowFilter
+		want   []string
+	}{
+		{name: "tanpa filter", filter: nil, want: []string{"Apel", "belimbing", "Ceri", "  ", "Durian"}},
+		{name: "equals text tidak peka huruf besar", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.name, Operator: FilterOpEquals, Value: "APEL"}}}, want: []string{"Apel"}},
+		{name: "contains text", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.name, Operator: FilterOpContains, Value: "E"}}}, want: []string{"Apel", "belimbing", "Ceri"}},
+		{name: "gt number numerik bukan leksikal", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.amount, Operator: FilterOpGreater, Value: "9"}}}, want: []string{"Apel", "Ceri", "Durian"}},
+		{name: "lte number mengabaikan nilai kosong", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.amount, Operator: FilterOpLessEq, Value: 10}}}, want: []string{"Apel", "belimbing", "Durian"}},
+		{name: "between date", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.due, Operator: FilterOpBetween, Value: []interface{}{"2024-01-01", "2024-02-01"}}}}, want: []string{"belimbing", "  "}},
+		{name: "between dengan batas terbuka", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.amount, Operator: FilterOpBetween, Value: []interface{}{float64(10), nil}}}}, want: []string{"Apel", "Ceri", "Durian"}},
+		{name: "is_empty menganggap spasi kosong", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.name, Operator: FilterOpIsEmpty}}}, want: []string{"  "}},
+		{name: "is_not_empty", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.due, Operator: FilterOpIsNotEmpty}}}, want: []string{"Apel", "belimbing", "  "}},
+		{name: "equals select lewat value opsi", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.status, Operator: FilterOpEquals, Value: "todo"}}}, want: []string{"belimbing", "Ceri"}},
+		{name: "in select lewat ID opsi", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.status, Operator: FilterOpIn, Value: []interface{}{f.optDone.ID.Hex()}}}}, want: []string{"Apel"}},
+		{name: "not_equals boolean", filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.done, Operator: FilterOpNotEquals, Value: false}}}, want: []string{"Apel", "  ", "Durian"}},
+		{
+			name: "match all",
+			filter: &RowFilter{Conditions: []RowFilterCondition{
+				{ColumnID: f.amount, Operator: FilterOpGreaterEq, Value: 10},
+				{ColumnID: f.done, Operator: FilterOpEquals, Value: false},
+			}},
+			want: []string{"Ceri"},
+		},
+		{
+			name: "match any",
+			filter: &RowFilter{Match: "any", Conditions: []RowFilterCondition{
+				{ColumnID: f.amount, Operator: FilterOpEquals, Value: 2},
+				{ColumnID: f.name, Operator: FilterOpEquals, Value: "durian"},
+			}},
+			want: []string{"belimbing", "Durian"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := f.rowNames(FilterRows(f.columns, f.rows, tt.filter))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterRows() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRowFilterParamErrors(t *testing.T) {
+	f := newRowQueryFixture()
+
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "JSON tidak valid", raw: `{"conditions": [`},
+		{name: "kolom tidak ada", raw: `[{"columnId": "000000000000000000000000", "operator": "equals", "value": 1}]`},
+		{name: "operator tidak dikenal", raw: `[{"columnId": "` + f.amount + `", "operator": "like", "value": 1}]`},
+		{name: "contains pada number", raw: `[{"columnId": "` + f.amount + `", "operator": "contains", "value": "1"}]`},
+		{name: "gt pada text", raw: `[{"columnId": "` + f.name + `", "operator": "gt", "value": "a"}]`},
+		{name: "between tanpa array", raw: `[{"columnId": "` + f.amount + `", "operator": "between", "value": 1}]`},
+		{name: "in pada number", raw: `[{"columnId": "` + f.amount + `", "operator": "in", "value": [1]}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRowFilterParam(tt.raw, f.columns); !errors.Is(err, ErrInvalidRowQuery) {
+				t.Errorf("ParseRowFilterParam(%s) error = %v, want ErrInvalidRowQuery", tt.raw, err)
+			}
+		})
+	}
+}
+
+func TestQueryRowsSort(t *testing.T) {
+	f := newRowQueryFixture()
+
+	tests := []struct {
+		name string
+		sort string
+		want []string
+	}{
+		{name: "text asc tidak peka huruf besar, kosong di akhir", sort: f.name, want: []string{"Apel", "belimbing", "Ceri", "Durian", "  "}},
+		{name: "number desc, kosong tetap di akhir", sort: f.amount + ":desc", want: []string{"Ceri", "Apel", "Durian", "belimbing", "  "}},
+		{name: "number asc dengan seri diputus kolom berikutnya", sort: f.amount + ":asc," + f.name + ":desc", want: []string{"belimbing", "Durian", "Apel", "Ceri", "  "}},
+		{name: "date asc", sort: f.due, want: []string{"belimbing", "  ", "Apel", "Ceri", "Durian"}},
+		{name: "select diurutkan lewat value opsi", sort: f.status, want: []string{"Apel", "belimbing", "Ceri", "  ", "Durian"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorts, err := ParseRowSort(tt.sort, f.columns)
+			if err != nil {
+				t.Fatalf("ParseRowSort(%q) error = %v", tt.sort, err)
+			}
+			result, err := QueryRows(f.columns, f.rows, RowQuery{Sort: sorts})
+			if err != nil {
+				t.Fatalf("QueryRows() error = %v", err)
+			}
+			if got := f.rowNames(result.Rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryRows() sort %q = %q, want %q", tt.sort, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryRowsPagination(t *testing.T) {
+	f := newRowQueryFixture()
+	sorts := []RowSort{{ColumnID: f.amount, Descending: true}}
+	want := []string{"Ceri", "Apel", "Durian", "belimbing", "  "}
+
+	// Cursor dari setiap halaman harus melanjutkan tepat setelah baris terakhir halaman sebelumnya
+	var got []string
+	cursor := ""
+	for page := 0; page < len(f.rows); page++ {
+		result, err := QueryRows(f.columns, f.rows, RowQuery{Sort: sorts, Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("QueryRows() halaman %d error = %v", page+1, err)
+		}
+		if result.Total != len(f.rows) {
+			t.Errorf("Total = %d, want %d", result.Total, len(f.rows))
+		}
+		got = append(got, f.rowNames(result.Rows)...)
+		if !result.HasMore {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cursor pagination = %q, want %q", got, want)
+	}
+
+	result, err := QueryRows(f.columns, f.rows, RowQuery{Sort: sorts, Page: 2, Limit: 2})
+	if err != nil {
+		t.Fatalf("QueryRows() page 2 error = %v", err)
+	}
+	if names := f.rowNames(result.Rows); !reflect.DeepEqual(names, want[2:4]) || !result.HasMore {
+		t.Errorf("page 2 = %q (hasMore %v), want %q (hasMore true)", names, result.HasMore, want[2:4])
+	}
+
+	invalid := []struct {
+		name  string
+		query RowQuery
+	}{
+		{name: "limit melebihi maksimum", query: RowQuery{Limit: MaxRowQueryLimit + 1}},
+		{name: "page dan cursor bersamaan", query: RowQuery{Page: 2, Cursor: result.NextCursor}},
+		{name: "cursor tidak valid", query: RowQuery{Cursor: "bukan-cursor"}},
+		{name: "cursor dari sort lain", query: RowQuery{Sort: []RowSort{{ColumnID: f.amount}, {ColumnID: f.name}}, Cursor: result.NextCursor}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := QueryRows(f.columns, f.rows, tt.query); !errors.Is(err, ErrInvalidRowQuery) {
+				t.Errorf("QueryRows() error = %v, want ErrInvalidRowQuery", err)
+			}
+		})
+	}
+}
+
+func TestBuildRowPageQuery(t *testing.T) {
+	f := newRowQueryFixture()
+
+	tests := []struct {
+		name      string
+		query     RowQuery
+		wantOK    bool
+		wantMatch bson.M
+	}{
+		{name: "tanpa filter", query: RowQuery{}, wantOK: true},
+		{
+			name:      "gt number dikonversi ke angka",
+			query:     RowQuery{Filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.amount, Operator: FilterOpGreater, Value: "9"}}}},
+			wantOK:    true,
+			wantMatch: bson.M{"$and": bson.A{bson.M{"values." + f.amount: bson.M{"$gt": float64(9)}}}},
+		},
+		{
+			name:      "equals select dikonversi ke ID opsi",
+			query:     RowQuery{Filter: &RowFilter{Match: "any", Conditions: []RowFilterCondition{{ColumnID: f.status, Operator: FilterOpEquals, Value: "done"}}}},
+			wantOK:    true,
+			wantMatch: bson.M{"$or": bson.A{bson.M{"values." + f.status: f.optDone.ID.Hex()}}},
+		},
+		{
+			name:      "contains text di-escape",
+			query:     RowQuery{Filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.name, Operator: FilterOpContains, Value: "a.b"}}}},
+			wantOK:    true,
+			wantMatch: bson.M{"$and": bson.A{bson.M{"values." + f.name: bson.M{"$regex": `a\.b`, "$options": "i"}}}},
+		},
+		{name: "sort number", query: RowQuery{Sort: []RowSort{{ColumnID: f.amount}}}, wantOK: true},
+		{name: "filter formula diproses di memori", query: RowQuery{Filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.formula, Operator: FilterOpEquals, Value: "x"}}}}},
+		{name: "sort select diproses di memori", query: RowQuery{Sort: []RowSort{{ColumnID: f.status}}}},
+		{name: "nilai select tidak dikenal diproses di memori", query: RowQuery{Filter: &RowFilter{Conditions: []RowFilterCondition{{ColumnID: f.status, Operator: FilterOpEquals, Value: "Blocked"}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := BuildRowPageQuery(f.columns, tt.query, 10)
+			if err != nil {
+				t.Fatalf("BuildRowPageQuery() error = %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("BuildRowPageQuery() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if !reflect.DeepEqual(got.Match, tt.wantMatch) {
+				t.Errorf("Match = %#v, want %#v", got.Match, tt.wantMatch)
+			}
+			if got.Limit != 11 {
+				t.Errorf("Limit = %d, want 11 (satu baris tambahan untuk hasMore)", got.Limit)
+			}
+			if last := got.Sort[len(got.Sort)-1]; last.Key != "_id" {
+				t.Errorf("kunci sort terakhir = %q, want _id sebagai pemutus seri", last.Key)
+			}
+		})
+	}
+}
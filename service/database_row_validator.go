@@ -0,0 +1,234 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend_my_manajer/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RowFieldError menjelaskan kesalahan nilai pada satu kolom.
+type RowFieldError struct {
+	ColumnID   string `json:"columnId"`
+	ColumnName string `json:"columnName,omitempty"`
+	Message    string `json:"message"`
+}
+
+// RowValidationError dikembalikan oleh ValidateRowValues dan berisi semua kolom yang bermasalah,
+// bukan hanya yang pertama ditemukan.
+type RowValidationError struct {
+	Errors []RowFieldError `json:"errors"`
+}
+
+// Error mengimplementasikan interface error.
+func (e *RowValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fmt.Sprintf("%s: %s", fieldErr.ColumnID, fieldErr.Message)
+	}
+	return "nilai baris tidak valid: " + strings.Join(messages, "; ")
+}
+
+// ValidateRowValues memeriksa setiap nilai terhadap tipe DatabaseColumn-nya dan mengembalikan
+// nilai yang sudah dikonversi ke tipe kanonik:
+//   - number  : float64 (string angka dikonversi)
+//   - date    : time.Time (RFC3339 atau YYYY-MM-DD)
+//   - boolean : bool (string "true"/"false" dikonversi)
+//   - select  : ID hex SelectOption (value opsi yang cocok dikonversi ke ID-nya)
+//   - text    : string (angka dan boolean dikonversi ke teks)
+//   - relation: array ID baris hex (tanpa duplikat)
+//   - file    : array ID DatabaseFile hex (tanpa duplikat)
+//
+// Nilai null diperbolehkan untuk mengosongkan sel. Key yang bukan ID kolom database ditolak.
+// Jika ada kesalahan, error bertipe *RowValidationError berisi semua kolom yang bermasalah.
+func ValidateRowValues(columns []model.DatabaseColumn, values map[string]interface{}) (model.DatabaseRowValue, error) {
+	columnsByID := indexColumns(columns)
+	coerced := make(model.DatabaseRowValue, len(values))
+	var fieldErrors []RowFieldError
+
+	// Urutkan key agar urutan error deterministik
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		col, ok := columnsByID[key]
+		if !ok {
+			fieldErrors = append(fieldErrors, RowFieldError{ColumnID: key, Message: "kolom tidak dikenal di database ini"})
+			continue
+		}
+		value, err := coerceCellValue(col, values[key])
+		if err != nil {
+			fieldErrors = append(fieldErrors, RowFieldError{ColumnID: key, ColumnName: col.Name, Message: err.Error()})
+			continue
+		}
+		coerced[key] = value
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &RowValidationError{Errors: fieldErrors}
+	}
+	return coerced, nil
+}
+
+// coerceCellValue mengonversi satu nilai ke tipe kanonik kolomnya.
+func coerceCellValue(col *model.DatabaseColumn, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch col.Type {
+	case "number":
+		f, ok := toFloat(value)
+		if !ok || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("nilai harus berupa angka, diterima %s", describeValue(value))
+		}
+		return f, nil
+	case "date":
+		t, ok := toTime(value)
+		if !ok {
+			return nil, fmt.Errorf("nilai harus berupa tanggal (RFC3339 atau YYYY-MM-DD), diterima %s", describeValue(value))
+		}
+		return t.UTC(), nil
+	case "boolean":
+		b, ok := toBool(value)
+		if !ok {
+			return nil, fmt.Errorf("nilai harus berupa boolean, diterima %s", describeValue(value))
+		}
+		return b, nil
+	case "select":
+		raw, ok := value.(string)
+		if oid, isOID := value.(primitive.ObjectID); isOID {
+			raw, ok = oid.Hex(), true
+		}
+		if !ok {
+			return nil, fmt.Errorf("nilai harus berupa ID opsi, diterima %s", describeValue(value))
+		}
+		if raw == "" {
+			return nil, nil
+		}
+		for _, opt := range col.Options {
+			if opt.ID.Hex() == raw {
+				return raw, nil
+			}
+		}
+		for _, opt := range col.Options {
+			if opt.Value == raw {
+				return opt.ID.Hex(), nil
+			}
+		}
+		return nil, fmt.Errorf("opsi '%s' tidak ada pada kolom ini", raw)
+	case "text":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+		if f, ok := toFloat(value); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64), nil
+		}
+		return nil, fmt.Errorf("nilai harus berupa teks, diterima %s", describeValue(value))
+	case "relation":
+		// Nilai relation adalah daftar ID baris database target; keberadaannya diperiksa oleh ValidateRelationValues
+		return coerceObjectIDList(value, "baris")
+	case "file":
+		// Nilai file adalah daftar ID file yang sudah diunggah; keberadaannya diperiksa oleh ValidateFileValues
+		return coerceObjectIDList(value, "file")
+	case "formula", "rollup":
+		return nil, fmt.Errorf("kolom %s dihitung oleh server dan tidak dapat diisi langsung", col.Type)
+	}
+	return nil, fmt.Errorf("tipe kolom '%s' tidak mendukung nilai yang ditulis langsung", col.Type)
+}
+
+// coerceObjectIDList mengonversi satu ID atau array ID hex menjadi []string tanpa duplikat.
+// label dipakai pada pesan error ("baris", "file").
+func coerceObjectIDList(value interface{}, label string) ([]string, error) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case string:
+		items = []interface{}{v}
+	default:
+		return nil, fmt.Errorf("nilai harus berupa array ID %s, diterima %s", label, describeValue(value))
+	}
+	ids := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		raw, ok := item.(string)
+		if !ok || !primitive.IsValidObjectID(raw) {
+			return nil, fmt.Errorf("ID %s %s tidak valid", label, describeValue(item))
+		}
+		if !seen[raw] {
+			seen[raw] = true
+			ids = append(ids, raw)
+		}
+	}
+	return ids, nil
+}
+
+// describeValue memberi deskripsi singkat tipe sebuah nilai untuk pesan error.
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("teks %q", v)
+	case bool:
+		return "boolean"
+	case float64, float32, int, int32, int64:
+		return "angka"
+	case map[string]interface{}:
+		return "objek"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// ResolveColumnDefaultValue menentukan nilai yang diisikan ke baris yang sudah ada saat kolom baru ditambahkan.
+// Jika value diberikan, nilai tersebut divalidasi dan dikonversi seperti nilai sel biasa. Jika tidak,
+// dipakai default sesuai tipe kolom: text "", number 0, boolean false, relation dan file [], sedangkan date dan select null.
+func ResolveColumnDefaultValue(col *model.DatabaseColumn, value interface{}) (interface{}, error) {
+	if value != nil {
+		return coerceCellValue(col, value)
+	}
+	switch col.Type {
+	case "text":
+		return "", nil
+	case "relation", "file":
+		return []string{}, nil
+	case "number":
+		return float64(0), nil
+	case "boolean":
+		return false, nil
+	}
+	return nil, nil
+}
+
+// RevisionRowValues menyiapkan nilai sebuah revisi baris untuk dipulihkan melalui ValidateRowValues.
+// Nilai kolom yang sudah dihapus dan kolom yang dihitung server (formula, rollup) dibuang, dan array hasil
+// decode BSON dinormalkan menjadi []interface{}. Mengembalikan nilai yang siap divalidasi dan ID kolom yang dibuang.
+func RevisionRowValues(columns []model.DatabaseColumn, values model.DatabaseRowValue) (map[string]interface{}, []string) {
+	columnsByID := indexColumns(columns)
+	prepared := make(map[string]interface{}, len(values))
+	var dropped []string
+	for columnID, value := range values {
+		col, ok := columnsByID[columnID]
+		if !ok || col.Type == "formula" || col.Type == "rollup" {
+			dropped = append(dropped, columnID)
+			continue
+		}
+		if arr, ok := value.(primitive.A); ok {
+			value = []interface{}(arr)
+		}
+		prepared[columnID] = value
+	}
+	sort.Strings(dropped)
+	return prepared, dropped
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"backend_my_manajer/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCoerceCellValue(t *testing.T) {
+	optTodo := model.SelectOption{ID: primitive.NewObjectID(), Value: "Todo"}
+	optDone := model.SelectOption{ID: primitive.NewObjectID(), Value: "Done"}
+	selectCol := &model.DatabaseColumn{Type: "select", Options: []model.SelectOption{optTodo, optDone}}
+	rowID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name    string
+		col     *model.DatabaseColumn
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "null mengosongkan sel", col: &model.DatabaseColumn{Type: "number"}, value: nil, want: nil},
+		{name: "number dari float", col: &model.DatabaseColumn{Type: "number"}, value: 12.5, want: 12.5},
+		{name: "number dari int", col: &model.DatabaseColumn{Type: "number"}, value: 7, want: float64(7)},
+		{name: "number dari string dengan spasi", col: &model.DatabaseColumn{Type: "number"}, value: " 3.25 ", want: 3.25},
+		{name: "number menolak teks", col: &model.DatabaseColumn{Type: "number"}, value: "dua", wantErr: true},
+		{name: "number menolak string kosong", col: &model.DatabaseColumn{Type: "number"}, value: "", wantErr: true},
+		{name: "number menolak Inf", col: &model.DatabaseColumn{Type: "number"}, value: math.Inf(1), wantErr: true},
+		{name: "number menolak NaN", col: &model.DatabaseColumn{Type: "number"}, value: "NaN", wantErr: true},
+		{name: "number menolak boolean", col: &model.DatabaseColumn{Type: "number"}, value: true, wantErr: true},
+		{name: "date dari YYYY-MM-DD", col: &model.DatabaseColumn{Type: "date"}, value: "2024-02-29", want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "date RFC3339 dinormalkan ke UTC", col: &model.DatabaseColumn{Type: "date"}, value: "2024-03-01T07:00:00+07:00", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "date menolak tanggal tidak ada", col: &model.DatabaseColumn{Type: "date"}, value: "2023-02-29", wantErr: true},
+		{name: "date menolak format lain", col: &model.DatabaseColumn{Type: "date"}, value: "01/03/2024", wantErr: true},
+		{name: "boolean dari string", col: &model.DatabaseColumn{Type: "boolean"}, value: "true", want: true},
+		{name: "boolean menolak angka", col: &model.DatabaseColumn{Type: "boolean"}, value: 1, wantErr: true},
+		{name: "select dari ID opsi", col: selectCol, value: optDone.ID.Hex(), want: optDone.ID.Hex()},
+		{name: "select dari ObjectID", col: selectCol, value: optTodo.ID, want: optTodo.ID.Hex()},
+		{name: "select dari value opsi", col: selectCol, value: "Todo", want: optTodo.ID.Hex()},
+		{name: "select string kosong menjadi null", col: selectCol, value: "", want: nil},
+		{name: "select menolak opsi yang tidak ada", col: selectCol, value: "Blocked", wantErr: true},
+		{name: "select menolak angka", col: selectCol, value: 1.0, wantErr: true},
+		{name: "text dari angka", col: &model.DatabaseColumn{Type: "text"}, value: 1.50, want: "1.5"},
+		{name: "text dari boolean", col: &model.DatabaseColumn{Type: "text"}, value: false, want: "false"},
+		{name: "text menolak objek", col: &model.DatabaseColumn{Type: "text"}, value: map[string]interface{}{"a": 1}, wantErr: true},
+		{name: "relation dari satu ID", col: &model.DatabaseColumn{Type: "relation"}, value: rowID, want: []string{rowID}},
+		{name: "relation tanpa duplikat", col: &model.DatabaseColumn{Type: "relation"}, value: []interface{}{rowID, rowID}, want: []string{rowID}},
+		{name: "relation menolak ID tidak valid", col: &model.DatabaseColumn{Type: "relation"}, value: []interface{}{"abc"}, wantErr: true},
+		{name: "file menolak angka", col: &model.DatabaseColumn{Type: "file"}, value: 10, wantErr: true},
+		{name: "formula tidak dapat diisi", col: &model.DatabaseColumn{Type: "formula"}, value: 1, wantErr: true},
+		{name: "rollup tidak dapat diisi", col: &model.DatabaseColumn{Type: "rollup"}, value: 1, wantErr: true},
+		{name: "tipe tidak dikenal", col: &model.DatabaseColumn{Type: "unknown"}, value: "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerceCellValue(tt.col, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("coerceCellValue(%v) = %v, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("coerceCellValue(%v) error = %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("coerceCellValue(%v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRowValues(t *testing.T) {
+	numberCol := model.DatabaseColumn{ID: primitive.NewObjectID(), Name: "Jumlah", Type: "number"}
+	dateCol := model.DatabaseColumn{ID: primitive.NewObjectID(), Name: "Tanggal", Type: "date"}
+	columns := []model.DatabaseColumn{numberCol, dateCol}
+	unknownID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name       string
+		values     map[string]interface{}
+		want       model.DatabaseRowValue
+		wantErrIDs []string
+	}{
+		{
+			name:   "semua nilai valid dikonversi",
+			values: map[string]interface{}{numberCol.ID.Hex(): "42", dateCol.ID.Hex(): "2024-01-02"},
+			want: model.DatabaseRowValue{
+				numberCol.ID.Hex(): float64(42),
+				dateCol.ID.Hex():   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:   "tanpa nilai",
+			values: map[string]interface{}{},
+			want:   model.DatabaseRowValue{},
+		},
+		{
+			name:       "semua kolom yang salah dilaporkan",
+			values:     map[string]interface{}{numberCol.ID.Hex(): "x", dateCol.ID.Hex(): "bukan tanggal", unknownID: 1},
+			wantErrIDs: sortedStrings(numberCol.ID.Hex(), dateCol.ID.Hex(), unknownID),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateRowValues(columns, tt.values)
+			if tt.wantErrIDs != nil {
+				var validationErr *RowValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("ValidateRowValues() error = %v, want *RowValidationError", err)
+				}
+				var gotIDs []string
+				for _, fieldErr := range validationErr.Errors {
+					gotIDs = append(gotIDs, fieldErr.ColumnID)
+				}
+				if !reflect.DeepEqual(gotIDs, tt.wantErrIDs) {
+					t.Errorf("ValidateRowValues() error columns = %v, want %v", gotIDs, tt.wantErrIDs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateRowValues() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateRowValues() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// sortedStrings mengembalikan values yang sudah diurutkan, sesuai urutan error ValidateRowValues.
+func sortedStrings(values ...string) []string {
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format file spreadsheet yang didukung untuk import dan export database.
+const (
+	SpreadsheetFormatCSV  = "csv"
+	SpreadsheetFormatXLSX = "xlsx"
+)
+
+// ErrInvalidSpreadsheet dikembalikan jika file CSV/XLSX tidak dapat dibaca.
+var ErrInvalidSpreadsheet = errors.New("file spreadsheet tidak valid")
+
+// SpreadsheetCell adalah satu sel untuk ditulis ke file export. Sel dengan IsNumber ditulis sebagai
+// angka pada XLSX; pada CSV semua sel ditulis sebagai teks.
+type SpreadsheetCell struct {
+	Text     string
+	IsNumber bool
+}
+
+// ReadSpreadsheet membaca seluruh isi file CSV atau XLSX (sheet pertama) sebagai baris-baris teks.
+// Baris pertama adalah header.
+func ReadSpreadsheet(format string, data []byte) ([][]string, error) {
+	switch format {
+	case SpreadsheetFormatCSV:
+		return readCSV(data)
+	case SpreadsheetFormatXLSX:
+		return readXLSX(data)
+	}
+	return nil, fmt.Errorf("%w: format '%s' tidak didukung (gunakan csv atau xlsx)", ErrInvalidSpreadsheet, format)
+}
+
+// WriteSpreadsheet menulis header dan baris-baris sel ke w dalam format CSV atau XLSX.
+func WriteSpreadsheet(w io.Writer, format, sheetName string, header []string, rows [][]SpreadsheetCell) error {
+	switch format {
+	case SpreadsheetFormatCSV:
+		return writeCSV(w, header, rows)
+	case SpreadsheetFormatXLSX:
+		return writeXLSX(w, sheetName, header, rows)
+	}
+	return fmt.Errorf("%w: format '%s' tidak didukung (gunakan csv atau xlsx)", ErrInvalidSpreadsheet, format)
+}
+
+// readCSV membaca file CSV. BOM UTF-8 di awal file diabaikan dan jumlah kolom per baris boleh berbeda.
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("%w: file CSV harus berenkoding UTF-8", ErrInvalidSpreadsheet)
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpreadsheet, err)
+	}
+	return records, nil
+}
+
+// writeCSV menulis file CSV dengan BOM UTF-8 agar terbaca benar oleh Excel.
+func writeCSV(w io.Writer, header []string, rows [][]SpreadsheetCell) error {
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(header))
+	for _, row := range rows {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = row[i].Text
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ---------------------------------------------------------------------------
+// XLSX (Office Open XML) minimal: hanya sheet pertama, tanpa style
+// ---------------------------------------------------------------------------
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String menggabungkan teks biasa dan rich text.
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var sb strings.Builder
+	sb.WriteString(t.Text)
+	for _, run := range t.Runs {
+		sb.WriteString(run.Text)
+	}
+	return sb.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX membaca sheet pertama file XLSX. Sel angka dikembalikan apa adanya (tanggal Excel
+// tersimpan sebagai nomor seri karena style tidak dibaca).
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: file XLSX tidak dapat dibuka: %v", ErrInvalidSpreadsheet, err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath := "xl/worksheets/sheet1.xml"
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	if decodeXLSXPart(files, "xl/workbook.xml", &workbook) == nil && len(workbook.Sheets) > 0 &&
+		decodeXLSXPart(files, "xl/_rels/workbook.xml.rels", &rels) == nil {
+		for _, rel := range rels.Relationships {
+			if rel.ID == workbook.Sheets[0].RID {
+				target := strings.TrimPrefix(rel.Target, "/")
+				if !strings.HasPrefix(target, "xl/") {
+					target = path.Join("xl", target)
+				}
+				sheetPath = target
+			}
+		}
+	}
+
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXLSXPart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := decodeXLSXPart(files, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	for rowPos, row := range sheet.Rows {
+		rowIndex := row.Index
+		if rowIndex <= 0 {
+			rowIndex = rowPos + 1
+		}
+		// Baris kosong yang dilewati XLSX diisi agar nomor baris tetap sesuai dengan spreadsheet
+		for len(records) < rowIndex-1 {
+			records = append(records, []string{})
+		}
+		var record []string
+		for cellPos, cell := range row.Cells {
+			colIndex := cellPos
+			if cell.Ref != "" {
+				if idx, ok := xlsxColumnIndex(cell.Ref); ok {
+					colIndex = idx
+				}
+			}
+			var text string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("%w: shared string pada sel %s tidak ditemukan", ErrInvalidSpreadsheet, cell.Ref)
+				}
+				text = shared.Items[idx].String()
+			case "inlineStr":
+				text = cell.Inline.String()
+			case "b":
+				text = strconv.FormatBool(strings.TrimSpace(cell.Value) == "1")
+			default:
+				text = cell.Value
+			}
+			for len(record) <= colIndex {
+				record = append(record, "")
+			}
+			record[colIndex] = text
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decodeXLSXPart membaca dan men-decode satu bagian XML di dalam arsip XLSX.
+func decodeXLSXPart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: bagian %s tidak ditemukan", ErrInvalidSpreadsheet, name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSpreadsheet, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s tidak dapat dibaca: %v", ErrInvalidSpreadsheet, name, err)
+	}
+	return nil
+}
+
+// xlsxColumnIndex mengubah referensi sel seperti "C12" menjadi indeks kolom berbasis nol (2).
+func xlsxColumnIndex(ref string) (int, bool) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			index = index*26 + int(r-'A'+1)
+			letters++
+			continue
+		}
+		break
+	}
+	if letters == 0 {
+		return 0, false
+	}
+	return index - 1, true
+}
+
+// xlsxColumnName mengubah indeks kolom berbasis nol menjadi nama kolom Excel (0 -> "A", 27 -> "AB").
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// writeXLSX menulis workbook satu sheet. Teks ditulis sebagai inline string sehingga tidak perlu sharedStrings.
+func writeXLSX(w io.Writer, sheetName string, header []string, rows [][]SpreadsheetCell) error {
+	archive := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + xlsxEscape(xlsxSheetName(sheetName)) + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+	for _, part := range parts {
+		fw, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return err
+		}
+	}
+
+	fw, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	writeRow := func(rowNumber int, cells []SpreadsheetCell) {
+		fmt.Fprintf(&sb, `<row r="%d">`, rowNumber)
+		for i, cell := range cells {
+			ref := xlsxColumnName(i) + strconv.Itoa(rowNumber)
+			if cell.Text == "" {
+				continue
+			}
+			if cell.IsNumber {
+				fmt.Fprintf(&sb, `<c r="%s"><v>%s</v></c>`, ref, xlsxEscape(cell.Text))
+				continue
+			}
+			fmt.Fprintf(&sb, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xlsxEscape(cell.Text))
+		}
+		sb.WriteString(`</row>`)
+	}
+	headerCells := make([]SpreadsheetCell, len(header))
+	for i, name := range header {
+		headerCells[i] = SpreadsheetCell{Text: name}
+	}
+	writeRow(1, headerCells)
+	for i, row := range rows {
+		writeRow(i+2, row)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	if _, err := io.WriteString(fw, sb.String()); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// xlsxEscape meng-escape teks untuk XML dan membuang karakter kontrol yang tidak diizinkan XML 1.0.
+func xlsxEscape(text string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 {
+			return r
+		}
+		return -1
+	}, text)
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(cleaned))
+	return buf.String()
+}
+
+// xlsxSheetName menyesuaikan nama sheet dengan batasan Excel (maksimal 31 karakter, tanpa []:*?/\).
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
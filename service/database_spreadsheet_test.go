@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend_my_manajer/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSpreadsheetRoundTrip(t *testing.T) {
+	header := []string{"Nama", "Jumlah", "Catatan"}
+	rows := [][]SpreadsheetCell{
+		{{Text: "Apel, merah"}, {Text: "10", IsNumber: true}, {Text: `kata "kutip"`}},
+		{{Text: "Baris\nbaru"}, {Text: "-2.5", IsNumber: true}, {Text: "<xml> & 'amp'"}},
+		{{Text: "Ünïcödé 漢字"}, {}, {Text: "  spasi  "}},
+		{{}, {Text: "0", IsNumber: true}, {}},
+	}
+	want := [][]string{
+		{"Nama", "Jumlah", "Catatan"},
+		{"Apel, merah", "10", `kata "kutip"`},
+		{"Baris\nbaru", "-2.5", "<xml> & 'amp'"},
+		{"Ünïcödé 漢字", "", "  spasi  "},
+		{"", "0", ""},
+	}
+
+	for _, format := range []string{SpreadsheetFormatCSV, SpreadsheetFormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteSpreadsheet(&buf, format, "Data", header, rows); err != nil {
+				t.Fatalf("WriteSpreadsheet() error = %v", err)
+			}
+			got, err := ReadSpreadsheet(format, buf.Bytes())
+			if err != nil {
+				t.Fatalf("ReadSpreadsheet() error = %v", err)
+			}
+			// XLSX tidak menyimpan sel kosong, sehingga baris hasil baca bisa lebih pendek dari header
+			for i := range got {
+				for len(got[i]) < len(header) {
+					got[i] = append(got[i], "")
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round-trip %s = %q, want %q", format, got, want)
+			}
+		})
+	}
+}
+
+func TestReadSpreadsheetErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   []byte
+	}{
+		{name: "format tidak didukung", format: "ods", data: []byte("a,b")},
+		{name: "CSV bukan UTF-8", format: SpreadsheetFormatCSV, data: []byte{'a', ',', 0xff, 0xfe}},
+		{name: "XLSX bukan zip", format: SpreadsheetFormatXLSX, data: []byte("bukan zip")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadSpreadsheet(tt.format, tt.data); !errors.Is(err, ErrInvalidSpreadsheet) {
+				t.Errorf("ReadSpreadsheet() error = %v, want ErrInvalidSpreadsheet", err)
+			}
+		})
+	}
+}
+
+// TestDatabaseExportImportRoundTrip memastikan file hasil export dapat di-import kembali ke
+// kolom yang sama dengan nilai yang sama.
+func TestDatabaseExportImportRoundTrip(t *testing.T) {
+	optTodo := model.SelectOption{ID: primitive.NewObjectID(), Value: "Todo"}
+	optDone := model.SelectOption{ID: primitive.NewObjectID(), Value: "Done"}
+	columns := []model.DatabaseColumn{
+		{ID: primitive.NewObjectID(), Name: "Nama", Type: "text", Order: 1},
+		{ID: primitive.NewObjectID(), Name: "Jumlah", Type: "number", Order: 2},
+		{ID: primitive.NewObjectID(), Name: "Tenggat", Type: "date", Order: 3},
+		{ID: primitive.NewObjectID(), Name: "Selesai", Type: "boolean", Order: 4},
+		{ID: primitive.NewObjectID(), Name: "Status", Type: "select", Order: 5, Options: []model.SelectOption{optTodo, optDone}},
+	}
+	name, amount, due, done, status := columns[0].ID.Hex(), columns[1].ID.Hex(), columns[2].ID.Hex(), columns[3].ID.Hex(), columns[4].ID.Hex()
+	values := []model.DatabaseRowValue{
+		{name: "Apel, merah", amount: 1.25, due: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), done: true, status: optDone.ID.Hex()},
+		{name: "Rapat \"mingguan\"", amount: float64(-3), due: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC), done: false, status: optTodo.ID.Hex()},
+		{name: "Tanpa nilai lain"},
+	}
+	rows := make([]model.DatabaseRow, len(values))
+	for i := range values {
+		rows[i] = model.DatabaseRow{ID: primitive.NewObjectID(), Values: values[i]}
+	}
+
+	for _, format := range []string{SpreadsheetFormatCSV, SpreadsheetFormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			header, records := BuildExportTable(columns, rows)
+			var buf bytes.Buffer
+			if err := WriteSpreadsheet(&buf, format, "Database", header, records); err != nil {
+				t.Fatalf("WriteSpreadsheet() error = %v", err)
+			}
+			read, err := ReadSpreadsheet(format, buf.Bytes())
+			if err != nil {
+				t.Fatalf("ReadSpreadsheet() error = %v", err)
+			}
+
+			targets, ignored, err := ResolveImportMapping(columns, read[0], nil)
+			if err != nil || len(ignored) > 0 {
+				t.Fatalf("ResolveImportMapping() ignored = %v, error = %v", ignored, err)
+			}
+			imported, rowErrors, err := BuildImportRows(context.Background(), nil, columns, targets, primitive.NilObjectID, read[1:])
+			if err != nil || len(rowErrors) > 0 {
+				t.Fatalf("BuildImportRows() rowErrors = %+v, error = %v", rowErrors, err)
+			}
+			if len(imported) != len(values) {
+				t.Fatalf("BuildImportRows() = %d baris, want %d", len(imported), len(values))
+			}
+			for i := range values {
+				if !reflect.DeepEqual(imported[i].Values, values[i]) {
+					t.Errorf("baris %d = %#v, want %#v", i+1, imported[i].Values, values[i])
+				}
+			}
+		})
+	}
+}
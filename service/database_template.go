@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"backend_my_manajer/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Template dan duplikasi database menyalin kolom apa adanya, termasuk ID kolom dan ID select option.
+// ID kolom hanya berlaku di dalam satu database, sehingga referensi formula ({<ID kolom>}), rollup,
+// view dan nilai select pada baris tetap valid tanpa diterjemahkan. Yang dibuat ulang hanya:
+//   - ID dan timestamp view
+//   - Relation.DatabaseID yang merujuk database sumber (relation ke diri sendiri), diganti database baru
+//   - ID baris (jika baris ikut diduplikasi) beserta nilai relation ke diri sendiri yang merujuknya
+//
+// Nilai kolom file tidak ikut disalin karena file terikat pada database pemiliknya.
+//
+// Template tidak boleh berisi relation ke database lain karena database tersebut terikat pada satu
+// channel, sedangkan template dapat dipakai di channel mana pun milik bisnisnya.
+
+// ErrInvalidDatabaseTemplate dikembalikan jika database tidak dapat disimpan sebagai template.
+var ErrInvalidDatabaseTemplate = errors.New("template database tidak valid")
+
+// TemplateSchema menyalin kolom dan view database untuk disimpan sebagai template. Relation ke
+// database itu sendiri disimpan dengan DatabaseID kosong.
+func TemplateSchema(database *model.Database) ([]model.DatabaseColumn, []model.DatabaseView, error) {
+	for _, col := range database.DatabaseData.Columns {
+		if col.Type == "relation" && col.Relation != nil && col.Relation.DatabaseID != database.ID {
+			return nil, nil, fmt.Errorf("%w: kolom relation '%s' merujuk database lain", ErrInvalidDatabaseTemplate, col.Name)
+		}
+	}
+	columns, views := CopyDatabaseSchema(database.DatabaseData.Columns, database.Views, database.ID, primitive.NilObjectID)
+	return columns, views, nil
+}
+
+// CopyDatabaseSchema membuat salinan kolom dan view untuk database targetID. Relation yang merujuk
+// sourceID (atau DatabaseID kosong dari template) diarahkan ke targetID; relation lain tidak diubah.
+func CopyDatabaseSchema(columns []model.DatabaseColumn, views []model.DatabaseView, sourceID, targetID primitive.ObjectID) ([]model.DatabaseColumn, []model.DatabaseView) {
+	copiedColumns := make([]model.DatabaseColumn, len(columns))
+	for i, col := range columns {
+		if col.Options != nil {
+			col.Options = append([]model.SelectOption(nil), col.Options...)
+		}
+		if col.Relation != nil {
+			relation := *col.Relation
+			if relation.DatabaseID.IsZero() || relation.DatabaseID == sourceID {
+				relation.DatabaseID = targetID
+			}
+			col.Relation = &relation
+		}
+		if col.Rollup != nil {
+			rollup := *col.Rollup
+			col.Rollup = &rollup
+		}
+		if col.Constraints != nil {
+			constraints := *col.Constraints
+			col.Constraints = &constraints
+		}
+		copiedColumns[i] = col
+	}
+
+	var copiedViews []model.DatabaseView
+	if len(views) > 0 {
+		now := time.Now()
+		copiedViews = make([]model.DatabaseView, len(views))
+		for i, view := range views {
+			view.ID = primitive.NewObjectID()
+			if view.Filter != nil {
+				filter := *view.Filter
+				filter.Conditions = append([]model.DatabaseViewFilterCondition(nil), filter.Conditions...)
+				view.Filter = &filter
+			}
+			view.Sort = append([]model.DatabaseViewSort(nil), view.Sort...)
+			view.VisibleColumns = append([]string(nil), view.VisibleColumns...)
+			view.CreatedAt = now
+			view.UpdatedAt = now
+			copiedViews[i] = view
+		}
+	}
+	return copiedColumns, copiedViews
+}
+
+// CopyDatabaseRows membuat salinan baris dengan ID baru untuk database targetID. columns adalah kolom
+// hasil CopyDatabaseSchema; nilai kolom relation ke diri sendiri diterjemahkan ke ID baris baru dan
+// nilai kolom file dikosongkan.
+func CopyDatabaseRows(columns []model.DatabaseColumn, rows []model.DatabaseRow, targetID primitive.ObjectID) []model.DatabaseRow {
+	newIDs := make(map[string]primitive.ObjectID, len(rows))
+	for _, row := range rows {
+		newIDs[row.ID.Hex()] = primitive.NewObjectID()
+	}
+	selfRelations := make(map[string]bool)
+	fileColumns := make(map[string]bool)
+	for _, col := range columns {
+		if col.Type == "relation" && col.Relation != nil && col.Relation.DatabaseID == targetID {
+			selfRelations[col.ID.Hex()] = true
+		}
+		if col.Type == "file" {
+			fileColumns[col.ID.Hex()] = true
+		}
+	}
+
+	copied := make([]model.DatabaseRow, len(rows))
+	for i, row := range rows {
+		values := make(model.DatabaseRowValue, len(row.Values))
+		for key, value := range row.Values {
+			if selfRelations[key] {
+				linked := make([]string, 0)
+				for _, rowID := range relationRowIDs(value) {
+					if newID, ok := newIDs[rowID]; ok {
+						linked = append(linked, newID.Hex())
+					}
+				}
+				value = linked
+			}
+			if fileColumns[key] {
+				value = []string{}
+			}
+			values[key] = value
+		}
+		copied[i] = model.DatabaseRow{ID: newIDs[row.ID.Hex()], Values: values}
+	}
+	return copied
+}
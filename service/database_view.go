@@ -0,0 +1,370 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"backend_my_manajer/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// View database menentukan bentuk baris yang dikembalikan server:
+//   - table    : satu halaman baris (page/limit atau cursor), sama seperti GET /databases/:id/rows
+//   - kanban   : baris dikelompokkan per opsi kolom select (groupByColumnId) sesuai urutan opsi,
+//                ditambah grup "(empty)" di akhir untuk baris tanpa opsi
+//   - calendar : baris dikelompokkan per tanggal (UTC, YYYY-MM-DD) kolom date (dateColumnId)
+//
+// Filter dan sort view diterapkan sebelum pengelompokan, dan nilai baris hanya berisi kolom yang terlihat.
+
+// Tipe view yang didukung.
+const (
+	ViewTypeTable    = "table"
+	ViewTypeKanban   = "kanban"
+	ViewTypeCalendar = "calendar"
+)
+
+// ErrInvalidDatabaseView dikembalikan jika konfigurasi view tidak valid terhadap kolom database.
+var ErrInvalidDatabaseView = errors.New("view database tidak valid")
+
+// ViewRowsOptions adalah parameter pembacaan baris view. Page, Limit dan Cursor dipakai oleh view table;
+// Limit juga membatasi jumlah baris per grup kanban. From dan To (inklusif) membatasi rentang tanggal view calendar.
+type ViewRowsOptions struct {
+	Page   int
+	Limit  int
+	Cursor string
+	From   *time.Time
+	To     *time.Time
+}
+
+// ViewGroup adalah satu kolom kanban. Key adalah ID opsi select, atau string kosong untuk grup "(empty)".
+// Total adalah jumlah seluruh baris dalam grup, sedangkan Rows dibatasi oleh limit.
+type ViewGroup struct {
+	Key   string
+	Label string
+	Rows  []model.DatabaseRow
+	Total int
+}
+
+// ViewDateBucket adalah kumpulan baris calendar pada satu tanggal.
+type ViewDateBucket struct {
+	Date string
+	Rows []model.DatabaseRow
+}
+
+// ViewResult adalah baris database yang sudah dibentuk sesuai view. Hanya salah satu dari Table, Groups
+// atau Buckets yang terisi sesuai tipe view. Undated adalah jumlah baris calendar tanpa tanggal.
+type ViewResult struct {
+	Columns []model.DatabaseColumn
+	Table   *RowQueryResult
+	Groups  []ViewGroup
+	Buckets []ViewDateBucket
+	Undated int
+}
+
+// ValidateDatabaseView memeriksa konfigurasi view terhadap kolom database dan menormalkannya (in-place):
+// kolom terlihat tanpa duplikat, arah sort huruf kecil, dan field yang tidak sesuai tipe view dikosongkan.
+func ValidateDatabaseView(columns []model.DatabaseColumn, view *model.DatabaseView) error {
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		return fmt.Errorf("%w: nama view wajib diisi", ErrInvalidDatabaseView)
+	}
+	columnsByID := indexColumns(columns)
+
+	switch view.Type {
+	case ViewTypeTable:
+		view.GroupByColumnID, view.DateColumnID = "", ""
+	case ViewTypeKanban:
+		view.DateColumnID = ""
+		if err := checkViewColumn(columnsByID, view.GroupByColumnID, "groupByColumnId", "select"); err != nil {
+			return err
+		}
+	case ViewTypeCalendar:
+		view.GroupByColumnID = ""
+		if err := checkViewColumn(columnsByID, view.DateColumnID, "dateColumnId", "date"); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: tipe view '%s' tidak dikenal (gunakan table, kanban atau calendar)", ErrInvalidDatabaseView, view.Type)
+	}
+
+	if view.Filter != nil {
+		if err := viewRowFilter(view.Filter).Validate(columns); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDatabaseView, err)
+		}
+		if len(view.Filter.Conditions) == 0 {
+			view.Filter = nil
+		}
+	}
+
+	for i := range view.Sort {
+		s := &view.Sort[i]
+		if _, ok := columnsByID[s.ColumnID]; !ok {
+			return fmt.Errorf("%w: kolom sort '%s' tidak ditemukan", ErrInvalidDatabaseView, s.ColumnID)
+		}
+		s.Direction = strings.ToLower(s.Direction)
+		switch s.Direction {
+		case "":
+			s.Direction = "asc"
+		case "asc", "desc":
+		default:
+			return fmt.Errorf("%w: arah sort '%s' tidak dikenal (gunakan asc atau desc)", ErrInvalidDatabaseView, s.Direction)
+		}
+	}
+
+	visible := make([]string, 0, len(view.VisibleColumns))
+	seen := make(map[string]bool, len(view.VisibleColumns))
+	for _, columnID := range view.VisibleColumns {
+		if _, ok := columnsByID[columnID]; !ok {
+			return fmt.Errorf("%w: kolom terlihat '%s' tidak ditemukan", ErrInvalidDatabaseView, columnID)
+		}
+		if !seen[columnID] {
+			seen[columnID] = true
+			visible = append(visible, columnID)
+		}
+	}
+	view.VisibleColumns = visible
+	return nil
+}
+
+// checkViewColumn memastikan field view merujuk kolom dengan tipe yang diharapkan.
+func checkViewColumn(columnsByID map[string]*model.DatabaseColumn, columnID, field, columnType string) error {
+	if columnID == "" {
+		return fmt.Errorf("%w: %s wajib diisi", ErrInvalidDatabaseView, field)
+	}
+	col, ok := columnsByID[columnID]
+	if !ok {
+		return fmt.Errorf("%w: kolom %s '%s' tidak ditemukan", ErrInvalidDatabaseView, field, columnID)
+	}
+	if col.Type != columnType {
+		return fmt.Errorf("%w: %s harus merujuk kolom %s, kolom '%s' bertipe %s", ErrInvalidDatabaseView, field, columnType, col.Name, col.Type)
+	}
+	return nil
+}
+
+// ViewReferencingColumn mengembalikan nama view kanban atau calendar pertama yang dikelompokkan berdasarkan
+// columnID, atau string kosong jika tidak ada. View seperti ini tidak dapat berfungsi tanpa kolom tersebut.
+func ViewReferencingColumn(views []model.DatabaseView, columnID string) string {
+	for _, view := range views {
+		if (view.Type == ViewTypeKanban && view.GroupByColumnID == columnID) ||
+			(view.Type == ViewTypeCalendar && view.DateColumnID == columnID) {
+			return view.Name
+		}
+	}
+	return ""
+}
+
+// QueryViewRows membentuk baris database sesuai view. Nilai kolom turunan harus sudah dihitung dengan
+// ComputeDerivedColumns. Referensi ke kolom yang sudah tidak ada pada filter, sort dan kolom terlihat diabaikan.
+func QueryViewRows(columns []model.DatabaseColumn, rows []model.DatabaseRow, view *model.DatabaseView, opts ViewRowsOptions) (*ViewResult, error) {
+	columnsByID := indexColumns(columns)
+
+	var filter *RowFilter
+	if view.Filter != nil {
+		filter = viewRowFilter(view.Filter)
+		conditions := filter.Conditions[:0]
+		for _, cond := range filter.Conditions {
+			if _, ok := columnsByID[cond.ColumnID]; ok {
+				conditions = append(conditions, cond)
+			}
+		}
+		filter.Conditions = conditions
+	}
+	var sorts []RowSort
+	for _, s := range view.Sort {
+		if _, ok := columnsByID[s.ColumnID]; ok {
+			sorts = append(sorts, RowSort{ColumnID: s.ColumnID, Descending: s.Direction == "desc"})
+		}
+	}
+
+	result := &ViewResult{Columns: visibleViewColumns(columns, view.VisibleColumns)}
+	visible := make(map[string]bool, len(result.Columns))
+	for _, col := range result.Columns {
+		visible[col.ID.Hex()] = true
+	}
+
+	switch view.Type {
+	case ViewTypeTable:
+		table, err := QueryRows(columns, rows, RowQuery{Filter: filter, Sort: sorts, Page: opts.Page, Limit: opts.Limit, Cursor: opts.Cursor})
+		if err != nil {
+			return nil, err
+		}
+		table.Rows = projectViewRows(table.Rows, visible)
+		result.Table = table
+		return result, nil
+	case ViewTypeKanban:
+		if err := checkViewColumn(columnsByID, view.GroupByColumnID, "groupByColumnId", "select"); err != nil {
+			return nil, err
+		}
+		limit := opts.Limit
+		if limit <= 0 {
+			limit = DefaultRowQueryLimit
+		}
+		if limit > MaxRowQueryLimit {
+			return nil, fmt.Errorf("%w: limit maksimum adalah %d", ErrInvalidRowQuery, MaxRowQueryLimit)
+		}
+		matched := append([]model.DatabaseRow(nil), FilterRows(columns, rows, filter)...)
+		SortRows(columns, matched, sorts)
+		result.Groups = groupKanbanRows(columnsByID[view.GroupByColumnID], matched, visible, limit)
+		return result, nil
+	case ViewTypeCalendar:
+		if err := checkViewColumn(columnsByID, view.DateColumnID, "dateColumnId", "date"); err != nil {
+			return nil, err
+		}
+		if opts.From != nil && opts.To != nil && opts.To.Before(*opts.From) {
+			return nil, fmt.Errorf("%w: to tidak boleh sebelum from", ErrInvalidRowQuery)
+		}
+		matched := append([]model.DatabaseRow(nil), FilterRows(columns, rows, filter)...)
+		SortRows(columns, matched, sorts)
+		result.Buckets, result.Undated = bucketCalendarRows(view.DateColumnID, matched, visible, opts.From, opts.To)
+		return result, nil
+	}
+	return nil, fmt.Errorf("%w: tipe view '%s' tidak dikenal", ErrInvalidDatabaseView, view.Type)
+}
+
+// groupKanbanRows mengelompokkan baris per opsi select sesuai urutan opsi. Nilai yang bukan opsi
+// kolom (misalnya opsi yang sudah dihapus) masuk ke grup "(empty)".
+func groupKanbanRows(col *model.DatabaseColumn, rows []model.DatabaseRow, visible map[string]bool, limit int) []ViewGroup {
+	options := append([]model.SelectOption(nil), col.Options...)
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Order < options[j].Order })
+
+	groups := make([]ViewGroup, 0, len(options)+1)
+	groupIndex := make(map[string]int, len(options))
+	for _, opt := range options {
+		groupIndex[opt.ID.Hex()] = len(groups)
+		groups = append(groups, ViewGroup{Key: opt.ID.Hex(), Label: opt.Value, Rows: []model.DatabaseRow{}})
+	}
+	emptyIndex := len(groups)
+	groups = append(groups, ViewGroup{Key: "", Label: emptyGroupLabel, Rows: []model.DatabaseRow{}})
+
+	for _, row := range rows {
+		index := emptyIndex
+		if key := kanbanOptionID(col, row.Values[col.ID.Hex()]); key != "" {
+			if i, ok := groupIndex[key]; ok {
+				index = i
+			}
+		}
+		group := &groups[index]
+		group.Total++
+		if len(group.Rows) < limit {
+			group.Rows = append(group.Rows, projectViewRow(row, visible))
+		}
+	}
+	return groups
+}
+
+// kanbanOptionID mengembalikan ID opsi dari nilai sel select. Data lama yang menyimpan value opsi juga didukung.
+func kanbanOptionID(col *model.DatabaseColumn, value interface{}) string {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case primitive.ObjectID:
+		raw = v.Hex()
+	default:
+		return ""
+	}
+	for _, opt := range col.Options {
+		if opt.ID.Hex() == raw {
+			return raw
+		}
+	}
+	for _, opt := range col.Options {
+		if opt.Value == raw {
+			return opt.ID.Hex()
+		}
+	}
+	return ""
+}
+
+// bucketCalendarRows mengelompokkan baris per tanggal UTC kolom date, terurut dari tanggal paling awal.
+// Baris di luar rentang from/to dilewati; baris tanpa tanggal dihitung sebagai undated.
+func bucketCalendarRows(dateColumnID string, rows []model.DatabaseRow, visible map[string]bool, from, to *time.Time) ([]ViewDateBucket, int) {
+	var fromDay, toDay string
+	if from != nil {
+		fromDay = from.UTC().Format("2006-01-02")
+	}
+	if to != nil {
+		toDay = to.UTC().Format("2006-01-02")
+	}
+
+	buckets := make([]ViewDateBucket, 0)
+	bucketIndex := make(map[string]int)
+	undated := 0
+	for _, row := range rows {
+		t, ok := toTime(row.Values[dateColumnID])
+		if !ok {
+			undated++
+			continue
+		}
+		day := t.UTC().Format("2006-01-02")
+		if (fromDay != "" && day < fromDay) || (toDay != "" && day > toDay) {
+			continue
+		}
+		index, ok := bucketIndex[day]
+		if !ok {
+			index = len(buckets)
+			bucketIndex[day] = index
+			buckets = append(buckets, ViewDateBucket{Date: day})
+		}
+		buckets[index].Rows = append(buckets[index].Rows, projectViewRow(row, visible))
+	}
+	sort.SliceStable(buckets, func(i, j int) bool { return buckets[i].Date < buckets[j].Date })
+	return buckets, undated
+}
+
+// visibleViewColumns mengembalikan kolom yang terlihat sesuai urutan visibleColumns, atau semua kolom
+// sesuai DatabaseColumn.Order jika visibleColumns kosong.
+func visibleViewColumns(columns []model.DatabaseColumn, visibleColumns []string) []model.DatabaseColumn {
+	if len(visibleColumns) == 0 {
+		ordered := append([]model.DatabaseColumn(nil), columns...)
+		sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Order < ordered[j].Order })
+		return ordered
+	}
+	columnsByID := indexColumns(columns)
+	visible := make([]model.DatabaseColumn, 0, len(visibleColumns))
+	for _, columnID := range visibleColumns {
+		if col, ok := columnsByID[columnID]; ok {
+			visible = append(visible, *col)
+		}
+	}
+	return visible
+}
+
+// projectViewRows menyalin baris dengan hanya menyertakan nilai kolom yang terlihat.
+func projectViewRows(rows []model.DatabaseRow, visible map[string]bool) []model.DatabaseRow {
+	projected := make([]model.DatabaseRow, len(rows))
+	for i, row := range rows {
+		projected[i] = projectViewRow(row, visible)
+	}
+	return projected
+}
+
+// projectViewRow menyalin satu baris dengan hanya menyertakan nilai kolom yang terlihat.
+func projectViewRow(row model.DatabaseRow, visible map[string]bool) model.DatabaseRow {
+	values := make(model.DatabaseRowValue, len(visible))
+	for columnID, value := range row.Values {
+		if visible[columnID] {
+			values[columnID] = value
+		}
+	}
+	row.Values = values
+	return row
+}
+
+// viewRowFilter mengonversi filter tersimpan menjadi RowFilter. Array hasil decode BSON (primitive.A)
+// dinormalkan menjadi []interface{} agar dikenali operator "between" dan "in".
+func viewRowFilter(filter *model.DatabaseViewFilter) *RowFilter {
+	rowFilter := &RowFilter{Match: filter.Match, Conditions: make([]RowFilterCondition, len(filter.Conditions))}
+	for i, cond := range filter.Conditions {
+		value := cond.Value
+		if arr, ok := value.(primitive.A); ok {
+			value = []interface{}(arr)
+		}
+		rowFilter.Conditions[i] = RowFilterCondition{ColumnID: cond.ColumnID, Operator: cond.Operator, Value: value}
+	}
+	return rowFilter
+}
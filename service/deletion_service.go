@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Penghapusan berantai channel dan bisnis.
+//
+// Channel menghapus: file kolom database (isi di FileStorage dan metadata), revisi dan baris database,
+// referensi relation dari database lain ke database tersebut, database, pesan, dokumen, drawing, laporan,
+// lalu channel itu sendiri. Kategori channel dimiliki bisnis (bukan channel) sehingga hanya dihapus bersama
+// bisnisnya.
+//
+// Bisnis menghapus semua channel miliknya seperti di atas, ditambah kategori channel, role, template
+// database, dan melepas bisnis dari businessIds/roles user. Dokumen target dihapus paling akhir, sehingga
+// penghapusan yang gagal di tengah jalan dapat diulang dengan memanggil delete lagi.
+//
+// Jika jumlah dokumen yang terkena tidak lebih dari CascadeTransactionLimit, semua langkah dijalankan dalam
+// satu transaksi MongoDB. Penghapusan yang lebih besar, atau jika server MongoDB tidak mendukung transaksi
+// (standalone tanpa replica set), dijalankan sebagai DeletionJob di background secara batch. Progres setiap
+// langkah disimpan sehingga job dilanjutkan dari langkah terakhir setelah server restart.
+
+// CascadeTransactionLimit adalah jumlah dokumen maksimum yang dihapus dalam satu transaksi.
+const CascadeTransactionLimit = 1000
+
+// deletionBatchSize adalah jumlah dokumen per batch pada job background.
+const deletionBatchSize = 500
+
+// Tipe target penghapusan.
+const (
+	DeletionTargetChannel  = "channel"
+	DeletionTargetBusiness = "business"
+)
+
+// Mode penghapusan pada DeletionResult.
+const (
+	DeletionModeDryRun      = "dry_run"
+	DeletionModeTransaction = "transaction"
+	DeletionModeBackground  = "background"
+)
+
+// ErrDeletionTargetNotFound dikembalikan jika channel atau bisnis yang akan dihapus tidak ditemukan.
+var ErrDeletionTargetNotFound = errors.New("target penghapusan tidak ditemukan")
+
+// DeletionPlan adalah daftar langkah penghapusan beserta jumlah dokumen yang akan terkena.
+type DeletionPlan struct {
+	TargetType string
+	TargetID   primitive.ObjectID
+	Steps      []model.DeletionStep
+}
+
+// Total mengembalikan jumlah seluruh dokumen yang direncanakan terkena.
+func (p *DeletionPlan) Total() int64 {
+	var total int64
+	for _, step := range p.Steps {
+		total += step.Planned
+	}
+	return total
+}
+
+// DeletionResult adalah hasil DeleteChannel/DeleteBusiness. Job hanya diisi untuk mode background.
+type DeletionResult struct {
+	Mode  string
+	Steps []model.DeletionStep
+	Job   *model.DeletionJob
+}
+
+// DeletionService adalah antarmuka untuk layanan penghapusan berantai channel dan bisnis.
+type DeletionService interface {
+	// DeleteChannel menghapus channel beserta kontennya. dryRun hanya menghitung dokumen yang akan terkena.
+	DeleteChannel(ctx context.Context, channelID, requestedBy primitive.ObjectID, dryRun bool) (*DeletionResult, error)
+	// DeleteBusiness menghapus bisnis beserta channel, kategori, role dan template miliknya.
+	DeleteBusiness(ctx context.Context, businessID, requestedBy primitive.ObjectID, dryRun bool) (*DeletionResult, error)
+	// GetJob mengambil status job penghapusan background.
+	GetJob(ctx context.Context, jobID primitive.ObjectID) (*model.DeletionJob, error)
+	// ResumeUnfinishedJobs melanjutkan job yang belum selesai, dipanggil sekali saat aplikasi dimulai.
+	ResumeUnfinishedJobs(ctx context.Context)
+}
+
+type deletionServiceImpl struct {
+	repo         repository.DeletionRepository
+	channelRepo  repository.ChannelRepository
+	businessRepo repository.BusinessRepository
+	storage      FileStorage // Boleh nil; isi file di storage tidak ikut dihapus
+
+	mu      sync.Mutex
+	running map[primitive.ObjectID]bool // Job yang sedang dijalankan oleh proses ini
+}
+
+// NewDeletionService membuat instance baru dari DeletionService.
+func NewDeletionService(repo repository.DeletionRepository, channelRepo repository.ChannelRepository, businessRepo repository.BusinessRepository, storage FileStorage) DeletionService {
+	return &deletionServiceImpl{
+		repo:         repo,
+		channelRepo:  channelRepo,
+		businessRepo: businessRepo,
+		storage:      storage,
+		running:      make(map[primitive.ObjectID]bool),
+	}
+}
+
+// DeleteChannel merencanakan lalu menjalankan penghapusan channel.
+func (s *deletionServiceImpl) DeleteChannel(ctx context.Context, channelID, requestedBy primitive.ObjectID, dryRun bool) (*DeletionResult, error) {
+	channel, err := s.channelRepo.GetChannelByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("%w: channel %s", ErrDeletionTargetNotFound, channelID.Hex())
+	}
+	plan, err := s.planChannels(ctx, DeletionTargetChannel, channelID, []primitive.ObjectID{channelID})
+	if err != nil {
+		return nil, err
+	}
+	plan.Steps = append(plan.Steps, model.DeletionStep{Collection: "Channels", Field: "_id", IDs: []primitive.ObjectID{channelID}, Action: model.DeletionActionDelete})
+	return s.execute(ctx, plan, requestedBy, dryRun)
+}
+
+// DeleteBusiness merencanakan lalu menjalankan penghapusan bisnis.
+func (s *deletionServiceImpl) DeleteBusiness(ctx context.Context, businessID, requestedBy primitive.ObjectID, dryRun bool) (*DeletionResult, error) {
+	business, err := s.businessRepo.GetBusinessByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, fmt.Errorf("%w: bisnis %s", ErrDeletionTargetNotFound, businessID.Hex())
+	}
+	channelIDs, err := s.repo.FindIDs(ctx, "Channels", "businessId", []primitive.ObjectID{businessID})
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.planChannels(ctx, DeletionTargetBusiness, businessID, channelIDs)
+	if err != nil {
+		return nil, err
+	}
+	businessIDs := []primitive.ObjectID{businessID}
+	plan.Steps = append(plan.Steps,
+		model.DeletionStep{Collection: "Channels", Field: "businessId", IDs: businessIDs, Action: model.DeletionActionDelete},
+		model.DeletionStep{Collection: "ChannelCategories", Field: "businessId", IDs: businessIDs, Action: model.DeletionActionDelete},
+		model.DeletionStep{Collection: "Roles", Field: "businessId", IDs: businessIDs, Action: model.DeletionActionDelete},
+		model.DeletionStep{Collection: "DatabaseTemplates", Field: "businessId", IDs: businessIDs, Action: model.DeletionActionDelete},
+		model.DeletionStep{Collection: "Users", Field: "businessIds", IDs: businessIDs, Action: model.DeletionActionDetachUsers},
+		model.DeletionStep{Collection: "Businesses", Field: "_id", IDs: businessIDs, Action: model.DeletionActionDelete},
+	)
+	return s.execute(ctx, plan, requestedBy, dryRun)
+}
+
+// planChannels menyusun langkah untuk konten channel-channel tersebut (tanpa dokumen channel itu sendiri).
+func (s *deletionServiceImpl) planChannels(ctx context.Context, targetType string, targetID primitive.ObjectID, channelIDs []primitive.ObjectID) (*DeletionPlan, error) {
+	databaseIDs, err := s.repo.FindIDs(ctx, "Databases", "channelId", channelIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &DeletionPlan{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Steps: []model.DeletionStep{
+			{Collection: "DatabaseFiles", Field: "databaseId", IDs: databaseIDs, Action: model.DeletionActionDeleteFiles},
+			{Collection: "DatabaseRowRevisions", Field: "databaseId", IDs: databaseIDs, Action: model.DeletionActionDelete},
+			{Collection: "DatabaseRows", Field: "databaseId", IDs: databaseIDs, Action: model.DeletionActionDelete},
+			{Collection: "DatabaseRows", Field: "values", IDs: databaseIDs, Action: model.DeletionActionClearRelations},
+			{Collection: "Databases", Field: "_id", IDs: databaseIDs, Action: model.DeletionActionDelete},
+			{Collection: "Notifications", Field: "channelId", IDs: channelIDs, Action: model.DeletionActionDelete},
+			{Collection: "ChannelReadStates", Field: "channelId", IDs: channelIDs, Action: model.DeletionActionDelete},
+			{Collection: "Messages", Field: "channelId", IDs: channelIDs, Action: model.DeletionActionDelete},
+			{Collection: "Documents", Field: "channelId", IDs: channelIDs, Action: model.DeletionActionDelete},
+			{Collection: "Drawings", Field: "channelId", IDs: channelIDs, Action: model.DeletionActionDelete},
+			{Collection: "Reports", Field: "channelId", IDs: channelIDs, Action: model.DeletionActionDelete},
+		},
+	}, nil
+}
+
+// execute menghitung rencana lalu memilih mode: dry run, transaksi, atau job background.
+func (s *deletionServiceImpl) execute(ctx context.Context, plan *DeletionPlan, requestedBy primitive.ObjectID, dryRun bool) (*DeletionResult, error) {
+	if !dryRun {
+		// Penghapusan yang sama sedang berjalan; kembalikan job yang ada daripada membuat job kedua
+		active, err := s.repo.GetActiveJobByTarget(ctx, plan.TargetType, plan.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		if active != nil {
+			return &DeletionResult{Mode: DeletionModeBackground, Steps: active.Steps, Job: active}, nil
+		}
+	}
+
+	for i := range plan.Steps {
+		count, err := s.repo.CountStep(ctx, plan.Steps[i])
+		if err != nil {
+			return nil, err
+		}
+		plan.Steps[i].Planned = count
+	}
+	if dryRun {
+		return &DeletionResult{Mode: DeletionModeDryRun, Steps: plan.Steps}, nil
+	}
+
+	if plan.Total() <= CascadeTransactionLimit {
+		steps, err := s.runInTransaction(ctx, plan)
+		if err == nil {
+			utils.LogInfo("Berhasil menghapus %s %s beserta %d dokumen terkait dalam transaksi", plan.TargetType, plan.TargetID.Hex(), plan.Total())
+			return &DeletionResult{Mode: DeletionModeTransaction, Steps: steps}, nil
+		}
+		if !isTransactionUnsupported(err) {
+			return nil, err
+		}
+		utils.LogWarning("MongoDB tidak mendukung transaksi, penghapusan %s %s dijalankan sebagai job background", plan.TargetType, plan.TargetID.Hex())
+	}
+
+	job := &model.DeletionJob{
+		TargetType:  plan.TargetType,
+		TargetID:    plan.TargetID,
+		RequestedBy: requestedBy,
+		Status:      model.DeletionJobPending,
+		Steps:       plan.Steps,
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	s.startJob(*job)
+	return &DeletionResult{Mode: DeletionModeBackground, Steps: job.Steps, Job: job}, nil
+}
+
+// runInTransaction menjalankan semua langkah dalam satu transaksi. Isi file di storage dihapus setelah
+// commit karena storage tidak ikut transaksi.
+func (s *deletionServiceImpl) runInTransaction(ctx context.Context, plan *DeletionPlan) ([]model.DeletionStep, error) {
+	var steps []model.DeletionStep
+	var storageKeys []string
+	err := s.repo.RunInTransaction(ctx, func(txCtx context.Context) error {
+		// Fungsi dapat diulang oleh driver, sehingga hasil sebelumnya dibuang
+		steps = append([]model.DeletionStep(nil), plan.Steps...)
+		storageKeys = nil
+		for i := range steps {
+			if steps[i].Action == model.DeletionActionDeleteFiles {
+				files, err := s.repo.FindFilesByDatabaseIDs(txCtx, steps[i].IDs, 0)
+				if err != nil {
+					return err
+				}
+				fileIDs := make([]primitive.ObjectID, len(files))
+				for j, file := range files {
+					fileIDs[j] = file.ID
+					storageKeys = append(storageKeys, file.StorageKey)
+				}
+				affected, err := s.repo.DeleteFilesByIDs(txCtx, fileIDs)
+				if err != nil {
+					return err
+				}
+				steps[i].Affected, steps[i].Done = affected, true
+				continue
+			}
+			affected, err := s.repo.ExecuteStep(txCtx, steps[i], 0)
+			if err != nil {
+				return err
+			}
+			steps[i].Affected, steps[i].Done = affected, true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range storageKeys {
+		s.deleteStoredFile(ctx, key)
+	}
+	return steps, nil
+}
+
+// isTransactionUnsupported mendeteksi error transaksi pada MongoDB standalone (IllegalOperation, kode 20).
+func isTransactionUnsupported(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == 20 {
+		return true
+	}
+	return strings.Contains(err.Error(), "Transaction numbers are only allowed")
+}
+
+// GetJob mengambil job penghapusan berdasarkan ID.
+func (s *deletionServiceImpl) GetJob(ctx context.Context, jobID primitive.ObjectID) (*model.DeletionJob, error) {
+	return s.repo.GetJobByID(ctx, jobID)
+}
+
+// ResumeUnfinishedJobs menjalankan ulang job pending/running yang tertinggal saat server berhenti.
+func (s *deletionServiceImpl) ResumeUnfinishedJobs(ctx context.Context) {
+	jobs, err := s.repo.GetUnfinishedJobs(ctx)
+	if err != nil {
+		utils.LogError(err, "Gagal mengambil job penghapusan yang belum selesai")
+		return
+	}
+	for _, job := range jobs {
+		utils.LogInfo("Melanjutkan job penghapusan %s untuk %s %s", job.ID.Hex(), job.TargetType, job.TargetID.Hex())
+		s.startJob(job)
+	}
+}
+
+// startJob menjalankan job di goroutine terpisah, kecuali job tersebut sudah berjalan di proses ini.
+func (s *deletionServiceImpl) startJob(job model.DeletionJob) {
+	s.mu.Lock()
+	if s.running[job.ID] {
+		s.mu.Unlock()
+		return
+	}
+	s.running[job.ID] = true
+	s.mu.Unlock()
+	// Steps disalin karena slice aslinya masih dipakai pemanggil untuk response
+	job.Steps = append([]model.DeletionStep(nil), job.Steps...)
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, job.ID)
+			s.mu.Unlock()
+		}()
+		s.runJob(&job)
+	}()
+}
+
+// runJob menjalankan langkah-langkah job yang belum selesai secara batch dan menyimpan progres setelah
+// setiap batch. Job yang gagal ditandai failed; memanggil delete lagi akan membuat job baru.
+func (s *deletionServiceImpl) runJob(job *model.DeletionJob) {
+	ctx := context.Background()
+	job.Status = model.DeletionJobRunning
+	job.Error = ""
+	if err := s.saveJob(ctx, job); err != nil {
+		return
+	}
+
+	for i := range job.Steps {
+		step := &job.Steps[i]
+		for !step.Done {
+			affected, err := s.runJobBatch(ctx, *step)
+			if err != nil {
+				utils.LogError(err, "Job penghapusan %s gagal pada koleksi %s", job.ID.Hex(), step.Collection)
+				job.Status = model.DeletionJobFailed
+				job.Error = err.Error()
+				s.saveJob(ctx, job)
+				return
+			}
+			step.Affected += affected
+			// detach_users dan clear_relations selesai dalam satu update; langkah lain selesai saat batch kosong
+			if affected == 0 || step.Action == model.DeletionActionDetachUsers || step.Action == model.DeletionActionClearRelations {
+				step.Done = true
+			}
+			if err := s.saveJob(ctx, job); err != nil {
+				return
+			}
+		}
+	}
+
+	now := time.Now()
+	job.Status = model.DeletionJobCompleted
+	job.CompletedAt = &now
+	s.saveJob(ctx, job)
+	utils.LogInfo("Job penghapusan %s untuk %s %s selesai", job.ID.Hex(), job.TargetType, job.TargetID.Hex())
+}
+
+// runJobBatch menjalankan satu batch dari step dengan timeout tersendiri.
+func (s *deletionServiceImpl) runJobBatch(ctx context.Context, step model.DeletionStep) (int64, error) {
+	batchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if step.Action != model.DeletionActionDeleteFiles {
+		return s.repo.ExecuteStep(batchCtx, step, deletionBatchSize)
+	}
+	files, err := s.repo.FindFilesByDatabaseIDs(batchCtx, step.IDs, deletionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	// Isi file dihapus lebih dulu; jika job berhenti di tengah, metadata yang tersisa akan diproses ulang
+	fileIDs := make([]primitive.ObjectID, len(files))
+	for i, file := range files {
+		s.deleteStoredFile(batchCtx, file.StorageKey)
+		fileIDs[i] = file.ID
+	}
+	return s.repo.DeleteFilesByIDs(batchCtx, fileIDs)
+}
+
+// saveJob menyimpan progres job dengan timeout tersendiri.
+func (s *deletionServiceImpl) saveJob(ctx context.Context, job *model.DeletionJob) error {
+	saveCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return s.repo.SaveJob(saveCtx, job)
+}
+
+// deleteStoredFile menghapus isi file dari storage. Kegagalan hanya dicatat karena metadata file sudah
+// atau akan dihapus dan tidak ada lagi yang merujuk key tersebut.
+func (s *deletionServiceImpl) deleteStoredFile(ctx context.Context, key string) {
+	if s.storage == nil || key == "" {
+		return
+	}
+	if err := s.storage.Delete(ctx, key); err != nil {
+		utils.LogWarning("Gagal menghapus isi file %s dari storage: %v", key, err)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStorage menyimpan isi file yang diunggah ke kolom database bertipe "file". Metadata file
+// (nama, tipe, ukuran, pemilik) disimpan terpisah di koleksi DatabaseFiles; storage hanya mengenal key.
+//
+// Implementasi dipilih lewat variabel lingkungan FILE_STORAGE_DRIVER (lihat NewFileStorageFromEnv):
+//   - local : direktori di filesystem server (default)
+//   - s3    : bucket S3 atau layanan yang kompatibel (MinIO, dsb.)
+type FileStorage interface {
+	// Put menyimpan isi file dengan key tertentu, menimpa objek lama jika ada.
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	// Get membuka isi file. Mengembalikan ErrFileNotFound jika objek tidak ada.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete menghapus file. Objek yang tidak ada tidak dianggap error.
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrFileNotFound dikembalikan oleh FileStorage.Get jika objek tidak ditemukan.
+var ErrFileNotFound = errors.New("file tidak ditemukan di storage")
+
+// ErrInvalidStorageKey dikembalikan jika key file kosong atau keluar dari direktori storage.
+var ErrInvalidStorageKey = errors.New("key file storage tidak valid")
+
+// NewFileStorageFromEnv membuat FileStorage sesuai variabel lingkungan:
+//   - FILE_STORAGE_DRIVER    : "local" (default) atau "s3"
+//   - FILE_STORAGE_LOCAL_DIR : direktori untuk driver local (default "./uploads")
+//   - S3_ENDPOINT            : URL endpoint, misalnya http://localhost:9000 untuk MinIO (default AWS S3 sesuai region)
+//   - S3_REGION              : region untuk tanda tangan request (default "us-east-1")
+//   - S3_BUCKET              : nama bucket (wajib)
+//   - S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY : kredensial (wajib)
+//   - S3_FORCE_PATH_STYLE    : "true" untuk URL endpoint/bucket/key; default true jika S3_ENDPOINT diisi
+func NewFileStorageFromEnv() (FileStorage, error) {
+	switch driver := strings.ToLower(os.Getenv("FILE_STORAGE_DRIVER")); driver {
+	case "", "local":
+		dir := os.Getenv("FILE_STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		return NewLocalFileStorage(dir)
+	case "s3":
+		endpoint := os.Getenv("S3_ENDPOINT")
+		pathStyle := endpoint != ""
+		if raw := os.Getenv("S3_FORCE_PATH_STYLE"); raw != "" {
+			pathStyle = strings.EqualFold(raw, "true") || raw == "1"
+		}
+		return NewS3FileStorage(S3Config{
+			Endpoint:        endpoint,
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			ForcePathStyle:  pathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("FILE_STORAGE_DRIVER '%s' tidak dikenal, gunakan 'local' atau 's3'", driver)
+	}
+}
+
+// LocalFileStorage menyimpan file sebagai file biasa di bawah satu direktori root.
+type LocalFileStorage struct {
+	root string
+}
+
+// NewLocalFileStorage membuat LocalFileStorage dan memastikan direktori root ada.
+func NewLocalFileStorage(root string) (*LocalFileStorage, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(absRoot, 0o755); err != nil {
+		return nil, fmt.Errorf("gagal membuat direktori storage %s: %w", absRoot, err)
+	}
+	return &LocalFileStorage{root: absRoot}, nil
+}
+
+// Put menulis file ke file sementara lalu me-rename-nya, sehingga pembaca tidak melihat file setengah jadi.
+func (s *LocalFileStorage) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get membuka file untuk dibaca.
+func (s *LocalFileStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrFileNotFound
+	}
+	return file, err
+}
+
+// Delete menghapus file jika ada.
+func (s *LocalFileStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path menerjemahkan key (dipisah "/") menjadi path di bawah root dan menolak key yang keluar dari root.
+func (s *LocalFileStorage) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidStorageKey
+	}
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, s.root+string(filepath.Separator)) {
+		return "", ErrInvalidStorageKey
+	}
+	return path, nil
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3FileStorage menyimpan file di bucket S3 atau layanan yang kompatibel (MinIO, dsb.) melalui REST API
+// dengan AWS Signature Version 4. Hanya PutObject, GetObject dan DeleteObject yang dipakai.
+
+// S3Config adalah konfigurasi S3FileStorage.
+type S3Config struct {
+	Endpoint        string // URL endpoint; kosong berarti https://s3.<region>.amazonaws.com
+	Region          string // Kosong berarti us-east-1
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	ForcePathStyle  bool // true: endpoint/bucket/key, false: bucket.endpoint/key
+}
+
+// S3FileStorage adalah FileStorage berbasis S3.
+type S3FileStorage struct {
+	config     S3Config
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewS3FileStorage membuat S3FileStorage. Bucket harus sudah ada.
+func NewS3FileStorage(config S3Config) (*S3FileStorage, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("S3_BUCKET wajib diisi untuk storage s3")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("S3_ACCESS_KEY_ID dan S3_SECRET_ACCESS_KEY wajib diisi untuk storage s3")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("S3_ENDPOINT '%s' tidak valid", config.Endpoint)
+	}
+	return &S3FileStorage{
+		config:     config,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		now:        time.Now,
+	}, nil
+}
+
+// Put mengunggah file dengan PutObject. Isi file dibaca ke memori untuk menghitung hash payload.
+func (s *S3FileStorage) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3ResponseError(resp)
+	}
+	return nil
+}
+
+// Get mengunduh file dengan GetObject. Pemanggil wajib menutup reader.
+func (s *S3FileStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrFileNotFound
+	}
+	defer resp.Body.Close()
+	return nil, s3ResponseError(resp)
+}
+
+// Delete menghapus file dengan DeleteObject. S3 mengembalikan 204 juga untuk objek yang tidak ada.
+func (s *S3FileStorage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3ResponseError(resp)
+	}
+	return nil
+}
+
+// newRequest membuat request untuk objek key sesuai gaya URL (path-style atau virtual-hosted).
+func (s *S3FileStorage) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return nil, ErrInvalidStorageKey
+	}
+	target := *s.endpoint
+	if s.config.ForcePathStyle {
+		target.Path = s.endpoint.Path + "/" + s.config.Bucket + "/" + key
+	} else {
+		target.Host = s.config.Bucket + "." + s.endpoint.Host
+		target.Path = s.endpoint.Path + "/" + key
+	}
+	target.RawPath = s3EncodePath(target.Path)
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	return http.NewRequestWithContext(ctx, method, target.String(), reader)
+}
+
+// do menandatangani request dengan AWS Signature Version 4 lalu mengirimkannya.
+func (s *S3FileStorage) do(req *http.Request, body []byte) (*http.Response, error) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("Authorization", s.authorization(req, now, payloadHash))
+	return s.httpClient.Do(req)
+}
+
+// authorization menghitung header Authorization SigV4. Header yang ditandatangani adalah host,
+// content-type dan range (jika ada) serta semua header x-amz-*.
+func (s *S3FileStorage) authorization(req *http.Request, now time.Time, payloadHash string) string {
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := now.Format("20060102")
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.config.AccessKeyID, scope, signedHeaders, signature)
+}
+
+// s3CanonicalQuery menyusun query string kanonik SigV4 (key diurutkan, nilai di-encode RFC 3986).
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Encode(key, true)+"="+s3Encode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3EncodePath meng-encode path objek sesuai aturan SigV4; "/" dipertahankan.
+func s3EncodePath(path string) string {
+	return s3Encode(path, false)
+}
+
+// s3Encode meng-encode semua karakter kecuali karakter unreserved RFC 3986 (dan "/" jika encodeSlash false).
+func s3Encode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3ResponseError membuat error dari response S3 yang gagal, termasuk potongan body XML-nya.
+func s3ResponseError(resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("request S3 gagal dengan status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// sha256Hex mengembalikan hash SHA-256 data dalam hex.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 menghitung HMAC-SHA256 data dengan key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalFileStoragePath(t *testing.T) {
+	storage, err := NewLocalFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalFileStorage() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want string // Path relatif terhadap root; kosong berarti key harus ditolak
+	}{
+		{name: "key sederhana", key: "berkas.txt", want: "berkas.txt"},
+		{name: "key bertingkat", key: "db/123/berkas.txt", want: filepath.Join("db", "123", "berkas.txt")},
+		{name: "titik di tengah dinormalisasi", key: "db/./a/../berkas.txt", want: filepath.Join("db", "berkas.txt")},
+		{name: "key kosong", key: ""},
+		{name: "key absolut", key: "/etc/passwd"},
+		{name: "keluar dari root", key: "../rahasia.txt"},
+		{name: "keluar dari root lewat subdirektori", key: "db/../../rahasia.txt"},
+		{name: "menunjuk root itu sendiri", key: "."},
+		{name: "menunjuk root lewat subdirektori", key: "db/.."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := storage.path(tt.key)
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidStorageKey) {
+					t.Errorf("path(%q) = %q, %v, want ErrInvalidStorageKey", tt.key, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("path(%q) error = %v", tt.key, err)
+			}
+			if want := filepath.Join(storage.root, tt.want); got != want {
+				t.Errorf("path(%q) = %q, want %q", tt.key, got, want)
+			}
+		})
+	}
+}
+
+func TestLocalFileStorageRoundTrip(t *testing.T) {
+	storage, err := NewLocalFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalFileStorage() error = %v", err)
+	}
+	ctx := context.Background()
+	const key = "db/berkas.txt"
+
+	if err := storage.Put(ctx, key, strings.NewReader("isi file"), 8, "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	reader, err := storage.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	content, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(content) != "isi file" {
+		t.Errorf("Get() = %q, want %q", content, "isi file")
+	}
+
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := storage.Get(ctx, key); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Get() setelah Delete() error = %v, want ErrFileNotFound", err)
+	}
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() file yang tidak ada error = %v, want nil", err)
+	}
+	if err := storage.Put(ctx, "../luar.txt", strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidStorageKey) {
+		t.Errorf("Put() key di luar root error = %v, want ErrInvalidStorageKey", err)
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxMentionsPerMessage membatasi jumlah mention berbeda yang diproses per pesan.
+	maxMentionsPerMessage = 50
+	// notificationPreviewLength adalah panjang maksimum (dalam karakter) potongan isi pesan di notifikasi.
+	notificationPreviewLength = 140
+)
+
+// mentionPattern mengenali @user:<id>, @role:<id>, @channel dan @username. Karakter sebelum @ tidak boleh
+// huruf/angka agar alamat email seperti john@example.com tidak dianggap mention.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(?:(user|role):([0-9a-fA-F]{24})|([A-Za-z0-9_][A-Za-z0-9_.\-]*))`)
+
+// ParsedMentions berisi mention mentah hasil parsing isi pesan, sebelum divalidasi terhadap bisnis channel.
+type ParsedMentions struct {
+	UserIDs   []primitive.ObjectID
+	RoleIDs   []primitive.ObjectID
+	Usernames []string
+	Channel   bool
+}
+
+// Empty mengembalikan true jika tidak ada mention sama sekali.
+func (p ParsedMentions) Empty() bool {
+	return len(p.UserIDs) == 0 && len(p.RoleIDs) == 0 && len(p.Usernames) == 0 && !p.Channel
+}
+
+// ParseMentions mengambil mention dari isi pesan. Mention yang sama hanya dicatat sekali dan jumlah
+// mention dibatasi maxMentionsPerMessage.
+func ParseMentions(content string) ParsedMentions {
+	var parsed ParsedMentions
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		if len(seen) >= maxMentionsPerMessage {
+			break
+		}
+		kind, hexID := match[1], strings.ToLower(match[2])
+		username := strings.TrimRight(match[3], ".-")
+
+		switch {
+		case kind != "":
+			key := kind + ":" + hexID
+			if seen[key] {
+				continue
+			}
+			id, err := primitive.ObjectIDFromHex(hexID)
+			if err != nil {
+				continue
+			}
+			seen[key] = true
+			if kind == model.MentionKindUser {
+				parsed.UserIDs = append(parsed.UserIDs, id)
+			} else {
+				parsed.RoleIDs = append(parsed.RoleIDs, id)
+			}
+		case username == model.MentionKindChannel:
+			parsed.Channel = true
+		case username != "":
+			key := "username:" + username
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			parsed.Usernames = append(parsed.Usernames, username)
+		}
+	}
+	return parsed
+}
+
+// NotificationService adalah antarmuka untuk mengubah mention pada pesan menjadi notifikasi in-app.
+type NotificationService interface {
+	// ResolveMentions mem-parsing isi pesan lalu hanya menyimpan mention yang valid untuk bisnis channel:
+	// user yang merupakan anggota bisnis dan role milik bisnis tersebut. Mengembalikan nil jika tidak ada mention.
+	ResolveMentions(ctx context.Context, channelID primitive.ObjectID, content string) (*model.MessageMentions, error)
+	// NotifyMentions membuat satu notifikasi per penerima dari message.Mentions (pengirim tidak ikut)
+	// dan mengembalikan notifikasi yang tersimpan.
+	NotifyMentions(ctx context.Context, message *model.Message) ([]model.Notification, error)
+}
+
+type notificationServiceImpl struct {
+	notificationRepo repository.NotificationRepository
+	channelRepo      repository.ChannelRepository
+	businessRepo     repository.BusinessRepository
+	roleRepo         *repository.RoleRepository
+	userRepo         repository.UserRepository
+}
+
+// NewNotificationService membuat instance baru dari NotificationService.
+func NewNotificationService(notificationRepo repository.NotificationRepository, channelRepo repository.ChannelRepository, businessRepo repository.BusinessRepository, roleRepo *repository.RoleRepository, userRepo repository.UserRepository) NotificationService {
+	return &notificationServiceImpl{
+		notificationRepo: notificationRepo,
+		channelRepo:      channelRepo,
+		businessRepo:     businessRepo,
+		roleRepo:         roleRepo,
+		userRepo:         userRepo,
+	}
+}
+
+// ResolveMentions mengubah mention mentah menjadi ID. Mention yang tidak dikenal diabaikan tanpa error,
+// sehingga teks seperti "@besok" tidak menggagalkan pengiriman pesan.
+func (s *notificationServiceImpl) ResolveMentions(ctx context.Context, channelID primitive.ObjectID, content string) (*model.MessageMentions, error) {
+	parsed := ParseMentions(content)
+	if parsed.Empty() {
+		return nil, nil
+	}
+
+	channel, err := s.channelRepo.GetChannelByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, nil
+	}
+	business, err := s.businessRepo.GetBusinessByID(ctx, channel.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, nil
+	}
+
+	mentions := &model.MessageMentions{Channel: parsed.Channel}
+	added := make(map[primitive.ObjectID]bool)
+	addUser := func(user *model.User) {
+		if user == nil || added[user.ID] || !isBusinessMember(user, business) {
+			return
+		}
+		added[user.ID] = true
+		mentions.UserIDs = append(mentions.UserIDs, user.ID)
+	}
+
+	for _, userID := range parsed.UserIDs {
+		user, err := s.userRepo.FindUserByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		addUser(user)
+	}
+	for _, username := range parsed.Usernames {
+		user, err := s.userRepo.FindUserByUsername(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+		addUser(user)
+	}
+	for _, roleID := range parsed.RoleIDs {
+		role, err := s.roleRepo.GetRoleByID(ctx, roleID)
+		if err != nil {
+			return nil, err
+		}
+		if role != nil && role.BusinessID == business.ID {
+			mentions.RoleIDs = append(mentions.RoleIDs, role.ID)
+		}
+	}
+
+	if len(mentions.UserIDs) == 0 && len(mentions.RoleIDs) == 0 && !mentions.Channel {
+		return nil, nil
+	}
+	return mentions, nil
+}
+
+// NotifyMentions menentukan penerima dari mention. Jika satu user tercakup beberapa mention, jenis yang
+// paling spesifik dipakai: user, lalu role, lalu channel.
+func (s *notificationServiceImpl) NotifyMentions(ctx context.Context, message *model.Message) ([]model.Notification, error) {
+	if message.Mentions == nil {
+		return nil, nil
+	}
+	channel, err := s.channelRepo.GetChannelByID(ctx, message.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, nil
+	}
+	businessHex := channel.BusinessID.Hex()
+
+	recipients := make(map[primitive.ObjectID]string)
+	var order []primitive.ObjectID
+	setRecipient := func(userID primitive.ObjectID, kind string) {
+		if _, exists := recipients[userID]; !exists {
+			order = append(order, userID)
+		}
+		recipients[userID] = kind
+	}
+
+	if message.Mentions.Channel {
+		members, err := s.userRepo.FindUsersByBusinessID(ctx, businessHex)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			setRecipient(member.ID, model.MentionKindChannel)
+		}
+		// Pemilik bisnis belum tentu tercantum di BusinessIDs miliknya sendiri
+		business, err := s.businessRepo.GetBusinessByID(ctx, channel.BusinessID)
+		if err != nil {
+			return nil, err
+		}
+		if business != nil {
+			if ownerID, err := primitive.ObjectIDFromHex(business.OwnerID); err == nil {
+				if _, exists := recipients[ownerID]; !exists {
+					setRecipient(ownerID, model.MentionKindChannel)
+				}
+			}
+		}
+	}
+	if len(message.Mentions.RoleIDs) > 0 {
+		roleHexIDs := make([]string, len(message.Mentions.RoleIDs))
+		for i, roleID := range message.Mentions.RoleIDs {
+			roleHexIDs[i] = roleID.Hex()
+		}
+		users, err := s.userRepo.FindUsersByBusinessRoles(ctx, businessHex, roleHexIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			setRecipient(user.ID, model.MentionKindRole)
+		}
+	}
+	for _, userID := range message.Mentions.UserIDs {
+		setRecipient(userID, model.MentionKindUser)
+	}
+
+	preview := notificationPreview(message.Content)
+	notifications := make([]model.Notification, 0, len(order))
+	for _, userID := range order {
+		if userID == message.UserID {
+			continue
+		}
+		notifications = append(notifications, model.Notification{
+			UserID:      userID,
+			ActorID:     message.UserID,
+			Type:        model.NotificationTypeMention,
+			MentionKind: recipients[userID],
+			BusinessID:  channel.BusinessID,
+			ChannelID:   message.ChannelID,
+			MessageID:   message.ID,
+			ParentID:    message.ParentID,
+			Preview:     preview,
+		})
+	}
+	if err := s.notificationRepo.CreateNotifications(ctx, notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// isBusinessMember memeriksa apakah user adalah pemilik bisnis atau memiliki bisnis di BusinessIDs.
+func isBusinessMember(user *model.User, business *model.Business) bool {
+	if business.OwnerID == user.ID.Hex() {
+		return true
+	}
+	for _, id := range user.BusinessIDs {
+		if id == business.ID.Hex() {
+			return true
+		}
+	}
+	return false
+}
+
+// notificationPreview memotong isi pesan menjadi notificationPreviewLength karakter.
+func notificationPreview(content string) string {
+	runes := []rune(strings.TrimSpace(content))
+	if len(runes) <= notificationPreviewLength {
+		return string(runes)
+	}
+	return string(runes[:notificationPreviewLength]) + "…"
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseMentions(t *testing.T) {
+	userA, userB := primitive.NewObjectID(), primitive.NewObjectID()
+	role := primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		content string
+		want    ParsedMentions
+	}{
+		{name: "tanpa mention", content: "halo semua"},
+		{name: "mention user lewat ID", content: "tolong cek @user:" + userA.Hex(), want: ParsedMentions{UserIDs: []primitive.ObjectID{userA}}},
+		{name: "ID huruf besar", content: "@user:" + strings.ToUpper(userA.Hex()), want: ParsedMentions{UserIDs: []primitive.ObjectID{userA}}},
+		{name: "mention role", content: "(@role:" + role.Hex() + ")", want: ParsedMentions{RoleIDs: []primitive.ObjectID{role}}},
+		{name: "mention channel", content: "@channel rapat dimulai", want: ParsedMentions{Channel: true}},
+		{name: "username tanpa tanda baca penutup", content: "terima kasih @budi.santoso.", want: ParsedMentions{Usernames: []string{"budi.santoso"}}},
+		{name: "alamat email bukan mention", content: "kirim ke john@example.com"},
+		{name: "@@ bukan mention", content: "@@budi"},
+		{name: "mention di awal baris berikutnya", content: "halo\n@budi", want: ParsedMentions{Usernames: []string{"budi"}}},
+		{
+			name:    "duplikat dicatat sekali dengan urutan kemunculan",
+			content: fmt.Sprintf("@user:%s @user:%s @user:%s @ani @ani", userB.Hex(), userA.Hex(), userB.Hex()),
+			want:    ParsedMentions{UserIDs: []primitive.ObjectID{userB, userA}, Usernames: []string{"ani"}},
+		},
+		{
+			name:    "jenis mention berbeda",
+			content: fmt.Sprintf("@channel @role:%s @user:%s @ani", role.Hex(), userA.Hex()),
+			want:    ParsedMentions{UserIDs: []primitive.ObjectID{userA}, RoleIDs: []primitive.ObjectID{role}, Usernames: []string{"ani"}, Channel: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseMentions(tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+			if got.Empty() != reflect.DeepEqual(tt.want, ParsedMentions{}) {
+				t.Errorf("ParseMentions(%q).Empty() = %v", tt.content, got.Empty())
+			}
+		})
+	}
+}
+
+func TestParseMentionsLimit(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < maxMentionsPerMessage+10; i++ {
+		fmt.Fprintf(&sb, "@user%d ", i)
+	}
+	got := ParseMentions(sb.String())
+	if len(got.Usernames) != maxMentionsPerMessage {
+		t.Errorf("ParseMentions() = %d mention, want %d", len(got.Usernames), maxMentionsPerMessage)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrMessageNotInChannel dikembalikan jika pesan untuk posisi baca tidak ada, berada di channel lain,
+// atau merupakan balasan thread.
+var ErrMessageNotInChannel = errors.New("pesan utama tidak ditemukan di channel ini")
+
+// ChannelUnread berisi posisi baca dan hitungan belum dibaca user pada satu channel.
+type ChannelUnread struct {
+	ChannelID         primitive.ObjectID
+	UnreadCount       int64
+	MentionCount      int64
+	LastReadMessageID *primitive.ObjectID
+	LastReadAt        *time.Time
+}
+
+// ReadStateService adalah antarmuka untuk posisi baca (read cursor) per channel dan hitungan belum dibaca.
+type ReadStateService interface {
+	// MarkRead memajukan posisi baca user pada channel ke messageID, atau ke pesan utama terbaru jika
+	// messageID nil. Notifikasi mention sampai posisi tersebut ikut ditandai dibaca.
+	MarkRead(ctx context.Context, userID, channelID primitive.ObjectID, messageID *primitive.ObjectID) (*ChannelUnread, error)
+	// GetBusinessUnreadCounts menghitung pesan dan mention belum dibaca untuk semua channel bisnis.
+	GetBusinessUnreadCounts(ctx context.Context, businessID, userID primitive.ObjectID) ([]ChannelUnread, error)
+}
+
+type readStateServiceImpl struct {
+	repo        repository.ReadStateRepository
+	messageRepo repository.MessageRepository
+	channelRepo repository.ChannelRepository
+	channels    ChannelAccessService
+}
+
+// NewReadStateService membuat instance baru dari ReadStateService.
+func NewReadStateService(repo repository.ReadStateRepository, messageRepo repository.MessageRepository, channelRepo repository.ChannelRepository, channels ChannelAccessService) ReadStateService {
+	return &readStateServiceImpl{repo: repo, messageRepo: messageRepo, channelRepo: channelRepo, channels: channels}
+}
+
+// MarkRead memvalidasi pesan tujuan lalu menyimpan posisi baca. Channel tanpa pesan tidak mengubah apa pun.
+func (s *readStateServiceImpl) MarkRead(ctx context.Context, userID, channelID primitive.ObjectID, messageID *primitive.ObjectID) (*ChannelUnread, error) {
+	channel, err := s.channelRepo.GetChannelByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotFound, channelID.Hex())
+	}
+
+	if messageID == nil {
+		messageID, err = s.repo.GetLatestMessageID(ctx, channelID)
+		if err != nil {
+			return nil, err
+		}
+		if messageID == nil {
+			return &ChannelUnread{ChannelID: channelID}, nil
+		}
+	} else {
+		message, err := s.messageRepo.GetMessageByID(ctx, *messageID)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil || message.ChannelID != channelID || message.ParentID != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMessageNotInChannel, messageID.Hex())
+		}
+	}
+
+	state, err := s.repo.MarkRead(ctx, userID, channelID, channel.BusinessID, *messageID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.MarkMentionsRead(ctx, userID, channelID, state.LastReadMessageID); err != nil {
+		return nil, err
+	}
+
+	unread, err := s.repo.CountUnreadMessages(ctx, map[primitive.ObjectID]primitive.ObjectID{channelID: state.LastReadMessageID})
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := s.repo.CountUnreadMentions(ctx, userID, channel.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+	return &ChannelUnread{
+		ChannelID:         channelID,
+		UnreadCount:       unread[channelID],
+		MentionCount:      mentions[channelID],
+		LastReadMessageID: &state.LastReadMessageID,
+		LastReadAt:        &state.LastReadAt,
+	}, nil
+}
+
+// GetBusinessUnreadCounts memakai dua aggregation (pesan dan mention) untuk seluruh channel bisnis,
+// bukan satu query per channel. Pesan hanya dihitung untuk channel bertipe messages.
+func (s *readStateServiceImpl) GetBusinessUnreadCounts(ctx context.Context, businessID, userID primitive.ObjectID) ([]ChannelUnread, error) {
+	member, err := s.channels.IsBusinessMember(ctx, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("%w: %s", ErrNotBusinessMember, businessID.Hex())
+	}
+
+	channels, err := s.channelRepo.GetChannelsByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.repo.GetReadStatesByBusiness(ctx, userID, businessID)
+	if err != nil {
+		return nil, err
+	}
+	stateByChannel := make(map[primitive.ObjectID]model.ChannelReadState, len(states))
+	for _, state := range states {
+		stateByChannel[state.ChannelID] = state
+	}
+
+	cursors := make(map[primitive.ObjectID]primitive.ObjectID)
+	for _, channel := range channels {
+		if channel.Type == ChannelTypeMessages {
+			cursors[channel.ID] = stateByChannel[channel.ID].LastReadMessageID
+		}
+	}
+	unread, err := s.repo.CountUnreadMessages(ctx, cursors)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := s.repo.CountUnreadMentions(ctx, userID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ChannelUnread, len(channels))
+	for i, channel := range channels {
+		result[i] = ChannelUnread{
+			ChannelID:    channel.ID,
+			UnreadCount:  unread[channel.ID],
+			MentionCount: mentions[channel.ID],
+		}
+		if state, ok := stateByChannel[channel.ID]; ok {
+			lastReadMessageID, lastReadAt := state.LastReadMessageID, state.LastReadAt
+			result[i].LastReadMessageID = &lastReadMessageID
+			result[i].LastReadAt = &lastReadAt
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,534 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"backend_my_manajer/dto"
+	"backend_my_manajer/model"
+	"backend_my_manajer/repository"
+	"backend_my_manajer/utils"
+)
+
+/*
+Skema konfigurasi laporan (ReportVisualizationConfig):
+
+	filterConfig: {"match": "all|any", "conditions": [{"columnId": "...", "operator": "equals", "value": ...}]}
+	    Lihat RowFilter di database_row_filter.go untuk daftar operator.
+
+	chartConfig (reportType *_chart): {
+	    "groupBy": "<columnId>",               // wajib, menjadi kategori/sumbu X
+	    "dateBucket": "day|week|month|year",   // opsional, hanya untuk groupBy kolom date
+	    "metrics": [{"columnId": "<columnId>", "aggregation": "sum|avg|count|min|max", "label": "Total"}]
+	}
+
+	cardConfig (reportType "card"): {"metrics": [...]}  // satu card per metric
+
+	tableConfig (reportType "table"):
+	    - dengan "groupBy" + "metrics": satu baris per grup (sama seperti chart)
+	    - tanpa groupBy: baris mentah hasil filter, {"columns": ["<columnId>", ...], "limit": 100}
+
+Jika "metrics" kosong, digunakan satu metric count atas semua baris.
+*/
+
+// Tipe laporan yang didukung.
+var validReportTypes = map[string]bool{
+	"card":       true,
+	"table":      true,
+	"bar_chart":  true,
+	"line_chart": true,
+	"area_chart": true,
+	"pie_chart":  true,
+}
+
+// Agregasi yang didukung.
+const (
+	AggregationSum   = "sum"
+	AggregationAvg   = "avg"
+	AggregationCount = "count"
+	AggregationMin   = "min"
+	AggregationMax   = "max"
+)
+
+// emptyGroupLabel adalah label kategori untuk baris yang nilai kolom groupBy-nya kosong.
+const emptyGroupLabel = "(empty)"
+
+var (
+	// ErrInvalidReportConfig dikembalikan jika konfigurasi laporan tidak valid.
+	ErrInvalidReportConfig = errors.New("konfigurasi laporan tidak valid")
+	// ErrReportSourceNotFound dikembalikan jika database sumber laporan tidak ditemukan.
+	ErrReportSourceNotFound = errors.New("database sumber laporan tidak ditemukan")
+)
+
+// ReportMetric adalah satu agregasi yang dihitung oleh laporan.
+type ReportMetric struct {
+	ColumnID    string `json:"columnId,omitempty"` // Boleh kosong untuk aggregation "count" (menghitung baris)
+	Aggregation string `json:"aggregation"`
+	Label       string `json:"label,omitempty"`
+}
+
+// reportAggregationConfig adalah bentuk terstruktur dari ChartConfig, CardConfig dan TableConfig.
+type reportAggregationConfig struct {
+	GroupBy    string         `json:"groupBy,omitempty"`
+	DateBucket string         `json:"dateBucket,omitempty"`
+	Metrics    []ReportMetric `json:"metrics,omitempty"`
+	Columns    []string       `json:"columns,omitempty"`
+	Limit      int            `json:"limit,omitempty"`
+}
+
+// ReportService adalah antarmuka untuk layanan evaluasi laporan.
+type ReportService interface {
+	// ValidateConfiguration memeriksa konfigurasi terhadap database sumber dan mengembalikan database tersebut.
+	ValidateConfiguration(ctx context.Context, config *model.ReportVisualizationConfig) (*model.Database, error)
+	// GenerateReportData mengevaluasi laporan terhadap baris-baris database sumbernya.
+	GenerateReportData(ctx context.Context, report *model.Report) (*dto.ReportDataResponse, error)
+}
+
+type reportServiceImpl struct {
+	dbRepo repository.DatabaseRepository
+}
+
+// NewReportService membuat instance baru dari ReportService.
+func NewReportService(dbRepo repository.DatabaseRepository) ReportService {
+	return &reportServiceImpl{dbRepo: dbRepo}
+}
+
+// ValidateConfiguration memeriksa tipe laporan, filter dan konfigurasi agregasi terhadap kolom database sumber.
+func (s *reportServiceImpl) ValidateConfiguration(ctx context.Context, config *model.ReportVisualizationConfig) (*model.Database, error) {
+	database, err := s.loadSourceDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := parseReportConfig(config, database.DatabaseData.Columns); err != nil {
+		return nil, err
+	}
+	return database, nil
+}
+
+// GenerateReportData memuat database sumber, menerapkan filter lalu mengelompokkan dan mengagregasi
+// baris sesuai tipe laporan.
+func (s *reportServiceImpl) GenerateReportData(ctx context.Context, report *model.Report) (*dto.ReportDataResponse, error) {
+	config := &report.Configuration
+	database, err := s.loadSourceDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := database.DatabaseData.Columns
+	filter, aggConfig, err := parseReportConfig(config, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := FilterRows(columns, database.DatabaseData.Rows, filter)
+	result := &dto.ReportDataResponse{
+		ReportID:         report.ID.Hex(),
+		ReportType:       config.ReportType,
+		SourceDatabaseID: database.ID.Hex(),
+		TotalRows:        len(database.DatabaseData.Rows),
+		MatchedRows:      len(rows),
+		GeneratedAt:      time.Now(),
+	}
+
+	columnsByID := indexColumns(columns)
+	switch {
+	case config.ReportType == "card":
+		result.Cards = buildReportCards(rows, aggConfig.Metrics)
+	case config.ReportType == "table" && aggConfig.GroupBy == "":
+		result.Table = buildReportRawTable(columns, columnsByID, rows, aggConfig)
+	case config.ReportType == "table":
+		result.Table = buildReportGroupedTable(columnsByID, rows, aggConfig)
+	default:
+		result.Chart = buildReportChart(columnsByID, rows, aggConfig)
+	}
+
+	utils.LogInfo("Berhasil mengevaluasi laporan %s (%s): %d dari %d baris", report.ID.Hex(), config.ReportType, len(rows), len(database.DatabaseData.Rows))
+	return result, nil
+}
+
+// loadSourceDatabase memeriksa tipe laporan lalu memuat database sumbernya.
+func (s *reportServiceImpl) loadSourceDatabase(ctx context.Context, config *model.ReportVisualizationConfig) (*model.Database, error) {
+	if !validReportTypes[config.ReportType] {
+		return nil, fmt.Errorf("%w: reportType '%s' tidak didukung", ErrInvalidReportConfig, config.ReportType)
+	}
+	database, err := s.dbRepo.GetDatabaseByID(ctx, config.SourceDatabaseID)
+	if err != nil {
+		return nil, err
+	}
+	if database == nil {
+		return nil, ErrReportSourceNotFound
+	}
+	// Kolom rollup dan formula dihitung lebih dulu lalu diperlakukan sesuai tipe hasilnya (mis. number untuk agregasi)
+	ComputeDerivedColumns(ctx, s.dbRepo, database)
+	database.DatabaseData.Columns = computedResultColumns(database.DatabaseData.Columns)
+	return database, nil
+}
+
+// parseReportConfig memilih konfigurasi sesuai tipe laporan, lalu memvalidasi filter, groupBy dan metrics.
+func parseReportConfig(config *model.ReportVisualizationConfig, columns []model.DatabaseColumn) (*RowFilter, *reportAggregationConfig, error) {
+	filter, err := ParseRowFilter(config.FilterConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidReportConfig, err)
+	}
+	if err := filter.Validate(columns); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidReportConfig, err)
+	}
+
+	raw := config.ChartConfig
+	switch config.ReportType {
+	case "card":
+		raw = config.CardConfig
+	case "table":
+		raw = config.TableConfig
+	}
+
+	aggConfig := &reportAggregationConfig{}
+	if len(raw) > 0 {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidReportConfig, err)
+		}
+		if err := json.Unmarshal(data, aggConfig); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidReportConfig, err)
+		}
+	}
+	if len(aggConfig.Metrics) == 0 {
+		aggConfig.Metrics = []ReportMetric{{Aggregation: AggregationCount, Label: "Count"}}
+	}
+
+	columnsByID := indexColumns(columns)
+	isChart := config.ReportType != "card" && config.ReportType != "table"
+	if isChart && aggConfig.GroupBy == "" {
+		return nil, nil, fmt.Errorf("%w: chartConfig.groupBy wajib diisi untuk laporan %s", ErrInvalidReportConfig, config.ReportType)
+	}
+	if aggConfig.GroupBy != "" {
+		groupCol, ok := columnsByID[aggConfig.GroupBy]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: kolom groupBy '%s' tidak ditemukan", ErrInvalidReportConfig, aggConfig.GroupBy)
+		}
+		if aggConfig.DateBucket != "" {
+			if groupCol.Type != "date" {
+				return nil, nil, fmt.Errorf("%w: dateBucket hanya dapat digunakan untuk groupBy kolom date", ErrInvalidReportConfig)
+			}
+			switch aggConfig.DateBucket {
+			case "day", "week", "month", "year":
+			default:
+				return nil, nil, fmt.Errorf("%w: dateBucket '%s' tidak dikenal", ErrInvalidReportConfig, aggConfig.DateBucket)
+			}
+		}
+	}
+	for _, colID := range aggConfig.Columns {
+		if _, ok := columnsByID[colID]; !ok {
+			return nil, nil, fmt.Errorf("%w: kolom '%s' pada tableConfig.columns tidak ditemukan", ErrInvalidReportConfig, colID)
+		}
+	}
+	if aggConfig.Limit < 0 {
+		return nil, nil, fmt.Errorf("%w: limit tidak boleh negatif", ErrInvalidReportConfig)
+	}
+
+	for i := range aggConfig.Metrics {
+		metric := &aggConfig.Metrics[i]
+		switch metric.Aggregation {
+		case AggregationCount:
+		case AggregationSum, AggregationAvg, AggregationMin, AggregationMax:
+			if metric.ColumnID == "" {
+				return nil, nil, fmt.Errorf("%w: aggregation '%s' membutuhkan columnId", ErrInvalidReportConfig, metric.Aggregation)
+			}
+		default:
+			return nil, nil, fmt.Errorf("%w: aggregation '%s' tidak didukung", ErrInvalidReportConfig, metric.Aggregation)
+		}
+		if metric.ColumnID == "" {
+			if metric.Label == "" {
+				metric.Label = "Count"
+			}
+			continue
+		}
+		col, ok := columnsByID[metric.ColumnID]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: kolom metric '%s' tidak ditemukan", ErrInvalidReportConfig, metric.ColumnID)
+		}
+		if metric.Aggregation != AggregationCount && col.Type != "number" {
+			return nil, nil, fmt.Errorf("%w: aggregation '%s' membutuhkan kolom number, kolom '%s' bertipe %s", ErrInvalidReportConfig, metric.Aggregation, col.Name, col.Type)
+		}
+		if metric.Label == "" {
+			metric.Label = fmt.Sprintf("%s(%s)", metric.Aggregation, col.Name)
+		}
+	}
+	return filter, aggConfig, nil
+}
+
+// reportAccumulator menampung nilai sementara untuk satu metric pada satu grup.
+type reportAccumulator struct {
+	count    int
+	numCount int
+	sum      float64
+	min      float64
+	max      float64
+}
+
+// add menambahkan satu baris ke akumulator.
+func (a *reportAccumulator) add(metric ReportMetric, row model.DatabaseRow) {
+	if metric.ColumnID == "" {
+		a.count++
+		return
+	}
+	value := row.Values[metric.ColumnID]
+	if isEmptyValue(value) {
+		return
+	}
+	a.count++
+	f, ok := toFloat(value)
+	if !ok {
+		return
+	}
+	if a.numCount == 0 || f < a.min {
+		a.min = f
+	}
+	if a.numCount == 0 || f > a.max {
+		a.max = f
+	}
+	a.numCount++
+	a.sum += f
+}
+
+// result menghitung hasil akhir agregasi. Mengembalikan nil jika tidak ada angka untuk diagregasi.
+func (a *reportAccumulator) result(aggregation string) *float64 {
+	var value float64
+	switch aggregation {
+	case AggregationCount:
+		value = float64(a.count)
+	case AggregationSum:
+		value = a.sum
+	case AggregationAvg:
+		if a.numCount == 0 {
+			return nil
+		}
+		value = a.sum / float64(a.numCount)
+	case AggregationMin:
+		if a.numCount == 0 {
+			return nil
+		}
+		value = a.min
+	case AggregationMax:
+		if a.numCount == 0 {
+			return nil
+		}
+		value = a.max
+	}
+	return &value
+}
+
+// reportGroup adalah satu kategori hasil pengelompokan beserta akumulator tiap metric.
+type reportGroup struct {
+	label        string
+	sortValue    interface{}
+	accumulators []reportAccumulator
+}
+
+// groupReportRows mengelompokkan baris berdasarkan kolom groupBy dan mengagregasi setiap metric.
+// Urutan grup mengikuti tipe kolom: kronologis untuk date, numerik untuk number, urutan opsi untuk select.
+func groupReportRows(columnsByID map[string]*model.DatabaseColumn, rows []model.DatabaseRow, config *reportAggregationConfig) []*reportGroup {
+	groupCol := columnsByID[config.GroupBy]
+	groups := make(map[string]*reportGroup)
+	var ordered []*reportGroup
+
+	for _, row := range rows {
+		label, sortValue := reportGroupKey(groupCol, row.Values[config.GroupBy], config.DateBucket)
+		group, ok := groups[label]
+		if !ok {
+			group = &reportGroup{label: label, sortValue: sortValue, accumulators: make([]reportAccumulator, len(config.Metrics))}
+			groups[label] = group
+			ordered = append(ordered, group)
+		}
+		for i, metric := range config.Metrics {
+			group.accumulators[i].add(metric, row)
+		}
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		// Grup kosong selalu ditempatkan paling akhir
+		if a.sortValue == nil || b.sortValue == nil {
+			return a.sortValue != nil
+		}
+		switch x := a.sortValue.(type) {
+		case float64:
+			if y, ok := b.sortValue.(float64); ok {
+				return x < y
+			}
+		case int:
+			if y, ok := b.sortValue.(int); ok {
+				return x < y
+			}
+		}
+		return a.label < b.label
+	})
+	return ordered
+}
+
+// reportGroupKey menentukan label dan nilai urut grup untuk satu nilai sel.
+func reportGroupKey(col *model.DatabaseColumn, value interface{}, dateBucket string) (string, interface{}) {
+	if isEmptyValue(value) {
+		return emptyGroupLabel, nil
+	}
+	switch col.Type {
+	case "number":
+		if f, ok := toFloat(value); ok {
+			return CellDisplayValue(col, value), f
+		}
+	case "date":
+		if t, ok := toTime(value); ok {
+			// Format tanggal dipilih agar urutan leksikografis sama dengan urutan kronologis
+			t = t.UTC()
+			switch dateBucket {
+			case "year":
+				return t.Format("2006"), t.Format("2006")
+			case "month":
+				return t.Format("2006-01"), t.Format("2006-01")
+			case "week":
+				year, week := t.ISOWeek()
+				label := fmt.Sprintf("%04d-W%02d", year, week)
+				return label, label
+			default:
+				return t.Format("2006-01-02"), t.Format("2006-01-02")
+			}
+		}
+	case "select":
+		label := CellDisplayValue(col, value)
+		for _, opt := range col.Options {
+			if opt.Value == label {
+				return label, opt.Order
+			}
+		}
+		return label, len(col.Options)
+	}
+	label := CellDisplayValue(col, value)
+	return label, label
+}
+
+// buildReportChart menghasilkan kategori dan seri chart dari baris yang telah difilter.
+func buildReportChart(columnsByID map[string]*model.DatabaseColumn, rows []model.DatabaseRow, config *reportAggregationConfig) *dto.ReportChartData {
+	groups := groupReportRows(columnsByID, rows, config)
+	chart := &dto.ReportChartData{
+		GroupBy:    config.GroupBy,
+		Categories: make([]string, 0, len(groups)),
+		Series:     make([]dto.ReportChartSeries, 0, len(config.Metrics)),
+	}
+	for _, group := range groups {
+		chart.Categories = append(chart.Categories, group.label)
+	}
+	for i, metric := range config.Metrics {
+		series := dto.ReportChartSeries{
+			Name:        metric.Label,
+			ColumnID:    metric.ColumnID,
+			Aggregation: metric.Aggregation,
+			Data:        make([]*float64, 0, len(groups)),
+		}
+		for _, group := range groups {
+			series.Data = append(series.Data, group.accumulators[i].result(metric.Aggregation))
+		}
+		chart.Series = append(chart.Series, series)
+	}
+	return chart
+}
+
+// buildReportCards menghitung satu nilai untuk setiap metric atas seluruh baris yang telah difilter.
+func buildReportCards(rows []model.DatabaseRow, metrics []ReportMetric) []dto.ReportCardValue {
+	cards := make([]dto.ReportCardValue, 0, len(metrics))
+	for _, metric := range metrics {
+		var acc reportAccumulator
+		for _, row := range rows {
+			acc.add(metric, row)
+		}
+		cards = append(cards, dto.ReportCardValue{
+			Label:       metric.Label,
+			ColumnID:    metric.ColumnID,
+			Aggregation: metric.Aggregation,
+			Value:       acc.result(metric.Aggregation),
+		})
+	}
+	return cards
+}
+
+// buildReportGroupedTable menghasilkan satu baris tabel per grup dengan satu kolom per metric.
+func buildReportGroupedTable(columnsByID map[string]*model.DatabaseColumn, rows []model.DatabaseRow, config *reportAggregationConfig) *dto.ReportTableData {
+	groupCol := columnsByID[config.GroupBy]
+	table := &dto.ReportTableData{
+		Columns: []dto.ReportTableColumn{{ID: groupCol.ID.Hex(), Name: groupCol.Name, Type: groupCol.Type}},
+	}
+	for i, metric := range config.Metrics {
+		table.Columns = append(table.Columns, dto.ReportTableColumn{ID: fmt.Sprintf("metric_%d", i), Name: metric.Label, Type: "number"})
+	}
+
+	groups := groupReportRows(columnsByID, rows, config)
+	if config.Limit > 0 && len(groups) > config.Limit {
+		groups = groups[:config.Limit]
+	}
+	table.Rows = make([]map[string]interface{}, 0, len(groups))
+	for _, group := range groups {
+		tableRow := map[string]interface{}{groupCol.ID.Hex(): group.label}
+		for i, metric := range config.Metrics {
+			tableRow[fmt.Sprintf("metric_%d", i)] = group.accumulators[i].result(metric.Aggregation)
+		}
+		table.Rows = append(table.Rows, tableRow)
+	}
+	return table
+}
+
+// buildReportRawTable menghasilkan baris mentah hasil filter dengan kolom yang dipilih.
+// Nilai select diterjemahkan ke value opsi dan nilai date diformat RFC3339.
+func buildReportRawTable(columns []model.DatabaseColumn, columnsByID map[string]*model.DatabaseColumn, rows []model.DatabaseRow, config *reportAggregationConfig) *dto.ReportTableData {
+	selected := make([]*model.DatabaseColumn, 0, len(columns))
+	if len(config.Columns) > 0 {
+		for _, colID := range config.Columns {
+			selected = append(selected, columnsByID[colID])
+		}
+	} else {
+		for i := range columns {
+			selected = append(selected, &columns[i])
+		}
+		sort.SliceStable(selected, func(i, j int) bool { return selected[i].Order < selected[j].Order })
+	}
+
+	table := &dto.ReportTableData{Columns: make([]dto.ReportTableColumn, 0, len(selected))}
+	for _, col := range selected {
+		table.Columns = append(table.Columns, dto.ReportTableColumn{ID: col.ID.Hex(), Name: col.Name, Type: col.Type})
+	}
+
+	if config.Limit > 0 && len(rows) > config.Limit {
+		rows = rows[:config.Limit]
+	}
+	table.Rows = make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		tableRow := map[string]interface{}{"id": row.ID.Hex()}
+		for _, col := range selected {
+			tableRow[col.ID.Hex()] = reportCellValue(col, row.Values[col.ID.Hex()])
+		}
+		table.Rows = append(table.Rows, tableRow)
+	}
+	return table
+}
+
+// reportCellValue menormalkan nilai sel untuk ditampilkan pada tabel laporan.
+func reportCellValue(col *model.DatabaseColumn, value interface{}) interface{} {
+	if isEmptyValue(value) {
+		return nil
+	}
+	switch col.Type {
+	case "number":
+		if f, ok := toFloat(value); ok {
+			return f
+		}
+	case "boolean":
+		if b, ok := toBool(value); ok {
+			return b
+		}
+	case "date", "select":
+		return CellDisplayValue(col, value)
+	}
+	return value
+}